@@ -0,0 +1,75 @@
+/*
+File: main.go
+Author: lxp
+Description: 离线任务：按当前的徽章规则为已有用户补发徽章，新获得的徽章同样会推送通知
+用法：go run ./cmd/backfill-badges [-user <用户ID>]，不指定用户时处理所有有学习档案的用户
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"os/signal"
+	"syscall"
+	"zhixue-backend/internal/config"
+	"zhixue-backend/internal/database"
+	"zhixue-backend/internal/redis"
+	badge_repo "zhixue-backend/internal/repository/badge"
+	notification_repo "zhixue-backend/internal/repository/notification"
+	badge_service "zhixue-backend/internal/service/badge"
+	notification_service "zhixue-backend/internal/service/notification"
+	"zhixue-backend/logger"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	userID := flag.Int64("user", 0, "只处理指定用户，默认处理全部用户")
+	flag.Parse()
+
+	// 初始化日志系统
+	logger.InitLogger()
+	defer logger.Cleanup()
+
+	// 加载配置
+	cfg, err := config.LoadConfig("./configs")
+	if err != nil {
+		logger.Logger.Fatal("配置加载失败", zap.Error(err))
+	}
+
+	// 初始化数据库
+	if err := database.InitDatabase(&cfg.Database); err != nil {
+		logger.Logger.Fatal("数据库初始化失败", zap.Error(err))
+	}
+	defer database.CloseDatabase()
+
+	// 初始化Redis，用于推送获得徽章的通知
+	if err := redis.InitRedis(&cfg.Redis); err != nil {
+		logger.Logger.Fatal("Redis初始化失败", zap.Error(err))
+	}
+	defer redis.CloseRedis()
+
+	service := badge_service.NewBadgeService(
+		badge_repo.NewBadgeRepository(database.DB),
+		notification_service.NewNotificationService(notification_repo.NewNotificationRepository(database.DB), redis.Client))
+
+	// 收到中断信号时处理完当前用户后退出
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if *userID > 0 {
+		awarded, err := service.Evaluate(ctx, *userID)
+		if err != nil {
+			logger.Logger.Fatal("徽章补发失败", zap.Int64("user_id", *userID), zap.Error(err))
+		}
+		logger.Logger.Info("徽章补发完成", zap.Int64("user_id", *userID), zap.Int("awarded", awarded))
+		return
+	}
+
+	users, awarded, err := service.EvaluateAll(ctx)
+	if err != nil {
+		logger.Logger.Error("徽章补发中止", zap.Int("users", users), zap.Int("awarded", awarded), zap.Error(err))
+		return
+	}
+	logger.Logger.Info("徽章补发完成", zap.Int("users", users), zap.Int("awarded", awarded))
+}
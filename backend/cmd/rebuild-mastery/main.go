@@ -0,0 +1,67 @@
+/*
+File: main.go
+Author: lxp
+Description: 离线任务：根据 answer_records 历史重建知识点掌握度
+用法：go run ./cmd/rebuild-mastery [-user <用户ID>]，不指定用户时重建所有有答题记录的用户
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"os/signal"
+	"syscall"
+	"zhixue-backend/internal/config"
+	"zhixue-backend/internal/database"
+	knowledge_repo "zhixue-backend/internal/repository/knowledge"
+	mastery_repo "zhixue-backend/internal/repository/mastery"
+	mastery_service "zhixue-backend/internal/service/mastery"
+	"zhixue-backend/logger"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	userID := flag.Int64("user", 0, "只重建指定用户的掌握度，默认重建全部用户")
+	flag.Parse()
+
+	// 初始化日志系统
+	logger.InitLogger()
+	defer logger.Cleanup()
+
+	// 加载配置
+	cfg, err := config.LoadConfig("./configs")
+	if err != nil {
+		logger.Logger.Fatal("配置加载失败", zap.Error(err))
+	}
+
+	// 初始化数据库
+	if err := database.InitDatabase(&cfg.Database); err != nil {
+		logger.Logger.Fatal("数据库初始化失败", zap.Error(err))
+	}
+	defer database.CloseDatabase()
+
+	service := mastery_service.NewMasteryService(
+		mastery_repo.NewMasteryRepository(database.DB),
+		knowledge_repo.NewKnowledgeRepository(database.DB),
+		&cfg.Mastery)
+
+	if *userID > 0 {
+		if err := service.Rebuild(*userID); err != nil {
+			logger.Logger.Fatal("掌握度重建失败", zap.Int64("user_id", *userID), zap.Error(err))
+		}
+		logger.Logger.Info("掌握度重建完成", zap.Int64("user_id", *userID))
+		return
+	}
+
+	// 收到中断信号时处理完当前用户后退出
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	rebuilt, err := service.RebuildAll(ctx)
+	if err != nil {
+		logger.Logger.Error("掌握度重建中止", zap.Int("rebuilt", rebuilt), zap.Error(err))
+		return
+	}
+	logger.Logger.Info("掌握度重建完成", zap.Int("rebuilt", rebuilt))
+}
@@ -6,17 +6,57 @@ Description: 智学奇境后端API服务主入口 (Gin框架)
 package main
 
 import (
+	"context"
 	"fmt"
+	_ "time/tzdata" // 内嵌时区数据，运行环境缺少时区数据库时仍可按用户时区划分学习日
+	"zhixue-backend/internal/aiclient"
 	"zhixue-backend/internal/api/middleware"
 	"zhixue-backend/internal/config"
 	"zhixue-backend/internal/database"
+	"zhixue-backend/internal/event"
+	"zhixue-backend/internal/jwtkeys"
+	"zhixue-backend/internal/mail"
+	"zhixue-backend/internal/rbac"
 	"zhixue-backend/internal/redis"
+	"zhixue-backend/internal/session"
 	"zhixue-backend/logger"
 
 	// 依赖注入
 	"zhixue-backend/internal/api/handlers"
+	assignment_repo "zhixue-backend/internal/repository/assignment"
+	badge_repo "zhixue-backend/internal/repository/badge"
+	class_repo "zhixue-backend/internal/repository/class"
+	difficulty_repo "zhixue-backend/internal/repository/difficulty"
+	exam_repo "zhixue-backend/internal/repository/exam"
+	knowledge_repo "zhixue-backend/internal/repository/knowledge"
+	learning_repo "zhixue-backend/internal/repository/learning"
+	mastery_repo "zhixue-backend/internal/repository/mastery"
+	notebook_repo "zhixue-backend/internal/repository/notebook"
+	notification_repo "zhixue-backend/internal/repository/notification"
+	parent_repo "zhixue-backend/internal/repository/parent"
+	progression_repo "zhixue-backend/internal/repository/progression"
+	question_repo "zhixue-backend/internal/repository/question"
+	review_repo "zhixue-backend/internal/repository/review"
+	task_repo "zhixue-backend/internal/repository/task"
 	user_repo "zhixue-backend/internal/repository/user"
+	wallet_repo "zhixue-backend/internal/repository/wallet"
+	assignment_service "zhixue-backend/internal/service/assignment"
+	badge_service "zhixue-backend/internal/service/badge"
+	class_service "zhixue-backend/internal/service/class"
+	difficulty_service "zhixue-backend/internal/service/difficulty"
+	exam_service "zhixue-backend/internal/service/exam"
+	knowledge_service "zhixue-backend/internal/service/knowledge"
+	learning_service "zhixue-backend/internal/service/learning"
+	mastery_service "zhixue-backend/internal/service/mastery"
+	notebook_service "zhixue-backend/internal/service/notebook"
+	notification_service "zhixue-backend/internal/service/notification"
+	parent_service "zhixue-backend/internal/service/parent"
+	progression_service "zhixue-backend/internal/service/progression"
+	question_service "zhixue-backend/internal/service/question"
+	review_service "zhixue-backend/internal/service/review"
+	task_service "zhixue-backend/internal/service/task"
 	user_service "zhixue-backend/internal/service/user"
+	wallet_service "zhixue-backend/internal/service/wallet"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -103,18 +143,284 @@ func main() {
 	}
 
 	// 实例化Repository, Service, Handler
+	eventBus := event.NewBus()
+
+	sessionRegistry := session.NewRegistry(redis.Client, &cfg.Auth)
+	signingKeys, err := jwtkeys.LoadKeySet(&cfg.Auth)
+	if err != nil {
+		logger.Logger.Fatal("JWT签名密钥加载失败", zap.Error(err))
+	}
+	jwksHandler := handlers.NewJWKSHandler(signingKeys)
+
+	mailer, err := mail.NewSender(&cfg.Mail)
+	if err != nil {
+		logger.Logger.Fatal("邮件发送器初始化失败", zap.Error(err))
+	}
+
+	notificationService := notification_service.NewNotificationService(notification_repo.NewNotificationRepository(database.DB), redis.Client)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	badgeService := badge_service.NewBadgeService(badge_repo.NewBadgeRepository(database.DB), notificationService)
+
 	userRepository := user_repo.NewUserRepository(database.DB)
-	userService := user_service.NewUserService(userRepository, cfg)
+	userService := user_service.NewUserService(userRepository, sessionRegistry, signingKeys, mailer, badgeService, cfg)
 	userHandler := handlers.NewUserHandler(userService)
+	adminUserHandler := handlers.NewAdminUserHandler(userService)
+
+	learningRepository := learning_repo.NewLearningRepository(database.DB)
+	parentRepository := parent_repo.NewParentRepository(database.DB)
+	learningService := learning_service.NewLearningService(learningRepository, parent_service.NewSessionPolicy(parentRepository, learningRepository), eventBus, &cfg.Learning)
+	learningHandler := handlers.NewLearningHandler(learningService)
+
+	questionRepository := question_repo.NewQuestionRepository(database.DB)
+	notebookService := notebook_service.NewNotebookService(notebook_repo.NewNotebookRepository(database.DB), questionRepository, learningService)
+	notebookHandler := handlers.NewNotebookHandler(notebookService)
+
+	parentService := parent_service.NewParentService(parentRepository, userRepository, learningRepository, learningService, notebookService, &cfg.Parent)
+	parentHandler := handlers.NewParentHandler(parentService)
+
+	classRepository := class_repo.NewClassRepository(database.DB)
+	classService := class_service.NewClassService(classRepository, userRepository)
+	classHandler := handlers.NewClassHandler(classService)
+	adminClassHandler := handlers.NewAdminClassHandler(classService)
+
+	assignmentRepository := assignment_repo.NewAssignmentRepository(database.DB)
+	assignmentService := assignment_service.NewAssignmentService(assignmentRepository, classRepository, questionRepository, learningService)
+	assignmentHandler := handlers.NewAssignmentHandler(assignmentService)
+	adminAssignmentHandler := handlers.NewAdminAssignmentHandler(assignmentService)
+
+	examRepository := exam_repo.NewExamRepository(database.DB)
+	examService := exam_service.NewExamService(examRepository, questionRepository, userRepository, learningService, eventBus, &cfg.Learning)
+	examHandler := handlers.NewExamHandler(examService)
+
+	answerPolicy := question_service.AnswerPolicies{assignmentService, examService, notebookService}
+	questionService := question_service.NewQuestionService(questionRepository, userRepository, learningRepository, learningService, answerPolicy, eventBus)
+	questionHandler := handlers.NewQuestionHandler(questionService)
+	questionAdminService := question_service.NewAdminService(questionRepository)
+	adminQuestionHandler := handlers.NewAdminQuestionHandler(questionAdminService)
+
+	knowledgeRepository := knowledge_repo.NewKnowledgeRepository(database.DB)
+	knowledgeService := knowledge_service.NewKnowledgeService(knowledgeRepository)
+	knowledgeHandler := handlers.NewKnowledgeHandler(knowledgeService)
+	adminKnowledgeHandler := handlers.NewAdminKnowledgeHandler(knowledgeService)
+
+	masteryService := mastery_service.NewMasteryService(mastery_repo.NewMasteryRepository(database.DB), knowledgeRepository, &cfg.Mastery)
+	masteryHandler := handlers.NewMasteryHandler(masteryService)
+	adminMasteryHandler := handlers.NewAdminMasteryHandler(masteryService)
+
+	walletService := wallet_service.NewWalletService(wallet_repo.NewWalletRepository(database.DB), redis.Client)
+	walletHandler := handlers.NewWalletHandler(walletService)
+
+	progressionService := progression_service.NewProgressionService(progression_repo.NewProgressionRepository(database.DB), walletService, eventBus, &cfg.Progression)
+	progressionHandler := handlers.NewProgressionHandler(progressionService)
+
+	reviewService := review_service.NewReviewService(review_repo.NewReviewRepository(database.DB), redis.Client, progressionService)
+	reviewHandler := handlers.NewReviewHandler(reviewService)
+
+	taskService := task_service.NewTaskService(task_repo.NewTaskRepository(database.DB), learningRepository, reviewService, walletService, progressionService)
+	taskHandler := handlers.NewTaskHandler(taskService)
+
+	aiClient := aiclient.NewClient(&cfg.AIService)
+	difficulty_service.RegisterEngine("ai", difficulty_service.NewAIEngineFactory(aiClient))
+	difficultyEngine, err := difficulty_service.NewEngine(&cfg.Difficulty)
+	if err != nil {
+		logger.Logger.Fatal("难度引擎初始化失败", zap.Error(err))
+	}
+	difficultyRepository := difficulty_repo.NewDifficultyRepository(database.DB)
+	difficultyService := difficulty_service.NewDifficultyService(difficultyRepository, difficultyEngine, &cfg.Difficulty)
+
+	// 订阅领域事件
+	eventBus.Subscribe(event.TopicAnswerSubmitted, difficultyService.HandleAnswerSubmitted)
+	eventBus.Subscribe(event.TopicAnswerSubmitted, masteryService.HandleAnswerSubmitted)
+	eventBus.Subscribe(event.TopicAnswerSubmitted, reviewService.HandleAnswerSubmitted)
+	eventBus.Subscribe(event.TopicAnswerSubmitted, taskService.HandleAnswerSubmitted) // 复习类任务依赖复习服务先完成计数
+	eventBus.Subscribe(event.TopicSessionEnded, taskService.HandleSessionEnded)
+	eventBus.Subscribe(event.TopicAnswerSubmitted, progressionService.HandleAnswerSubmitted)
+	eventBus.Subscribe(event.TopicSessionEnded, progressionService.HandleSessionEnded)
+	eventBus.Subscribe(event.TopicLevelUp, notificationService.HandleLevelUp)
+	// 徽章依赖学习档案中的连续天数与等级，需在成长服务之后判定
+	eventBus.Subscribe(event.TopicAnswerSubmitted, badgeService.HandleAnswerSubmitted)
+	eventBus.Subscribe(event.TopicSessionEnded, badgeService.HandleSessionEnded)
+	eventBus.Subscribe(event.TopicExamFinished, badgeService.HandleExamFinished)
+
+	// 启动后台任务
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	learningService.StartIdleSweeper(ctx)
+	examService.StartExpirySweeper(ctx)
+	difficultyService.StartScheduler(ctx)
+	signingKeys.StartRotation(ctx)
+
+	// 公开的JWKS端点，网关等服务从这里获取校验Token的公钥
+	r.GET("/.well-known/jwks.json", jwksHandler.GetJWKS)
 
 	// 注册用户系统路由
 	userRoutes := api.Group("/users")
 	{
 		userRoutes.POST("/register", userHandler.Register)
 		userRoutes.POST("/login", userHandler.Login)
+		userRoutes.POST("/refresh", userHandler.Refresh)
+		userRoutes.POST("/verify-email", userHandler.VerifyEmail)
+		userRoutes.POST("/password/forgot", userHandler.ForgotPassword)
+		userRoutes.POST("/password/reset", userHandler.ResetPassword)
 		userRoutes.POST("/logout", userHandler.Logout)
 		userRoutes.GET("/me", userHandler.GetMe)
 		userRoutes.PUT("/me", userHandler.UpdateMe)
+		userRoutes.GET("/me/sessions", userHandler.ListSessions)
+		userRoutes.DELETE("/me/sessions", userHandler.RevokeAllSessions)
+		userRoutes.DELETE("/me/sessions/:id", userHandler.RevokeSession)
+		userRoutes.POST("/me/verify-email", userHandler.SendVerificationEmail)
+		userRoutes.POST("/me/parent-invites", rbac.RequirePermission(rbac.PermParentInvite), parentHandler.CreateInvite)
+		userRoutes.GET("/me/parents", rbac.RequirePermission(rbac.PermParentInvite), parentHandler.ListParents)
+		userRoutes.GET("/me/mastery", rbac.RequirePermission(rbac.PermLearningSession), masteryHandler.GetMyMastery)
+		userRoutes.GET("/me/wallet", rbac.RequirePermission(rbac.PermLearningSession), walletHandler.GetMyWallet)
+		userRoutes.GET("/me/progress", rbac.RequirePermission(rbac.PermLearningSession), progressionHandler.GetMyProgress)
+		userRoutes.POST("/me/streak-freezes", rbac.RequirePermission(rbac.PermLearningSession), progressionHandler.PurchaseFreeze)
+		userRoutes.GET("/me/notifications", notificationHandler.ListNotifications)
+		userRoutes.POST("/me/notifications/read-all", notificationHandler.MarkAllRead)
+		userRoutes.POST("/me/notifications/:id/read", notificationHandler.MarkRead)
+	}
+
+	// 注册题库系统路由
+	questionRoutes := api.Group("/questions")
+	{
+		questionRoutes.GET("", rbac.RequirePermission(rbac.PermQuestionRead), questionHandler.ListQuestions)
+		questionRoutes.GET("/:id", rbac.RequirePermission(rbac.PermQuestionRead), questionHandler.GetQuestion)
+		questionRoutes.POST("/:id/answer", rbac.RequirePermission(rbac.PermQuestionAnswer), questionHandler.SubmitAnswer)
+	}
+	knowledgeRoutes := api.Group("/knowledge-points", rbac.RequirePermission(rbac.PermQuestionRead))
+	{
+		knowledgeRoutes.GET("", questionHandler.ListKnowledgePoints)
+		knowledgeRoutes.GET("/tree", knowledgeHandler.GetTree)
+		knowledgeRoutes.GET("/:id/subtree", knowledgeHandler.GetSubtree)
+		knowledgeRoutes.GET("/:id/breadcrumbs", knowledgeHandler.GetBreadcrumbs)
+		knowledgeRoutes.GET("/:id/prerequisites", knowledgeHandler.GetPrerequisites)
+	}
+
+	// 注册后台管理路由 (资源归属在服务层校验)
+	adminRoutes := api.Group("/admin", rbac.RequirePermission(rbac.PermAdminAccess), middleware.RequireEmailVerified(userService))
+	{
+		manage := rbac.RequirePermission(rbac.PermQuestionManage)
+		review := rbac.RequirePermission(rbac.PermQuestionReview)
+
+		adminRoutes.GET("/questions", adminQuestionHandler.ListQuestions)
+		adminRoutes.POST("/questions", manage, adminQuestionHandler.CreateQuestion)
+		adminRoutes.GET("/questions/:id", adminQuestionHandler.GetQuestion)
+		adminRoutes.PUT("/questions/:id", manage, adminQuestionHandler.UpdateQuestion)
+		adminRoutes.DELETE("/questions/:id", manage, adminQuestionHandler.DeleteQuestion)
+		adminRoutes.POST("/questions/:id/submit", manage, adminQuestionHandler.SubmitForReview)
+		adminRoutes.POST("/questions/:id/approve", review, adminQuestionHandler.ApproveQuestion)
+		adminRoutes.POST("/questions/:id/reject", review, adminQuestionHandler.RejectQuestion)
+
+		adminRoutes.POST("/users/:id/unlock", rbac.RequirePermission(rbac.PermUserManage), adminUserHandler.UnlockUser)
+		adminRoutes.POST("/users/:id/points", rbac.RequirePermission(rbac.PermUserManage), walletHandler.GrantPoints)
+
+		classes := adminRoutes.Group("/classes", rbac.RequirePermission(rbac.PermClassManage))
+		classes.GET("", adminClassHandler.ListClasses)
+		classes.POST("", adminClassHandler.CreateClass)
+		classes.GET("/:id", adminClassHandler.GetClass)
+		classes.PUT("/:id", adminClassHandler.UpdateClass)
+		classes.DELETE("/:id", adminClassHandler.DeleteClass)
+		classes.POST("/:id/join-code", adminClassHandler.RegenerateJoinCode)
+		classes.GET("/:id/members", adminClassHandler.ListMembers)
+		classes.DELETE("/:id/members/:user_id", adminClassHandler.RemoveMember)
+		classes.POST("/:id/members/:user_id/transfer", adminClassHandler.TransferMember)
+		classes.GET("/:id/stats", adminClassHandler.GetStats)
+
+		knowledgePoints := adminRoutes.Group("/knowledge-points", rbac.RequirePermission(rbac.PermKnowledgeManage))
+		knowledgePoints.POST("/:id/prerequisites", adminKnowledgeHandler.AddPrerequisite)
+		knowledgePoints.DELETE("/:id/prerequisites/:prerequisite_id", adminKnowledgeHandler.RemovePrerequisite)
+		knowledgePoints.GET("/:id/mastery-params", adminMasteryHandler.GetParams)
+		knowledgePoints.PUT("/:id/mastery-params", adminMasteryHandler.UpdateParams)
+		knowledgePoints.DELETE("/:id/mastery-params", adminMasteryHandler.ResetParams)
+
+		assignments := adminRoutes.Group("/assignments", rbac.RequirePermission(rbac.PermClassManage))
+		assignments.GET("", adminAssignmentHandler.ListAssignments)
+		assignments.POST("", adminAssignmentHandler.CreateAssignment)
+		assignments.GET("/:id", adminAssignmentHandler.GetAssignment)
+		assignments.PUT("/:id", adminAssignmentHandler.UpdateAssignment)
+		assignments.DELETE("/:id", adminAssignmentHandler.DeleteAssignment)
+		assignments.GET("/:id/overview", adminAssignmentHandler.GetOverview)
+	}
+
+	// 注册学习会话路由
+	sessionRoutes := api.Group("/learning-sessions", rbac.RequirePermission(rbac.PermLearningSession))
+	{
+		sessionRoutes.POST("", learningHandler.StartSession)
+		sessionRoutes.GET("", learningHandler.ListSessions)
+		sessionRoutes.GET("/:session_id", learningHandler.GetSession)
+		sessionRoutes.POST("/:session_id/pause", learningHandler.PauseSession)
+		sessionRoutes.POST("/:session_id/resume", learningHandler.ResumeSession)
+		sessionRoutes.POST("/:session_id/finish", learningHandler.FinishSession)
+		sessionRoutes.POST("/:session_id/interrupt", learningHandler.InterruptSession)
+	}
+	api.GET("/answer-records", rbac.RequirePermission(rbac.PermLearningSession), learningHandler.ListAnswers)
+
+	// 注册错题复习路由 (复习作答仍通过题目答题接口)
+	reviewRoutes := api.Group("/reviews", rbac.RequirePermission(rbac.PermLearningSession))
+	{
+		reviewRoutes.GET("/due", reviewHandler.ListDue)
+		reviewRoutes.GET("/stats", reviewHandler.GetStats)
+	}
+
+	// 注册错题本路由 (错题练习作答仍通过题目答题接口，携带练习的会话ID)
+	notebookRoutes := api.Group("/notebook", rbac.RequirePermission(rbac.PermLearningSession))
+	{
+		notebookRoutes.GET("", notebookHandler.ListEntries)
+		notebookRoutes.GET("/groups", notebookHandler.ListGroups)
+		notebookRoutes.POST("/:question_id/resolve", notebookHandler.Resolve)
+		notebookRoutes.POST("/practice", notebookHandler.StartPractice)
+		notebookRoutes.GET("/practice/:session_id", notebookHandler.GetPractice)
+	}
+
+	// 注册游戏化任务路由 (任务进度由答题与学习会话事件推进)
+	taskRoutes := api.Group("/tasks", rbac.RequirePermission(rbac.PermLearningSession))
+	{
+		taskRoutes.GET("", taskHandler.ListTasks)
+		taskRoutes.GET("/:id", taskHandler.GetTask)
+		taskRoutes.POST("/:id/claim", taskHandler.ClaimTask)
+	}
+
+	// 注册限时考试路由 (答题仍通过题目答题接口，携带考试的会话ID)
+	examRoutes := api.Group("/exams", rbac.RequirePermission(rbac.PermLearningSession))
+	{
+		examRoutes.POST("", examHandler.StartExam)
+		examRoutes.GET("", examHandler.ListExams)
+		examRoutes.GET("/:session_id", examHandler.GetExam)
+		examRoutes.POST("/:session_id/submit", examHandler.SubmitExam)
+	}
+
+	// 注册学生端班级路由
+	classRoutes := api.Group("/classes", rbac.RequirePermission(rbac.PermClassJoin))
+	{
+		classRoutes.GET("", classHandler.ListJoinedClasses)
+		classRoutes.POST("/join", classHandler.JoinClass)
+		classRoutes.DELETE("/:id/membership", classHandler.LeaveClass)
+	}
+
+	// 注册学生端作业路由 (班级成员身份在服务层校验)
+	assignmentRoutes := api.Group("/assignments", rbac.RequirePermission(rbac.PermAssignmentDo))
+	{
+		assignmentRoutes.GET("", assignmentHandler.ListAssignments)
+		assignmentRoutes.GET("/:id", assignmentHandler.GetAssignment)
+		assignmentRoutes.POST("/:id/attempts", assignmentHandler.StartAttempt)
+		assignmentRoutes.POST("/:id/submit", assignmentHandler.SubmitAttempt)
+	}
+
+	// 注册家长端路由 (孩子归属在服务层校验)
+	parentRoutes := api.Group("/parent", rbac.RequirePermission(rbac.PermChildRead))
+	{
+		control := rbac.RequirePermission(rbac.PermChildControl)
+
+		parentRoutes.GET("/children", parentHandler.ListChildren)
+		parentRoutes.POST("/children", control, parentHandler.LinkChild)
+		parentRoutes.GET("/children/:id", parentHandler.GetChild)
+		parentRoutes.DELETE("/children/:id", control, parentHandler.UnlinkChild)
+		parentRoutes.GET("/children/:id/learning-sessions", parentHandler.ListChildSessions)
+		parentRoutes.GET("/children/:id/answer-records", parentHandler.ListChildAnswers)
+		parentRoutes.GET("/children/:id/notebook", parentHandler.ListChildNotebook)
+		parentRoutes.GET("/children/:id/notebook/groups", parentHandler.ListChildNotebookGroups)
+		parentRoutes.GET("/children/:id/controls", parentHandler.GetControls)
+		parentRoutes.PUT("/children/:id/controls", control, parentHandler.UpdateControls)
 	}
 
 	// 启动服务器
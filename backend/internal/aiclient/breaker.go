@@ -0,0 +1,95 @@
+/*
+File: breaker.go
+Author: lxp
+Description: AI服务调用的熔断器
+*/
+package aiclient
+
+import (
+	"sync"
+	"time"
+)
+
+// 熔断器状态
+const (
+	stateClosed   = "closed"    // 正常放行
+	stateOpen     = "open"      // 熔断中，直接拒绝
+	stateHalfOpen = "half_open" // 冷却结束，放行一个试探请求
+)
+
+// breaker 是一个简单的连续失败计数熔断器
+// 连续失败达到阈值后熔断，冷却期过后放行一个试探请求：成功则恢复，失败则重新熔断
+type breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     string
+	failures  int
+	openedAt  time.Time
+	probing   bool // 半开状态下是否已有试探请求在进行
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{threshold: threshold, cooldown: cooldown, state: stateClosed}
+}
+
+// Allow 判断当前是否允许发起请求
+func (b *breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case stateOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = stateHalfOpen
+		b.probing = true
+		return true
+	case stateHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// Success 记录一次成功调用
+func (b *breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = stateClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// Failure 记录一次失败调用
+func (b *breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == stateHalfOpen || b.failures >= b.threshold {
+		b.state = stateOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// Cancel 记录一次被调用方取消的请求，不影响失败计数
+func (b *breaker) Cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// State 返回熔断器当前状态
+func (b *breaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
@@ -0,0 +1,227 @@
+/*
+File: client.go
+Author: lxp
+Description: ai-service 的 Go 客户端，支持超时、指数退避重试、熔断和本地降级
+*/
+package aiclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"zhixue-backend/internal/config"
+	"zhixue-backend/logger"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrCircuitOpen = errors.New("ai service circuit breaker is open")
+)
+
+// 未配置时使用的默认值
+const (
+	defaultTimeout          = 30 * time.Second
+	defaultRetryBackoff     = 200 * time.Millisecond
+	defaultMaxRetryBackoff  = 2 * time.Second
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+)
+
+// ai-service 接口路径
+const (
+	pathAdjustDifficulty = "/ai/adjust-difficulty"
+)
+
+// StatusError 表示 AI 服务返回了非 2xx 状态码
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("ai service returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// retryable 5xx 和 429 视为临时错误，可以重试
+func (e *StatusError) retryable() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests
+}
+
+// Client 是 ai-service 的客户端，可被多个 goroutine 并发使用
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	retries    int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	breaker    *breaker
+}
+
+// NewClient 根据配置创建 AI 服务客户端
+// Timeout 作用于单次 HTTP 请求，整体耗时还受调用方 ctx 约束
+func NewClient(cfg *config.AIServiceConfig) *Client {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	backoff := cfg.RetryBackoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+	maxBackoff := cfg.MaxRetryBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxRetryBackoff
+	}
+	threshold := cfg.BreakerThreshold
+	if threshold <= 0 {
+		threshold = defaultBreakerThreshold
+	}
+	cooldown := cfg.BreakerCooldown
+	if cooldown <= 0 {
+		cooldown = defaultBreakerCooldown
+	}
+	retries := cfg.RetryCount
+	if retries < 0 {
+		retries = 0
+	}
+
+	return &Client{
+		baseURL:    strings.TrimRight(cfg.URL, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+		retries:    retries,
+		backoff:    backoff,
+		maxBackoff: maxBackoff,
+		breaker:    newBreaker(threshold, cooldown),
+	}
+}
+
+// AdjustDifficulty 调用 AI 服务计算推荐难度
+// AI 服务不可用 (熔断、超时、重试耗尽) 时使用本地规则降级，此时响应的 Fallback 为 true；
+// 仅当调用方 ctx 被取消时返回错误
+func (c *Client) AdjustDifficulty(ctx context.Context, req *DifficultyRequest) (*DifficultyResponse, error) {
+	var resp DifficultyResponse
+	err := c.post(ctx, pathAdjustDifficulty, req.UserID, "difficulty_adjustment", c.retries, req, &resp)
+	if err == nil {
+		return &resp, nil
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	logger.Logger.Warn("AI难度调节不可用，使用本地规则",
+		zap.String("user_id", req.UserID),
+		zap.Error(err))
+	return LocalAdjustDifficulty(req), nil
+}
+
+// TryAdjustDifficulty 只调用一次 AI 服务，不重试也不使用本地规则降级
+// 供对延迟敏感的调用方 (如答题链路) 配合短超时的 ctx 使用，失败时由调用方自行降级
+func (c *Client) TryAdjustDifficulty(ctx context.Context, req *DifficultyRequest) (*DifficultyResponse, error) {
+	var resp DifficultyResponse
+	if err := c.post(ctx, pathAdjustDifficulty, req.UserID, "difficulty_adjustment", 0, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// post 以 JSON 调用 AI 服务接口，失败时按指数退避最多重试 retries 次
+// 新增的 AI 接口 (如推荐) 只需定义请求/响应类型并复用该方法
+func (c *Client) post(ctx context.Context, path, userID, model string, retries int, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to encode ai request: %w", err)
+	}
+
+	start := time.Now()
+	attempts := 0
+	var lastErr error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			if err := c.wait(ctx, attempt); err != nil {
+				return err
+			}
+		}
+
+		if !c.breaker.Allow() {
+			return ErrCircuitOpen
+		}
+
+		attempts++
+		lastErr = c.doOnce(ctx, path, userID, body, out)
+		if lastErr == nil {
+			c.breaker.Success()
+			logger.LogAIRequest(userID, model, len(body), time.Since(start))
+			return nil
+		}
+		if errors.Is(ctx.Err(), context.Canceled) {
+			c.breaker.Cancel() // 调用方取消不代表服务异常
+			return ctx.Err()
+		}
+		if ctx.Err() != nil {
+			c.breaker.Failure() // 超出调用方时限说明服务响应过慢，计入熔断，避免后续请求继续等待
+			return ctx.Err()
+		}
+		var statusErr *StatusError
+		if errors.As(lastErr, &statusErr) && !statusErr.retryable() {
+			c.breaker.Success() // 服务可用，是请求本身的问题，不计入熔断
+			break
+		}
+		c.breaker.Failure()
+	}
+
+	logger.LogError("aiclient", path, lastErr, map[string]interface{}{
+		"user_id":  userID,
+		"attempts": attempts,
+		"breaker":  c.breaker.State(),
+	})
+	return lastErr
+}
+
+// doOnce 发起一次 HTTP 请求
+func (c *Client) doOnce(ctx context.Context, path, userID string, body []byte, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("user-id", userID) // ai-service 的请求日志中间件读取该头
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(msg)}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode ai response: %w", err)
+	}
+	return nil
+}
+
+// wait 在第 attempt 次重试前等待 backoff * 2^(attempt-1)，不超过 maxBackoff
+func (c *Client) wait(ctx context.Context, attempt int) error {
+	delay := c.backoff << (attempt - 1)
+	if delay <= 0 || delay > c.maxBackoff {
+		delay = c.maxBackoff
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,42 @@
+/*
+File: fallback.go
+Author: lxp
+Description: AI服务不可用时使用的本地确定性规则
+*/
+package aiclient
+
+import "math"
+
+// LocalAdjustDifficulty 在本地复现 ai-service /ai/adjust-difficulty 的规则
+// 修改 ai-service 的算法时需要同步修改这里，保证降级前后结果一致
+func LocalAdjustDifficulty(req *DifficultyRequest) *DifficultyResponse {
+	accuracy := float64(req.CorrectAnswers) / math.Max(float64(req.TotalAnswers), 1)
+
+	var newDifficulty float64
+	var reasoning string
+	switch {
+	case accuracy > 0.8:
+		newDifficulty = math.Min(req.CurrentDifficulty+0.2, 5.0)
+		reasoning = "准确率高，增加难度"
+	case accuracy < 0.5:
+		newDifficulty = math.Max(req.CurrentDifficulty-0.3, 1.0)
+		reasoning = "准确率低，降低难度"
+	default:
+		newDifficulty = req.CurrentDifficulty
+		reasoning = "难度适中，保持当前水平"
+	}
+
+	// 考虑响应时间
+	if req.AvgResponseTime > 30 {
+		newDifficulty = math.Max(newDifficulty-0.1, 1.0)
+		reasoning += "，响应时间长"
+	}
+
+	return &DifficultyResponse{
+		UserID:                req.UserID,
+		RecommendedDifficulty: newDifficulty,
+		Confidence:            math.Min(math.Abs(accuracy-0.65)*2, 1.0),
+		Reasoning:             reasoning,
+		Fallback:              true,
+	}
+}
@@ -0,0 +1,26 @@
+/*
+File: types.go
+Author: lxp
+Description: AI服务接口的请求与响应类型，字段与 ai-service 的 Pydantic 模型保持一致
+*/
+package aiclient
+
+// DifficultyRequest 对应 ai-service 的 UserPerformance
+type DifficultyRequest struct {
+	UserID            string  `json:"user_id"`
+	CorrectAnswers    int     `json:"correct_answers"`
+	TotalAnswers      int     `json:"total_answers"`
+	AvgResponseTime   float64 `json:"avg_response_time"` // 秒
+	CurrentDifficulty float64 `json:"current_difficulty"`
+}
+
+// DifficultyResponse 对应 ai-service 的 DifficultyResponse
+type DifficultyResponse struct {
+	UserID                string  `json:"user_id"`
+	RecommendedDifficulty float64 `json:"recommended_difficulty"`
+	Confidence            float64 `json:"confidence"`
+	Reasoning             string  `json:"reasoning"`
+
+	// Fallback 为 true 表示结果来自本地规则，而非 AI 服务
+	Fallback bool `json:"-"`
+}
@@ -0,0 +1,88 @@
+/*
+File: admin_question_dto.go
+Author: lxp
+Description: 题库后台管理相关的API数据传输对象 (DTOs)
+*/
+package dto
+
+import (
+	"time"
+	"zhixue-backend/models"
+)
+
+// ================== 请求 (Request) ==================
+
+// AdminListQuestionsQuery 定义后台获取题目列表的查询参数
+type AdminListQuestionsQuery struct {
+	PageQuery
+	ReviewStatus     string `form:"review_status" binding:"omitempty,oneof=draft reviewing approved rejected"`
+	QuestionType     string `form:"question_type" binding:"omitempty,oneof=single_choice multiple_choice fill_blank calculation proof"`
+	KnowledgePointID *int64 `form:"knowledge_point_id" binding:"omitempty,min=1"`
+	GradeLevel       *int   `form:"grade_level" binding:"omitempty,min=1,max=12"`
+	IsActive         *bool  `form:"is_active"`
+	AuthorID         *int64 `form:"author_id" binding:"omitempty,min=1"`
+	Keyword          string `form:"keyword" binding:"omitempty,max=100"`
+}
+
+// SaveQuestionRequest 定义创建/更新题目的请求结构体 (更新时为整体替换)
+type SaveQuestionRequest struct {
+	Title             string       `json:"title" binding:"required,max=200"`
+	Content           string       `json:"content" binding:"required"`
+	QuestionType      string       `json:"question_type" binding:"required,oneof=single_choice multiple_choice fill_blank calculation proof"`
+	Difficulty        float64      `json:"difficulty" binding:"required,min=1,max=5"`
+	GradeLevel        int          `json:"grade_level" binding:"required,min=1,max=12"`
+	EstimatedTime     int          `json:"estimated_time" binding:"omitempty,min=1"` // 预计用时 (秒)
+	CorrectAnswer     string       `json:"correct_answer" binding:"required"`
+	AnswerAnalysis    string       `json:"answer_analysis"`
+	Hints             models.JSONB `json:"hints"`
+	Choices           models.JSONB `json:"choices"`
+	Tags              models.JSONB `json:"tags"`
+	Source            string       `json:"source" binding:"max=100"`
+	KnowledgePointIDs []int64      `json:"knowledge_point_ids" binding:"omitempty,dive,min=1"`
+}
+
+// ReviewQuestionRequest 定义提交审核/审核通过/驳回时附带的审核意见
+type ReviewQuestionRequest struct {
+	Comment string `json:"comment" binding:"max=1000"`
+}
+
+// ================== 响应 (Response) ==================
+
+// AdminQuestionResponse 是后台管理使用的完整题目数据结构，包含答案与统计信息
+type AdminQuestionResponse struct {
+	ID              int64                 `json:"id"`
+	Title           string                `json:"title"`
+	Content         string                `json:"content"`
+	QuestionType    string                `json:"question_type"`
+	Difficulty      float64               `json:"difficulty"`
+	DifficultyLevel string                `json:"difficulty_level"`
+	GradeLevel      int                   `json:"grade_level"`
+	EstimatedTime   int                   `json:"estimated_time"`
+	CorrectAnswer   string                `json:"correct_answer"`
+	AnswerAnalysis  string                `json:"answer_analysis"`
+	Hints           models.JSONB          `json:"hints"`
+	Choices         models.JSONB          `json:"choices"`
+	Tags            models.JSONB          `json:"tags"`
+	Source          string                `json:"source"`
+	AuthorID        *int64                `json:"author_id"`
+	ReviewStatus    string                `json:"review_status"`
+	IsActive        bool                  `json:"is_active"`
+	UsageCount      int                   `json:"usage_count"`
+	CorrectRate     float64               `json:"correct_rate"`
+	AvgResponseTime int                   `json:"avg_response_time"`
+	KnowledgePoints []KnowledgePointBrief `json:"knowledge_points"`
+	Reviews         []QuestionReviewItem  `json:"reviews,omitempty"` // 仅详情接口返回
+	CreatedAt       time.Time             `json:"created_at"`
+	UpdatedAt       time.Time             `json:"updated_at"`
+}
+
+// QuestionReviewItem 是一条题目审核记录
+type QuestionReviewItem struct {
+	ID         int64     `json:"id"`
+	ReviewerID *int64    `json:"reviewer_id"`
+	Action     string    `json:"action"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Comment    string    `json:"comment"`
+	CreatedAt  time.Time `json:"created_at"`
+}
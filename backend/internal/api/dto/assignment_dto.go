@@ -0,0 +1,161 @@
+/*
+File: assignment_dto.go
+Author: lxp
+Description: 班级作业相关的API数据传输对象 (DTOs)
+*/
+package dto
+
+import (
+	"time"
+	"zhixue-backend/models"
+)
+
+// ================== 请求 (Request) ==================
+
+// ListAssignmentsQuery 定义教师端获取作业列表的查询参数
+type ListAssignmentsQuery struct {
+	PageQuery
+	ClassID *int64 `form:"class_id" binding:"omitempty,min=1"`
+}
+
+// AssignmentQuestionItem 定义作业中的一道题目，题目顺序即数组顺序
+type AssignmentQuestionItem struct {
+	QuestionID int64 `json:"question_id" binding:"required,min=1"`
+	Points     int   `json:"points" binding:"omitempty,min=1,max=100"` // 分值，默认1分
+}
+
+// CreateAssignmentRequest 定义布置作业的请求结构体
+type CreateAssignmentRequest struct {
+	ClassID     int64                    `json:"class_id" binding:"required,min=1"`
+	Title       string                   `json:"title" binding:"required,max=200"`
+	Description string                   `json:"description" binding:"max=2000"`
+	Questions   []AssignmentQuestionItem `json:"questions" binding:"required,min=1,max=100,dive"`
+	OpenAt      *time.Time               `json:"open_at"` // 开放时间，默认立即开放
+	DueAt       time.Time                `json:"due_at" binding:"required"`
+	MaxAttempts int                      `json:"max_attempts" binding:"omitempty,min=1,max=20"` // 最多作答次数，默认1次
+	ShowAnswers bool                     `json:"show_answers"`                                  // 提交后是否展示答案与解析
+	AllowLate   bool                     `json:"allow_late"`                                    // 截止后是否允许迟交
+}
+
+// UpdateAssignmentRequest 定义更新作业的请求结构体
+// 使用指针类型以支持部分更新；已有学生开始作答后不能再修改题目
+type UpdateAssignmentRequest struct {
+	Title       *string                  `json:"title" binding:"omitempty,min=1,max=200"`
+	Description *string                  `json:"description" binding:"omitempty,max=2000"`
+	Questions   []AssignmentQuestionItem `json:"questions" binding:"omitempty,min=1,max=100,dive"`
+	OpenAt      *time.Time               `json:"open_at"`
+	DueAt       *time.Time               `json:"due_at"`
+	MaxAttempts *int                     `json:"max_attempts" binding:"omitempty,min=1,max=20"`
+	ShowAnswers *bool                    `json:"show_answers"`
+	AllowLate   *bool                    `json:"allow_late"`
+}
+
+// ================== 响应 (Response) ==================
+
+// AssignmentQuestionResponse 是作业中的一道题目
+// 教师端总是返回答案；学生端仅在作业允许且已提交过后返回答案与本人作答结果
+type AssignmentQuestionResponse struct {
+	QuestionID     int64        `json:"question_id"`
+	Points         int          `json:"points"`
+	Title          string       `json:"title"`
+	Content        string       `json:"content"`
+	QuestionType   string       `json:"question_type"`
+	Difficulty     float64      `json:"difficulty"`
+	Hints          models.JSONB `json:"hints"`
+	Choices        models.JSONB `json:"choices"`
+	CorrectAnswer  *string      `json:"correct_answer,omitempty"`
+	AnswerAnalysis *string      `json:"answer_analysis,omitempty"`
+	Answered       *bool        `json:"answered,omitempty"`  // 学生端：进行中的作答是否已答过该题
+	MyAnswer       *string      `json:"my_answer,omitempty"` // 学生端：最近一次提交中的作答
+	IsCorrect      *bool        `json:"is_correct,omitempty"`
+}
+
+// AssignmentResponse 是教师端作业的数据结构
+type AssignmentResponse struct {
+	ID            int64                        `json:"id"`
+	ClassID       int64                        `json:"class_id"`
+	ClassName     string                       `json:"class_name"`
+	CreatedBy     *int64                       `json:"created_by"`
+	Title         string                       `json:"title"`
+	Description   string                       `json:"description"`
+	OpenAt        time.Time                    `json:"open_at"`
+	DueAt         time.Time                    `json:"due_at"`
+	MaxAttempts   int                          `json:"max_attempts"`
+	ShowAnswers   bool                         `json:"show_answers"`
+	AllowLate     bool                         `json:"allow_late"`
+	QuestionCount int                          `json:"question_count"`
+	TotalPoints   int                          `json:"total_points"`
+	Questions     []AssignmentQuestionResponse `json:"questions,omitempty"` // 仅详情接口返回
+	CreatedAt     time.Time                    `json:"created_at"`
+	UpdatedAt     time.Time                    `json:"updated_at"`
+}
+
+// AssignmentAttemptResponse 是学生的一次作答
+type AssignmentAttemptResponse struct {
+	AttemptNo     int        `json:"attempt_no"`
+	SessionID     string     `json:"session_id"` // 作答时提交答案需携带该会话ID
+	Status        string     `json:"status"`     // in_progress | submitted
+	StartedAt     time.Time  `json:"started_at"`
+	SubmittedAt   *time.Time `json:"submitted_at"`
+	IsLate        bool       `json:"is_late"`
+	AnsweredCount int        `json:"answered_count"`
+	CorrectCount  int        `json:"correct_count"`
+	Score         float64    `json:"score"` // 得分率 (百分制)
+}
+
+// StudentAssignmentResponse 是学生端作业的数据结构
+type StudentAssignmentResponse struct {
+	ID            int64                        `json:"id"`
+	ClassID       int64                        `json:"class_id"`
+	ClassName     string                       `json:"class_name"`
+	Title         string                       `json:"title"`
+	Description   string                       `json:"description"`
+	OpenAt        time.Time                    `json:"open_at"`
+	DueAt         time.Time                    `json:"due_at"`
+	MaxAttempts   int                          `json:"max_attempts"`
+	ShowAnswers   bool                         `json:"show_answers"`
+	AllowLate     bool                         `json:"allow_late"`
+	QuestionCount int                          `json:"question_count"`
+	TotalPoints   int                          `json:"total_points"`
+	Status        string                       `json:"status"` // not_started | in_progress | submitted
+	AttemptsUsed  int                          `json:"attempts_used"`
+	BestScore     *float64                     `json:"best_score"`
+	IsOverdue     bool                         `json:"is_overdue"`
+	Questions     []AssignmentQuestionResponse `json:"questions,omitempty"` // 仅详情接口返回
+	Attempts      []AssignmentAttemptResponse  `json:"attempts,omitempty"`  // 仅详情接口返回
+}
+
+// AssignmentStudentResponse 是批改概览中某个学生的完成情况
+type AssignmentStudentResponse struct {
+	UserID           int64      `json:"user_id"`
+	Username         string     `json:"username"`
+	Nickname         string     `json:"nickname"`
+	Status           string     `json:"status"` // not_started | in_progress | submitted
+	Attempts         int        `json:"attempts"`
+	BestScore        *float64   `json:"best_score"`
+	FirstSubmittedAt *time.Time `json:"first_submitted_at"`
+	IsLate           bool       `json:"is_late"` // 已提交但没有按时提交的作答
+}
+
+// AssignmentQuestionStatResponse 是批改概览中某道题的答题统计
+type AssignmentQuestionStatResponse struct {
+	QuestionID   int64   `json:"question_id"`
+	Title        string  `json:"title"`
+	Points       int     `json:"points"`
+	AnswerCount  int     `json:"answer_count"`
+	CorrectCount int     `json:"correct_count"`
+	Accuracy     float64 `json:"accuracy"` // 正确率 (百分比)
+}
+
+// AssignmentOverviewResponse 是教师端作业的批改概览
+type AssignmentOverviewResponse struct {
+	AssignmentID    int64                            `json:"assignment_id"`
+	MemberCount     int                              `json:"member_count"`
+	SubmittedCount  int                              `json:"submitted_count"`
+	InProgressCount int                              `json:"in_progress_count"`
+	NotStartedCount int                              `json:"not_started_count"`
+	LateCount       int                              `json:"late_count"`
+	AvgScore        float64                          `json:"avg_score"` // 已提交学生最高分的平均值
+	Students        []AssignmentStudentResponse      `json:"students"`
+	Questions       []AssignmentQuestionStatResponse `json:"questions"`
+}
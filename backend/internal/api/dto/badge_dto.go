@@ -0,0 +1,18 @@
+/*
+File: badge_dto.go
+Author: lxp
+Description: 成就徽章相关的API数据传输对象 (DTOs)
+*/
+package dto
+
+import "time"
+
+// ================== 响应 (Response) ==================
+
+// BadgeResponse 是用户已获得的徽章
+type BadgeResponse struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	ObtainedAt  time.Time `json:"obtained_at"`
+}
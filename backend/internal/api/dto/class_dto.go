@@ -0,0 +1,114 @@
+/*
+File: class_dto.go
+Author: lxp
+Description: 班级相关的API数据传输对象 (DTOs)
+*/
+package dto
+
+import "time"
+
+// ================== 请求 (Request) ==================
+
+// ListClassesQuery 定义后台获取班级列表的查询参数
+type ListClassesQuery struct {
+	PageQuery
+	TeacherID  *int64 `form:"teacher_id" binding:"omitempty,min=1"` // 仅拥有 class:manage_all 权限时生效
+	Keyword    string `form:"keyword" binding:"omitempty,max=100"`
+	IsArchived *bool  `form:"is_archived"`
+}
+
+// CreateClassRequest 定义创建班级的请求结构体
+type CreateClassRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=1000"`
+	GradeLevel  int    `json:"grade_level" binding:"required,min=1,max=12"`
+}
+
+// UpdateClassRequest 定义更新班级的请求结构体
+// 使用指针类型以支持部分更新
+type UpdateClassRequest struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=1000"`
+	GradeLevel  *int    `json:"grade_level" binding:"omitempty,min=1,max=12"`
+	IsArchived  *bool   `json:"is_archived"`
+}
+
+// JoinClassRequest 定义学生通过加入码加入班级的请求结构体
+type JoinClassRequest struct {
+	JoinCode string `json:"join_code" binding:"required,len=6,alphanum"`
+}
+
+// TransferMemberRequest 定义将学生转到其他班级的请求结构体
+type TransferMemberRequest struct {
+	TargetClassID int64 `json:"target_class_id" binding:"required,min=1"`
+}
+
+// ClassStatsQuery 定义班级统计的查询参数
+type ClassStatsQuery struct {
+	Days int `form:"days" binding:"omitempty,min=1,max=365"` // 统计最近多少天的答题，默认7天
+}
+
+// Normalize 为未填写的统计天数设置默认值
+func (q *ClassStatsQuery) Normalize() {
+	if q.Days <= 0 {
+		q.Days = 7
+	}
+}
+
+// ================== 响应 (Response) ==================
+
+// ClassResponse 是教师端班级的数据结构
+type ClassResponse struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	GradeLevel  int       `json:"grade_level"`
+	TeacherID   int64     `json:"teacher_id"`
+	JoinCode    string    `json:"join_code"`
+	IsArchived  bool      `json:"is_archived"`
+	MemberCount int       `json:"member_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// JoinedClassResponse 是学生端已加入班级的数据结构，不包含加入码
+type JoinedClassResponse struct {
+	ID              int64     `json:"id"`
+	Name            string    `json:"name"`
+	Description     string    `json:"description"`
+	GradeLevel      int       `json:"grade_level"`
+	TeacherNickname string    `json:"teacher_nickname"`
+	IsArchived      bool      `json:"is_archived"`
+	JoinedAt        time.Time `json:"joined_at"`
+}
+
+// ClassMemberResponse 是班级花名册中的学生及其学习统计
+type ClassMemberResponse struct {
+	UserID            int64      `json:"user_id"`
+	Username          string     `json:"username"`
+	Nickname          string     `json:"nickname"`
+	AvatarURL         string     `json:"avatar_url"`
+	GradeLevel        int        `json:"grade_level"`
+	JoinedAt          time.Time  `json:"joined_at"`
+	CurrentDifficulty float64    `json:"current_difficulty"`
+	TotalQuestions    int        `json:"total_questions"`
+	CorrectAnswers    int        `json:"correct_answers"`
+	StreakDays        int        `json:"streak_days"`
+	PeriodAnswers     int        `json:"period_answers"`  // 统计周期内的答题数
+	PeriodAccuracy    float64    `json:"period_accuracy"` // 统计周期内的正确率 (百分比)
+	LastAnsweredAt    *time.Time `json:"last_answered_at"`
+}
+
+// ClassStatsResponse 是班级整体的学习统计
+type ClassStatsResponse struct {
+	ClassID         int64   `json:"class_id"`
+	Days            int     `json:"days"`
+	MemberCount     int     `json:"member_count"`
+	ActiveMembers   int     `json:"active_members"` // 统计周期内有答题的学生数
+	AnswerCount     int     `json:"answer_count"`
+	CorrectCount    int     `json:"correct_count"`
+	Accuracy        float64 `json:"accuracy"`          // 统计周期内的正确率 (百分比)
+	AvgResponseTime float64 `json:"avg_response_time"` // 统计周期内的平均答题用时 (秒)
+	AvgDifficulty   float64 `json:"avg_difficulty"`    // 学生当前难度的平均值
+	TotalQuestions  int     `json:"total_questions"`   // 学生累计答题数之和
+}
@@ -0,0 +1,47 @@
+/*
+File: common_dto.go
+Author: lxp
+Description: 通用的API数据传输对象 (分页等)
+*/
+package dto
+
+// PageResponse 定义了统一的分页响应结构体
+// 字段命名与接口文档中的分页响应示例保持一致
+type PageResponse struct {
+	Items      interface{} `json:"items"`
+	Page       int         `json:"page"`
+	PageSize   int         `json:"pageSize"`
+	TotalItems int64       `json:"totalItems"`
+	TotalPages int         `json:"totalPages"`
+}
+
+// NewPageResponse 根据总条数计算总页数并构造分页响应
+func NewPageResponse(items interface{}, page, pageSize int, totalItems int64) *PageResponse {
+	totalPages := 0
+	if pageSize > 0 {
+		totalPages = int((totalItems + int64(pageSize) - 1) / int64(pageSize))
+	}
+	return &PageResponse{
+		Items:      items,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalItems: totalItems,
+		TotalPages: totalPages,
+	}
+}
+
+// PageQuery 定义通用的分页查询参数
+type PageQuery struct {
+	Page     int `form:"page" binding:"omitempty,min=1"`
+	PageSize int `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// Normalize 为未填写的分页参数设置默认值 (默认第1页，每页20条)
+func (q *PageQuery) Normalize() {
+	if q.Page <= 0 {
+		q.Page = 1
+	}
+	if q.PageSize <= 0 {
+		q.PageSize = 20
+	}
+}
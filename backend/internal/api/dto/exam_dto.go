@@ -0,0 +1,61 @@
+/*
+File: exam_dto.go
+Author: lxp
+Description: 限时考试相关的API数据传输对象 (DTOs)
+*/
+package dto
+
+import (
+	"time"
+	"zhixue-backend/models"
+)
+
+// ================== 请求 (Request) ==================
+
+// StartExamRequest 定义开始考试的请求结构体
+// 指定 question_ids 时按给定顺序组卷，否则按知识点/年级与学生当前难度自动选题
+type StartExamRequest struct {
+	QuestionIDs      []int64 `json:"question_ids" binding:"omitempty,max=100,dive,min=1"`
+	QuestionCount    int     `json:"question_count" binding:"omitempty,min=1,max=50"` // 自动组卷的题数，默认10题
+	KnowledgePointID *int64  `json:"knowledge_point_id" binding:"omitempty,min=1"`
+	GradeLevel       *int    `json:"grade_level" binding:"omitempty,min=1,max=12"`
+	TimeLimitMinutes int     `json:"time_limit_minutes" binding:"omitempty,min=1,max=180"` // 考试时长，默认使用系统配置
+	Shuffle          bool    `json:"shuffle"`                                              // 是否随机打乱题序
+}
+
+// ================== 响应 (Response) ==================
+
+// ExamQuestionResponse 是试卷中的一道题目
+// 考试进行中不返回提示、答案与作答结果；交卷后返回本人作答、判分结果与答案解析
+type ExamQuestionResponse struct {
+	Position       int          `json:"position"`
+	QuestionID     int64        `json:"question_id"`
+	Title          string       `json:"title"`
+	Content        string       `json:"content"`
+	QuestionType   string       `json:"question_type"`
+	Difficulty     float64      `json:"difficulty"`
+	Choices        models.JSONB `json:"choices"`
+	Answered       bool         `json:"answered"`
+	MyAnswer       *string      `json:"my_answer,omitempty"`
+	IsCorrect      *bool        `json:"is_correct,omitempty"`
+	CorrectAnswer  *string      `json:"correct_answer,omitempty"`
+	AnswerAnalysis *string      `json:"answer_analysis,omitempty"`
+}
+
+// ExamResponse 是一场考试的数据结构，交卷后即为成绩单
+type ExamResponse struct {
+	SessionID        string                 `json:"session_id"` // 答题时需携带该会话ID
+	Status           string                 `json:"status"`     // in_progress | submitted | expired
+	TimeLimitSeconds int                    `json:"time_limit_seconds"`
+	StartedAt        time.Time              `json:"started_at"`
+	DeadlineAt       time.Time              `json:"deadline_at"`
+	RemainingSeconds int                    `json:"remaining_seconds"` // 服务端计算的剩余时间，已交卷时为0
+	Shuffled         bool                   `json:"shuffled"`
+	ShuffleSeed      *int64                 `json:"shuffle_seed,omitempty"`
+	SubmittedAt      *time.Time             `json:"submitted_at"`
+	QuestionCount    int                    `json:"question_count"`
+	AnsweredCount    int                    `json:"answered_count"`
+	CorrectCount     int                    `json:"correct_count"`
+	Score            float64                `json:"score"`               // 得分率 (百分制)，交卷后有效
+	Questions        []ExamQuestionResponse `json:"questions,omitempty"` // 列表接口不返回
+}
@@ -0,0 +1,28 @@
+/*
+File: knowledge_dto.go
+Author: lxp
+Description: 知识点树与先修关系相关的API数据传输对象 (DTOs)
+*/
+package dto
+
+// ================== 请求 (Request) ==================
+
+// AddPrerequisiteRequest 定义添加先修知识点的请求结构体
+type AddPrerequisiteRequest struct {
+	PrerequisiteID int64 `json:"prerequisite_id" binding:"required,min=1"`
+}
+
+// ================== 响应 (Response) ==================
+
+// KnowledgePointNode 是知识点树中的一个节点，子节点按排序值排列
+type KnowledgePointNode struct {
+	KnowledgePointResponse
+	Children []KnowledgePointNode `json:"children"`
+}
+
+// KnowledgePointPrerequisitesResponse 是知识点的直接先修与后续知识点
+type KnowledgePointPrerequisitesResponse struct {
+	KnowledgePoint KnowledgePointResponse   `json:"knowledge_point"`
+	Prerequisites  []KnowledgePointResponse `json:"prerequisites"` // 需要先掌握的知识点
+	Dependents     []KnowledgePointResponse `json:"dependents"`    // 以该知识点为先修的知识点
+}
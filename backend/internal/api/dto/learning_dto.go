@@ -0,0 +1,59 @@
+/*
+File: learning_dto.go
+Author: lxp
+Description: 学习行为记录相关的API数据传输对象 (DTOs)
+*/
+package dto
+
+import "time"
+
+// ================== 请求 (Request) ==================
+
+// StartSessionRequest 定义创建学习会话的请求结构体
+// test 类型的会话只能通过开始考试创建，homework 类型的会话只能通过作业作答创建
+type StartSessionRequest struct {
+	SessionType string `json:"session_type" binding:"omitempty,oneof=practice challenge"`
+}
+
+// ListSessionsQuery 定义查询学习会话记录的查询参数
+type ListSessionsQuery struct {
+	PageQuery
+	Status      string `form:"status" binding:"omitempty,oneof=ongoing paused completed interrupted"`
+	SessionType string `form:"session_type" binding:"omitempty,oneof=practice test challenge homework"`
+}
+
+// ListAnswersQuery 定义查询答题记录的查询参数
+type ListAnswersQuery struct {
+	PageQuery
+	SessionID string `form:"session_id" binding:"omitempty,max=64"`
+	IsCorrect *bool  `form:"is_correct"`
+}
+
+// ================== 响应 (Response) ==================
+
+// LearningSessionResponse 是学习会话返回的数据结构
+type LearningSessionResponse struct {
+	SessionID        string     `json:"session_id"`
+	SessionType      string     `json:"session_type"`
+	CompletionStatus string     `json:"completion_status"`
+	StartTime        time.Time  `json:"start_time"`
+	EndTime          *time.Time `json:"end_time"`
+	LastActiveAt     *time.Time `json:"last_active_at"`
+	QuestionsCount   int        `json:"questions_count"`
+	CorrectCount     int        `json:"correct_count"`
+	AvgDifficulty    float64    `json:"avg_difficulty"`
+	DurationSeconds  int        `json:"duration_seconds"` // 有效学习时长，不含暂停时间
+}
+
+// AnswerRecordResponse 是答题记录返回的数据结构
+type AnswerRecordResponse struct {
+	QuestionID    int64     `json:"question_id"`
+	QuestionTitle string    `json:"question_title"`
+	SessionID     string    `json:"session_id"`
+	UserAnswer    string    `json:"user_answer"`
+	IsCorrect     *bool     `json:"is_correct,omitempty"` // 题目属于进行中的考试时不返回
+	ResponseTime  int       `json:"response_time"`
+	HintUsedCount int       `json:"hint_used_count"`
+	Difficulty    float64   `json:"difficulty"`
+	AnsweredAt    time.Time `json:"answered_at"`
+}
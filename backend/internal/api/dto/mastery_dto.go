@@ -0,0 +1,46 @@
+/*
+File: mastery_dto.go
+Author: lxp
+Description: 知识点掌握度相关的API数据传输对象 (DTOs)
+*/
+package dto
+
+import "time"
+
+// ================== 请求 (Request) ==================
+
+// ListMasteryQuery 定义查询知识点掌握度的查询参数
+type ListMasteryQuery struct {
+	GradeLevel *int `form:"grade_level" binding:"omitempty,min=1,max=12"`
+}
+
+// UpdateMasteryParamsRequest 定义设置知识点BKT参数的请求结构体，各项取值在 (0,1) 之间且 p_slip+p_guess<1
+type UpdateMasteryParamsRequest struct {
+	PInit  float64 `json:"p_init" binding:"required,gt=0,lt=1"`
+	PLearn float64 `json:"p_learn" binding:"required,gt=0,lt=1"`
+	PSlip  float64 `json:"p_slip" binding:"required,gt=0,lt=1"`
+	PGuess float64 `json:"p_guess" binding:"required,gt=0,lt=1"`
+}
+
+// ================== 响应 (Response) ==================
+
+// KnowledgeMasteryResponse 是用户在单个知识点上的掌握情况
+type KnowledgeMasteryResponse struct {
+	KnowledgePoint KnowledgePointBrief `json:"knowledge_point"`
+	GradeLevel     int                 `json:"grade_level"`
+	Mastery        float64             `json:"mastery"`  // 掌握概率 0-1
+	Mastered       bool                `json:"mastered"` // 掌握概率是否达到已掌握阈值
+	AttemptCount   int                 `json:"attempt_count"`
+	CorrectCount   int                 `json:"correct_count"`
+	LastAnsweredAt *time.Time          `json:"last_answered_at"`
+}
+
+// MasteryParamsResponse 是知识点当前生效的BKT参数
+type MasteryParamsResponse struct {
+	KnowledgePointID int64   `json:"knowledge_point_id"`
+	PInit            float64 `json:"p_init"`
+	PLearn           float64 `json:"p_learn"`
+	PSlip            float64 `json:"p_slip"`
+	PGuess           float64 `json:"p_guess"`
+	IsDefault        bool    `json:"is_default"` // 未单独配置，使用全局默认参数
+}
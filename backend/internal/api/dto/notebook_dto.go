@@ -0,0 +1,97 @@
+/*
+File: notebook_dto.go
+Author: lxp
+Description: 错题本相关的API数据传输对象 (DTOs)
+*/
+package dto
+
+import (
+	"time"
+	"zhixue-backend/models"
+)
+
+// ================== 请求 (Request) ==================
+
+// NotebookFilter 是错题本的筛选条件，日期按服务器时区解析，起止日期均包含在内
+// 日期范围与会话限定参与汇总的错误答题记录
+type NotebookFilter struct {
+	From             string `form:"from" json:"from" binding:"omitempty,datetime=2006-01-02"`
+	To               string `form:"to" json:"to" binding:"omitempty,datetime=2006-01-02"`
+	SessionID        string `form:"session_id" json:"session_id" binding:"omitempty,max=64"`
+	KnowledgePointID *int64 `form:"knowledge_point_id" json:"knowledge_point_id" binding:"omitempty,min=1"`
+	Tag              string `form:"tag" json:"tag" binding:"omitempty,max=50"`
+}
+
+// ListNotebookQuery 定义获取错题本的查询参数
+type ListNotebookQuery struct {
+	PageQuery
+	NotebookFilter
+	Status string `form:"status" binding:"omitempty,oneof=unresolved resolved all"` // 默认 unresolved
+}
+
+// NotebookGroupsQuery 定义错题本分组统计的查询参数，只统计未掌握的错题
+type NotebookGroupsQuery struct {
+	NotebookFilter
+	By string `form:"by" binding:"omitempty,oneof=knowledge_point tag"` // 默认 knowledge_point
+}
+
+// StartNotebookPracticeRequest 定义由错题本生成练习会话的请求结构体，只选取未掌握的错题
+type StartNotebookPracticeRequest struct {
+	NotebookFilter
+	QuestionCount int `json:"question_count" binding:"omitempty,min=1,max=50"` // 默认10题
+}
+
+// ================== 响应 (Response) ==================
+
+// NotebookEntryResponse 是错题本中的一道题目，不包含正确答案
+type NotebookEntryResponse struct {
+	QuestionID   int64        `json:"question_id"`
+	Title        string       `json:"title"`
+	Content      string       `json:"content"`
+	QuestionType string       `json:"question_type"`
+	Difficulty   float64      `json:"difficulty"`
+	Hints        models.JSONB `json:"hints"`
+	Choices      models.JSONB `json:"choices"`
+	Tags         models.JSONB `json:"tags"`
+	MyAnswer     string       `json:"my_answer"`  // 最近一次答错的答案
+	WrongAt      time.Time    `json:"wrong_at"`   // 最近一次答错的时间
+	SessionID    string       `json:"session_id"` // 最近一次答错所在的会话
+	WrongCount   int          `json:"wrong_count"`
+	CorrectSince int          `json:"correct_since"` // 最近一次答错之后答对的次数
+	CanResolve   bool         `json:"can_resolve"`   // 是否可以标记为已掌握
+	Resolved     bool         `json:"resolved"`
+	ResolvedAt   *time.Time   `json:"resolved_at"`
+}
+
+// NotebookGroupResponse 是错题本的一个分组
+// 按知识点分组时 key 为知识点ID，按标签分组时 key 为标签，未归类的错题 key 为 null
+type NotebookGroupResponse struct {
+	Key   *string `json:"key"`
+	Name  string  `json:"name"`
+	Count int64   `json:"count"`
+}
+
+// NotebookGroupsResponse 是错题本的分组统计
+type NotebookGroupsResponse struct {
+	By     string                  `json:"by"`
+	Groups []NotebookGroupResponse `json:"groups"`
+}
+
+// NotebookPracticeQuestionResponse 是错题练习中的一道题目，不包含答案
+type NotebookPracticeQuestionResponse struct {
+	Position     int          `json:"position"`
+	QuestionID   int64        `json:"question_id"`
+	Title        string       `json:"title"`
+	Content      string       `json:"content"`
+	QuestionType string       `json:"question_type"`
+	Difficulty   float64      `json:"difficulty"`
+	Hints        models.JSONB `json:"hints"`
+	Choices      models.JSONB `json:"choices"`
+	Answered     bool         `json:"answered"`
+}
+
+// NotebookPracticeResponse 是一次错题练习，答题时需携带会话ID
+type NotebookPracticeResponse struct {
+	Session   *LearningSessionResponse           `json:"session"`
+	Questions []NotebookPracticeQuestionResponse `json:"questions"`
+}
@@ -0,0 +1,43 @@
+/*
+File: notification_dto.go
+Author: lxp
+Description: 站内通知相关的API数据传输对象 (DTOs)
+*/
+package dto
+
+import (
+	"time"
+	"zhixue-backend/models"
+)
+
+// ================== 请求 (Request) ==================
+
+// ListNotificationsQuery 定义获取通知列表的查询参数
+type ListNotificationsQuery struct {
+	PageQuery
+	UnreadOnly bool `form:"unread_only"`
+}
+
+// ================== 响应 (Response) ==================
+
+// NotificationResponse 是一条站内通知
+type NotificationResponse struct {
+	ID        int64        `json:"id"`
+	Type      string       `json:"type"` // badge_earned | level_up
+	Title     string       `json:"title"`
+	Content   string       `json:"content"`
+	Data      models.JSONB `json:"data"`
+	ReadAt    *time.Time   `json:"read_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// NotificationListResponse 是分页的通知列表及未读通知数
+type NotificationListResponse struct {
+	*PageResponse
+	UnreadCount int64 `json:"unread_count"`
+}
+
+// MarkAllReadResponse 是全部标记为已读的结果
+type MarkAllReadResponse struct {
+	Marked int64 `json:"marked"`
+}
@@ -0,0 +1,67 @@
+/*
+File: parent_dto.go
+Author: lxp
+Description: 家长账号相关的API数据传输对象 (DTOs)
+*/
+package dto
+
+import "time"
+
+// ================== 请求 (Request) ==================
+
+// LinkChildRequest 定义家长使用邀请码关联孩子的请求结构体
+type LinkChildRequest struct {
+	InviteCode string `json:"invite_code" binding:"required,len=8,alphanum"`
+}
+
+// UpdateParentalControlRequest 定义更新家长管控设置的请求结构体
+// 使用指针与空值表示不修改对应字段
+type UpdateParentalControlRequest struct {
+	DailyLimitMinutes   *int     `json:"daily_limit_minutes" binding:"omitempty,min=0,max=1440"` // 0 表示不限制
+	AllowedSessionTypes []string `json:"allowed_session_types" binding:"omitempty,min=1,dive,oneof=practice test challenge"`
+}
+
+// ================== 响应 (Response) ==================
+
+// ParentInviteResponse 是孩子生成的家长关联邀请码
+type ParentInviteResponse struct {
+	InviteCode string    `json:"invite_code"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// LinkedUserResponse 是已关联的孩子或家长的数据结构
+type LinkedUserResponse struct {
+	ID         int64     `json:"id"`
+	Username   string    `json:"username"`
+	Nickname   string    `json:"nickname"`
+	AvatarURL  string    `json:"avatar_url"`
+	GradeLevel int       `json:"grade_level"`
+	LinkedAt   time.Time `json:"linked_at"`
+}
+
+// ChildProfileResponse 是家长查看的孩子资料与学习概况
+type ChildProfileResponse struct {
+	ID                int64      `json:"id"`
+	Username          string     `json:"username"`
+	Nickname          string     `json:"nickname"`
+	AvatarURL         string     `json:"avatar_url"`
+	GradeLevel        int        `json:"grade_level"`
+	BirthDate         *time.Time `json:"birth_date"`
+	Gender            string     `json:"gender"`
+	LastLoginAt       *time.Time `json:"last_login_at"`
+	CurrentDifficulty float64    `json:"current_difficulty"`
+	TotalStudyTime    int        `json:"total_study_time"` // 累计有效学习时长 (秒)
+	TotalQuestions    int        `json:"total_questions"`
+	CorrectAnswers    int        `json:"correct_answers"`
+	StreakDays        int        `json:"streak_days"`
+	UserLevel         int        `json:"user_level"`
+}
+
+// ParentalControlResponse 是孩子当前的家长管控设置及今日用量
+type ParentalControlResponse struct {
+	DailyLimitMinutes     int        `json:"daily_limit_minutes"` // 0 表示不限制
+	AllowedSessionTypes   []string   `json:"allowed_session_types"`
+	UsedTodayMinutes      int        `json:"used_today_minutes"`
+	RemainingTodayMinutes *int       `json:"remaining_today_minutes"` // 不限制时为 null
+	UpdatedAt             *time.Time `json:"updated_at"`
+}
@@ -0,0 +1,41 @@
+/*
+File: progression_dto.go
+Author: lxp
+Description: 连续学习与等级成长相关的API数据传输对象 (DTOs)
+*/
+package dto
+
+// ================== 请求 (Request) ==================
+
+// ProgressQuery 定义获取成长进度的查询参数
+type ProgressQuery struct {
+	Days int `form:"days" binding:"omitempty,min=1,max=90"` // 返回最近多少个学习日的活动，默认30
+}
+
+// ================== 响应 (Response) ==================
+
+// ActivityDayResponse 是一个学习日 (用户时区) 的学习活动
+type ActivityDayResponse struct {
+	Date         string `json:"date"` // YYYY-MM-DD
+	Answers      int    `json:"answers"`
+	StudySeconds int    `json:"study_seconds"`
+	XP           int    `json:"xp"`
+	Frozen       bool   `json:"frozen"` // 该日没有学习，由保护卡保住了连续天数
+}
+
+// ProgressResponse 是用户的连续学习与等级成长进度
+type ProgressResponse struct {
+	Timezone       string                `json:"timezone"`    // 划分学习日所用的时区
+	StreakDays     int                   `json:"streak_days"` // 当前有效的连续学习天数，已中断时为0
+	MaxStreakDays  int                   `json:"max_streak_days"`
+	StudiedToday   bool                  `json:"studied_today"`
+	StreakFreezes  int                   `json:"streak_freezes"` // 持有的连续学习保护卡数
+	MaxFreezes     int                   `json:"max_freezes"`
+	FreezePrice    int                   `json:"freeze_price"`
+	Level          int                   `json:"level"`
+	LevelScore     int                   `json:"level_score"`      // 累计经验值
+	LevelStartXP   int                   `json:"level_start_xp"`   // 当前等级需要的累计经验值
+	NextLevelXP    *int                  `json:"next_level_xp"`    // 升到下一级需要的累计经验值，已满级时为 null
+	TotalStudyTime int                   `json:"total_study_time"` // 累计有效学习时长 (秒)
+	Activity       []ActivityDayResponse `json:"activity"`         // 最近的学习日活动，按日期升序，没有活动的日期不返回
+}
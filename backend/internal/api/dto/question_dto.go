@@ -0,0 +1,87 @@
+/*
+File: question_dto.go
+Author: lxp
+Description: 题库相关的API数据传输对象 (DTOs)
+*/
+package dto
+
+import "zhixue-backend/models"
+
+// ================== 请求 (Request) ==================
+
+// ListQuestionsQuery 定义获取题目列表的查询参数
+type ListQuestionsQuery struct {
+	PageQuery
+	KnowledgePointID *int64 `form:"knowledge_point_id" binding:"omitempty,min=1"`
+	Difficulty       string `form:"difficulty"`
+	GradeLevel       *int   `form:"grade_level" binding:"omitempty,min=1,max=12"`
+	Recommend        bool   `form:"recommend"`
+}
+
+// ListKnowledgePointsQuery 定义获取知识点列表的查询参数
+type ListKnowledgePointsQuery struct {
+	GradeLevel *int `form:"grade_level" binding:"omitempty,min=1,max=12"`
+}
+
+// SubmitAnswerRequest 定义提交题目答案的请求结构体
+// 填空题的多个空以JSON数组字符串提交，如 "[\"3\", \"5\"]"
+type SubmitAnswerRequest struct {
+	Answer          string       `json:"answer" binding:"required,max=1000"`
+	ResponseTime    int          `json:"response_time" binding:"min=0"` // 答题用时 (秒)
+	HintUsedCount   int          `json:"hint_used_count" binding:"min=0"`
+	SessionID       string       `json:"session_id" binding:"omitempty,max=64"`
+	ConfidenceScore *float64     `json:"confidence_score" binding:"omitempty,min=0,max=1"`
+	AnswerMethod    string       `json:"answer_method" binding:"omitempty,oneof=direct hint guess"`
+	DeviceInfo      models.JSONB `json:"device_info"`
+}
+
+// ================== 响应 (Response) ==================
+
+// KnowledgePointBrief 是题目中附带的知识点简要信息
+type KnowledgePointBrief struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Code string `json:"code"`
+}
+
+// KnowledgePointResponse 是知识点列表返回的数据结构
+type KnowledgePointResponse struct {
+	ID              int64  `json:"id"`
+	Name            string `json:"name"`
+	Code            string `json:"code"`
+	ParentID        *int64 `json:"parent_id"`
+	GradeLevel      int    `json:"grade_level"`
+	DifficultyRange string `json:"difficulty_range"`
+	Description     string `json:"description"`
+	SortOrder       int    `json:"sort_order"`
+}
+
+// QuestionResponse 是面向学生的题目数据结构
+// CorrectAnswer 与 AnswerAnalysis 仅在学生作答过该题、且该题不在进行中的作业里时才会返回
+type QuestionResponse struct {
+	ID              int64                 `json:"id"`
+	Title           string                `json:"title"`
+	Content         string                `json:"content"`
+	QuestionType    string                `json:"question_type"`
+	Difficulty      float64               `json:"difficulty"`
+	DifficultyLevel string                `json:"difficulty_level"`
+	GradeLevel      int                   `json:"grade_level"`
+	EstimatedTime   int                   `json:"estimated_time"`
+	Hints           models.JSONB          `json:"hints"`
+	Choices         models.JSONB          `json:"choices"`
+	Tags            models.JSONB          `json:"tags"`
+	KnowledgePoints []KnowledgePointBrief `json:"knowledge_points"`
+	CorrectAnswer   *string               `json:"correct_answer,omitempty"`
+	AnswerAnalysis  *string               `json:"answer_analysis,omitempty"`
+}
+
+// SubmitAnswerResponse 是提交答案后返回的判分结果
+// 作答进行中的作业题目时不返回 CorrectAnswer 与 AnswerAnalysis；
+// 进行中的考试题目在交卷或超时前连 IsCorrect 与 BlankResults 也不返回
+type SubmitAnswerResponse struct {
+	QuestionID     int64  `json:"question_id"`
+	IsCorrect      *bool  `json:"is_correct,omitempty"`
+	BlankResults   []bool `json:"blank_results,omitempty"`
+	CorrectAnswer  string `json:"correct_answer,omitempty"`
+	AnswerAnalysis string `json:"answer_analysis,omitempty"`
+}
@@ -0,0 +1,43 @@
+/*
+File: review_dto.go
+Author: lxp
+Description: 错题间隔复习相关的API数据传输对象 (DTOs)
+*/
+package dto
+
+import (
+	"time"
+	"zhixue-backend/models"
+)
+
+// ================== 请求 (Request) ==================
+
+// ListDueReviewsQuery 定义获取待复习题目的查询参数
+type ListDueReviewsQuery struct {
+	PageQuery
+}
+
+// ================== 响应 (Response) ==================
+
+// ReviewItemResponse 是一道待复习的题目及其复习进度，不包含答案
+type ReviewItemResponse struct {
+	QuestionID     int64        `json:"question_id"`
+	Title          string       `json:"title"`
+	Content        string       `json:"content"`
+	QuestionType   string       `json:"question_type"`
+	Difficulty     float64      `json:"difficulty"`
+	Hints          models.JSONB `json:"hints"`
+	Choices        models.JSONB `json:"choices"`
+	DueAt          time.Time    `json:"due_at"`
+	IntervalDays   int          `json:"interval_days"`
+	EaseFactor     float64      `json:"ease_factor"`
+	Repetitions    int          `json:"repetitions"` // 连续答对的复习次数
+	LapseCount     int          `json:"lapse_count"` // 进入复习队列后答错的次数
+	LastReviewedAt *time.Time   `json:"last_reviewed_at"`
+}
+
+// ReviewStatsResponse 是当天的复习统计
+type ReviewStatsResponse struct {
+	DueToday      int `json:"due_today"`      // 今天结束前到期的题目数
+	ReviewedToday int `json:"reviewed_today"` // 今天已复习的题目次数
+}
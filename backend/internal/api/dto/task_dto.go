@@ -0,0 +1,50 @@
+/*
+File: task_dto.go
+Author: lxp
+Description: 游戏化任务与奖励相关的API数据传输对象 (DTOs)
+*/
+package dto
+
+import "time"
+
+// ================== 请求 (Request) ==================
+
+// ListTasksQuery 定义获取任务列表的查询参数
+type ListTasksQuery struct {
+	Type string `form:"type" binding:"omitempty,oneof=daily weekly achievement"`
+}
+
+// ================== 响应 (Response) ==================
+
+// TaskGoalResponse 是任务的目标
+type TaskGoalResponse struct {
+	Metric         string `json:"metric"` // answers | correct_answers | sessions_completed | study_minutes | reviews
+	Target         int    `json:"target"`
+	KnowledgePoint string `json:"knowledge_point,omitempty"` // 知识点编码 (包含其子知识点)
+	SessionType    string `json:"session_type,omitempty"`
+}
+
+// RewardResponse 是任务的奖励
+type RewardResponse struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	RewardType  string `json:"reward_type"` // points | item | badge
+	Value       int    `json:"value"`
+}
+
+// TaskResponse 是任务及用户在当前周期的进度
+type TaskResponse struct {
+	ID          int64            `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Type        string           `json:"type"` // daily | weekly | achievement
+	Goal        TaskGoalResponse `json:"goal"`
+	Progress    int              `json:"progress"`
+	Status      string           `json:"status"` // pending | completed | claimed
+	CompletedAt *time.Time       `json:"completed_at"`
+	ClaimedAt   *time.Time       `json:"claimed_at"`
+	PeriodStart time.Time        `json:"period_start"` // 当前周期的开始时间
+	ResetsAt    *time.Time       `json:"resets_at"`    // 当前周期的结束时间，成就任务为 null
+	Reward      *RewardResponse  `json:"reward"`
+}
@@ -15,12 +15,36 @@ type RegisterRequest struct {
 	Password string `json:"password" binding:"required,min=6,max=30"`
 	Email    string `json:"email" binding:"required,email"`
 	Nickname string `json:"nickname" binding:"required,min=2,max=20"`
+	// 账号类型，默认为学生 (student)，家长账号 (parent) 用于关联并管理孩子的学习
+	AccountType string `json:"account_type" binding:"omitempty,oneof=student parent"`
 }
 
 // LoginRequest 定义用户登录请求的结构体
 type LoginRequest struct {
-	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Username   string `json:"username" binding:"required"`
+	Password   string `json:"password" binding:"required"`
+	DeviceName string `json:"device_name" binding:"omitempty,max=100"` // 可选，用于在会话列表中识别设备
+}
+
+// RefreshRequest 定义刷新令牌请求的结构体
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+// VerifyEmailRequest 定义验证邮箱请求的结构体
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ForgotPasswordRequest 定义找回密码请求的结构体
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest 定义重置密码请求的结构体
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6,max=30"`
 }
 
 // UpdateUserRequest 定义更新用户信息请求的结构体
@@ -31,23 +55,28 @@ type UpdateUserRequest struct {
 	GradeLevel *int       `json:"grade_level" binding:"omitempty,min=1,max=12"`
 	BirthDate  *time.Time `json:"birth_date" binding:"omitempty"`
 	Gender     *string    `json:"gender" binding:"omitempty,oneof=male female other"`
+	Timezone   *string    `json:"timezone" binding:"omitempty,max=64"` // IANA时区名，如 Asia/Shanghai，空字符串表示使用默认时区
 }
 
 // ================== 响应 (Response) ==================
 
 // UserResponse 是用于API返回的安全用户数据结构
 type UserResponse struct {
-	ID          int64      `json:"id"`
-	Username    string     `json:"username"`
-	Email       string     `json:"email"`
-	Nickname    string     `json:"nickname"`
-	AvatarURL   string     `json:"avatar_url"`
-	GradeLevel  int        `json:"grade_level"`
-	BirthDate   *time.Time `json:"birth_date"`
-	Gender      string     `json:"gender"`
-	Role        string     `json:"role"`
-	CreatedAt   time.Time  `json:"created_at"`
-	LastLoginAt *time.Time `json:"last_login_at"`
+	ID            int64      `json:"id"`
+	Username      string     `json:"username"`
+	Email         string     `json:"email"`
+	Nickname      string     `json:"nickname"`
+	AvatarURL     string     `json:"avatar_url"`
+	GradeLevel    int        `json:"grade_level"`
+	BirthDate     *time.Time `json:"birth_date"`
+	Gender        string     `json:"gender"`
+	Role          string     `json:"role"`
+	EmailVerified bool       `json:"email_verified"`
+	Timezone      string     `json:"timezone"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastLoginAt   *time.Time `json:"last_login_at"`
+
+	Badges []BadgeResponse `json:"badges,omitempty"` // 已获得的徽章，仅获取当前用户信息时返回
 }
 
 // RegisterResponse 是用于注册成功后返回的数据结构
@@ -56,10 +85,29 @@ type RegisterResponse struct {
 	Username string `json:"username"`
 	Email    string `json:"email"`
 	Nickname string `json:"nickname"`
+	Role     string `json:"role"`
 }
 
 // LoginResponse 定义用户登录响应的结构体
 type LoginResponse struct {
-	Token string        `json:"token"`
-	User  *UserResponse `json:"user"`
+	Token        string        `json:"token"`
+	RefreshToken string        `json:"refreshToken"`
+	User         *UserResponse `json:"user"`
+}
+
+// RefreshResponse 定义刷新令牌响应的结构体
+type RefreshResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+// SessionResponse 是一个登录会话 (登录设备) 的信息
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	DeviceName string    `json:"device_name"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	Current    bool      `json:"current"` // 是否为发起本次请求的会话
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
 }
@@ -0,0 +1,40 @@
+/*
+File: wallet_dto.go
+Author: lxp
+Description: 积分钱包相关的API数据传输对象 (DTOs)
+*/
+package dto
+
+import "time"
+
+// ================== 请求 (Request) ==================
+
+// WalletQuery 定义获取积分钱包的查询参数，分页参数作用于积分流水
+type WalletQuery struct {
+	PageQuery
+}
+
+// GrantPointsRequest 定义管理员发放积分的请求体
+type GrantPointsRequest struct {
+	Amount int    `json:"amount" binding:"required,min=1,max=100000"`
+	Note   string `json:"note" binding:"required,max=500"`
+}
+
+// ================== 响应 (Response) ==================
+
+// PointsTransactionResponse 是一条积分流水
+type PointsTransactionResponse struct {
+	ID           int64     `json:"id"`
+	Amount       int       `json:"amount"` // 正数为入账，负数为扣减
+	BalanceAfter int       `json:"balance_after"`
+	Reason       string    `json:"reason"` // task_claim | streak_bonus | admin_grant | purchase
+	Reference    *string   `json:"reference"`
+	Note         string    `json:"note"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// WalletResponse 是用户的积分余额及分页的积分流水
+type WalletResponse struct {
+	Balance int           `json:"balance"`
+	History *PageResponse `json:"history"`
+}
@@ -0,0 +1,178 @@
+/*
+File: admin_assignment_handler.go
+Author: lxp
+Description: 班级作业后台管理API处理器 (教师端)
+*/
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/api/response"
+	"zhixue-backend/internal/rbac"
+	"zhixue-backend/internal/service/assignment"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AdminAssignmentHandler 封装了作业后台管理相关的API处理器
+type AdminAssignmentHandler struct {
+	service assignment.Service
+}
+
+// NewAdminAssignmentHandler 创建一个新的AdminAssignmentHandler
+func NewAdminAssignmentHandler(service assignment.Service) *AdminAssignmentHandler {
+	return &AdminAssignmentHandler{service: service}
+}
+
+// ListAssignments 处理获取作业列表的请求
+func (h *AdminAssignmentHandler) ListAssignments(c *gin.Context) {
+	op, ok := currentSubject(c)
+	if !ok {
+		return
+	}
+
+	var query dto.ListAssignmentsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	page, err := h.service.ListAssignments(op, &query)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取作业列表失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, page, "获取成功")
+}
+
+// GetAssignment 处理获取作业详情的请求
+func (h *AdminAssignmentHandler) GetAssignment(c *gin.Context) {
+	op, assignmentID, ok := h.subjectAndAssignment(c)
+	if !ok {
+		return
+	}
+
+	a, err := h.service.GetAssignment(op, assignmentID)
+	if err != nil {
+		h.handleError(c, err, "获取作业详情失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, a, "获取成功")
+}
+
+// CreateAssignment 处理布置作业的请求
+func (h *AdminAssignmentHandler) CreateAssignment(c *gin.Context) {
+	op, ok := currentSubject(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateAssignmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	a, err := h.service.CreateAssignment(op, &req)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Error(c, http.StatusNotFound, "班级不存在")
+			return
+		}
+		h.handleError(c, err, "布置作业失败")
+		return
+	}
+
+	response.Success(c, http.StatusCreated, a, "布置成功")
+}
+
+// UpdateAssignment 处理更新作业的请求
+func (h *AdminAssignmentHandler) UpdateAssignment(c *gin.Context) {
+	op, assignmentID, ok := h.subjectAndAssignment(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateAssignmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	a, err := h.service.UpdateAssignment(op, assignmentID, &req)
+	if err != nil {
+		h.handleError(c, err, "更新作业失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, a, "更新成功")
+}
+
+// DeleteAssignment 处理删除作业的请求
+func (h *AdminAssignmentHandler) DeleteAssignment(c *gin.Context) {
+	op, assignmentID, ok := h.subjectAndAssignment(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteAssignment(op, assignmentID); err != nil {
+		h.handleError(c, err, "删除作业失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, nil, "删除成功")
+}
+
+// GetOverview 处理获取作业批改概览的请求
+func (h *AdminAssignmentHandler) GetOverview(c *gin.Context) {
+	op, assignmentID, ok := h.subjectAndAssignment(c)
+	if !ok {
+		return
+	}
+
+	overview, err := h.service.GetOverview(op, assignmentID)
+	if err != nil {
+		h.handleError(c, err, "获取批改概览失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, overview, "获取成功")
+}
+
+// subjectAndAssignment 解析当前用户与路径中的作业ID
+func (h *AdminAssignmentHandler) subjectAndAssignment(c *gin.Context) (rbac.Subject, int64, bool) {
+	op, ok := currentSubject(c)
+	if !ok {
+		return rbac.Subject{}, 0, false
+	}
+	assignmentID, ok := parseIDParam(c, "id")
+	if !ok {
+		return rbac.Subject{}, 0, false
+	}
+	return op, assignmentID, true
+}
+
+// handleError 将作业管理相关的业务错误映射为HTTP响应
+func (h *AdminAssignmentHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		response.Error(c, http.StatusNotFound, "作业不存在")
+	case errors.Is(err, assignment.ErrAssignmentForbidden):
+		response.Error(c, http.StatusForbidden, "无权管理该班级的作业")
+	case errors.Is(err, assignment.ErrClassArchived):
+		response.Error(c, http.StatusConflict, "班级已归档，无法布置作业")
+	case errors.Is(err, assignment.ErrInvalidSchedule):
+		response.Error(c, http.StatusBadRequest, "截止时间必须晚于开放时间")
+	case errors.Is(err, assignment.ErrInvalidQuestions):
+		response.Error(c, http.StatusBadRequest, "题目不能重复且必须是已发布的题目")
+	case errors.Is(err, assignment.ErrAssignmentStarted):
+		response.Error(c, http.StatusConflict, "已有学生开始作答，不能再修改题目")
+	default:
+		response.Error(c, http.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,262 @@
+/*
+File: admin_class_handler.go
+Author: lxp
+Description: 班级后台管理API处理器 (教师端)
+*/
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/api/response"
+	"zhixue-backend/internal/rbac"
+	"zhixue-backend/internal/service/class"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AdminClassHandler 封装了班级后台管理相关的API处理器
+type AdminClassHandler struct {
+	service class.Service
+}
+
+// NewAdminClassHandler 创建一个新的AdminClassHandler
+func NewAdminClassHandler(service class.Service) *AdminClassHandler {
+	return &AdminClassHandler{service: service}
+}
+
+// ListClasses 处理获取班级列表的请求
+func (h *AdminClassHandler) ListClasses(c *gin.Context) {
+	op, ok := currentSubject(c)
+	if !ok {
+		return
+	}
+
+	var query dto.ListClassesQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	page, err := h.service.ListClasses(op, &query)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取班级列表失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, page, "获取成功")
+}
+
+// GetClass 处理获取班级详情的请求
+func (h *AdminClassHandler) GetClass(c *gin.Context) {
+	op, classID, ok := h.subjectAndClass(c)
+	if !ok {
+		return
+	}
+
+	cls, err := h.service.GetClass(op, classID)
+	if err != nil {
+		h.handleError(c, err, "获取班级详情失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, cls, "获取成功")
+}
+
+// CreateClass 处理创建班级的请求
+func (h *AdminClassHandler) CreateClass(c *gin.Context) {
+	op, ok := currentSubject(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateClassRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	cls, err := h.service.CreateClass(op, &req)
+	if err != nil {
+		h.handleError(c, err, "创建班级失败")
+		return
+	}
+
+	response.Success(c, http.StatusCreated, cls, "创建成功")
+}
+
+// UpdateClass 处理更新班级的请求
+func (h *AdminClassHandler) UpdateClass(c *gin.Context) {
+	op, classID, ok := h.subjectAndClass(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateClassRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	cls, err := h.service.UpdateClass(op, classID, &req)
+	if err != nil {
+		h.handleError(c, err, "更新班级失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, cls, "更新成功")
+}
+
+// DeleteClass 处理删除班级的请求
+func (h *AdminClassHandler) DeleteClass(c *gin.Context) {
+	op, classID, ok := h.subjectAndClass(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteClass(op, classID); err != nil {
+		h.handleError(c, err, "删除班级失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, nil, "删除成功")
+}
+
+// RegenerateJoinCode 处理重新生成班级加入码的请求
+func (h *AdminClassHandler) RegenerateJoinCode(c *gin.Context) {
+	op, classID, ok := h.subjectAndClass(c)
+	if !ok {
+		return
+	}
+
+	cls, err := h.service.RegenerateJoinCode(op, classID)
+	if err != nil {
+		h.handleError(c, err, "重新生成加入码失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, cls, "加入码已更新")
+}
+
+// ListMembers 处理获取班级花名册的请求
+func (h *AdminClassHandler) ListMembers(c *gin.Context) {
+	op, classID, ok := h.subjectAndClass(c)
+	if !ok {
+		return
+	}
+
+	var query dto.ClassStatsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	members, err := h.service.ListMembers(op, classID, &query)
+	if err != nil {
+		h.handleError(c, err, "获取班级成员失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, members, "获取成功")
+}
+
+// RemoveMember 处理将学生移出班级的请求
+func (h *AdminClassHandler) RemoveMember(c *gin.Context) {
+	op, classID, ok := h.subjectAndClass(c)
+	if !ok {
+		return
+	}
+	userID, ok := parseIDParam(c, "user_id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveMember(op, classID, userID); err != nil {
+		h.handleError(c, err, "移出学生失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, nil, "已移出班级")
+}
+
+// TransferMember 处理将学生转到其他班级的请求
+func (h *AdminClassHandler) TransferMember(c *gin.Context) {
+	op, classID, ok := h.subjectAndClass(c)
+	if !ok {
+		return
+	}
+	userID, ok := parseIDParam(c, "user_id")
+	if !ok {
+		return
+	}
+
+	var req dto.TransferMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	if err := h.service.TransferMember(op, classID, userID, req.TargetClassID); err != nil {
+		h.handleError(c, err, "转班失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, nil, "转班成功")
+}
+
+// GetStats 处理获取班级学习统计的请求
+func (h *AdminClassHandler) GetStats(c *gin.Context) {
+	op, classID, ok := h.subjectAndClass(c)
+	if !ok {
+		return
+	}
+
+	var query dto.ClassStatsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	stats, err := h.service.GetStats(op, classID, &query)
+	if err != nil {
+		h.handleError(c, err, "获取班级统计失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, stats, "获取成功")
+}
+
+// subjectAndClass 解析当前用户与路径中的班级ID
+func (h *AdminClassHandler) subjectAndClass(c *gin.Context) (rbac.Subject, int64, bool) {
+	op, ok := currentSubject(c)
+	if !ok {
+		return rbac.Subject{}, 0, false
+	}
+	classID, ok := parseIDParam(c, "id")
+	if !ok {
+		return rbac.Subject{}, 0, false
+	}
+	return op, classID, true
+}
+
+// handleError 将班级管理相关的业务错误映射为HTTP响应
+func (h *AdminClassHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		response.Error(c, http.StatusNotFound, "班级不存在")
+	case errors.Is(err, class.ErrClassForbidden):
+		response.Error(c, http.StatusForbidden, "无权管理该班级")
+	case errors.Is(err, class.ErrNotMember):
+		response.Error(c, http.StatusNotFound, "该学生不在班级中")
+	case errors.Is(err, class.ErrAlreadyMember):
+		response.Error(c, http.StatusConflict, "该学生已在目标班级中")
+	case errors.Is(err, class.ErrSameClass):
+		response.Error(c, http.StatusBadRequest, "目标班级不能与当前班级相同")
+	case errors.Is(err, class.ErrClassArchived):
+		response.Error(c, http.StatusConflict, "目标班级已归档")
+	default:
+		response.Error(c, http.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,88 @@
+/*
+File: admin_knowledge_handler.go
+Author: lxp
+Description: 知识点先修关系后台管理API处理器
+*/
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/api/response"
+	"zhixue-backend/internal/service/knowledge"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AdminKnowledgeHandler 封装了知识点后台管理相关的API处理器
+type AdminKnowledgeHandler struct {
+	service knowledge.Service
+}
+
+// NewAdminKnowledgeHandler 创建一个新的AdminKnowledgeHandler
+func NewAdminKnowledgeHandler(service knowledge.Service) *AdminKnowledgeHandler {
+	return &AdminKnowledgeHandler{service: service}
+}
+
+// AddPrerequisite 处理添加先修知识点的请求
+func (h *AdminKnowledgeHandler) AddPrerequisite(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.AddPrerequisiteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	result, err := h.service.AddPrerequisite(id, &req)
+	if err != nil {
+		h.handleError(c, err, "添加先修知识点失败")
+		return
+	}
+
+	response.Success(c, http.StatusCreated, result, "添加成功")
+}
+
+// RemovePrerequisite 处理删除先修知识点的请求
+func (h *AdminKnowledgeHandler) RemovePrerequisite(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	prerequisiteID, ok := parseIDParam(c, "prerequisite_id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemovePrerequisite(id, prerequisiteID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Error(c, http.StatusNotFound, "先修关系不存在")
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "删除先修知识点失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, nil, "删除成功")
+}
+
+// handleError 将先修关系管理相关的业务错误映射为HTTP响应
+func (h *AdminKnowledgeHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		response.Error(c, http.StatusNotFound, "知识点不存在")
+	case errors.Is(err, knowledge.ErrPrerequisiteNotFound):
+		response.Error(c, http.StatusBadRequest, "先修知识点不存在")
+	case errors.Is(err, knowledge.ErrPrerequisiteExists):
+		response.Error(c, http.StatusConflict, "先修关系已存在")
+	case errors.Is(err, knowledge.ErrPrerequisiteCycle):
+		response.Error(c, http.StatusConflict, "添加后先修关系将形成环")
+	default:
+		response.Error(c, http.StatusInternalServerError, fallback)
+	}
+}
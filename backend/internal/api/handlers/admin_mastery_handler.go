@@ -0,0 +1,99 @@
+/*
+File: admin_mastery_handler.go
+Author: lxp
+Description: 知识点掌握度参数后台管理API处理器
+*/
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/api/response"
+	"zhixue-backend/internal/service/mastery"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AdminMasteryHandler 封装了掌握度参数后台管理相关的API处理器
+type AdminMasteryHandler struct {
+	service mastery.Service
+}
+
+// NewAdminMasteryHandler 创建一个新的AdminMasteryHandler
+func NewAdminMasteryHandler(service mastery.Service) *AdminMasteryHandler {
+	return &AdminMasteryHandler{service: service}
+}
+
+// GetParams 处理获取知识点BKT参数的请求
+func (h *AdminMasteryHandler) GetParams(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	params, err := h.service.GetParams(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Error(c, http.StatusNotFound, "知识点不存在")
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "获取掌握度参数失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, params, "获取成功")
+}
+
+// UpdateParams 处理设置知识点BKT参数的请求
+func (h *AdminMasteryHandler) UpdateParams(c *gin.Context) {
+	operatorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateMasteryParamsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	params, err := h.service.UpdateParams(operatorID, id, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			response.Error(c, http.StatusNotFound, "知识点不存在")
+		case errors.Is(err, mastery.ErrInvalidParams):
+			response.Error(c, http.StatusBadRequest, "p_slip 与 p_guess 之和必须小于1")
+		default:
+			response.Error(c, http.StatusInternalServerError, "设置掌握度参数失败")
+		}
+		return
+	}
+
+	response.Success(c, http.StatusOK, params, "设置成功")
+}
+
+// ResetParams 处理恢复知识点默认BKT参数的请求
+func (h *AdminMasteryHandler) ResetParams(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.ResetParams(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Error(c, http.StatusNotFound, "该知识点未单独设置掌握度参数")
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "恢复默认掌握度参数失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, nil, "已恢复默认参数")
+}
@@ -0,0 +1,205 @@
+/*
+File: admin_question_handler.go
+Author: lxp
+Description: 题库后台管理API处理器
+*/
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/api/response"
+	"zhixue-backend/internal/rbac"
+	"zhixue-backend/internal/service/question"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AdminQuestionHandler 封装了题库后台管理相关的API处理器
+type AdminQuestionHandler struct {
+	service question.AdminService
+}
+
+// NewAdminQuestionHandler 创建一个新的AdminQuestionHandler
+func NewAdminQuestionHandler(service question.AdminService) *AdminQuestionHandler {
+	return &AdminQuestionHandler{service: service}
+}
+
+// ListQuestions 处理后台获取题目列表的请求
+func (h *AdminQuestionHandler) ListQuestions(c *gin.Context) {
+	op, ok := currentSubject(c)
+	if !ok {
+		return
+	}
+
+	var query dto.AdminListQuestionsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	page, err := h.service.ListQuestions(op, &query)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取题目列表失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, page, "获取成功")
+}
+
+// GetQuestion 处理后台获取题目详情的请求
+func (h *AdminQuestionHandler) GetQuestion(c *gin.Context) {
+	op, ok := currentSubject(c)
+	if !ok {
+		return
+	}
+	questionID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	q, err := h.service.GetQuestion(op, questionID)
+	if err != nil {
+		h.handleError(c, err, "获取题目详情失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, q, "获取成功")
+}
+
+// CreateQuestion 处理创建题目的请求
+func (h *AdminQuestionHandler) CreateQuestion(c *gin.Context) {
+	op, ok := currentSubject(c)
+	if !ok {
+		return
+	}
+
+	var req dto.SaveQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	q, err := h.service.CreateQuestion(op, &req)
+	if err != nil {
+		h.handleError(c, err, "创建题目失败")
+		return
+	}
+
+	response.Success(c, http.StatusCreated, q, "创建成功")
+}
+
+// UpdateQuestion 处理更新题目的请求
+func (h *AdminQuestionHandler) UpdateQuestion(c *gin.Context) {
+	op, ok := currentSubject(c)
+	if !ok {
+		return
+	}
+	questionID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.SaveQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	q, err := h.service.UpdateQuestion(op, questionID, &req)
+	if err != nil {
+		h.handleError(c, err, "更新题目失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, q, "更新成功")
+}
+
+// DeleteQuestion 处理删除题目的请求 (软删除)
+func (h *AdminQuestionHandler) DeleteQuestion(c *gin.Context) {
+	op, ok := currentSubject(c)
+	if !ok {
+		return
+	}
+	questionID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteQuestion(op, questionID); err != nil {
+		h.handleError(c, err, "删除题目失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, nil, "删除成功")
+}
+
+// SubmitForReview 处理提交审核的请求
+func (h *AdminQuestionHandler) SubmitForReview(c *gin.Context) {
+	h.review(c, h.service.SubmitForReview, "已提交审核")
+}
+
+// ApproveQuestion 处理审核通过的请求
+func (h *AdminQuestionHandler) ApproveQuestion(c *gin.Context) {
+	h.review(c, h.service.ApproveQuestion, "审核通过")
+}
+
+// RejectQuestion 处理驳回题目的请求
+func (h *AdminQuestionHandler) RejectQuestion(c *gin.Context) {
+	h.review(c, h.service.RejectQuestion, "已驳回")
+}
+
+// review 执行审核操作的通用流程
+func (h *AdminQuestionHandler) review(c *gin.Context,
+	action func(op rbac.Subject, questionID int64, comment string) (*dto.AdminQuestionResponse, error), msg string) {
+	op, ok := currentSubject(c)
+	if !ok {
+		return
+	}
+	questionID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.ReviewQuestionRequest
+	// 审核意见可以为空，此时允许不带请求体
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+			return
+		}
+	}
+
+	q, err := action(op, questionID, strings.TrimSpace(req.Comment))
+	if err != nil {
+		h.handleError(c, err, "审核操作失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, q, msg)
+}
+
+// handleError 将题库管理相关的业务错误映射为HTTP响应
+func (h *AdminQuestionHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		response.Error(c, http.StatusNotFound, "题目不存在")
+	case errors.Is(err, question.ErrQuestionForbidden):
+		response.Error(c, http.StatusForbidden, "无权管理该题目")
+	case errors.Is(err, question.ErrSelfReview):
+		response.Error(c, http.StatusForbidden, "不能审核自己创建的题目")
+	case errors.Is(err, question.ErrInvalidReviewAction):
+		response.Error(c, http.StatusConflict, "当前审核状态不允许该操作")
+	case errors.Is(err, question.ErrReviewCommentRequired):
+		response.Error(c, http.StatusBadRequest, "驳回时必须填写审核意见")
+	case errors.Is(err, question.ErrKnowledgePointNotFound):
+		response.Error(c, http.StatusBadRequest, "知识点不存在或已停用")
+	case errors.Is(err, question.ErrInvalidQuestionContent):
+		response.Error(c, http.StatusBadRequest, "题目内容不合法: "+strings.TrimPrefix(err.Error(), question.ErrInvalidQuestionContent.Error()+": "))
+	default:
+		response.Error(c, http.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,49 @@
+/*
+File: admin_user_handler.go
+Author: lxp
+Description: 用户后台管理API处理器
+*/
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"zhixue-backend/internal/api/response"
+	"zhixue-backend/internal/service/user"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AdminUserHandler 封装了用户后台管理相关的API处理器
+type AdminUserHandler struct {
+	service user.Service
+}
+
+// NewAdminUserHandler 创建一个新的AdminUserHandler
+func NewAdminUserHandler(service user.Service) *AdminUserHandler {
+	return &AdminUserHandler{service: service}
+}
+
+// UnlockUser 处理解除账号登录锁定的请求
+func (h *AdminUserHandler) UnlockUser(c *gin.Context) {
+	operatorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	userID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.UnlockUser(operatorID, userID, c.ClientIP()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Error(c, http.StatusNotFound, "用户不存在")
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "解除锁定失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, nil, "已解除锁定")
+}
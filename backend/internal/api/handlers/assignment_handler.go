@@ -0,0 +1,130 @@
+/*
+File: assignment_handler.go
+Author: lxp
+Description: 班级作业API处理器 (学生端)
+*/
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/api/response"
+	"zhixue-backend/internal/service/assignment"
+	"zhixue-backend/internal/service/learning"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AssignmentHandler 封装了学生端作业相关的API处理器
+type AssignmentHandler struct {
+	service assignment.Service
+}
+
+// NewAssignmentHandler 创建一个新的AssignmentHandler
+func NewAssignmentHandler(service assignment.Service) *AssignmentHandler {
+	return &AssignmentHandler{service: service}
+}
+
+// ListAssignments 处理获取我的作业列表的请求
+func (h *AssignmentHandler) ListAssignments(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var query dto.PageQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	page, err := h.service.ListMyAssignments(userID, &query)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取作业列表失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, page, "获取成功")
+}
+
+// GetAssignment 处理获取作业详情的请求
+func (h *AssignmentHandler) GetAssignment(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	assignmentID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	a, err := h.service.GetMyAssignment(userID, assignmentID)
+	if err != nil {
+		h.handleError(c, err, "获取作业详情失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, a, "获取成功")
+}
+
+// StartAttempt 处理开始或继续作答的请求
+func (h *AssignmentHandler) StartAttempt(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	assignmentID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	attempt, err := h.service.StartAttempt(userID, assignmentID)
+	if err != nil {
+		h.handleError(c, err, "开始作答失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, attempt, "开始作答")
+}
+
+// SubmitAttempt 处理提交作业的请求
+func (h *AssignmentHandler) SubmitAttempt(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	assignmentID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	attempt, err := h.service.SubmitAttempt(userID, assignmentID)
+	if err != nil {
+		h.handleError(c, err, "提交作业失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, attempt, "提交成功")
+}
+
+// handleError 将学生端作业相关的业务错误映射为HTTP响应
+func (h *AssignmentHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		response.Error(c, http.StatusNotFound, "作业不存在")
+	case errors.Is(err, assignment.ErrAssignmentClosed):
+		response.Error(c, http.StatusConflict, "作业已截止")
+	case errors.Is(err, assignment.ErrNoAttemptsLeft):
+		response.Error(c, http.StatusConflict, "作答次数已用完")
+	case errors.Is(err, assignment.ErrNoOpenAttempt):
+		response.Error(c, http.StatusConflict, "没有进行中的作答")
+	case errors.Is(err, assignment.ErrAttemptConflict):
+		response.Error(c, http.StatusConflict, "作答已在进行中，请勿重复开始")
+	case errors.Is(err, learning.ErrDailyLimitReached):
+		response.Error(c, http.StatusForbidden, "今日学习时长已达到家长设置的上限")
+	default:
+		response.Error(c, http.StatusInternalServerError, fallback)
+	}
+}
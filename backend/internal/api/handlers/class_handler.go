@@ -0,0 +1,98 @@
+/*
+File: class_handler.go
+Author: lxp
+Description: 班级API处理器 (学生端)
+*/
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/api/response"
+	"zhixue-backend/internal/service/class"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClassHandler 封装了学生端班级相关的API处理器
+type ClassHandler struct {
+	service class.Service
+}
+
+// NewClassHandler 创建一个新的ClassHandler
+func NewClassHandler(service class.Service) *ClassHandler {
+	return &ClassHandler{service: service}
+}
+
+// ListJoinedClasses 处理获取已加入班级列表的请求
+func (h *ClassHandler) ListJoinedClasses(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	classes, err := h.service.ListJoinedClasses(userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取班级列表失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, classes, "获取成功")
+}
+
+// JoinClass 处理通过加入码加入班级的请求
+func (h *ClassHandler) JoinClass(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.JoinClassRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	cls, err := h.service.JoinClass(userID, req.JoinCode)
+	if err != nil {
+		switch {
+		case errors.Is(err, class.ErrInvalidJoinCode):
+			response.Error(c, http.StatusBadRequest, "加入码无效")
+		case errors.Is(err, class.ErrClassArchived):
+			response.Error(c, http.StatusConflict, "班级已归档，无法加入")
+		case errors.Is(err, class.ErrAlreadyMember):
+			response.Error(c, http.StatusConflict, "已加入该班级")
+		case errors.Is(err, class.ErrNotStudent):
+			response.Error(c, http.StatusForbidden, "只有学生可以加入班级")
+		default:
+			response.Error(c, http.StatusInternalServerError, "加入班级失败")
+		}
+		return
+	}
+
+	response.Success(c, http.StatusCreated, cls, "加入成功")
+}
+
+// LeaveClass 处理退出班级的请求
+func (h *ClassHandler) LeaveClass(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	classID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.LeaveClass(userID, classID); err != nil {
+		if errors.Is(err, class.ErrNotMember) {
+			response.Error(c, http.StatusNotFound, "未加入该班级")
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "退出班级失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, nil, "已退出班级")
+}
@@ -0,0 +1,124 @@
+/*
+File: exam_handler.go
+Author: lxp
+Description: 限时考试API处理器
+*/
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/api/response"
+	"zhixue-backend/internal/service/exam"
+	"zhixue-backend/internal/service/learning"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ExamHandler 封装了限时考试相关的API处理器
+type ExamHandler struct {
+	service exam.Service
+}
+
+// NewExamHandler 创建一个新的ExamHandler
+func NewExamHandler(service exam.Service) *ExamHandler {
+	return &ExamHandler{service: service}
+}
+
+// StartExam 处理开始考试的请求
+func (h *ExamHandler) StartExam(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.StartExamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	e, err := h.service.StartExam(userID, &req)
+	if err != nil {
+		h.handleError(c, err, "开始考试失败")
+		return
+	}
+
+	response.Success(c, http.StatusCreated, e, "考试开始")
+}
+
+// ListExams 处理获取我的考试记录的请求
+func (h *ExamHandler) ListExams(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var query dto.PageQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	page, err := h.service.ListExams(userID, &query)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取考试记录失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, page, "获取成功")
+}
+
+// GetExam 处理获取试卷或成绩单的请求
+func (h *ExamHandler) GetExam(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	e, err := h.service.GetExam(userID, c.Param("session_id"))
+	if err != nil {
+		h.handleError(c, err, "获取考试详情失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, e, "获取成功")
+}
+
+// SubmitExam 处理交卷的请求
+func (h *ExamHandler) SubmitExam(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	e, err := h.service.SubmitExam(userID, c.Param("session_id"))
+	if err != nil {
+		h.handleError(c, err, "交卷失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, e, "交卷成功")
+}
+
+// handleError 将考试相关的业务错误映射为HTTP响应
+func (h *ExamHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		response.Error(c, http.StatusNotFound, "考试不存在")
+	case errors.Is(err, exam.ErrInvalidQuestions):
+		response.Error(c, http.StatusBadRequest, "题目不能重复且必须是已发布的题目")
+	case errors.Is(err, exam.ErrNoQuestions):
+		response.Error(c, http.StatusUnprocessableEntity, "没有符合条件的题目，无法组卷")
+	case errors.Is(err, exam.ErrExamFinished):
+		response.Error(c, http.StatusConflict, "考试已交卷")
+	case errors.Is(err, learning.ErrSessionTypeBlocked):
+		response.Error(c, http.StatusForbidden, "家长未允许该类型的学习会话")
+	case errors.Is(err, learning.ErrDailyLimitReached):
+		response.Error(c, http.StatusForbidden, "今日学习时长已达到家长设置的上限")
+	default:
+		response.Error(c, http.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,53 @@
+/*
+File: helpers.go
+Author: lxp
+Description: API处理器通用辅助函数
+*/
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"zhixue-backend/internal/api/response"
+	"zhixue-backend/internal/rbac"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currentUserID 从认证中间件注入的X-User-ID头中解析当前用户ID
+// 解析失败时会直接写入401响应，调用方只需判断ok后返回
+func currentUserID(c *gin.Context) (int64, bool) {
+	userIDStr := c.Request.Header.Get("X-User-ID")
+	if userIDStr == "" {
+		response.Error(c, http.StatusUnauthorized, "无法获取用户信息，缺少X-User-ID头")
+		return 0, false
+	}
+
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "无法解析用户信息")
+		return 0, false
+	}
+	return userID, true
+}
+
+// parseIDParam 解析路径中的数字ID参数
+// 解析失败时会直接写入400响应
+func parseIDParam(c *gin.Context, name string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil || id <= 0 {
+		response.Error(c, http.StatusBadRequest, "无效的ID参数")
+		return 0, false
+	}
+	return id, true
+}
+
+// currentSubject 获取当前用户及其角色，用于权限与资源归属校验
+// 解析失败时会直接写入401响应
+func currentSubject(c *gin.Context) (rbac.Subject, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return rbac.Subject{}, false
+	}
+	return rbac.Subject{UserID: userID, Role: c.Request.Header.Get(rbac.RoleHeader)}, true
+}
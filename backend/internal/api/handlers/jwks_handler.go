@@ -0,0 +1,30 @@
+/*
+File: jwks_handler.go
+Author: lxp
+Description: JWKS公钥发布处理器
+*/
+package handlers
+
+import (
+	"net/http"
+	"zhixue-backend/internal/jwtkeys"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWKSHandler 发布校验Token所需的公钥
+type JWKSHandler struct {
+	keys *jwtkeys.KeySet
+}
+
+// NewJWKSHandler 创建一个新的JWKSHandler
+func NewJWKSHandler(keys *jwtkeys.KeySet) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// GetJWKS 处理获取公钥集合的请求
+// 按 RFC 7517 直接返回 {"keys": [...]}，不使用统一响应包装，以便标准JWT库直接使用
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keys.JWKS())
+}
@@ -0,0 +1,101 @@
+/*
+File: knowledge_handler.go
+Author: lxp
+Description: 知识点树与先修关系API处理器
+*/
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/api/response"
+	"zhixue-backend/internal/service/knowledge"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// KnowledgeHandler 封装了知识点相关的API处理器
+type KnowledgeHandler struct {
+	service knowledge.Service
+}
+
+// NewKnowledgeHandler 创建一个新的KnowledgeHandler
+func NewKnowledgeHandler(service knowledge.Service) *KnowledgeHandler {
+	return &KnowledgeHandler{service: service}
+}
+
+// GetTree 处理获取知识点树的请求
+func (h *KnowledgeHandler) GetTree(c *gin.Context) {
+	var query dto.ListKnowledgePointsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	tree, err := h.service.GetTree(query.GradeLevel)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取知识点树失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, tree, "获取成功")
+}
+
+// GetSubtree 处理获取知识点子树的请求
+func (h *KnowledgeHandler) GetSubtree(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	node, err := h.service.GetSubtree(id)
+	if err != nil {
+		h.handleError(c, err, "获取知识点子树失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, node, "获取成功")
+}
+
+// GetBreadcrumbs 处理获取知识点路径的请求
+func (h *KnowledgeHandler) GetBreadcrumbs(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	path, err := h.service.GetBreadcrumbs(id)
+	if err != nil {
+		h.handleError(c, err, "获取知识点路径失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, path, "获取成功")
+}
+
+// GetPrerequisites 处理获取知识点先修关系的请求
+func (h *KnowledgeHandler) GetPrerequisites(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	result, err := h.service.GetPrerequisites(id)
+	if err != nil {
+		h.handleError(c, err, "获取先修知识点失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, result, "获取成功")
+}
+
+// handleError 将知识点相关的业务错误映射为HTTP响应
+func (h *KnowledgeHandler) handleError(c *gin.Context, err error, fallback string) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		response.Error(c, http.StatusNotFound, "知识点不存在")
+		return
+	}
+	response.Error(c, http.StatusInternalServerError, fallback)
+}
@@ -0,0 +1,174 @@
+/*
+File: learning_handler.go
+Author: lxp
+Description: 学习会话API处理器
+*/
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/api/response"
+	"zhixue-backend/internal/service/learning"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// LearningHandler 封装了学习会话相关的API处理器
+type LearningHandler struct {
+	service learning.Service
+}
+
+// NewLearningHandler 创建一个新的LearningHandler
+func NewLearningHandler(service learning.Service) *LearningHandler {
+	return &LearningHandler{service: service}
+}
+
+// StartSession 处理开始学习会话的请求
+func (h *LearningHandler) StartSession(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.StartSessionRequest
+	// 请求体可以为空，此时按练习模式创建
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+			return
+		}
+	}
+
+	session, err := h.service.StartSession(userID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, learning.ErrSessionTypeBlocked):
+			response.Error(c, http.StatusForbidden, "家长未允许该类型的学习会话")
+		case errors.Is(err, learning.ErrDailyLimitReached):
+			response.Error(c, http.StatusForbidden, "今日学习时长已达到家长设置的上限")
+		default:
+			response.Error(c, http.StatusInternalServerError, "创建学习会话失败")
+		}
+		return
+	}
+
+	response.Success(c, http.StatusCreated, session, "学习会话已开始")
+}
+
+// ListSessions 处理查询学习会话记录的请求
+func (h *LearningHandler) ListSessions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var query dto.ListSessionsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	page, err := h.service.ListSessions(userID, &query)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取学习会话记录失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, page, "获取成功")
+}
+
+// ListAnswers 处理查询答题记录的请求
+func (h *LearningHandler) ListAnswers(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var query dto.ListAnswersQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	page, err := h.service.ListAnswers(userID, &query)
+	if err != nil {
+		if errors.Is(err, learning.ErrExamInProgress) {
+			response.Error(c, http.StatusBadRequest, "考试进行中，不能按对错筛选")
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "获取答题记录失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, page, "获取成功")
+}
+
+// GetSession 处理获取学习会话详情的请求
+func (h *LearningHandler) GetSession(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	session, err := h.service.GetSession(userID, c.Param("session_id"))
+	if err != nil {
+		h.handleSessionError(c, err, "获取学习会话失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, session, "获取成功")
+}
+
+// PauseSession 处理暂停学习会话的请求
+func (h *LearningHandler) PauseSession(c *gin.Context) {
+	h.transition(c, h.service.PauseSession, "学习会话已暂停")
+}
+
+// ResumeSession 处理恢复学习会话的请求
+func (h *LearningHandler) ResumeSession(c *gin.Context) {
+	h.transition(c, h.service.ResumeSession, "学习会话已恢复")
+}
+
+// FinishSession 处理结束学习会话的请求
+func (h *LearningHandler) FinishSession(c *gin.Context) {
+	h.transition(c, h.service.FinishSession, "学习会话已完成")
+}
+
+// InterruptSession 处理中断学习会话的请求
+func (h *LearningHandler) InterruptSession(c *gin.Context) {
+	h.transition(c, h.service.InterruptSession, "学习会话已中断")
+}
+
+// transition 执行会话状态变更的通用流程
+func (h *LearningHandler) transition(c *gin.Context,
+	action func(userID int64, sessionID string) (*dto.LearningSessionResponse, error), msg string) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	session, err := action(userID, c.Param("session_id"))
+	if err != nil {
+		h.handleSessionError(c, err, "更新学习会话失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, session, msg)
+}
+
+// handleSessionError 将会话相关的业务错误映射为HTTP响应
+func (h *LearningHandler) handleSessionError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		response.Error(c, http.StatusNotFound, "学习会话不存在")
+	case errors.Is(err, learning.ErrInvalidTransition):
+		response.Error(c, http.StatusConflict, "当前会话状态不允许该操作")
+	case errors.Is(err, learning.ErrPauseNotAllowed):
+		response.Error(c, http.StatusForbidden, "当前会话类型不允许暂停")
+	default:
+		response.Error(c, http.StatusInternalServerError, fallback)
+	}
+}
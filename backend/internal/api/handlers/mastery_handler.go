@@ -0,0 +1,47 @@
+/*
+File: mastery_handler.go
+Author: lxp
+Description: 知识点掌握度API处理器
+*/
+package handlers
+
+import (
+	"net/http"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/api/response"
+	"zhixue-backend/internal/service/mastery"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MasteryHandler 封装了知识点掌握度相关的API处理器
+type MasteryHandler struct {
+	service mastery.Service
+}
+
+// NewMasteryHandler 创建一个新的MasteryHandler
+func NewMasteryHandler(service mastery.Service) *MasteryHandler {
+	return &MasteryHandler{service: service}
+}
+
+// GetMyMastery 处理获取当前用户知识点掌握度的请求
+func (h *MasteryHandler) GetMyMastery(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var query dto.ListMasteryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	result, err := h.service.ListMastery(userID, &query)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取知识点掌握度失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, result, "获取成功")
+}
@@ -0,0 +1,156 @@
+/*
+File: notebook_handler.go
+Author: lxp
+Description: 错题本API处理器
+*/
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/api/response"
+	"zhixue-backend/internal/service/learning"
+	"zhixue-backend/internal/service/notebook"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// NotebookHandler 封装了错题本相关的API处理器
+type NotebookHandler struct {
+	service notebook.Service
+}
+
+// NewNotebookHandler 创建一个新的NotebookHandler
+func NewNotebookHandler(service notebook.Service) *NotebookHandler {
+	return &NotebookHandler{service: service}
+}
+
+// ListEntries 处理获取错题本的请求
+func (h *NotebookHandler) ListEntries(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var query dto.ListNotebookQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	page, err := h.service.ListEntries(userID, &query)
+	if err != nil {
+		h.handleError(c, err, "获取错题本失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, page, "获取成功")
+}
+
+// ListGroups 处理获取错题分组统计的请求
+func (h *NotebookHandler) ListGroups(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var query dto.NotebookGroupsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	groups, err := h.service.ListGroups(userID, &query)
+	if err != nil {
+		h.handleError(c, err, "获取错题分组失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, groups, "获取成功")
+}
+
+// Resolve 处理将错题标记为已掌握的请求
+func (h *NotebookHandler) Resolve(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	questionID, ok := parseIDParam(c, "question_id")
+	if !ok {
+		return
+	}
+
+	entry, err := h.service.Resolve(userID, questionID)
+	if err != nil {
+		h.handleError(c, err, "标记已掌握失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, entry, "已标记为已掌握")
+}
+
+// StartPractice 处理由错题本生成练习会话的请求
+func (h *NotebookHandler) StartPractice(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.StartNotebookPracticeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	practice, err := h.service.StartPractice(userID, &req)
+	if err != nil {
+		h.handleError(c, err, "生成错题练习失败")
+		return
+	}
+
+	response.Success(c, http.StatusCreated, practice, "错题练习开始")
+}
+
+// GetPractice 处理获取错题练习详情的请求
+func (h *NotebookHandler) GetPractice(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	practice, err := h.service.GetPractice(userID, c.Param("session_id"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		response.Error(c, http.StatusNotFound, "错题练习不存在")
+		return
+	}
+	if err != nil {
+		h.handleError(c, err, "获取错题练习失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, practice, "获取成功")
+}
+
+// handleError 将错题本相关的业务错误映射为HTTP响应
+func (h *NotebookHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		response.Error(c, http.StatusNotFound, "错题不存在")
+	case errors.Is(err, notebook.ErrInvalidDateRange):
+		response.Error(c, http.StatusBadRequest, "日期范围不正确")
+	case errors.Is(err, notebook.ErrAlreadyResolved):
+		response.Error(c, http.StatusConflict, "该错题已标记为已掌握")
+	case errors.Is(err, notebook.ErrNotEnoughCorrect):
+		response.Error(c, http.StatusConflict, "答错后需再答对两次才能标记为已掌握")
+	case errors.Is(err, notebook.ErrNoEntries):
+		response.Error(c, http.StatusUnprocessableEntity, "没有符合条件的未掌握错题")
+	case errors.Is(err, learning.ErrSessionTypeBlocked):
+		response.Error(c, http.StatusForbidden, "家长未允许该类型的学习会话")
+	case errors.Is(err, learning.ErrDailyLimitReached):
+		response.Error(c, http.StatusForbidden, "今日学习时长已达到家长设置的上限")
+	default:
+		response.Error(c, http.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,88 @@
+/*
+File: notification_handler.go
+Author: lxp
+Description: 站内通知API处理器
+*/
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/api/response"
+	"zhixue-backend/internal/service/notification"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// NotificationHandler 封装了站内通知相关的API处理器
+type NotificationHandler struct {
+	service notification.Service
+}
+
+// NewNotificationHandler 创建一个新的NotificationHandler
+func NewNotificationHandler(service notification.Service) *NotificationHandler {
+	return &NotificationHandler{service: service}
+}
+
+// ListNotifications 处理获取当前用户通知列表的请求
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var query dto.ListNotificationsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	list, err := h.service.List(userID, &query)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取通知失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, list, "获取成功")
+}
+
+// MarkRead 处理将一条通知标记为已读的请求
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.MarkRead(userID, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Error(c, http.StatusNotFound, "通知不存在")
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "标记已读失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, nil, "已标记为已读")
+}
+
+// MarkAllRead 处理将全部通知标记为已读的请求
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.MarkAllRead(userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "标记已读失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, result, "已全部标记为已读")
+}
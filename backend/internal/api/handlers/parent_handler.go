@@ -0,0 +1,292 @@
+/*
+File: parent_handler.go
+Author: lxp
+Description: 家长账号API处理器
+*/
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/api/response"
+	"zhixue-backend/internal/service/learning"
+	"zhixue-backend/internal/service/notebook"
+	"zhixue-backend/internal/service/parent"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ParentHandler 封装了家长账号相关的API处理器
+type ParentHandler struct {
+	service parent.Service
+}
+
+// NewParentHandler 创建一个新的ParentHandler
+func NewParentHandler(service parent.Service) *ParentHandler {
+	return &ParentHandler{service: service}
+}
+
+// CreateInvite 处理孩子生成家长关联邀请码的请求
+func (h *ParentHandler) CreateInvite(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	invite, err := h.service.CreateInvite(userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "生成邀请码失败")
+		return
+	}
+
+	response.Success(c, http.StatusCreated, invite, "邀请码已生成")
+}
+
+// ListParents 处理孩子查看已关联家长的请求
+func (h *ParentHandler) ListParents(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	parents, err := h.service.ListParents(userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取家长列表失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, parents, "获取成功")
+}
+
+// LinkChild 处理家长使用邀请码关联孩子的请求
+func (h *ParentHandler) LinkChild(c *gin.Context) {
+	parentID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.LinkChildRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	child, err := h.service.LinkChild(parentID, req.InviteCode)
+	if err != nil {
+		h.handleError(c, err, "关联孩子失败")
+		return
+	}
+
+	response.Success(c, http.StatusCreated, child, "关联成功")
+}
+
+// UnlinkChild 处理家长解除与孩子关联的请求
+func (h *ParentHandler) UnlinkChild(c *gin.Context) {
+	parentID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	childID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.UnlinkChild(parentID, childID); err != nil {
+		h.handleError(c, err, "解除关联失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, nil, "已解除关联")
+}
+
+// ListChildren 处理家长获取已关联孩子列表的请求
+func (h *ParentHandler) ListChildren(c *gin.Context) {
+	parentID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	children, err := h.service.ListChildren(parentID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取孩子列表失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, children, "获取成功")
+}
+
+// GetChild 处理家长获取孩子资料与学习概况的请求
+func (h *ParentHandler) GetChild(c *gin.Context) {
+	parentID, childID, ok := h.parentAndChild(c)
+	if !ok {
+		return
+	}
+
+	profile, err := h.service.GetChildProfile(parentID, childID)
+	if err != nil {
+		h.handleError(c, err, "获取孩子资料失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, profile, "获取成功")
+}
+
+// ListChildSessions 处理家长查询孩子学习会话记录的请求
+func (h *ParentHandler) ListChildSessions(c *gin.Context) {
+	parentID, childID, ok := h.parentAndChild(c)
+	if !ok {
+		return
+	}
+
+	var query dto.ListSessionsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	page, err := h.service.ListChildSessions(parentID, childID, &query)
+	if err != nil {
+		h.handleError(c, err, "获取学习会话记录失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, page, "获取成功")
+}
+
+// ListChildAnswers 处理家长查询孩子答题记录的请求
+func (h *ParentHandler) ListChildAnswers(c *gin.Context) {
+	parentID, childID, ok := h.parentAndChild(c)
+	if !ok {
+		return
+	}
+
+	var query dto.ListAnswersQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	page, err := h.service.ListChildAnswers(parentID, childID, &query)
+	if err != nil {
+		h.handleError(c, err, "获取答题记录失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, page, "获取成功")
+}
+
+// ListChildNotebook 处理家长查询孩子错题本的请求
+func (h *ParentHandler) ListChildNotebook(c *gin.Context) {
+	parentID, childID, ok := h.parentAndChild(c)
+	if !ok {
+		return
+	}
+
+	var query dto.ListNotebookQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	page, err := h.service.ListChildNotebook(parentID, childID, &query)
+	if err != nil {
+		h.handleError(c, err, "获取错题本失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, page, "获取成功")
+}
+
+// ListChildNotebookGroups 处理家长查询孩子错题分组统计的请求
+func (h *ParentHandler) ListChildNotebookGroups(c *gin.Context) {
+	parentID, childID, ok := h.parentAndChild(c)
+	if !ok {
+		return
+	}
+
+	var query dto.NotebookGroupsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	groups, err := h.service.ListChildNotebookGroups(parentID, childID, &query)
+	if err != nil {
+		h.handleError(c, err, "获取错题分组失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, groups, "获取成功")
+}
+
+// GetControls 处理家长获取孩子学习管控设置的请求
+func (h *ParentHandler) GetControls(c *gin.Context) {
+	parentID, childID, ok := h.parentAndChild(c)
+	if !ok {
+		return
+	}
+
+	controls, err := h.service.GetControls(parentID, childID)
+	if err != nil {
+		h.handleError(c, err, "获取管控设置失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, controls, "获取成功")
+}
+
+// UpdateControls 处理家长更新孩子学习管控设置的请求
+func (h *ParentHandler) UpdateControls(c *gin.Context) {
+	parentID, childID, ok := h.parentAndChild(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateParentalControlRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	controls, err := h.service.UpdateControls(parentID, childID, &req)
+	if err != nil {
+		h.handleError(c, err, "更新管控设置失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, controls, "更新成功")
+}
+
+// parentAndChild 解析当前家长ID与路径中的孩子ID
+func (h *ParentHandler) parentAndChild(c *gin.Context) (int64, int64, bool) {
+	parentID, ok := currentUserID(c)
+	if !ok {
+		return 0, 0, false
+	}
+	childID, ok := parseIDParam(c, "id")
+	if !ok {
+		return 0, 0, false
+	}
+	return parentID, childID, true
+}
+
+// handleError 将家长相关的业务错误映射为HTTP响应
+// 未关联的孩子与不存在的孩子返回相同的响应，避免探测其他用户
+func (h *ParentHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, parent.ErrNotLinked), errors.Is(err, gorm.ErrRecordNotFound):
+		response.Error(c, http.StatusNotFound, "孩子不存在或未关联")
+	case errors.Is(err, parent.ErrInvalidInviteCode):
+		response.Error(c, http.StatusBadRequest, "邀请码无效或已过期")
+	case errors.Is(err, parent.ErrAlreadyLinked):
+		response.Error(c, http.StatusConflict, "已关联该孩子")
+	case errors.Is(err, notebook.ErrInvalidDateRange):
+		response.Error(c, http.StatusBadRequest, "日期范围不正确")
+	case errors.Is(err, learning.ErrExamInProgress):
+		response.Error(c, http.StatusBadRequest, "考试进行中，不能按对错筛选")
+	default:
+		response.Error(c, http.StatusInternalServerError, fallback)
+	}
+}
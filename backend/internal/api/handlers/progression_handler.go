@@ -0,0 +1,80 @@
+/*
+File: progression_handler.go
+Author: lxp
+Description: 连续学习与等级成长API处理器
+*/
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/api/response"
+	"zhixue-backend/internal/repository/wallet"
+	"zhixue-backend/internal/service/progression"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ProgressionHandler 封装了连续学习与等级成长相关的API处理器
+type ProgressionHandler struct {
+	service progression.Service
+}
+
+// NewProgressionHandler 创建一个新的ProgressionHandler
+func NewProgressionHandler(service progression.Service) *ProgressionHandler {
+	return &ProgressionHandler{service: service}
+}
+
+// GetMyProgress 处理获取当前用户连续学习与等级进度的请求
+func (h *ProgressionHandler) GetMyProgress(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var query dto.ProgressQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	progress, err := h.service.GetProgress(userID, &query)
+	if err != nil {
+		h.handleError(c, err, "获取成长进度失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, progress, "获取成功")
+}
+
+// PurchaseFreeze 处理用积分购买连续学习保护卡的请求
+func (h *ProgressionHandler) PurchaseFreeze(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	progress, err := h.service.PurchaseFreeze(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err, "购买保护卡失败")
+		return
+	}
+
+	response.Success(c, http.StatusCreated, progress, "购买成功")
+}
+
+// handleError 将成长相关的业务错误映射为HTTP响应
+func (h *ProgressionHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		response.Error(c, http.StatusNotFound, "学习档案不存在")
+	case errors.Is(err, progression.ErrFreezeLimitReached):
+		response.Error(c, http.StatusConflict, "持有的保护卡已达上限")
+	case errors.Is(err, wallet.ErrInsufficientBalance):
+		response.Error(c, http.StatusConflict, "积分不足")
+	default:
+		response.Error(c, http.StatusInternalServerError, fallback)
+	}
+}
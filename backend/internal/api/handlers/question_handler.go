@@ -0,0 +1,167 @@
+/*
+File: question_handler.go
+Author: lxp
+Description: 题库API处理器
+*/
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/api/response"
+	"zhixue-backend/internal/service/assignment"
+	"zhixue-backend/internal/service/exam"
+	"zhixue-backend/internal/service/learning"
+	"zhixue-backend/internal/service/notebook"
+	"zhixue-backend/internal/service/question"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// QuestionHandler 封装了题库相关的API处理器
+type QuestionHandler struct {
+	service question.Service
+}
+
+// NewQuestionHandler 创建一个新的QuestionHandler
+func NewQuestionHandler(service question.Service) *QuestionHandler {
+	return &QuestionHandler{service: service}
+}
+
+// ListQuestions 处理获取题目列表的请求 (支持分页/筛选/推荐)
+func (h *QuestionHandler) ListQuestions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var query dto.ListQuestionsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	page, err := h.service.ListQuestions(userID, &query)
+	if err != nil {
+		if errors.Is(err, question.ErrInvalidDifficulty) {
+			response.Error(c, http.StatusBadRequest, "无效的难度筛选条件")
+			return
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Error(c, http.StatusNotFound, "用户学习档案不存在")
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "获取题目列表失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, page, "获取成功")
+}
+
+// GetQuestion 处理获取题目详情的请求
+func (h *QuestionHandler) GetQuestion(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	questionID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	detail, err := h.service.GetQuestion(userID, questionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Error(c, http.StatusNotFound, "题目不存在")
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "获取题目详情失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, detail, "获取成功")
+}
+
+// SubmitAnswer 处理提交题目答案的请求
+func (h *QuestionHandler) SubmitAnswer(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	questionID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.SubmitAnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	result, err := h.service.SubmitAnswer(userID, questionID, &req, c.ClientIP())
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			response.Error(c, http.StatusNotFound, "题目不存在")
+		case errors.Is(err, question.ErrInvalidAnswer):
+			response.Error(c, http.StatusBadRequest, "答案格式不正确")
+		case errors.Is(err, question.ErrManualGradingRequired):
+			response.Error(c, http.StatusUnprocessableEntity, "该题型需要人工批改，暂不支持在线提交")
+		case errors.Is(err, question.ErrUnsupportedQuestionType):
+			response.Error(c, http.StatusUnprocessableEntity, "不支持的题目类型")
+		case errors.Is(err, learning.ErrSessionNotFound):
+			response.Error(c, http.StatusNotFound, "学习会话不存在")
+		case errors.Is(err, learning.ErrSessionNotActive):
+			response.Error(c, http.StatusConflict, "学习会话未在进行中")
+		case errors.Is(err, learning.ErrDuplicateAnswer):
+			response.Error(c, http.StatusConflict, "当前会话已作答过该题")
+		case errors.Is(err, learning.ErrHintsNotAllowed):
+			response.Error(c, http.StatusForbidden, "当前会话类型不允许使用提示")
+		case errors.Is(err, learning.ErrQuestionLimitReached):
+			response.Error(c, http.StatusConflict, "当前会话已达到答题数量上限")
+		case errors.Is(err, assignment.ErrQuestionNotInAssignment):
+			response.Error(c, http.StatusBadRequest, "该题目不属于当前作业")
+		case errors.Is(err, assignment.ErrQuestionAnswered):
+			response.Error(c, http.StatusConflict, "本次作答已提交过该题")
+		case errors.Is(err, assignment.ErrAttemptSubmitted):
+			response.Error(c, http.StatusConflict, "作业已提交")
+		case errors.Is(err, assignment.ErrAssignmentClosed):
+			response.Error(c, http.StatusConflict, "作业已截止")
+		case errors.Is(err, exam.ErrQuestionNotInExam):
+			response.Error(c, http.StatusBadRequest, "该题目不属于当前考试")
+		case errors.Is(err, exam.ErrQuestionAnswered):
+			response.Error(c, http.StatusConflict, "本场考试已作答过该题")
+		case errors.Is(err, exam.ErrExamFinished):
+			response.Error(c, http.StatusConflict, "考试已交卷")
+		case errors.Is(err, exam.ErrExamTimeUp):
+			response.Error(c, http.StatusConflict, "考试时间已到，已自动交卷")
+		case errors.Is(err, notebook.ErrQuestionNotInPractice):
+			response.Error(c, http.StatusBadRequest, "该题目不属于当前错题练习")
+		default:
+			response.Error(c, http.StatusInternalServerError, "提交答案失败")
+		}
+		return
+	}
+
+	response.Success(c, http.StatusCreated, result, "提交成功")
+}
+
+// ListKnowledgePoints 处理获取知识点列表的请求
+func (h *QuestionHandler) ListKnowledgePoints(c *gin.Context) {
+	var query dto.ListKnowledgePointsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	points, err := h.service.ListKnowledgePoints(query.GradeLevel)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取知识点列表失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, points, "获取成功")
+}
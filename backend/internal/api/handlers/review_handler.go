@@ -0,0 +1,63 @@
+/*
+File: review_handler.go
+Author: lxp
+Description: 错题间隔复习API处理器
+*/
+package handlers
+
+import (
+	"net/http"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/api/response"
+	"zhixue-backend/internal/service/review"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReviewHandler 封装了错题间隔复习相关的API处理器
+type ReviewHandler struct {
+	service review.Service
+}
+
+// NewReviewHandler 创建一个新的ReviewHandler
+func NewReviewHandler(service review.Service) *ReviewHandler {
+	return &ReviewHandler{service: service}
+}
+
+// ListDue 处理获取待复习题目的请求
+func (h *ReviewHandler) ListDue(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var query dto.ListDueReviewsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	page, err := h.service.ListDue(userID, &query)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取待复习题目失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, page, "获取成功")
+}
+
+// GetStats 处理获取当天复习统计的请求
+func (h *ReviewHandler) GetStats(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.service.GetStats(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取复习统计失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, stats, "获取成功")
+}
@@ -0,0 +1,105 @@
+/*
+File: task_handler.go
+Author: lxp
+Description: 游戏化任务API处理器
+*/
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/api/response"
+	"zhixue-backend/internal/service/task"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// TaskHandler 封装了游戏化任务相关的API处理器
+type TaskHandler struct {
+	service task.Service
+}
+
+// NewTaskHandler 创建一个新的TaskHandler
+func NewTaskHandler(service task.Service) *TaskHandler {
+	return &TaskHandler{service: service}
+}
+
+// ListTasks 处理获取任务列表的请求
+func (h *TaskHandler) ListTasks(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var query dto.ListTasksQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	tasks, err := h.service.ListTasks(userID, &query)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取任务列表失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, tasks, "获取成功")
+}
+
+// GetTask 处理获取任务详情的请求
+func (h *TaskHandler) GetTask(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	taskID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	t, err := h.service.GetTask(userID, taskID)
+	if err != nil {
+		h.handleError(c, err, "获取任务详情失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, t, "获取成功")
+}
+
+// ClaimTask 处理领取任务奖励的请求，重复领取返回当前状态而不重复发放
+func (h *TaskHandler) ClaimTask(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	taskID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	t, claimed, err := h.service.ClaimTask(c.Request.Context(), userID, taskID)
+	if err != nil {
+		h.handleError(c, err, "领取奖励失败")
+		return
+	}
+
+	msg := "领取成功"
+	if !claimed {
+		msg = "奖励已领取"
+	}
+	response.Success(c, http.StatusOK, t, msg)
+}
+
+// handleError 将任务相关的业务错误映射为HTTP响应
+func (h *TaskHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		response.Error(c, http.StatusNotFound, "任务不存在")
+	case errors.Is(err, task.ErrTaskNotCompleted):
+		response.Error(c, http.StatusConflict, "任务尚未完成")
+	default:
+		response.Error(c, http.StatusInternalServerError, fallback)
+	}
+}
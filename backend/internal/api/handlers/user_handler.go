@@ -7,12 +7,15 @@ package handlers
 
 import (
 	"errors"
+	"math"
 	"net/http"
 	"strconv"
 	"zhixue-backend/internal/api/dto"
 	"zhixue-backend/internal/api/response"
+	"zhixue-backend/internal/mail"
 	user_repo "zhixue-backend/internal/repository/user"
 	"zhixue-backend/internal/service/user"
+	"zhixue-backend/internal/session"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -36,7 +39,7 @@ func (h *UserHandler) Register(c *gin.Context) {
 		return
 	}
 
-	regResponse, err := h.service.Register(req.Username, req.Password, req.Email, req.Nickname)
+	regResponse, err := h.service.Register(req.Username, req.Password, req.Email, req.Nickname, req.AccountType, requestLang(c))
 	if err != nil {
 		if errors.Is(err, user_repo.ErrUsernameExists) {
 			response.Error(c, http.StatusConflict, "用户名已存在")
@@ -65,16 +68,66 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
-	token, user, err := h.service.Login(req.Username, req.Password)
+	loginResponse, err := h.service.Login(req.Username, req.Password, session.Device{
+		Name:      req.DeviceName,
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	})
 	if err != nil {
-		response.Error(c, http.StatusUnauthorized, "用户名或密码错误")
+		var blocked *user.LoginBlockedError
+		switch {
+		case errors.As(err, &blocked):
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(blocked.RetryAfter.Seconds()))))
+			if errors.Is(err, user.ErrAccountLocked) {
+				response.Error(c, http.StatusTooManyRequests, "登录失败次数过多，账号已临时锁定，请稍后再试")
+			} else {
+				response.Error(c, http.StatusTooManyRequests, "登录尝试过于频繁，请稍后再试")
+			}
+		case errors.Is(err, user.ErrInvalidCredentials):
+			response.Error(c, http.StatusUnauthorized, "用户名或密码错误")
+		case errors.Is(err, user.ErrAccountBanned):
+			response.Error(c, http.StatusForbidden, "账号已被封禁")
+		case errors.Is(err, user.ErrAccountInactive):
+			response.Error(c, http.StatusForbidden, "账号未激活或已停用")
+		default:
+			response.Error(c, http.StatusInternalServerError, "登录失败")
+		}
 		return
 	}
 
-	response.Success(c, http.StatusOK, dto.LoginResponse{
-		Token: token,
-		User:  user,
-	}, "登录成功")
+	response.Success(c, http.StatusOK, loginResponse, "登录成功")
+}
+
+// Refresh 处理刷新令牌请求
+func (h *UserHandler) Refresh(c *gin.Context) {
+	var req dto.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	tokens, err := h.service.Refresh(req.RefreshToken, session.Device{
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	})
+	if err != nil {
+		if errors.Is(err, user.ErrRefreshTokenReused) {
+			response.Error(c, http.StatusUnauthorized, "刷新令牌已被使用，请重新登录")
+			return
+		}
+		if errors.Is(err, user.ErrAccountBanned) || errors.Is(err, user.ErrAccountInactive) {
+			response.Error(c, http.StatusForbidden, "账号已被封禁或停用")
+			return
+		}
+		if errors.Is(err, user.ErrInvalidRefreshToken) {
+			response.Error(c, http.StatusUnauthorized, "刷新令牌无效或已过期")
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "刷新令牌失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, tokens, "刷新成功")
 }
 
 // GetMe 处理获取当前用户信息的请求
@@ -132,6 +185,10 @@ func (h *UserHandler) UpdateMe(c *gin.Context) {
 			response.Error(c, http.StatusNotFound, "用户不存在")
 			return
 		}
+		if errors.Is(err, user.ErrInvalidTimezone) {
+			response.Error(c, http.StatusBadRequest, "时区无法识别")
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, "更新用户信息失败: "+err.Error())
 		return
 	}
@@ -157,3 +214,138 @@ func (h *UserHandler) Logout(c *gin.Context) {
 	response.Success(c, http.StatusOK, nil, "登出成功")
 }
 
+// ListSessions 处理获取当前用户登录会话列表的请求
+func (h *UserHandler) ListSessions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.service.ListSessions(userID, c.Request.Header.Get(session.SessionHeader))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取会话列表失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, sessions, "获取成功")
+}
+
+// RevokeSession 处理吊销指定登录会话的请求
+func (h *UserHandler) RevokeSession(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RevokeSession(userID, c.Param("id")); err != nil {
+		if errors.Is(err, session.ErrSessionNotFound) {
+			response.Error(c, http.StatusNotFound, "会话不存在")
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "吊销会话失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, nil, "会话已吊销")
+}
+
+// RevokeAllSessions 处理吊销当前用户全部登录会话的请求 (所有设备退出登录)
+func (h *UserHandler) RevokeAllSessions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RevokeAllSessions(userID); err != nil {
+		response.Error(c, http.StatusInternalServerError, "吊销全部会话失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, nil, "已在所有设备上退出登录")
+}
+
+// SendVerificationEmail 处理重新发送邮箱验证邮件的请求
+func (h *UserHandler) SendVerificationEmail(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.SendVerificationEmail(userID, requestLang(c)); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			response.Error(c, http.StatusNotFound, "用户不存在")
+		case errors.Is(err, user.ErrEmailAlreadyVerified):
+			response.Error(c, http.StatusConflict, "邮箱已验证")
+		case errors.Is(err, user.ErrMailRateLimited):
+			response.Error(c, http.StatusTooManyRequests, "发送过于频繁，请稍后再试")
+		default:
+			response.Error(c, http.StatusInternalServerError, "发送验证邮件失败")
+		}
+		return
+	}
+
+	response.Success(c, http.StatusOK, nil, "验证邮件已发送")
+}
+
+// VerifyEmail 处理验证邮箱的请求
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	var req dto.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	if err := h.service.VerifyEmail(req.Token); err != nil {
+		if errors.Is(err, user.ErrInvalidEmailToken) {
+			response.Error(c, http.StatusBadRequest, "验证链接无效或已过期")
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "验证邮箱失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, nil, "邮箱验证成功")
+}
+
+// ForgotPassword 处理找回密码的请求
+// 无论邮箱是否注册都返回相同的结果，避免被用来探测邮箱
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	var req dto.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	if err := h.service.ForgotPassword(req.Email, requestLang(c)); err != nil {
+		response.Error(c, http.StatusInternalServerError, "发送重置邮件失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, nil, "如果该邮箱已注册，重置密码邮件将很快送达")
+}
+
+// ResetPassword 处理重置密码的请求
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	if err := h.service.ResetPassword(req.Token, req.NewPassword); err != nil {
+		if errors.Is(err, user.ErrInvalidEmailToken) {
+			response.Error(c, http.StatusBadRequest, "重置链接无效或已过期")
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "重置密码失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, nil, "密码已重置，请重新登录")
+}
+
+// requestLang 根据 Accept-Language 请求头选择邮件语言
+func requestLang(c *gin.Context) string {
+	return mail.NormalizeLang(c.GetHeader("Accept-Language"))
+}
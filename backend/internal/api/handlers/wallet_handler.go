@@ -0,0 +1,79 @@
+/*
+File: wallet_handler.go
+Author: lxp
+Description: 积分钱包API处理器
+*/
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/api/response"
+	"zhixue-backend/internal/service/wallet"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// WalletHandler 封装了积分钱包相关的API处理器
+type WalletHandler struct {
+	service wallet.Service
+}
+
+// NewWalletHandler 创建一个新的WalletHandler
+func NewWalletHandler(service wallet.Service) *WalletHandler {
+	return &WalletHandler{service: service}
+}
+
+// GetMyWallet 处理获取当前用户积分余额与积分流水的请求
+func (h *WalletHandler) GetMyWallet(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var query dto.WalletQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	w, err := h.service.GetWallet(c.Request.Context(), userID, &query)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取积分钱包失败")
+		return
+	}
+
+	response.Success(c, http.StatusOK, w, "获取成功")
+}
+
+// GrantPoints 处理管理员为用户发放积分的请求
+func (h *WalletHandler) GrantPoints(c *gin.Context) {
+	operatorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	userID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.GrantPointsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	t, err := h.service.GrantPoints(c.Request.Context(), operatorID, userID, &req)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Error(c, http.StatusNotFound, "用户不存在")
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "发放积分失败")
+		return
+	}
+
+	response.Success(c, http.StatusCreated, t, "发放成功")
+}
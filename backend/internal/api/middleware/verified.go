@@ -0,0 +1,45 @@
+/*
+File: verified.go
+Author: lxp
+Description: Gin中间件 - 要求当前用户已验证邮箱
+*/
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"zhixue-backend/internal/api/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmailVerifier 查询用户邮箱是否已验证
+type EmailVerifier interface {
+	IsEmailVerified(userID int64) (bool, error)
+}
+
+// RequireEmailVerified 要求当前用户 (网关注入的X-User-ID) 已验证邮箱，否则返回403
+func RequireEmailVerified(verifier EmailVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := strconv.ParseInt(c.Request.Header.Get("X-User-ID"), 10, 64)
+		if err != nil {
+			response.Error(c, http.StatusUnauthorized, "无法获取用户信息")
+			c.Abort()
+			return
+		}
+
+		verified, err := verifier.IsEmailVerified(userID)
+		if err != nil {
+			response.Error(c, http.StatusInternalServerError, "获取用户信息失败")
+			c.Abort()
+			return
+		}
+		if !verified {
+			response.Error(c, http.StatusForbidden, "请先验证邮箱")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
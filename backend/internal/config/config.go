@@ -24,6 +24,13 @@ type Config struct {
 	GameServer GameServerConfig `mapstructure:"game_server"`
 	Logging    LoggingConfig    `mapstructure:"logging"`
 	Gateway    GatewayConfig    `mapstructure:"gateway"`
+	Learning   LearningConfig   `mapstructure:"learning"`
+	Difficulty DifficultyConfig `mapstructure:"difficulty"`
+	Mastery    MasteryConfig    `mapstructure:"mastery"`
+	Mail       MailConfig       `mapstructure:"mail"`
+	Parent     ParentConfig     `mapstructure:"parent"`
+
+	Progression ProgressionConfig `mapstructure:"progression"`
 }
 
 // AppConfig 应用配置
@@ -66,16 +73,37 @@ type RedisConfig struct {
 
 // AuthConfig 认证配置
 type AuthConfig struct {
-	JWTSecret      string        `mapstructure:"jwt_secret"`
-	JWTExpires     time.Duration `mapstructure:"jwt_expires"`
-	RefreshExpires time.Duration `mapstructure:"refresh_expires"`
+	JWTExpires          time.Duration         `mapstructure:"jwt_expires"`
+	RefreshExpires      time.Duration         `mapstructure:"refresh_expires"`
+	SigningAlgorithm    string                `mapstructure:"signing_algorithm"`     // RS256 或 EdDSA
+	KeysDir             string                `mapstructure:"keys_dir"`              // 签名私钥目录，仅签发方需要
+	KeyRotationInterval time.Duration         `mapstructure:"key_rotation_interval"` // 签名密钥轮换周期
+	JWKSURL             string                `mapstructure:"jwks_url"`              // 校验方拉取公钥的地址，为空时使用本机后端服务
+	JWKSRefresh         time.Duration         `mapstructure:"jwks_refresh"`          // 校验方公钥缓存的刷新周期
+	LoginProtection     LoginProtectionConfig `mapstructure:"login_protection"`
+}
+
+// LoginProtectionConfig 登录防暴力破解配置
+type LoginProtectionConfig struct {
+	FailureWindow   time.Duration `mapstructure:"failure_window"`    // 失败次数的统计窗口，窗口内无新的失败则计数清零
+	DelayAfter      int           `mapstructure:"delay_after"`       // 同一用户名连续失败达到该次数后开始要求等待
+	BaseDelay       time.Duration `mapstructure:"base_delay"`        // 首次等待时长，此后每失败一次翻倍
+	MaxDelay        time.Duration `mapstructure:"max_delay"`         // 单次等待时长上限
+	LockThreshold   int           `mapstructure:"lock_threshold"`    // 同一用户名失败达到该次数后锁定账号
+	LockDuration    time.Duration `mapstructure:"lock_duration"`     // 账号锁定时长
+	IPLockThreshold int           `mapstructure:"ip_lock_threshold"` // 同一IP失败达到该次数后锁定该IP的登录
+	IPLockDuration  time.Duration `mapstructure:"ip_lock_duration"`  // IP锁定时长
 }
 
 // AIServiceConfig AI服务配置
 type AIServiceConfig struct {
-	URL        string        `mapstructure:"url"`
-	Timeout    time.Duration `mapstructure:"timeout"`
-	RetryCount int           `mapstructure:"retry_count"`
+	URL              string        `mapstructure:"url"`
+	Timeout          time.Duration `mapstructure:"timeout"`
+	RetryCount       int           `mapstructure:"retry_count"`
+	RetryBackoff     time.Duration `mapstructure:"retry_backoff"`     // 首次重试前的等待时间，之后按指数增长
+	MaxRetryBackoff  time.Duration `mapstructure:"max_retry_backoff"` // 单次重试等待时间上限
+	BreakerThreshold int           `mapstructure:"breaker_threshold"` // 连续失败多少次后熔断
+	BreakerCooldown  time.Duration `mapstructure:"breaker_cooldown"`  // 熔断后多久允许试探请求
 }
 
 // GameServerConfig 游戏服务器配置
@@ -90,6 +118,72 @@ type GatewayConfig struct {
 	Port int `mapstructure:"port"`
 }
 
+// LearningConfig 学习会话配置
+type LearningConfig struct {
+	SessionIdleTimeout time.Duration `mapstructure:"session_idle_timeout"` // 会话无活动超过该时长将被标记为中断
+	SweepInterval      time.Duration `mapstructure:"sweep_interval"`       // 空闲会话清理与考试超时交卷任务的执行间隔
+	ExamTimeLimit      time.Duration `mapstructure:"exam_time_limit"`      // 开考未指定时长时的默认考试时长
+}
+
+// DifficultyConfig 自适应难度配置
+type DifficultyConfig struct {
+	Engine              string        `mapstructure:"engine"`               // 难度引擎名称，默认 elo
+	WindowSize          int           `mapstructure:"window_size"`          // 参与计算的最近答题数
+	MinAnswers          int           `mapstructure:"min_answers"`          // 窗口内至少需要的答题数
+	KFactor             float64       `mapstructure:"k_factor"`             // 单次调整的学习率
+	MaxStep             float64       `mapstructure:"max_step"`             // 单次调整的最大幅度
+	MinStep             float64       `mapstructure:"min_step"`             // 小于该幅度的调整将被忽略
+	RecalibrateInterval time.Duration `mapstructure:"recalibrate_interval"` // 定时重新评估的执行间隔
+	StaleAfter          time.Duration `mapstructure:"stale_after"`          // 有答题但超过该时长未调整的用户会被定时重新评估
+	AITimeout           time.Duration `mapstructure:"ai_timeout"`           // 答题触发时 ai 引擎调用 AI 服务的时限，超时后改用 elo 引擎
+}
+
+// MasteryConfig 知识点掌握度 (BKT) 配置，单个知识点的参数可在后台覆盖
+type MasteryConfig struct {
+	PInit             float64 `mapstructure:"p_init"`             // 默认初始掌握概率
+	PLearn            float64 `mapstructure:"p_learn"`            // 默认每次作答后学会的概率
+	PSlip             float64 `mapstructure:"p_slip"`             // 默认已掌握但答错的概率
+	PGuess            float64 `mapstructure:"p_guess"`            // 默认未掌握但猜对的概率
+	MasteredThreshold float64 `mapstructure:"mastered_threshold"` // 掌握概率达到该值视为已掌握
+}
+
+// ProgressionConfig 连续学习与等级成长配置
+type ProgressionConfig struct {
+	DefaultTimezone   string  `mapstructure:"default_timezone"`    // 用户未设置时区时按该时区划分学习日，为空时使用服务器时区
+	AnswerXP          int     `mapstructure:"answer_xp"`           // 每次答题获得的经验值
+	CorrectXP         int     `mapstructure:"correct_xp"`          // 答对额外获得的经验值
+	DifficultyXP      float64 `mapstructure:"difficulty_xp"`       // 答对时题目难度每高1级额外获得的经验值
+	LevelBaseXP       int     `mapstructure:"level_base_xp"`       // 等级曲线：升到 n 级需要的累计经验值为 level_base_xp * (n-1)^level_exponent
+	LevelExponent     float64 `mapstructure:"level_exponent"`      // 等级曲线的指数，大于1时越往后升级越慢
+	MaxLevel          int     `mapstructure:"max_level"`           // 最高等级
+	FreezePrice       int     `mapstructure:"freeze_price"`        // 连续学习保护卡的积分价格
+	MaxFreezes        int     `mapstructure:"max_freezes"`         // 最多同时持有的保护卡数
+	StreakBonusEvery  int     `mapstructure:"streak_bonus_every"`  // 连续学习每满多少天奖励一次积分，0 表示不奖励
+	StreakBonusPoints int     `mapstructure:"streak_bonus_points"` // 每次连续学习奖励的积分
+}
+
+// MailConfig 邮件配置
+type MailConfig struct {
+	Driver             string        `mapstructure:"driver"`               // smtp 或 file (本地开发)
+	From               string        `mapstructure:"from"`                 // 发件人，如 "智学奇境 <noreply@example.com>"
+	SMTPHost           string        `mapstructure:"smtp_host"`            // SMTP服务器地址
+	SMTPPort           int           `mapstructure:"smtp_port"`            // SMTP服务器端口
+	Username           string        `mapstructure:"username"`             // SMTP认证用户名，为空时不认证
+	Password           string        `mapstructure:"password"`             // SMTP认证密码
+	ImplicitTLS        bool          `mapstructure:"implicit_tls"`         // 是否使用隐式TLS (如465端口)
+	Timeout            time.Duration `mapstructure:"timeout"`              // 单封邮件的发送超时
+	OutputDir          string        `mapstructure:"output_dir"`           // file 驱动写入邮件的目录
+	LinkBaseURL        string        `mapstructure:"link_base_url"`        // 邮件中链接指向的前端地址
+	VerifyTokenExpires time.Duration `mapstructure:"verify_token_expires"` // 邮箱验证链接有效期
+	ResetTokenExpires  time.Duration `mapstructure:"reset_token_expires"`  // 重置密码链接有效期
+	ResendCooldown     time.Duration `mapstructure:"resend_cooldown"`      // 同一用户两次发送同类邮件的最小间隔
+}
+
+// ParentConfig 家长账号配置
+type ParentConfig struct {
+	InviteExpires time.Duration `mapstructure:"invite_expires"` // 孩子生成的关联邀请码有效期
+}
+
 // LoggingConfig 日志配置
 type LoggingConfig struct {
 	Level      string `mapstructure:"level"`
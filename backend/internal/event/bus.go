@@ -0,0 +1,52 @@
+/*
+File: bus.go
+Author: lxp
+Description: 进程内事件总线，用于在业务模块之间解耦地传递领域事件
+*/
+package event
+
+import (
+	"context"
+	"sync"
+	"zhixue-backend/logger"
+)
+
+// Event 定义领域事件的接口
+type Event interface {
+	Topic() string
+}
+
+// Handler 事件处理函数
+type Handler func(ctx context.Context, e Event) error
+
+// Bus 是一个同步的进程内事件总线
+// 订阅者按注册顺序依次执行，单个订阅者失败只记录日志，不影响其他订阅者和发布方
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+// NewBus 创建一个新的事件总线
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[string][]Handler)}
+}
+
+// Subscribe 订阅指定主题的事件
+func (b *Bus) Subscribe(topic string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[topic] = append(b.handlers[topic], handler)
+}
+
+// Publish 发布事件，并同步调用所有订阅者
+func (b *Bus) Publish(ctx context.Context, e Event) {
+	b.mu.RLock()
+	handlers := b.handlers[e.Topic()]
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		if err := handler(ctx, e); err != nil {
+			logger.LogError("event", e.Topic(), err, nil)
+		}
+	}
+}
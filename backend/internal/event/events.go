@@ -0,0 +1,73 @@
+/*
+File: events.go
+Author: lxp
+Description: 领域事件定义
+*/
+package event
+
+import "time"
+
+// 事件主题
+const (
+	TopicAnswerSubmitted = "answer.submitted"
+	TopicSessionEnded    = "session.ended"
+	TopicExamFinished    = "exam.finished"
+	TopicLevelUp         = "progression.level_up"
+)
+
+// AnswerSubmitted 在答题记录写入成功后发布
+type AnswerSubmitted struct {
+	UserID        int64
+	QuestionID    int64
+	SessionID     string
+	IsCorrect     bool
+	Difficulty    float64 // 答题时的题目难度
+	ResponseTime  int     // 答题用时 (秒)
+	HintUsedCount int
+	AnsweredAt    time.Time
+}
+
+// Topic 实现 Event 接口
+func (AnswerSubmitted) Topic() string { return TopicAnswerSubmitted }
+
+// SessionEnded 在学习会话结束 (正常完成或中断) 后发布
+type SessionEnded struct {
+	UserID          int64
+	SessionID       string
+	SessionType     string
+	Status          string // completed | interrupted
+	StartedAt       time.Time
+	EndedAt         time.Time
+	DurationSeconds int // 有效学习时长，不含暂停时间
+	QuestionsCount  int
+	CorrectCount    int
+}
+
+// Topic 实现 Event 接口
+func (SessionEnded) Topic() string { return TopicSessionEnded }
+
+// ExamFinished 在考试交卷 (主动交卷或超时自动交卷) 并完成判分后发布
+type ExamFinished struct {
+	UserID        int64
+	SessionID     string
+	Status        string // submitted | expired
+	QuestionCount int
+	CorrectCount  int
+	Score         float64
+	SubmittedAt   time.Time
+}
+
+// Topic 实现 Event 接口
+func (ExamFinished) Topic() string { return TopicExamFinished }
+
+// LevelUp 在用户的经验值达到更高等级后发布，一次获得的经验值跨越多级时只发布一次
+type LevelUp struct {
+	UserID     int64
+	OldLevel   int
+	NewLevel   int
+	LevelScore int // 升级后的累计经验值
+	At         time.Time
+}
+
+// Topic 实现 Event 接口
+func (LevelUp) Topic() string { return TopicLevelUp }
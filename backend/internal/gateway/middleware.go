@@ -7,12 +7,15 @@ package gateway
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 	"zhixue-backend/internal/api/response"
 	"zhixue-backend/internal/config"
+	"zhixue-backend/internal/jwtkeys"
 	"zhixue-backend/internal/redis"
+	"zhixue-backend/internal/session"
 	"zhixue-backend/logger"
 
 	"github.com/gin-gonic/gin"
@@ -28,17 +31,28 @@ const (
 )
 
 // AuthMiddleware 创建一个Gin中间件用于JWT认证
-func AuthMiddleware(cfg *config.AuthConfig) gin.HandlerFunc {
+// 网关只持有从JWKS拉取的公钥，可以校验Token但无法签发Token
+func AuthMiddleware(cfg *config.AuthConfig, keys *jwtkeys.RemoteKeySet) gin.HandlerFunc {
 	// 定义不需要认证的公开路由
 	publicPaths := map[string]bool{
-		"/api/v1/users/register": true,
-		"/api/v1/users/login":    true,
+		"/api/v1/users/register":        true,
+		"/api/v1/users/login":           true,
+		"/api/v1/users/refresh":         true,
+		"/api/v1/users/verify-email":    true,
+		"/api/v1/users/password/forgot": true,
+		"/api/v1/users/password/reset":  true,
 	}
+	sessions := session.NewRegistry(redis.Client, cfg)
 
 	return func(c *gin.Context) {
 		// [DEBUG] 打印进入中间件的请求路径
 		logger.Logger.Info("AuthMiddleware: checking request path", zap.String("path", c.Request.URL.Path))
 
+		// 身份信息只能由网关注入，丢弃客户端伪造的同名请求头
+		c.Request.Header.Del("X-User-ID")
+		c.Request.Header.Del("X-User-Role")
+		c.Request.Header.Del(session.SessionHeader)
+
 		// 如果当前请求路径在公开路由列表中，则直接跳过认证
 		if publicPaths[c.Request.URL.Path] {
 			c.Next()
@@ -61,12 +75,7 @@ func AuthMiddleware(cfg *config.AuthConfig) gin.HandlerFunc {
 
 		tokenString := parts[1]
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(cfg.JWTSecret), nil
-		})
+		token, err := jwt.Parse(tokenString, keys.Keyfunc, jwt.WithValidMethods(jwtkeys.ValidMethods))
 
 		if err != nil {
 			logger.Logger.Warn("JWT验证失败", zap.Error(err))
@@ -83,15 +92,6 @@ func AuthMiddleware(cfg *config.AuthConfig) gin.HandlerFunc {
 				return
 			}
 
-			// 检查jti是否在黑名单中
-			blacklistKey := "jwt_blacklist:" + jti
-			val, err := redis.Client.Get(context.Background(), blacklistKey).Result()
-			if err == nil && val == "true" {
-				response.Error(c, http.StatusUnauthorized, "Token已失效(登出)")
-				c.Abort()
-				return
-			}
-
 			// user_id 在 JWT claim 中是 float64 类型
 			userIDFloat, ok1 := claims["user_id"].(float64)
 			userRole, ok2 := claims["user_role"].(string)
@@ -102,12 +102,40 @@ func AuthMiddleware(cfg *config.AuthConfig) gin.HandlerFunc {
 				return
 			}
 
+			// 检查Token是否已登出、所属会话是否已吊销、令牌版本是否过期 (全部登出)
+			// fid 与 ver 为后来新增的 claim，旧Token中缺失时按空会话和0版本处理
+			sessionID, _ := claims["fid"].(string)
+			version, _ := claims["ver"].(float64)
+			err := sessions.Check(context.Background(), &session.TokenRef{
+				JTI:       jti,
+				SessionID: sessionID,
+				UserID:    int64(userIDFloat),
+				Version:   int64(version),
+				IP:        c.ClientIP(),
+			})
+			switch {
+			case errors.Is(err, session.ErrTokenBlacklisted):
+				response.Error(c, http.StatusUnauthorized, "Token已失效(登出)")
+				c.Abort()
+				return
+			case errors.Is(err, session.ErrSessionRevoked), errors.Is(err, session.ErrTokenVersionStale):
+				response.Error(c, http.StatusUnauthorized, "Token已失效(会话已吊销)")
+				c.Abort()
+				return
+			case err != nil:
+				// Redis 不可用时不阻断请求，与原黑名单校验的行为一致
+				logger.Logger.Warn("会话校验失败", zap.Error(err))
+			}
+
 			// 将 float64 类型的 userID 转换为字符串
 			userID := fmt.Sprintf("%.0f", userIDFloat)
 
 			// 将用户信息添加到请求头，以便后端服务获取
 			c.Request.Header.Set("X-User-ID", userID)
 			c.Request.Header.Set("X-User-Role", userRole)
+			if sessionID != "" {
+				c.Request.Header.Set(session.SessionHeader, sessionID)
+			}
 
 			c.Next()
 		} else {
@@ -9,6 +9,8 @@ import (
 	"fmt"
 	"zhixue-backend/internal/api/middleware"
 	"zhixue-backend/internal/config"
+	"zhixue-backend/internal/jwtkeys"
+	"zhixue-backend/internal/rbac"
 	"zhixue-backend/logger"
 
 	"github.com/gin-gonic/gin"
@@ -33,6 +35,13 @@ func RegisterRoutes(r *gin.Engine, cfg *config.Config) {
 		logger.Logger.Fatal("创建AI服务代理失败", zap.Error(err))
 	}
 
+	// 校验Token使用的公钥集，从后端API服务的JWKS端点拉取
+	jwksURL := cfg.Auth.JWKSURL
+	if jwksURL == "" {
+		jwksURL = fmt.Sprintf("http://localhost:%d/.well-known/jwks.json", cfg.App.Port)
+	}
+	jwks := jwtkeys.NewRemoteKeySet(jwksURL, cfg.Auth.JWKSRefresh)
+
 	// 健康检查
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -42,10 +51,15 @@ func RegisterRoutes(r *gin.Engine, cfg *config.Config) {
 		})
 	})
 
+	// 公开的JWKS端点，供其他服务获取校验Token的公钥
+	r.GET("/.well-known/jwks.json", backendAPIProxy)
+
 	// V1 API 路由组
 	// AuthMiddleware 将智能地跳过白名单中的 /register 和 /login 路由
 	apiV1 := r.Group("/api/v1")
-	apiV1.Use(AuthMiddleware(&cfg.Auth))
+	apiV1.Use(AuthMiddleware(&cfg.Auth, jwks))
+	apiV1.Use(rbac.RequirePermissionForPrefix("/api/v1/admin", rbac.PermAdminAccess))
+	apiV1.Use(rbac.RequirePermissionForPrefix("/api/v1/parent", rbac.PermChildRead))
 	{
 		// 将所有 /api/v1/* 的请求都代理到后端API服务
 		apiV1.Any("/*path", backendAPIProxy)
@@ -53,7 +67,7 @@ func RegisterRoutes(r *gin.Engine, cfg *config.Config) {
 
 	// AI 服务路由组 (所有AI路由都需要认证)
 	ai := r.Group("/ai")
-	ai.Use(AuthMiddleware(&cfg.Auth))
+	ai.Use(AuthMiddleware(&cfg.Auth, jwks))
 	{
 		// 将所有/ai的请求转发到AI服务
 		ai.Any("/*path", aiServiceProxy)
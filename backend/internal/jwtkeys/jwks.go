@@ -0,0 +1,87 @@
+/*
+File: jwks.go
+Author: lxp
+Description: JSON Web Key (RFC 7517) 的编码与解析
+*/
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// JWKS 是 /.well-known/jwks.json 返回的公钥集合
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK 是单个公钥，RSA 使用 n/e，Ed25519 使用 crv/x
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+// NewJWK 将公钥编码为JWK
+func NewJWK(kid string, public crypto.PublicKey) (*JWK, error) {
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		return &JWK{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			Alg: AlgRS256,
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return &JWK{
+			Kty: "OKP",
+			Kid: kid,
+			Use: "sig",
+			Alg: AlgEdDSA,
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(pub),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", public)
+	}
+}
+
+// PublicKey 解析JWK中的公钥
+func (k *JWK) PublicKey() (crypto.PublicKey, error) {
+	switch {
+	case k.Kty == "RSA" && k.Alg == AlgRS256:
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rsa modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rsa exponent: %w", err)
+		}
+		exp := new(big.Int).SetBytes(e)
+		if !exp.IsInt64() || exp.Int64() < 2 || exp.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid rsa exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+	case k.Kty == "OKP" && k.Crv == "Ed25519" && k.Alg == AlgEdDSA:
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ed25519 public key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported jwk kty=%s alg=%s", k.Kty, k.Alg)
+	}
+}
@@ -0,0 +1,351 @@
+/*
+File: keyset.go
+Author: lxp
+Description: JWT签名密钥集，从磁盘加载多把密钥并按计划轮换
+*/
+package jwtkeys
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"zhixue-backend/internal/config"
+	"zhixue-backend/logger"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrUnknownKey        = errors.New("unknown signing key")
+	ErrAlgorithmMismatch = errors.New("token algorithm does not match key")
+	ErrNoSigningKey      = errors.New("no signing key available")
+)
+
+// 支持的签名算法
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// 未配置时使用的默认值
+const (
+	defaultAlgorithm        = AlgEdDSA
+	defaultKeysDir          = "./keys"
+	defaultRotationInterval = 30 * 24 * time.Hour
+	rotationCheckInterval   = time.Hour
+	rsaKeyBits              = 2048
+	kidTimeLayout           = "20060102T150405Z"
+)
+
+// ValidMethods 是校验方允许的签名算法，用于拒绝 none/HS256 等算法混淆攻击
+var ValidMethods = []string{AlgRS256, AlgEdDSA}
+
+// Key 是一把签名密钥
+type Key struct {
+	ID        string
+	Algorithm string
+	CreatedAt time.Time
+	private   crypto.Signer
+}
+
+// Public 返回密钥的公钥部分
+func (k *Key) Public() crypto.PublicKey {
+	return k.private.Public()
+}
+
+// KeySet 是签名方持有的密钥集
+// 目录中的每个 <kid>.pem 文件是一把 PKCS#8 私钥；最新的一把用于签名，其余的仅用于校验，
+// 直到由它们签发的Token全部过期后才会被清理
+type KeySet struct {
+	mu        sync.RWMutex
+	dir       string
+	algorithm string
+	interval  time.Duration // 轮换周期
+	retention time.Duration // 密钥停止签名后继续保留的时长
+	keys      []*Key        // 按创建时间升序
+}
+
+// LoadKeySet 从配置的目录加载密钥集，目录中没有可用密钥时生成第一把
+func LoadKeySet(cfg *config.AuthConfig) (*KeySet, error) {
+	s := &KeySet{
+		dir:       cfg.KeysDir,
+		algorithm: cfg.SigningAlgorithm,
+		interval:  cfg.KeyRotationInterval,
+		retention: cfg.JWTExpires + rotationCheckInterval,
+	}
+	if s.dir == "" {
+		s.dir = defaultKeysDir
+	}
+	if s.algorithm == "" {
+		s.algorithm = defaultAlgorithm
+	}
+	if s.algorithm != AlgRS256 && s.algorithm != AlgEdDSA {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", s.algorithm)
+	}
+	if s.interval <= 0 {
+		s.interval = defaultRotationInterval
+	}
+
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create keys dir: %w", err)
+	}
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	if err := s.rotateIfDue(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Sign 使用当前密钥签发Token，并在头部写入 kid
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	k := s.current()
+	if k == nil {
+		return "", ErrNoSigningKey
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(k.Algorithm), claims)
+	token.Header["kid"] = k.ID
+	return token.SignedString(k.private)
+}
+
+// Keyfunc 按Token头部的 kid 查找校验公钥，可直接传给 jwt.Parse
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, k := range s.keys {
+		if k.ID == kid {
+			if token.Method.Alg() != k.Algorithm {
+				return nil, ErrAlgorithmMismatch
+			}
+			return k.Public(), nil
+		}
+	}
+	return nil, ErrUnknownKey
+}
+
+// JWKS 返回全部密钥的公钥，供 /.well-known/jwks.json 发布
+func (s *KeySet) JWKS() *JWKS {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	set := &JWKS{Keys: make([]JWK, 0, len(s.keys))}
+	// 最新的密钥排在前面
+	for i := len(s.keys) - 1; i >= 0; i-- {
+		jwk, err := NewJWK(s.keys[i].ID, s.keys[i].Public())
+		if err != nil {
+			continue
+		}
+		set.Keys = append(set.Keys, *jwk)
+	}
+	return set
+}
+
+// Rotate 立即生成一把新密钥并用于签名
+func (s *KeySet) Rotate() error {
+	if err := s.generate(); err != nil {
+		return err
+	}
+	if err := s.reload(); err != nil {
+		return err
+	}
+	return s.prune()
+}
+
+// StartRotation 启动密钥轮换任务
+// 定期重新加载目录 (以便多个实例共享同一密钥目录)，到期时生成新密钥并清理过期的旧密钥
+func (s *KeySet) StartRotation(ctx context.Context) {
+	ticker := time.NewTicker(rotationCheckInterval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.reload(); err != nil {
+					logger.Logger.Error("重新加载JWT签名密钥失败", zap.Error(err))
+					continue
+				}
+				if err := s.rotateIfDue(); err != nil {
+					logger.Logger.Error("JWT签名密钥轮换失败", zap.Error(err))
+					continue
+				}
+				if err := s.prune(); err != nil {
+					logger.Logger.Error("清理过期JWT签名密钥失败", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// current 返回当前用于签名的密钥
+func (s *KeySet) current() *Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.keys) == 0 {
+		return nil
+	}
+	return s.keys[len(s.keys)-1]
+}
+
+// rotateIfDue 没有密钥或当前密钥已超过轮换周期时生成新密钥
+func (s *KeySet) rotateIfDue() error {
+	k := s.current()
+	if k != nil && time.Since(k.CreatedAt) < s.interval {
+		return nil
+	}
+	if err := s.Rotate(); err != nil {
+		return err
+	}
+	logger.Logger.Info("JWT签名密钥已轮换", zap.String("kid", s.current().ID))
+	return nil
+}
+
+// generate 生成一把新密钥并写入目录
+func (s *KeySet) generate() error {
+	var private crypto.Signer
+	var err error
+	switch s.algorithm {
+	case AlgRS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	default:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return fmt.Errorf("failed to encode signing key: %w", err)
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Errorf("failed to generate key id: %w", err)
+	}
+	kid := time.Now().UTC().Format(kidTimeLayout) + "-" + hex.EncodeToString(suffix)
+
+	// 先写临时文件再重命名，避免其他实例读到不完整的密钥
+	path := filepath.Join(s.dir, kid+".pem")
+	tmp := path + ".tmp"
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write signing key: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write signing key: %w", err)
+	}
+	return nil
+}
+
+// reload 从目录重新加载全部密钥，无法解析的文件会被跳过
+func (s *KeySet) reload() error {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*.pem"))
+	if err != nil {
+		return fmt.Errorf("failed to list signing keys: %w", err)
+	}
+
+	keys := make([]*Key, 0, len(paths))
+	for _, path := range paths {
+		k, err := readKey(path)
+		if err != nil {
+			logger.Logger.Warn("跳过无法解析的JWT签名密钥", zap.String("path", path), zap.Error(err))
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].ID < keys[j].ID
+		}
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+	return nil
+}
+
+// prune 删除由其签发的Token已全部过期的旧密钥
+// 一把密钥在下一把密钥生成后停止签名，再经过 retention 后即可删除
+func (s *KeySet) prune() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := make([]*Key, 0, len(s.keys))
+	for i, k := range s.keys {
+		if i < len(s.keys)-1 && time.Since(s.keys[i+1].CreatedAt) > s.retention {
+			if err := os.Remove(filepath.Join(s.dir, k.ID+".pem")); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove signing key %s: %w", k.ID, err)
+			}
+			logger.Logger.Info("已清理过期的JWT签名密钥", zap.String("kid", k.ID))
+			continue
+		}
+		kept = append(kept, k)
+	}
+	s.keys = kept
+	return nil
+}
+
+// readKey 读取一个 PKCS#8 PEM 私钥文件，文件名 (去掉扩展名) 即 kid
+func readKey(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid pem data")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &Key{ID: strings.TrimSuffix(filepath.Base(path), ".pem")}
+	switch private := parsed.(type) {
+	case *rsa.PrivateKey:
+		k.Algorithm = AlgRS256
+		k.private = private
+	case ed25519.PrivateKey:
+		k.Algorithm = AlgEdDSA
+		k.private = private
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+
+	// kid 以创建时间开头，手工放入的密钥则使用文件修改时间
+	if len(k.ID) >= len(kidTimeLayout) {
+		if t, err := time.Parse(kidTimeLayout, k.ID[:len(kidTimeLayout)]); err == nil {
+			k.CreatedAt = t
+			return k, nil
+		}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	k.CreatedAt = info.ModTime()
+	return k, nil
+}
@@ -0,0 +1,149 @@
+/*
+File: remote.go
+Author: lxp
+Description: 校验方使用的远程公钥集，从签名方的JWKS端点拉取并缓存公钥
+*/
+package jwtkeys
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+	"zhixue-backend/logger"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+// 未配置时使用的默认值
+const (
+	defaultJWKSRefresh = 10 * time.Minute
+	minRefetchInterval = 10 * time.Second // 遇到未知 kid 时强制拉取的最小间隔，防止被伪造 kid 刷接口
+	jwksFetchTimeout   = 5 * time.Second
+)
+
+// remoteKey 是缓存中的一个公钥
+type remoteKey struct {
+	algorithm string
+	public    crypto.PublicKey
+}
+
+// RemoteKeySet 只持有公钥，网关等只需校验Token的服务使用它
+type RemoteKeySet struct {
+	url        string
+	refresh    time.Duration
+	httpClient *http.Client
+
+	mu        sync.RWMutex
+	keys      map[string]remoteKey
+	fetchedAt time.Time
+
+	fetchMu     sync.Mutex
+	lastAttempt time.Time
+}
+
+// NewRemoteKeySet 创建远程公钥集，首次校验Token时才会拉取
+func NewRemoteKeySet(url string, refresh time.Duration) *RemoteKeySet {
+	if refresh <= 0 {
+		refresh = defaultJWKSRefresh
+	}
+	return &RemoteKeySet{
+		url:        url,
+		refresh:    refresh,
+		httpClient: &http.Client{Timeout: jwksFetchTimeout},
+		keys:       map[string]remoteKey{},
+	}
+}
+
+// Keyfunc 按Token头部的 kid 查找校验公钥，可直接传给 jwt.Parse
+// 缓存过期或遇到未知 kid (签名方刚轮换了密钥) 时重新拉取JWKS
+func (r *RemoteKeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, ErrUnknownKey
+	}
+
+	k, found, stale := r.lookup(kid)
+	if !found || stale {
+		r.fetch()
+		k, found, _ = r.lookup(kid)
+	}
+	if !found {
+		return nil, ErrUnknownKey
+	}
+	if token.Method.Alg() != k.algorithm {
+		return nil, ErrAlgorithmMismatch
+	}
+	return k.public, nil
+}
+
+// lookup 在缓存中查找公钥
+func (r *RemoteKeySet) lookup(kid string) (remoteKey, bool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	k, ok := r.keys[kid]
+	return k, ok, time.Since(r.fetchedAt) > r.refresh
+}
+
+// fetch 拉取JWKS并替换缓存，失败时保留旧缓存
+// 两次拉取至少间隔 minRefetchInterval，并发请求中只有一个会真正发起拉取
+func (r *RemoteKeySet) fetch() {
+	r.fetchMu.Lock()
+	defer r.fetchMu.Unlock()
+
+	if time.Since(r.lastAttempt) < minRefetchInterval {
+		return
+	}
+	r.lastAttempt = time.Now()
+
+	keys, err := r.download()
+	if err != nil {
+		logger.Logger.Warn("拉取JWKS失败", zap.String("url", r.url), zap.Error(err))
+		return
+	}
+
+	r.mu.Lock()
+	r.keys = keys
+	r.fetchedAt = time.Now()
+	r.mu.Unlock()
+}
+
+// download 请求JWKS端点并解析其中的公钥
+func (r *RemoteKeySet) download() (map[string]remoteKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwks endpoint returned status %d", resp.StatusCode)
+	}
+
+	var set JWKS
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode jwks: %w", err)
+	}
+
+	keys := make(map[string]remoteKey, len(set.Keys))
+	for i := range set.Keys {
+		jwk := &set.Keys[i]
+		public, err := jwk.PublicKey()
+		if err != nil {
+			logger.Logger.Warn("跳过无法解析的JWK", zap.String("kid", jwk.Kid), zap.Error(err))
+			continue
+		}
+		keys[jwk.Kid] = remoteKey{algorithm: jwk.Alg, public: public}
+	}
+	return keys, nil
+}
@@ -0,0 +1,58 @@
+/*
+File: file.go
+Author: lxp
+Description: 本地开发使用的邮件发送器，将邮件写入文件并记录日志
+*/
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+	"zhixue-backend/internal/config"
+	"zhixue-backend/logger"
+
+	"go.uber.org/zap"
+)
+
+// unsafeFileChars 收件人地址中不适合出现在文件名里的字符
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9@._-]`)
+
+// FileSender 不真正发送邮件，而是把邮件写成 .eml 文件并输出日志，便于本地开发时查看验证链接
+// 未配置输出目录时只记录日志
+type FileSender struct {
+	dir  string
+	from string
+}
+
+// NewFileSender 创建文件邮件发送器
+func NewFileSender(cfg *config.MailConfig) *FileSender {
+	from := cfg.From
+	if from == "" {
+		from = "noreply@localhost"
+	}
+	return &FileSender{dir: cfg.OutputDir, from: from}
+}
+
+// Send 将邮件写入文件并记录日志
+func (s *FileSender) Send(ctx context.Context, msg *Message) error {
+	logger.Logger.Info("邮件 (file驱动，未真正发送)",
+		zap.String("to", msg.To),
+		zap.String("subject", msg.Subject),
+		zap.String("body", msg.Body))
+
+	if s.dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create mail output dir: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405.000"), unsafeFileChars.ReplaceAllString(msg.To, "_"))
+	if err := os.WriteFile(filepath.Join(s.dir, name), buildMessage(s.from, msg), 0o644); err != nil {
+		return fmt.Errorf("failed to write mail file: %w", err)
+	}
+	return nil
+}
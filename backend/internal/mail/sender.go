@@ -0,0 +1,84 @@
+/*
+File: sender.go
+Author: lxp
+Description: 邮件发送接口及驱动选择
+*/
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/mail"
+	"strings"
+	"time"
+	"zhixue-backend/internal/config"
+)
+
+// 邮件驱动
+const (
+	DriverSMTP = "smtp"
+	DriverFile = "file"
+)
+
+// Message 是一封待发送的纯文本邮件
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender 定义邮件发送方式，业务代码只依赖该接口
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// NewSender 根据配置创建邮件发送器
+// 未配置驱动时使用 file 驱动，邮件只写入本地文件和日志，适合本地开发
+func NewSender(cfg *config.MailConfig) (Sender, error) {
+	switch cfg.Driver {
+	case DriverSMTP:
+		return NewSMTPSender(cfg)
+	case DriverFile, "":
+		return NewFileSender(cfg), nil
+	default:
+		return nil, fmt.Errorf("unsupported mail driver %q", cfg.Driver)
+	}
+}
+
+// buildMessage 将邮件编码为 RFC 5322 格式，正文使用 UTF-8 + base64 以支持中文
+func buildMessage(from string, msg *Message) []byte {
+	var buf bytes.Buffer
+	host := "localhost"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		from = addr.String() // 对中文发件人名称做 RFC 2047 编码
+		if at := strings.LastIndex(addr.Address, "@"); at >= 0 {
+			host = addr.Address[at+1:]
+		}
+	}
+
+	id := make([]byte, 12)
+	_, _ = rand.Read(id)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.BEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@%s>\r\n", hex.EncodeToString(id), host)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	// base64 正文按 76 字符换行
+	encoded := base64.StdEncoding.EncodeToString([]byte(msg.Body))
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+	return buf.Bytes()
+}
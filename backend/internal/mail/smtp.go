@@ -0,0 +1,119 @@
+/*
+File: smtp.go
+Author: lxp
+Description: 基于SMTP的邮件发送器
+*/
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+	"zhixue-backend/internal/config"
+)
+
+// defaultSMTPTimeout 未配置时单封邮件的发送超时
+const defaultSMTPTimeout = 10 * time.Second
+
+// SMTPSender 通过SMTP服务器发送邮件
+// 端口 465 等隐式TLS端口需开启 ImplicitTLS；其他端口在服务器支持时自动使用 STARTTLS
+type SMTPSender struct {
+	host        string
+	addr        string
+	username    string
+	password    string
+	from        string
+	implicitTLS bool
+	timeout     time.Duration
+}
+
+// NewSMTPSender 创建SMTP邮件发送器
+func NewSMTPSender(cfg *config.MailConfig) (*SMTPSender, error) {
+	if cfg.SMTPHost == "" || cfg.From == "" {
+		return nil, errors.New("smtp host and from address are required")
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultSMTPTimeout
+	}
+	return &SMTPSender{
+		host:        cfg.SMTPHost,
+		addr:        net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort)),
+		username:    cfg.Username,
+		password:    cfg.Password,
+		from:        cfg.From,
+		implicitTLS: cfg.ImplicitTLS,
+		timeout:     timeout,
+	}, nil
+}
+
+// Send 发送一封邮件
+func (s *SMTPSender) Send(ctx context.Context, msg *Message) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect smtp server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if s.implicitTLS {
+		conn = tls.Client(conn, &tls.Config{ServerName: s.host})
+	}
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start smtp session: %w", err)
+	}
+	defer client.Close()
+
+	if !s.implicitTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+				return fmt.Errorf("smtp starttls failed: %w", err)
+			}
+		}
+	}
+	if s.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+			return fmt.Errorf("smtp auth failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(s.envelopeFrom()); err != nil {
+		return fmt.Errorf("smtp MAIL FROM failed: %w", err)
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return fmt.Errorf("smtp RCPT TO failed: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA failed: %w", err)
+	}
+	if _, err := w.Write(buildMessage(s.from, msg)); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to write mail body: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return client.Quit()
+}
+
+// envelopeFrom 从 "名称 <地址>" 格式的发件人中取出地址
+func (s *SMTPSender) envelopeFrom() string {
+	if addr, err := mail.ParseAddress(s.from); err == nil {
+		return addr.Address
+	}
+	return s.from
+}
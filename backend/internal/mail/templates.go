@@ -0,0 +1,139 @@
+/*
+File: templates.go
+Author: lxp
+Description: 中英文邮件模板
+*/
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// 支持的邮件语言
+const (
+	LangZH = "zh"
+	LangEN = "en"
+)
+
+// 邮件模板名称
+const (
+	TemplateVerifyEmail   = "verify_email"
+	TemplateResetPassword = "reset_password"
+)
+
+// TemplateData 是渲染邮件模板时可用的数据
+type TemplateData struct {
+	Nickname      string
+	Link          string
+	ExpiresInMins int
+}
+
+// localized 是某种语言下的邮件主题与正文模板
+type localized struct {
+	subject string
+	body    string
+}
+
+var rawTemplates = map[string]map[string]localized{
+	TemplateVerifyEmail: {
+		LangZH: {
+			subject: "【智学奇境】请验证你的邮箱",
+			body: `{{.Nickname}}，你好！
+
+欢迎来到智学奇境。请点击下面的链接完成邮箱验证：
+
+{{.Link}}
+
+链接将在 {{.ExpiresInMins}} 分钟后失效，且只能使用一次。如果这不是你本人的操作，请忽略这封邮件。
+
+—— 智学奇境`,
+		},
+		LangEN: {
+			subject: "[ZhiXue] Please verify your email address",
+			body: `Hi {{.Nickname}},
+
+Welcome to ZhiXue. Please open the link below to verify your email address:
+
+{{.Link}}
+
+The link expires in {{.ExpiresInMins}} minutes and can only be used once. If you did not sign up, you can safely ignore this email.
+
+— The ZhiXue Team`,
+		},
+	},
+	TemplateResetPassword: {
+		LangZH: {
+			subject: "【智学奇境】重置密码",
+			body: `{{.Nickname}}，你好！
+
+我们收到了重置你账号密码的请求。请点击下面的链接设置新密码：
+
+{{.Link}}
+
+链接将在 {{.ExpiresInMins}} 分钟后失效，且只能使用一次。重置成功后，所有设备上的登录都会失效。
+如果这不是你本人的操作，请忽略这封邮件，你的密码不会被修改。
+
+—— 智学奇境`,
+		},
+		LangEN: {
+			subject: "[ZhiXue] Reset your password",
+			body: `Hi {{.Nickname}},
+
+We received a request to reset the password of your account. Open the link below to choose a new password:
+
+{{.Link}}
+
+The link expires in {{.ExpiresInMins}} minutes and can only be used once. After the reset you will be signed out on all devices.
+If you did not request this, please ignore this email and your password will stay the same.
+
+— The ZhiXue Team`,
+		},
+	},
+}
+
+// templates 是预编译后的模板：名称 -> 语言 -> {主题, 正文}
+var templates = func() map[string]map[string][2]*template.Template {
+	compiled := make(map[string]map[string][2]*template.Template, len(rawTemplates))
+	for name, langs := range rawTemplates {
+		compiled[name] = make(map[string][2]*template.Template, len(langs))
+		for lang, t := range langs {
+			compiled[name][lang] = [2]*template.Template{
+				template.Must(template.New(name + "." + lang + ".subject").Parse(t.subject)),
+				template.Must(template.New(name + "." + lang + ".body").Parse(t.body)),
+			}
+		}
+	}
+	return compiled
+}()
+
+// NormalizeLang 将 Accept-Language 等语言标识归一为支持的语言，默认中文
+func NormalizeLang(lang string) string {
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(lang)), LangEN) {
+		return LangEN
+	}
+	return LangZH
+}
+
+// Render 渲染指定模板，生成发往 to 的邮件
+func Render(name, lang, to string, data *TemplateData) (*Message, error) {
+	langs, ok := templates[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown mail template %q", name)
+	}
+	t, ok := langs[NormalizeLang(lang)]
+	if !ok {
+		t = langs[LangZH]
+	}
+
+	var subject, body bytes.Buffer
+	if err := t[0].Execute(&subject, data); err != nil {
+		return nil, fmt.Errorf("failed to render mail subject: %w", err)
+	}
+	if err := t[1].Execute(&body, data); err != nil {
+		return nil, fmt.Errorf("failed to render mail body: %w", err)
+	}
+	return &Message{To: to, Subject: subject.String(), Body: body.String()}, nil
+}
@@ -0,0 +1,35 @@
+/*
+File: randcode.go
+Author: lxp
+Description: 生成便于人工输入的随机短码 (邀请码、班级加入码等)
+*/
+package randcode
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Alphabet 去掉易混淆字符 (0/O、1/I/L) 的大写字母与数字，方便口头或手写传递
+const Alphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// New 生成指定长度的随机短码
+func New(length int) (string, error) {
+	var b strings.Builder
+	max := big.NewInt(int64(len(Alphabet)))
+	for i := 0; i < length; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate random code: %w", err)
+		}
+		b.WriteByte(Alphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+// Normalize 将用户输入的短码统一为大写并去掉首尾空白
+func Normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
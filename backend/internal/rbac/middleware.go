@@ -0,0 +1,47 @@
+/*
+File: middleware.go
+Author: lxp
+Description: Gin中间件 - 基于权限的访问控制，网关与后端API服务均可使用
+*/
+package rbac
+
+import (
+	"net/http"
+	"strings"
+	"zhixue-backend/internal/api/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleHeader 网关认证通过后注入的用户角色请求头
+const RoleHeader = "X-User-Role"
+
+// RequirePermission 要求当前用户同时拥有所有给定权限，否则返回403
+// 角色来自 X-User-Role 请求头，因此必须挂载在认证中间件之后 (网关) 或网关之后的服务上 (cmd/server)
+func RequirePermission(perms ...Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.Request.Header.Get(RoleHeader)
+		for _, perm := range perms {
+			if !HasPermission(role, perm) {
+				response.Error(c, http.StatusForbidden, "权限不足")
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// RequirePermissionForPrefix 仅对指定路径前缀下的请求校验权限
+// 用于网关这类以通配路由统一转发、无法按路由挂载中间件的场景
+func RequirePermissionForPrefix(prefix string, perms ...Permission) gin.HandlerFunc {
+	check := RequirePermission(perms...)
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/") {
+			check(c)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,111 @@
+/*
+File: rbac.go
+Author: lxp
+Description: 基于角色的权限矩阵与资源归属校验
+*/
+package rbac
+
+// 用户角色，与数据库 user_role 枚举保持一致
+const (
+	RoleUser    = "user"
+	RoleTeacher = "teacher"
+	RoleAdmin   = "admin"
+	RoleParent  = "parent"
+)
+
+// Permission 表示一项操作权限，格式为 "资源:操作"
+type Permission string
+
+const (
+	// 学习端
+	PermQuestionRead    Permission = "question:read"    // 浏览已发布题目与知识点
+	PermQuestionAnswer  Permission = "question:answer"  // 提交答案
+	PermLearningSession Permission = "learning:session" // 管理自己的学习会话
+	PermParentInvite    Permission = "parent:invite"    // 生成家长关联邀请码
+	PermClassJoin       Permission = "class:join"       // 通过加入码加入班级
+	PermAssignmentDo    Permission = "assignment:do"    // 查看并作答所在班级的作业
+
+	// 家长端
+	PermChildRead    Permission = "child:read"    // 查看已关联孩子的资料与学习记录
+	PermChildControl Permission = "child:control" // 设置已关联孩子的学习管控
+
+	// 后台管理
+	PermAdminAccess       Permission = "admin:access"        // 访问 /admin 下的接口
+	PermQuestionManage    Permission = "question:manage"     // 创建、编辑、提交、删除自己的题目
+	PermQuestionManageAll Permission = "question:manage_all" // 管理任意作者的题目
+	PermQuestionReview    Permission = "question:review"     // 审核他人提交的题目
+	PermClassManage       Permission = "class:manage"        // 管理自己的班级
+	PermClassManageAll    Permission = "class:manage_all"    // 管理任意教师的班级
+	PermUserManage        Permission = "user:manage"         // 管理用户账号
+	PermKnowledgeManage   Permission = "knowledge:manage"    // 维护知识点先修关系与掌握度参数
+)
+
+// learnerPermissions 所有角色都具备的学习端权限
+var learnerPermissions = []Permission{
+	PermQuestionRead,
+	PermQuestionAnswer,
+	PermLearningSession,
+}
+
+// matrix 定义每个角色拥有的权限
+var matrix = map[string]map[Permission]bool{
+	RoleUser:   permissionSet(learnerPermissions, PermParentInvite, PermClassJoin, PermAssignmentDo),
+	RoleParent: permissionSet(nil, PermQuestionRead, PermChildRead, PermChildControl),
+	RoleTeacher: permissionSet(learnerPermissions,
+		PermAdminAccess,
+		PermQuestionManage,
+		PermQuestionReview,
+		PermClassManage,
+	),
+	RoleAdmin: permissionSet(learnerPermissions,
+		PermAdminAccess,
+		PermQuestionManage,
+		PermQuestionManageAll,
+		PermQuestionReview,
+		PermClassManage,
+		PermClassManageAll,
+		PermUserManage,
+		PermKnowledgeManage,
+	),
+}
+
+func permissionSet(base []Permission, extra ...Permission) map[Permission]bool {
+	set := make(map[Permission]bool, len(base)+len(extra))
+	for _, p := range base {
+		set[p] = true
+	}
+	for _, p := range extra {
+		set[p] = true
+	}
+	return set
+}
+
+// HasPermission 判断角色是否拥有指定权限，未知角色没有任何权限
+func HasPermission(role string, perm Permission) bool {
+	return matrix[role][perm]
+}
+
+// Subject 表示发起请求的用户
+type Subject struct {
+	UserID int64
+	Role   string
+}
+
+// Can 判断用户是否拥有指定权限
+func (s Subject) Can(perm Permission) bool {
+	return HasPermission(s.Role, perm)
+}
+
+// Owns 判断资源是否归属于该用户，ownerID 为空表示资源无归属者
+func (s Subject) Owns(ownerID *int64) bool {
+	return ownerID != nil && *ownerID == s.UserID
+}
+
+// CanAccess 判断用户能否操作某个有归属的资源：
+// 需要拥有 perm，且资源归属于自己或拥有 overridePerm (如管理员的 *_all 权限)
+func (s Subject) CanAccess(ownerID *int64, perm, overridePerm Permission) bool {
+	if !s.Can(perm) {
+		return false
+	}
+	return s.Owns(ownerID) || s.Can(overridePerm)
+}
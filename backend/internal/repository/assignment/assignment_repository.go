@@ -0,0 +1,434 @@
+/*
+File: assignment_repository.go
+Author: lxp
+Description: 班级作业、作业题目与学生作答数据访问层
+*/
+package assignment
+
+import (
+	"errors"
+	"time"
+	"zhixue-backend/models"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAttemptConflict = errors.New("an attempt is already in progress")
+)
+
+// 作答状态，与数据库 assignment_attempt_status 枚举保持一致
+const (
+	AttemptInProgress = "in_progress"
+	AttemptSubmitted  = "submitted"
+)
+
+// Filter 定义教师端作业列表的筛选条件
+type Filter struct {
+	Page      int
+	PageSize  int
+	ClassID   *int64
+	TeacherID *int64 // 按班级教师筛选
+}
+
+// AttemptFilter 定义作答记录的筛选条件，零值字段表示不限制
+type AttemptFilter struct {
+	AssignmentID int64
+	UserID       int64
+}
+
+// Summary 是附带班级名称与题目统计的作业
+type Summary struct {
+	models.Assignment
+	ClassName     string
+	QuestionCount int
+	TotalPoints   int
+}
+
+// StudentAssignment 是学生视角下的作业及其作答进度
+type StudentAssignment struct {
+	models.Assignment
+	ClassName      string
+	QuestionCount  int
+	TotalPoints    int
+	Attempts       int
+	SubmittedCount int
+	InProgress     bool
+	BestScore      *float64
+}
+
+// AttemptAnswer 是一次作答中某道题的首次答题记录
+type AttemptAnswer struct {
+	QuestionID int64
+	UserAnswer string
+	IsCorrect  bool
+	CreatedAt  time.Time
+}
+
+// StudentProgress 是班级中某个学生在作业上的完成情况
+type StudentProgress struct {
+	UserID           int64
+	Username         string
+	Nickname         string
+	Attempts         int
+	SubmittedCount   int
+	OnTimeCount      int // 按时提交的次数
+	InProgress       bool
+	BestScore        *float64
+	FirstSubmittedAt *time.Time
+}
+
+// QuestionStat 是作业中某道题在已提交作答中的答题统计
+type QuestionStat struct {
+	QuestionID   int64
+	Title        string
+	Points       int
+	AnswerCount  int
+	CorrectCount int
+}
+
+// Repository 定义作业数据仓库的接口
+type Repository interface {
+	Create(assignment *models.Assignment, questions []models.AssignmentQuestion) error
+	FindByID(id int64) (*models.Assignment, error)
+	List(filter Filter) ([]Summary, int64, error)
+	ListForStudent(userID int64, now time.Time, page, pageSize int) ([]StudentAssignment, int64, error)
+	Update(assignment *models.Assignment, questions []models.AssignmentQuestion) error
+	Delete(id int64) error
+	ListQuestions(assignmentID int64) ([]models.AssignmentQuestion, error)
+	CountAttempts(assignmentID int64) (int64, error)
+
+	// 学生作答
+	CreateAttempt(attempt *models.AssignmentAttempt) error
+	UpdateAttemptSession(attempt *models.AssignmentAttempt) error
+	SubmitAttempt(attempt *models.AssignmentAttempt) (bool, error)
+	FindAttemptBySession(sessionID string) (*models.AssignmentAttempt, error)
+	FindOpenAttempt(assignmentID, userID int64) (*models.AssignmentAttempt, error)
+	ListAttempts(assignmentID, userID int64) ([]models.AssignmentAttempt, error)
+	ListOverdueAttempts(filter AttemptFilter, now time.Time) ([]models.AssignmentAttempt, error)
+	HasOpenAttemptWithQuestion(userID, questionID int64) (bool, error)
+	AttemptAnswers(userID int64, sessionIDs []string) ([]AttemptAnswer, error)
+
+	// 批改概览
+	StudentProgress(assignmentID, classID int64) ([]StudentProgress, error)
+	QuestionStats(assignmentID int64) ([]QuestionStat, error)
+}
+
+// assignmentRepository 实现了Repository接口
+type assignmentRepository struct {
+	db *gorm.DB
+}
+
+// NewAssignmentRepository 创建一个新的作业数据仓库实例
+func NewAssignmentRepository(db *gorm.DB) Repository {
+	return &assignmentRepository{db: db}
+}
+
+// Create 在一个事务中创建作业及其题目
+func (r *assignmentRepository) Create(assignment *models.Assignment, questions []models.AssignmentQuestion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(assignment).Error; err != nil {
+			return err
+		}
+		return replaceQuestions(tx, assignment.ID, questions)
+	})
+}
+
+// FindByID 通过ID获取作业
+func (r *assignmentRepository) FindByID(id int64) (*models.Assignment, error) {
+	var assignment models.Assignment
+	err := r.db.First(&assignment, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// summaryColumns 作业列表中附带的班级名称与题目统计
+const summaryColumns = `assignments.*, c.name AS class_name,
+	(SELECT COUNT(*) FROM assignment_questions q WHERE q.assignment_id = assignments.id) AS question_count,
+	(SELECT COALESCE(SUM(q.points), 0) FROM assignment_questions q WHERE q.assignment_id = assignments.id) AS total_points`
+
+// List 按筛选条件分页获取作业，按截止时间倒序
+func (r *assignmentRepository) List(filter Filter) ([]Summary, int64, error) {
+	query := r.db.Model(&models.Assignment{}).Joins("JOIN classes c ON c.id = assignments.class_id")
+	if filter.ClassID != nil {
+		query = query.Where("assignments.class_id = ?", *filter.ClassID)
+	}
+	if filter.TeacherID != nil {
+		query = query.Where("c.teacher_id = ?", *filter.TeacherID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []Summary
+	err := query.Select(summaryColumns).
+		Order("assignments.due_at DESC, assignments.id DESC").
+		Offset((filter.Page - 1) * filter.PageSize).
+		Limit(filter.PageSize).
+		Scan(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// ListForStudent 分页获取学生所在班级中已开放的作业及其作答进度，按截止时间倒序
+func (r *assignmentRepository) ListForStudent(userID int64, now time.Time, page, pageSize int) ([]StudentAssignment, int64, error) {
+	query := r.db.Model(&models.Assignment{}).
+		Joins("JOIN classes c ON c.id = assignments.class_id").
+		Joins("JOIN class_members m ON m.class_id = assignments.class_id AND m.user_id = ?", userID).
+		Where("assignments.open_at <= ?", now)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []StudentAssignment
+	err := query.Select(summaryColumns+`,
+			COALESCE(t.attempts, 0) AS attempts,
+			COALESCE(t.submitted_count, 0) AS submitted_count,
+			COALESCE(t.in_progress, FALSE) AS in_progress,
+			t.best_score`).
+		Joins(`LEFT JOIN (
+			SELECT assignment_id, COUNT(*) AS attempts,
+				COUNT(*) FILTER (WHERE status = ?) AS submitted_count,
+				BOOL_OR(status = ?) AS in_progress,
+				MAX(score) FILTER (WHERE status = ?) AS best_score
+			FROM assignment_attempts WHERE user_id = ? GROUP BY assignment_id
+		) t ON t.assignment_id = assignments.id`, AttemptSubmitted, AttemptInProgress, AttemptSubmitted, userID).
+		Order("assignments.due_at DESC, assignments.id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Scan(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// Update 保存作业信息，questions 不为 nil 时在同一事务中替换作业题目
+func (r *assignmentRepository) Update(assignment *models.Assignment, questions []models.AssignmentQuestion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(assignment).Error; err != nil {
+			return err
+		}
+		if questions == nil {
+			return nil
+		}
+		return replaceQuestions(tx, assignment.ID, questions)
+	})
+}
+
+// Delete 删除作业，作业题目与作答记录随之级联删除，答题记录保留
+func (r *assignmentRepository) Delete(id int64) error {
+	return r.db.Delete(&models.Assignment{}, id).Error
+}
+
+// ListQuestions 获取作业题目，按题目顺序排序
+func (r *assignmentRepository) ListQuestions(assignmentID int64) ([]models.AssignmentQuestion, error) {
+	var questions []models.AssignmentQuestion
+	err := r.db.Where("assignment_id = ?", assignmentID).
+		Order("sort_order ASC, question_id ASC").
+		Find(&questions).Error
+	if err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+// CountAttempts 统计作业的作答次数 (含进行中的作答)
+func (r *assignmentRepository) CountAttempts(assignmentID int64) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.AssignmentAttempt{}).Where("assignment_id = ?", assignmentID).Count(&count).Error
+	return count, err
+}
+
+// CreateAttempt 创建一次作答，同一学生已有进行中的作答或作答序号冲突时返回 ErrAttemptConflict
+func (r *assignmentRepository) CreateAttempt(attempt *models.AssignmentAttempt) error {
+	err := r.db.Create(attempt).Error
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrAttemptConflict
+	}
+	return err
+}
+
+// UpdateAttemptSession 更新作答当前使用的学习会话
+func (r *assignmentRepository) UpdateAttemptSession(attempt *models.AssignmentAttempt) error {
+	return r.db.Model(attempt).Updates(map[string]interface{}{
+		"session_id":  attempt.SessionID,
+		"session_ids": attempt.SessionIDs,
+	}).Error
+}
+
+// SubmitAttempt 将进行中的作答标记为已提交并写入成绩
+// 返回 false 表示作答已不在进行中 (如被并发提交)
+func (r *assignmentRepository) SubmitAttempt(attempt *models.AssignmentAttempt) (bool, error) {
+	result := r.db.Model(&models.AssignmentAttempt{}).
+		Where("id = ? AND status = ?", attempt.ID, AttemptInProgress).
+		Updates(map[string]interface{}{
+			"status":         AttemptSubmitted,
+			"submitted_at":   attempt.SubmittedAt,
+			"is_late":        attempt.IsLate,
+			"answered_count": attempt.AnsweredCount,
+			"correct_count":  attempt.CorrectCount,
+			"score":          attempt.Score,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FindAttemptBySession 通过当前学习会话获取作答
+func (r *assignmentRepository) FindAttemptBySession(sessionID string) (*models.AssignmentAttempt, error) {
+	var attempt models.AssignmentAttempt
+	err := r.db.Where("session_id = ?", sessionID).First(&attempt).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+// FindOpenAttempt 获取学生在作业上进行中的作答
+func (r *assignmentRepository) FindOpenAttempt(assignmentID, userID int64) (*models.AssignmentAttempt, error) {
+	var attempt models.AssignmentAttempt
+	err := r.db.Where("assignment_id = ? AND user_id = ? AND status = ?", assignmentID, userID, AttemptInProgress).
+		First(&attempt).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+// ListAttempts 获取学生在作业上的全部作答，按作答序号排序
+func (r *assignmentRepository) ListAttempts(assignmentID, userID int64) ([]models.AssignmentAttempt, error) {
+	var attempts []models.AssignmentAttempt
+	err := r.db.Where("assignment_id = ? AND user_id = ?", assignmentID, userID).
+		Order("attempt_no ASC").
+		Find(&attempts).Error
+	if err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+// ListOverdueAttempts 获取不允许迟交且已过截止时间、但仍在进行中的作答
+func (r *assignmentRepository) ListOverdueAttempts(filter AttemptFilter, now time.Time) ([]models.AssignmentAttempt, error) {
+	query := r.db.Model(&models.AssignmentAttempt{}).
+		Joins("JOIN assignments a ON a.id = assignment_attempts.assignment_id").
+		Where("assignment_attempts.status = ? AND a.allow_late = ? AND a.due_at < ?", AttemptInProgress, false, now)
+	if filter.AssignmentID != 0 {
+		query = query.Where("assignment_attempts.assignment_id = ?", filter.AssignmentID)
+	}
+	if filter.UserID != 0 {
+		query = query.Where("assignment_attempts.user_id = ?", filter.UserID)
+	}
+
+	var attempts []models.AssignmentAttempt
+	if err := query.Select("assignment_attempts.*").Find(&attempts).Error; err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+// HasOpenAttemptWithQuestion 判断学生是否有包含该题目且仍在进行中的作答
+func (r *assignmentRepository) HasOpenAttemptWithQuestion(userID, questionID int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.AssignmentAttempt{}).
+		Joins("JOIN assignment_questions q ON q.assignment_id = assignment_attempts.assignment_id").
+		Where("assignment_attempts.user_id = ? AND assignment_attempts.status = ? AND q.question_id = ?",
+			userID, AttemptInProgress, questionID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// AttemptAnswers 获取学生在指定学习会话中每道题的首次答题记录
+func (r *assignmentRepository) AttemptAnswers(userID int64, sessionIDs []string) ([]AttemptAnswer, error) {
+	var answers []AttemptAnswer
+	if len(sessionIDs) == 0 {
+		return answers, nil
+	}
+	err := r.db.Table("answer_records").
+		Select("DISTINCT ON (question_id) question_id, user_answer, is_correct, created_at").
+		Where("user_id = ? AND session_id IN ?", userID, sessionIDs).
+		Order("question_id, created_at").
+		Scan(&answers).Error
+	if err != nil {
+		return nil, err
+	}
+	return answers, nil
+}
+
+// StudentProgress 获取班级中每个学生在作业上的完成情况，按加入班级时间排序
+func (r *assignmentRepository) StudentProgress(assignmentID, classID int64) ([]StudentProgress, error) {
+	var items []StudentProgress
+	err := r.db.Table("class_members m").
+		Select(`m.user_id, u.username, u.nickname,
+			COUNT(t.id) AS attempts,
+			COUNT(t.id) FILTER (WHERE t.status = ?) AS submitted_count,
+			COUNT(t.id) FILTER (WHERE t.status = ? AND NOT t.is_late) AS on_time_count,
+			COALESCE(BOOL_OR(t.status = ?), FALSE) AS in_progress,
+			MAX(t.score) FILTER (WHERE t.status = ?) AS best_score,
+			MIN(t.submitted_at) AS first_submitted_at`,
+			AttemptSubmitted, AttemptSubmitted, AttemptInProgress, AttemptSubmitted).
+		Joins("JOIN users u ON u.id = m.user_id").
+		Joins("LEFT JOIN assignment_attempts t ON t.user_id = m.user_id AND t.assignment_id = ?", assignmentID).
+		Where("m.class_id = ?", classID).
+		Group("m.user_id, u.username, u.nickname, m.joined_at").
+		Order("m.joined_at, m.user_id").
+		Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// QuestionStats 统计作业中每道题在已提交作答里的答题情况，每次作答只计每题的首次答题
+func (r *assignmentRepository) QuestionStats(assignmentID int64) ([]QuestionStat, error) {
+	var stats []QuestionStat
+	err := r.db.Table("assignment_questions aq").
+		Select(`aq.question_id, qs.title, aq.points,
+			COUNT(a.question_id) AS answer_count,
+			COUNT(a.question_id) FILTER (WHERE a.is_correct) AS correct_count`).
+		Joins("JOIN questions qs ON qs.id = aq.question_id").
+		Joins(`LEFT JOIN (
+			SELECT DISTINCT ON (t.id, ar.question_id) ar.question_id, ar.is_correct
+			FROM assignment_attempts t
+			JOIN answer_records ar ON ar.user_id = t.user_id
+				AND ar.session_id IN (SELECT jsonb_array_elements_text(t.session_ids))
+			WHERE t.assignment_id = ? AND t.status = ?
+			ORDER BY t.id, ar.question_id, ar.created_at
+		) a ON a.question_id = aq.question_id`, assignmentID, AttemptSubmitted).
+		Where("aq.assignment_id = ?", assignmentID).
+		Group("aq.question_id, qs.title, aq.points, aq.sort_order").
+		Order("aq.sort_order, aq.question_id").
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// replaceQuestions 删除作业原有题目后写入新的题目列表
+func replaceQuestions(tx *gorm.DB, assignmentID int64, questions []models.AssignmentQuestion) error {
+	if err := tx.Where("assignment_id = ?", assignmentID).Delete(&models.AssignmentQuestion{}).Error; err != nil {
+		return err
+	}
+	if len(questions) == 0 {
+		return nil
+	}
+	for i := range questions {
+		questions[i].AssignmentID = assignmentID
+	}
+	return tx.Create(&questions).Error
+}
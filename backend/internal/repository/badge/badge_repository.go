@@ -0,0 +1,168 @@
+/*
+File: badge_repository.go
+Author: lxp
+Description: 成就徽章数据访问层：徽章定义、已获得的徽章与规则所需的用户统计
+*/
+package badge
+
+import (
+	"errors"
+	"time"
+	"zhixue-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RewardTypeBadge 徽章类奖励，与数据库 reward_type 枚举保持一致
+const RewardTypeBadge = "badge"
+
+// EarnedBadge 是用户已获得的徽章
+type EarnedBadge struct {
+	models.Reward
+	ObtainedAt time.Time
+}
+
+// Repository 定义成就徽章数据仓库的接口
+type Repository interface {
+	ListRules() ([]models.Reward, error)
+	ListEarned(userID int64) ([]EarnedBadge, error)
+	Award(userID, rewardID int64, now time.Time) (bool, error)
+
+	// 规则使用的用户统计
+	FindProfile(userID int64) (*models.UserProfile, error)
+	CountAnswers(userID int64, correctOnly bool, knowledgePoint string) (int64, error)
+	CountPerfectExams(userID int64) (int64, error)
+	CountCompletedSessions(userID int64) (int64, error)
+	FindUsersWithProfile(afterID int64, limit int) ([]int64, error)
+}
+
+// badgeRepository 实现了Repository接口
+type badgeRepository struct {
+	db *gorm.DB
+}
+
+// NewBadgeRepository 创建一个新的成就徽章数据仓库实例
+func NewBadgeRepository(db *gorm.DB) Repository {
+	return &badgeRepository{db: db}
+}
+
+// ListRules 获取配置了获得条件的徽章
+func (r *badgeRepository) ListRules() ([]models.Reward, error) {
+	var rewards []models.Reward
+	err := r.db.Where("reward_type = ? AND criteria IS NOT NULL", RewardTypeBadge).
+		Order("id ASC").
+		Find(&rewards).Error
+	if err != nil {
+		return nil, err
+	}
+	return rewards, nil
+}
+
+// ListEarned 获取用户已获得的徽章 (包括领取任务奖励获得的)，每个徽章只取最早获得的一次
+func (r *badgeRepository) ListEarned(userID int64) ([]EarnedBadge, error) {
+	var badges []EarnedBadge
+	err := r.db.Model(&models.Reward{}).
+		Select("rewards.*, MIN(ur.obtained_at) AS obtained_at").
+		Joins("JOIN user_rewards ur ON ur.reward_id = rewards.id").
+		Where("ur.user_id = ? AND rewards.reward_type = ?", userID, RewardTypeBadge).
+		Group("rewards.id").
+		Order("obtained_at ASC, rewards.id ASC").
+		Scan(&badges).Error
+	if err != nil {
+		return nil, err
+	}
+	return badges, nil
+}
+
+// Award 为用户发放徽章，已获得过该徽章时不重复发放并返回 false
+// 在事务中锁定用户行，避免并发的事件重复发放
+func (r *badgeRepository) Award(userID, rewardID int64, now time.Time) (bool, error) {
+	awarded := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "NO KEY UPDATE"}).
+			Select("id").
+			Where("id = ?", userID).
+			Take(&models.User{}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("user_id = ? AND reward_id = ?", userID, rewardID).
+			Take(&models.UserReward{}).Error
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if err := tx.Create(&models.UserReward{UserID: userID, RewardID: rewardID, ObtainedAt: now}).Error; err != nil {
+			return err
+		}
+		awarded = true
+		return nil
+	})
+	return awarded, err
+}
+
+// FindProfile 获取用户的学习档案
+func (r *badgeRepository) FindProfile(userID int64) (*models.UserProfile, error) {
+	var profile models.UserProfile
+	if err := r.db.Where("user_id = ?", userID).Take(&profile).Error; err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// CountAnswers 统计用户在指定编码的知识点及其子知识点下的答题数 (只计启用的知识点)
+// correctOnly 为 true 时只统计答对的
+func (r *badgeRepository) CountAnswers(userID int64, correctOnly bool, knowledgePoint string) (int64, error) {
+	var count int64
+	err := r.db.Raw(`WITH RECURSIVE subtree(id) AS (
+			SELECT id FROM knowledge_points WHERE code = ? AND is_active
+			UNION
+			SELECT kp.id FROM knowledge_points kp JOIN subtree s ON kp.parent_id = s.id WHERE kp.is_active
+		)
+		SELECT COUNT(*) FROM answer_records ar
+		WHERE ar.user_id = ? AND (ar.is_correct OR NOT ?)
+			AND EXISTS (
+				SELECT 1 FROM question_knowledge_points qkp JOIN subtree s ON s.id = qkp.knowledge_point_id
+				WHERE qkp.question_id = ar.question_id
+			)`, knowledgePoint, userID, correctOnly).
+		Scan(&count).Error
+	return count, err
+}
+
+// CountPerfectExams 统计用户全部答对的考试次数 (包括超时自动交卷的)
+func (r *badgeRepository) CountPerfectExams(userID int64) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.ExamSession{}).
+		Where("user_id = ? AND status IN ?", userID, []string{"submitted", "expired"}).
+		Where("question_count > 0 AND correct_count = question_count").
+		Count(&count).Error
+	return count, err
+}
+
+// CountCompletedSessions 统计用户正常完成的学习会话数
+func (r *badgeRepository) CountCompletedSessions(userID int64) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.LearningSession{}).
+		Where("user_id = ? AND completion_status = ?", userID, "completed").
+		Count(&count).Error
+	return count, err
+}
+
+// FindUsersWithProfile 按ID顺序获取 afterID 之后有学习档案的用户，用于分批补发徽章
+func (r *badgeRepository) FindUsersWithProfile(afterID int64, limit int) ([]int64, error) {
+	var userIDs []int64
+	err := r.db.Model(&models.UserProfile{}).
+		Where("user_id > ?", afterID).
+		Order("user_id ASC").
+		Limit(limit).
+		Pluck("user_id", &userIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
@@ -0,0 +1,301 @@
+/*
+File: class_repository.go
+Author: lxp
+Description: 班级与班级成员数据访问层
+*/
+package class
+
+import (
+	"errors"
+	"time"
+	"zhixue-backend/models"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrJoinCodeExists = errors.New("join code already exists")
+	ErrAlreadyMember  = errors.New("user is already a member of the class")
+)
+
+// ClassFilter 定义班级列表的筛选条件
+type ClassFilter struct {
+	Page       int
+	PageSize   int
+	TeacherID  *int64
+	Keyword    string
+	IsArchived *bool
+}
+
+// ClassWithCount 是附带成员人数的班级
+type ClassWithCount struct {
+	models.Class
+	MemberCount int
+}
+
+// JoinedClass 是学生视角下已加入的班级
+type JoinedClass struct {
+	models.Class
+	TeacherNickname string
+	JoinedAt        time.Time
+}
+
+// Member 是班级成员及其学习统计
+// Period* 统计 since 之后的答题情况，其余为学习档案中的累计数据
+type Member struct {
+	UserID            int64
+	Username          string
+	Nickname          string
+	AvatarURL         string
+	GradeLevel        int
+	JoinedAt          time.Time
+	CurrentDifficulty float64
+	TotalQuestions    int
+	CorrectAnswers    int
+	StreakDays        int
+	PeriodAnswers     int
+	PeriodCorrect     int
+	LastAnsweredAt    *time.Time
+}
+
+// Stats 是班级整体的学习统计
+type Stats struct {
+	MemberCount     int
+	ActiveMembers   int // since 之后有答题记录的成员数
+	AnswerCount     int
+	CorrectCount    int
+	AvgResponseTime float64
+	AvgDifficulty   float64 // 成员当前难度的平均值
+	TotalQuestions  int     // 成员累计答题数之和
+}
+
+// Repository 定义班级数据仓库的接口
+type Repository interface {
+	Create(class *models.Class) error
+	FindByID(id int64) (*models.Class, error)
+	FindByJoinCode(code string) (*models.Class, error)
+	List(filter ClassFilter) ([]ClassWithCount, int64, error)
+	Update(class *models.Class) error
+	Delete(id int64) error
+	CountMembers(classID int64) (int, error)
+	IsMember(classID, userID int64) (bool, error)
+	AddMember(member *models.ClassMember) error
+	RemoveMember(classID, userID int64) (bool, error)
+	TransferMember(fromClassID, toClassID, userID int64) (bool, error)
+	ListMembers(classID int64, since time.Time) ([]Member, error)
+	ListJoinedClasses(userID int64) ([]JoinedClass, error)
+	Stats(classID int64, since time.Time) (*Stats, error)
+}
+
+// classRepository 实现了Repository接口
+type classRepository struct {
+	db *gorm.DB
+}
+
+// NewClassRepository 创建一个新的班级数据仓库实例
+func NewClassRepository(db *gorm.DB) Repository {
+	return &classRepository{db: db}
+}
+
+// Create 创建班级，加入码冲突时返回 ErrJoinCodeExists
+func (r *classRepository) Create(class *models.Class) error {
+	return translateError(r.db.Create(class).Error, ErrJoinCodeExists)
+}
+
+// FindByID 通过ID获取班级
+func (r *classRepository) FindByID(id int64) (*models.Class, error) {
+	var class models.Class
+	err := r.db.First(&class, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &class, nil
+}
+
+// FindByJoinCode 通过加入码获取班级
+func (r *classRepository) FindByJoinCode(code string) (*models.Class, error) {
+	var class models.Class
+	err := r.db.Where("join_code = ?", code).First(&class).Error
+	if err != nil {
+		return nil, err
+	}
+	return &class, nil
+}
+
+// List 按筛选条件分页获取班级，按创建时间倒序
+func (r *classRepository) List(filter ClassFilter) ([]ClassWithCount, int64, error) {
+	query := r.db.Model(&models.Class{})
+	if filter.TeacherID != nil {
+		query = query.Where("classes.teacher_id = ?", *filter.TeacherID)
+	}
+	if filter.Keyword != "" {
+		query = query.Where("classes.name ILIKE ?", "%"+filter.Keyword+"%")
+	}
+	if filter.IsArchived != nil {
+		query = query.Where("classes.is_archived = ?", *filter.IsArchived)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var classes []ClassWithCount
+	err := query.Select("classes.*, (SELECT COUNT(*) FROM class_members m WHERE m.class_id = classes.id) AS member_count").
+		Order("classes.created_at DESC, classes.id DESC").
+		Offset((filter.Page - 1) * filter.PageSize).
+		Limit(filter.PageSize).
+		Scan(&classes).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return classes, total, nil
+}
+
+// Update 保存班级信息，加入码冲突时返回 ErrJoinCodeExists
+func (r *classRepository) Update(class *models.Class) error {
+	return translateError(r.db.Save(class).Error, ErrJoinCodeExists)
+}
+
+// Delete 删除班级，成员关系随之级联删除
+func (r *classRepository) Delete(id int64) error {
+	return r.db.Delete(&models.Class{}, id).Error
+}
+
+// CountMembers 统计班级成员人数
+func (r *classRepository) CountMembers(classID int64) (int, error) {
+	var count int64
+	err := r.db.Model(&models.ClassMember{}).Where("class_id = ?", classID).Count(&count).Error
+	return int(count), err
+}
+
+// IsMember 判断用户是否在班级中
+func (r *classRepository) IsMember(classID, userID int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.ClassMember{}).
+		Where("class_id = ? AND user_id = ?", classID, userID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// AddMember 将用户加入班级，已在班级中时返回 ErrAlreadyMember
+func (r *classRepository) AddMember(member *models.ClassMember) error {
+	return translateError(r.db.Create(member).Error, ErrAlreadyMember)
+}
+
+// RemoveMember 将用户移出班级，返回 false 表示用户原本不在班级中
+func (r *classRepository) RemoveMember(classID, userID int64) (bool, error) {
+	result := r.db.Where("class_id = ? AND user_id = ?", classID, userID).Delete(&models.ClassMember{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// TransferMember 在一个事务中将用户从一个班级转到另一个班级
+// 返回 false 表示用户原本不在 fromClassID 中；已在目标班级时返回 ErrAlreadyMember
+func (r *classRepository) TransferMember(fromClassID, toClassID, userID int64) (bool, error) {
+	moved := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("class_id = ? AND user_id = ?", fromClassID, userID).Delete(&models.ClassMember{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		if err := tx.Create(&models.ClassMember{ClassID: toClassID, UserID: userID}).Error; err != nil {
+			return translateError(err, ErrAlreadyMember)
+		}
+		moved = true
+		return nil
+	})
+	return moved, err
+}
+
+// ListMembers 获取班级成员及其学习统计，按加入时间排序
+func (r *classRepository) ListMembers(classID int64, since time.Time) ([]Member, error) {
+	var members []Member
+	err := r.db.Table("class_members m").
+		Select(`m.user_id, u.username, u.nickname, u.avatar_url, u.grade_level, m.joined_at,
+			COALESCE(p.current_difficulty, 0) AS current_difficulty,
+			COALESCE(p.total_questions, 0) AS total_questions,
+			COALESCE(p.correct_answers, 0) AS correct_answers,
+			COALESCE(p.streak_days, 0) AS streak_days,
+			COALESCE(a.period_answers, 0) AS period_answers,
+			COALESCE(a.period_correct, 0) AS period_correct,
+			last.answered_at AS last_answered_at`).
+		Joins("JOIN users u ON u.id = m.user_id").
+		Joins("LEFT JOIN user_profiles p ON p.user_id = m.user_id").
+		Joins(`LEFT JOIN (
+			SELECT user_id, COUNT(*) AS period_answers, COUNT(*) FILTER (WHERE is_correct) AS period_correct
+			FROM answer_records WHERE created_at >= ? GROUP BY user_id
+		) a ON a.user_id = m.user_id`, since).
+		Joins(`LEFT JOIN LATERAL (
+			SELECT MAX(created_at) AS answered_at FROM answer_records WHERE user_id = m.user_id
+		) last ON TRUE`).
+		Where("m.class_id = ?", classID).
+		Order("m.joined_at, m.user_id").
+		Scan(&members).Error
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// ListJoinedClasses 获取学生已加入的班级，按加入时间倒序
+func (r *classRepository) ListJoinedClasses(userID int64) ([]JoinedClass, error) {
+	var classes []JoinedClass
+	err := r.db.Model(&models.Class{}).
+		Select("classes.*, t.nickname AS teacher_nickname, m.joined_at").
+		Joins("JOIN class_members m ON m.class_id = classes.id").
+		Joins("JOIN users t ON t.id = classes.teacher_id").
+		Where("m.user_id = ?", userID).
+		Order("m.joined_at DESC").
+		Scan(&classes).Error
+	if err != nil {
+		return nil, err
+	}
+	return classes, nil
+}
+
+// Stats 统计班级整体的学习情况，答题相关统计只计算 since 之后的记录
+func (r *classRepository) Stats(classID int64, since time.Time) (*Stats, error) {
+	var stats Stats
+	err := r.db.Table("class_members m").
+		Select(`COUNT(*) AS member_count,
+			COALESCE(ROUND(AVG(p.current_difficulty), 2), 0) AS avg_difficulty,
+			COALESCE(SUM(p.total_questions), 0) AS total_questions`).
+		Joins("LEFT JOIN user_profiles p ON p.user_id = m.user_id").
+		Where("m.class_id = ?", classID).
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Scan 会先清零目标结构体，因此答题统计单独查询后再合并
+	var period Stats
+	err = r.db.Table("answer_records a").
+		Select(`COUNT(DISTINCT a.user_id) AS active_members,
+			COUNT(*) AS answer_count,
+			COUNT(*) FILTER (WHERE a.is_correct) AS correct_count,
+			COALESCE(ROUND(AVG(a.response_time), 2), 0) AS avg_response_time`).
+		Joins("JOIN class_members m ON m.user_id = a.user_id").
+		Where("m.class_id = ? AND a.created_at >= ?", classID, since).
+		Scan(&period).Error
+	if err != nil {
+		return nil, err
+	}
+	stats.ActiveMembers = period.ActiveMembers
+	stats.AnswerCount = period.AnswerCount
+	stats.CorrectCount = period.CorrectCount
+	stats.AvgResponseTime = period.AvgResponseTime
+	return &stats, nil
+}
+
+// translateError 将唯一键冲突转换为指定的业务错误
+func translateError(err, duplicate error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return duplicate
+	}
+	return err
+}
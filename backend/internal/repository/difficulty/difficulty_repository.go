@@ -0,0 +1,81 @@
+/*
+File: difficulty_repository.go
+Author: lxp
+Description: 自适应难度数据访问层
+*/
+package difficulty
+
+import (
+	"time"
+	"zhixue-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// Repository 定义自适应难度数据仓库的接口
+type Repository interface {
+	FindCurrentDifficulty(userID int64) (float64, error)
+	RecentAnswers(userID int64, limit int) ([]models.AnswerRecord, error)
+	SaveAdjustment(adjustment *models.DifficultyAdjustment) error
+	FindUsersDueForRecalibration(since time.Time, limit int) ([]int64, error)
+}
+
+// difficultyRepository 实现了Repository接口
+type difficultyRepository struct {
+	db *gorm.DB
+}
+
+// NewDifficultyRepository 创建一个新的自适应难度数据仓库实例
+func NewDifficultyRepository(db *gorm.DB) Repository {
+	return &difficultyRepository{db: db}
+}
+
+// FindCurrentDifficulty 获取用户当前的难度水平
+func (r *difficultyRepository) FindCurrentDifficulty(userID int64) (float64, error) {
+	var profile models.UserProfile
+	err := r.db.Select("current_difficulty").Where("user_id = ?", userID).First(&profile).Error
+	if err != nil {
+		return 0, err
+	}
+	return profile.CurrentDifficulty, nil
+}
+
+// RecentAnswers 获取用户最近的答题记录，按答题时间倒序
+func (r *difficultyRepository) RecentAnswers(userID int64, limit int) ([]models.AnswerRecord, error) {
+	var records []models.AnswerRecord
+	err := r.db.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// SaveAdjustment 在一个事务中写入难度调整历史，并更新用户当前难度
+func (r *difficultyRepository) SaveAdjustment(adjustment *models.DifficultyAdjustment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(adjustment).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.UserProfile{}).
+			Where("user_id = ?", adjustment.UserID).
+			Update("current_difficulty", adjustment.NewDifficulty).Error
+	})
+}
+
+// FindUsersDueForRecalibration 查找 since 之后有答题、但 since 之后没有任何难度调整记录的用户
+func (r *difficultyRepository) FindUsersDueForRecalibration(since time.Time, limit int) ([]int64, error) {
+	var userIDs []int64
+	err := r.db.Model(&models.AnswerRecord{}).
+		Distinct("user_id").
+		Where("created_at >= ?", since).
+		Where("NOT EXISTS (SELECT 1 FROM difficulty_adjustments da WHERE da.user_id = answer_records.user_id AND da.created_at >= ?)", since).
+		Limit(limit).
+		Pluck("user_id", &userIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
@@ -0,0 +1,174 @@
+/*
+File: exam_repository.go
+Author: lxp
+Description: 限时考试、试卷题目与考试答题数据访问层
+*/
+package exam
+
+import (
+	"time"
+	"zhixue-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// 考试状态，与数据库 exam_status 枚举保持一致
+const (
+	StatusInProgress = "in_progress"
+	StatusSubmitted  = "submitted" // 学生主动交卷
+	StatusExpired    = "expired"   // 超时或手动结束考试会话后自动交卷
+)
+
+// DueExam 是需要自动交卷的进行中考试
+type DueExam struct {
+	models.ExamSession
+	SessionEndedAt *time.Time // 考试会话已被手动结束时为会话结束时间
+}
+
+// Answer 是考试中某道题的首次答题记录
+type Answer struct {
+	QuestionID int64
+	UserAnswer string
+	IsCorrect  bool
+	CreatedAt  time.Time
+}
+
+// Repository 定义了考试数据仓库的接口
+type Repository interface {
+	Create(exam *models.ExamSession, questions []models.ExamQuestion) error
+	FindBySession(sessionID string) (*models.ExamSession, error)
+	List(userID int64, page, pageSize int) ([]models.ExamSession, int64, error)
+	ListQuestions(sessionID string) ([]models.ExamQuestion, error)
+	Finish(exam *models.ExamSession) (bool, error)
+	ListDue(userID int64, now time.Time) ([]DueExam, error)
+	HasOpenExamWithQuestion(userID, questionID int64) (bool, error)
+	SessionAnswers(userID int64, sessionID string) ([]Answer, error)
+}
+
+// examRepository 实现了Repository接口
+type examRepository struct {
+	db *gorm.DB
+}
+
+// NewExamRepository 创建一个新的考试数据仓库实例
+func NewExamRepository(db *gorm.DB) Repository {
+	return &examRepository{db: db}
+}
+
+// Create 在一个事务中创建考试及其试卷题目
+func (r *examRepository) Create(exam *models.ExamSession, questions []models.ExamQuestion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(exam).Error; err != nil {
+			return err
+		}
+		return tx.Create(&questions).Error
+	})
+}
+
+// FindBySession 通过学习会话ID获取考试
+func (r *examRepository) FindBySession(sessionID string) (*models.ExamSession, error) {
+	var exam models.ExamSession
+	err := r.db.Where("session_id = ?", sessionID).First(&exam).Error
+	if err != nil {
+		return nil, err
+	}
+	return &exam, nil
+}
+
+// List 分页获取学生的考试记录，按开考时间倒序
+func (r *examRepository) List(userID int64, page, pageSize int) ([]models.ExamSession, int64, error) {
+	query := r.db.Model(&models.ExamSession{}).Where("user_id = ?", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var exams []models.ExamSession
+	err := query.Order("started_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&exams).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return exams, total, nil
+}
+
+// ListQuestions 获取试卷题目，按开考时固定的题序排序
+func (r *examRepository) ListQuestions(sessionID string) ([]models.ExamQuestion, error) {
+	var questions []models.ExamQuestion
+	err := r.db.Where("session_id = ?", sessionID).
+		Order("position ASC").
+		Find(&questions).Error
+	if err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+// Finish 将进行中的考试标记为已交卷并写入成绩
+// 返回 false 表示考试已不在进行中 (如被并发交卷)
+func (r *examRepository) Finish(exam *models.ExamSession) (bool, error) {
+	result := r.db.Model(&models.ExamSession{}).
+		Where("session_id = ? AND status = ?", exam.SessionID, StatusInProgress).
+		Updates(map[string]interface{}{
+			"status":         exam.Status,
+			"submitted_at":   exam.SubmittedAt,
+			"answered_count": exam.AnsweredCount,
+			"correct_count":  exam.CorrectCount,
+			"score":          exam.Score,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ListDue 获取已超时或考试会话已结束、但仍在进行中的考试，userID 为0时不限学生
+func (r *examRepository) ListDue(userID int64, now time.Time) ([]DueExam, error) {
+	query := r.db.Model(&models.ExamSession{}).
+		Joins("JOIN learning_sessions ls ON ls.session_id = exam_sessions.session_id").
+		Where("exam_sessions.status = ?", StatusInProgress).
+		Where("(exam_sessions.deadline_at <= ? OR ls.completion_status IN ?)", now, []string{"completed", "interrupted"})
+	if userID != 0 {
+		query = query.Where("exam_sessions.user_id = ?", userID)
+	}
+
+	var exams []DueExam
+	err := query.Select(`exam_sessions.*,
+		CASE WHEN ls.completion_status IN ('completed', 'interrupted') THEN ls.end_time END AS session_ended_at`).
+		Scan(&exams).Error
+	if err != nil {
+		return nil, err
+	}
+	return exams, nil
+}
+
+// HasOpenExamWithQuestion 判断学生是否有包含该题目且仍在进行中的考试
+func (r *examRepository) HasOpenExamWithQuestion(userID, questionID int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.ExamSession{}).
+		Joins("JOIN exam_questions q ON q.session_id = exam_sessions.session_id").
+		Where("exam_sessions.user_id = ? AND exam_sessions.status = ? AND q.question_id = ?",
+			userID, StatusInProgress, questionID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// SessionAnswers 获取学生在考试会话中每道题的首次答题记录
+func (r *examRepository) SessionAnswers(userID int64, sessionID string) ([]Answer, error) {
+	var answers []Answer
+	err := r.db.Table("answer_records").
+		Select("DISTINCT ON (question_id) question_id, user_answer, is_correct, created_at").
+		Where("user_id = ? AND session_id = ?", userID, sessionID).
+		Order("question_id, created_at").
+		Scan(&answers).Error
+	if err != nil {
+		return nil, err
+	}
+	return answers, nil
+}
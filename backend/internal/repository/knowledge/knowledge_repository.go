@@ -0,0 +1,150 @@
+/*
+File: knowledge_repository.go
+Author: lxp
+Description: 知识点层级与先修关系数据访问层
+*/
+package knowledge
+
+import (
+	"errors"
+	"zhixue-backend/models"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPrerequisiteExists = errors.New("prerequisite already exists")
+	ErrPrerequisiteCycle  = errors.New("prerequisite would create a cycle")
+)
+
+// Repository 定义了知识点数据仓库的接口
+type Repository interface {
+	ListActive(gradeLevel *int) ([]models.KnowledgePoint, error)
+	FindActiveByID(id int64) (*models.KnowledgePoint, error)
+	FindByID(id int64) (*models.KnowledgePoint, error)
+
+	// 先修关系
+	ListPrerequisites(knowledgePointID int64) ([]models.KnowledgePoint, error)
+	ListDependents(knowledgePointID int64) ([]models.KnowledgePoint, error)
+	AddPrerequisite(knowledgePointID, prerequisiteID int64) error
+	RemovePrerequisite(knowledgePointID, prerequisiteID int64) (bool, error)
+}
+
+// knowledgeRepository 实现了Repository接口
+type knowledgeRepository struct {
+	db *gorm.DB
+}
+
+// NewKnowledgeRepository 创建一个新的知识点数据仓库实例
+func NewKnowledgeRepository(db *gorm.DB) Repository {
+	return &knowledgeRepository{db: db}
+}
+
+// ListActive 获取启用状态的知识点，可按年级筛选
+func (r *knowledgeRepository) ListActive(gradeLevel *int) ([]models.KnowledgePoint, error) {
+	query := r.db.Where("is_active = ?", true)
+	if gradeLevel != nil {
+		query = query.Where("grade_level = ?", *gradeLevel)
+	}
+
+	var points []models.KnowledgePoint
+	err := query.Order("grade_level ASC, sort_order ASC, id ASC").Find(&points).Error
+	if err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// FindActiveByID 通过ID获取启用状态的知识点
+func (r *knowledgeRepository) FindActiveByID(id int64) (*models.KnowledgePoint, error) {
+	var point models.KnowledgePoint
+	err := r.db.Where("is_active = ?", true).First(&point, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &point, nil
+}
+
+// FindByID 通过ID获取知识点，不限启用状态
+func (r *knowledgeRepository) FindByID(id int64) (*models.KnowledgePoint, error) {
+	var point models.KnowledgePoint
+	err := r.db.First(&point, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &point, nil
+}
+
+// ListPrerequisites 获取知识点的直接先修知识点 (仅启用状态)
+func (r *knowledgeRepository) ListPrerequisites(knowledgePointID int64) ([]models.KnowledgePoint, error) {
+	var points []models.KnowledgePoint
+	err := r.db.Joins("JOIN knowledge_point_prerequisites p ON p.prerequisite_id = knowledge_points.id").
+		Where("p.knowledge_point_id = ? AND knowledge_points.is_active = ?", knowledgePointID, true).
+		Order("knowledge_points.grade_level ASC, knowledge_points.sort_order ASC, knowledge_points.id ASC").
+		Find(&points).Error
+	if err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// ListDependents 获取直接以该知识点为先修的知识点 (仅启用状态)
+func (r *knowledgeRepository) ListDependents(knowledgePointID int64) ([]models.KnowledgePoint, error) {
+	var points []models.KnowledgePoint
+	err := r.db.Joins("JOIN knowledge_point_prerequisites p ON p.knowledge_point_id = knowledge_points.id").
+		Where("p.prerequisite_id = ? AND knowledge_points.is_active = ?", knowledgePointID, true).
+		Order("knowledge_points.grade_level ASC, knowledge_points.sort_order ASC, knowledge_points.id ASC").
+		Find(&points).Error
+	if err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// AddPrerequisite 添加一条先修关系：knowledgePointID 需要先掌握 prerequisiteID
+// 在事务中锁定先修关系表后检查 prerequisiteID 是否 (直接或间接) 依赖 knowledgePointID，
+// 是则返回 ErrPrerequisiteCycle；关系已存在时返回 ErrPrerequisiteExists
+func (r *knowledgeRepository) AddPrerequisite(knowledgePointID, prerequisiteID int64) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("LOCK TABLE knowledge_point_prerequisites IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+			return err
+		}
+
+		var reachable bool
+		err := tx.Raw(`WITH RECURSIVE ancestors(id) AS (
+				SELECT prerequisite_id FROM knowledge_point_prerequisites WHERE knowledge_point_id = ?
+				UNION
+				SELECT p.prerequisite_id FROM knowledge_point_prerequisites p JOIN ancestors a ON p.knowledge_point_id = a.id
+			)
+			SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = ?)`, prerequisiteID, knowledgePointID).
+			Scan(&reachable).Error
+		if err != nil {
+			return err
+		}
+		if reachable {
+			return ErrPrerequisiteCycle
+		}
+
+		return tx.Create(&models.KnowledgePointPrerequisite{
+			KnowledgePointID: knowledgePointID,
+			PrerequisiteID:   prerequisiteID,
+		}).Error
+	})
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrPrerequisiteExists
+	}
+	return err
+}
+
+// RemovePrerequisite 删除一条先修关系，返回 false 表示关系不存在
+func (r *knowledgeRepository) RemovePrerequisite(knowledgePointID, prerequisiteID int64) (bool, error) {
+	result := r.db.Where("knowledge_point_id = ? AND prerequisite_id = ?", knowledgePointID, prerequisiteID).
+		Delete(&models.KnowledgePointPrerequisite{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
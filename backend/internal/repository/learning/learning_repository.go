@@ -0,0 +1,327 @@
+/*
+File: learning_repository.go
+Author: lxp
+Description: 学习行为记录数据访问层
+*/
+package learning
+
+import (
+	"errors"
+	"time"
+	"zhixue-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrSessionNotActive = errors.New("learning session is not active")
+	ErrDuplicateAnswer  = errors.New("question already answered in this session")
+)
+
+// singleAnswerSessionTypes 中的会话每道题只能作答一次 (考试、作业)
+var singleAnswerSessionTypes = map[string]bool{"test": true, "homework": true}
+
+// SessionFilter 定义学习会话列表的筛选条件
+type SessionFilter struct {
+	Page        int
+	PageSize    int
+	Status      string
+	SessionType string
+}
+
+// AnswerFilter 定义答题记录列表的筛选条件
+type AnswerFilter struct {
+	Page      int
+	PageSize  int
+	SessionID string
+	IsCorrect *bool
+}
+
+// AnswerWithQuestion 是附带题目标题的答题记录
+type AnswerWithQuestion struct {
+	models.AnswerRecord
+	QuestionTitle string
+	InOpenExam    bool // 题目属于用户进行中的考试，交卷前不公开对错
+}
+
+// inOpenExamSQL 判断答题记录的题目是否属于该用户进行中的考试
+const inOpenExamSQL = `EXISTS (SELECT 1 FROM exam_sessions e JOIN exam_questions eq ON eq.session_id = e.session_id
+	WHERE e.user_id = answer_records.user_id AND e.status = 'in_progress' AND eq.question_id = answer_records.question_id)`
+
+// Repository 定义学习行为记录数据仓库的接口
+type Repository interface {
+	SaveAnswer(record *models.AnswerRecord) error
+	CreateSession(session *models.LearningSession) error
+	FindSession(userID int64, sessionID string) (*models.LearningSession, error)
+	ListSessions(userID int64, filter SessionFilter) ([]models.LearningSession, int64, error)
+	TransitionSession(id int64, fromStatuses []string, updates map[string]interface{}) (bool, error)
+	InterruptUserSessions(userID int64, now time.Time) ([]models.LearningSession, error)
+	InterruptIdleSessions(idleBefore time.Time) ([]models.LearningSession, error)
+	ListAnswers(userID int64, filter AnswerFilter) ([]AnswerWithQuestion, int64, error)
+	StudySecondsSince(userID int64, since, now time.Time) (int, error)
+}
+
+// learningRepository 实现了Repository接口
+type learningRepository struct {
+	db *gorm.DB
+}
+
+// NewLearningRepository 创建一个新的学习行为记录数据仓库实例
+func NewLearningRepository(db *gorm.DB) Repository {
+	return &learningRepository{db: db}
+}
+
+// SaveAnswer 在一个事务中写入答题记录，并同步更新题目统计、用户学习档案和所属学习会话
+func (r *learningRepository) SaveAnswer(record *models.AnswerRecord) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// 0. 锁定所属学习会话，同一会话的答题串行写入，考试与作业中同一题的并发提交只有一次成功
+		if record.SessionID != "" {
+			var session models.LearningSession
+			err := tx.Clauses(clause.Locking{Strength: "NO KEY UPDATE"}).
+				Select("session_type").
+				Where("session_id = ? AND user_id = ?", record.SessionID, record.UserID).
+				Take(&session).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrSessionNotActive
+			}
+			if err != nil {
+				return err
+			}
+			if singleAnswerSessionTypes[session.SessionType] {
+				var count int64
+				err := tx.Model(&models.AnswerRecord{}).
+					Where("session_id = ? AND question_id = ?", record.SessionID, record.QuestionID).
+					Count(&count).Error
+				if err != nil {
+					return err
+				}
+				if count > 0 {
+					return ErrDuplicateAnswer
+				}
+			}
+		}
+
+		// 1. 写入答题记录 (id 由数据库 IDENTITY 生成；空IP无法写入inet列，需要忽略)
+		omit := []string{"ID"}
+		if record.IPAddress == "" {
+			omit = append(omit, "IPAddress")
+		}
+		if err := tx.Omit(omit...).Create(record).Error; err != nil {
+			return err
+		}
+
+		correct := 0
+		if record.IsCorrect {
+			correct = 1
+		}
+
+		// 2. 更新题目统计 (SET 子句右侧引用的均为更新前的值)
+		err := tx.Model(&models.Question{}).
+			Where("id = ?", record.QuestionID).
+			UpdateColumns(map[string]interface{}{
+				"usage_count":       gorm.Expr("usage_count + 1"),
+				"correct_rate":      gorm.Expr("ROUND((correct_rate * usage_count + ? * 100.0) / (usage_count + 1), 2)", correct),
+				"avg_response_time": gorm.Expr("(avg_response_time * usage_count + ?) / (usage_count + 1)", record.ResponseTime),
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		// 3. 更新用户学习档案
+		err = tx.Model(&models.UserProfile{}).
+			Where("user_id = ?", record.UserID).
+			Updates(map[string]interface{}{
+				"total_questions": gorm.Expr("total_questions + 1"),
+				"correct_answers": gorm.Expr("correct_answers + ?", correct),
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		// 4. 更新所属学习会话的统计，会话必须处于进行中
+		if record.SessionID == "" {
+			return nil
+		}
+		result := tx.Model(&models.LearningSession{}).
+			Where("session_id = ? AND user_id = ? AND completion_status = ?", record.SessionID, record.UserID, "ongoing").
+			Updates(map[string]interface{}{
+				"questions_count": gorm.Expr("questions_count + 1"),
+				"correct_count":   gorm.Expr("correct_count + ?", correct),
+				"avg_difficulty":  gorm.Expr("ROUND((avg_difficulty * questions_count + ?) / (questions_count + 1), 2)", record.DifficultyAtTime),
+				"last_active_at":  record.CreatedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrSessionNotActive
+		}
+		return nil
+	})
+}
+
+// CreateSession 创建一个新的学习会话
+func (r *learningRepository) CreateSession(session *models.LearningSession) error {
+	return r.db.Create(session).Error
+}
+
+// FindSession 通过会话ID获取属于指定用户的学习会话
+func (r *learningRepository) FindSession(userID int64, sessionID string) (*models.LearningSession, error) {
+	var session models.LearningSession
+	err := r.db.Where("session_id = ? AND user_id = ?", sessionID, userID).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListSessions 分页获取用户的学习会话，按开始时间倒序
+func (r *learningRepository) ListSessions(userID int64, filter SessionFilter) ([]models.LearningSession, int64, error) {
+	query := r.db.Model(&models.LearningSession{}).Where("user_id = ?", userID)
+	if filter.Status != "" {
+		query = query.Where("completion_status = ?", filter.Status)
+	}
+	if filter.SessionType != "" {
+		query = query.Where("session_type = ?", filter.SessionType)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var sessions []models.LearningSession
+	err := query.Order("start_time DESC").
+		Offset((filter.Page - 1) * filter.PageSize).
+		Limit(filter.PageSize).
+		Find(&sessions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return sessions, total, nil
+}
+
+// TransitionSession 以状态为条件更新会话，避免并发请求覆盖彼此的状态
+// 返回 false 表示会话当前状态不在 fromStatuses 中，未做任何更新
+func (r *learningRepository) TransitionSession(id int64, fromStatuses []string, updates map[string]interface{}) (bool, error) {
+	result := r.db.Model(&models.LearningSession{}).
+		Where("id = ? AND completion_status IN ?", id, fromStatuses).
+		Updates(updates)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// InterruptUserSessions 将用户所有未结束的会话标记为中断，返回被中断的会话
+// 考试会话由考试倒计时与交卷控制，开始其他会话不会中断进行中的考试
+func (r *learningRepository) InterruptUserSessions(userID int64, now time.Time) ([]models.LearningSession, error) {
+	var sessions []models.LearningSession
+	err := r.db.Model(&sessions).
+		Clauses(clause.Returning{}).
+		Where("user_id = ? AND completion_status IN ?", userID, []string{"ongoing", "paused"}).
+		Where("session_type <> ?", "test").
+		Updates(map[string]interface{}{
+			"completion_status": "interrupted",
+			"end_time":          now,
+			"paused_seconds":    gorm.Expr("paused_seconds + COALESCE(EXTRACT(EPOCH FROM (? - paused_at))::int, 0)", now),
+			"paused_at":         nil,
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// InterruptIdleSessions 将最后活跃时间早于 idleBefore 的进行中会话标记为中断
+// 考试会话由考试倒计时控制，不做空闲中断；结束时间记为最后一次活跃的时间，返回被中断的会话
+func (r *learningRepository) InterruptIdleSessions(idleBefore time.Time) ([]models.LearningSession, error) {
+	var sessions []models.LearningSession
+	err := r.db.Model(&sessions).
+		Clauses(clause.Returning{}).
+		Where("completion_status = ? AND COALESCE(last_active_at, start_time) < ?", "ongoing", idleBefore).
+		Where("session_type <> ?", "test").
+		Updates(map[string]interface{}{
+			"completion_status": "interrupted",
+			"end_time":          gorm.Expr("COALESCE(last_active_at, start_time)"),
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// ListAnswers 分页获取用户的答题记录，按作答时间倒序
+// 按对错筛选时不包含用户进行中考试的题目
+func (r *learningRepository) ListAnswers(userID int64, filter AnswerFilter) ([]AnswerWithQuestion, int64, error) {
+	query := r.db.Model(&models.AnswerRecord{}).Where("answer_records.user_id = ?", userID)
+	if filter.SessionID != "" {
+		query = query.Where("answer_records.session_id = ?", filter.SessionID)
+	}
+	if filter.IsCorrect != nil {
+		// 进行中考试的题目不参与按对错筛选，否则可以从筛选结果推断出对错
+		query = query.Where("answer_records.is_correct = ? AND NOT "+inOpenExamSQL, *filter.IsCorrect)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var records []AnswerWithQuestion
+	err := query.Select("answer_records.*, questions.title AS question_title, " + inOpenExamSQL + " AS in_open_exam").
+		Joins("LEFT JOIN questions ON questions.id = answer_records.question_id").
+		Order("answer_records.created_at DESC").
+		Offset((filter.Page - 1) * filter.PageSize).
+		Limit(filter.PageSize).
+		Scan(&records).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return records, total, nil
+}
+
+// StudySecondsSince 统计用户在 since 之后开始的会话的有效学习时长 (秒，不含暂停时间)
+// 未结束的会话计算到 now，暂停中的会话计算到暂停时刻
+// 进行中的考试不会被其他会话中断，时间上重叠的会话只计一次，见 studySeconds
+func (r *learningRepository) StudySecondsSince(userID int64, since, now time.Time) (int, error) {
+	var sessions []models.LearningSession
+	err := r.db.Select("start_time", "end_time", "paused_at", "paused_seconds").
+		Where("user_id = ? AND start_time >= ?", userID, since).
+		Order("start_time").
+		Find(&sessions).Error
+	if err != nil {
+		return 0, err
+	}
+	return studySeconds(sessions, now), nil
+}
+
+// studySeconds 计算会话学习时长的并集 (秒)，sessions 需按开始时间排序
+// 会话的暂停时段没有逐段记录，这里将每个会话视为从开始时刻起连续学习了其有效时长
+// (考试不能暂停，其时段是准确的)
+func studySeconds(sessions []models.LearningSession, now time.Time) int {
+	var total time.Duration
+	var spanStart, spanEnd time.Time
+	for _, s := range sessions {
+		end := now
+		if s.EndTime != nil {
+			end = *s.EndTime
+		} else if s.PausedAt != nil {
+			end = *s.PausedAt
+		}
+		end = end.Add(-time.Duration(s.PausedSeconds) * time.Second)
+		if !end.After(s.StartTime) {
+			continue
+		}
+
+		if s.StartTime.After(spanEnd) {
+			total += spanEnd.Sub(spanStart)
+			spanStart, spanEnd = s.StartTime, end
+		} else if end.After(spanEnd) {
+			spanEnd = end
+		}
+	}
+	total += spanEnd.Sub(spanStart)
+	return int(total / time.Second)
+}
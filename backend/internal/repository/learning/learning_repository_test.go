@@ -0,0 +1,61 @@
+/*
+File: learning_repository_test.go
+Author: lxp
+Description: 学习时长统计的单元测试
+*/
+package learning
+
+import (
+	"testing"
+	"time"
+	"zhixue-backend/models"
+)
+
+func TestStudySeconds(t *testing.T) {
+	base := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return base.Add(time.Duration(minutes) * time.Minute) }
+	ended := func(minutes int) *time.Time { t := at(minutes); return &t }
+	now := at(120)
+
+	tests := []struct {
+		name     string
+		sessions []models.LearningSession
+		want     int
+	}{
+		{"没有会话", nil, 0},
+		{"先后两个会话", []models.LearningSession{
+			{StartTime: at(0), EndTime: ended(10)},
+			{StartTime: at(20), EndTime: ended(50)},
+		}, 40 * 60},
+		{"扣除暂停时间", []models.LearningSession{
+			{StartTime: at(0), EndTime: ended(30), PausedSeconds: 10 * 60},
+		}, 20 * 60},
+		{"未结束的会话计算到现在", []models.LearningSession{
+			{StartTime: at(100)},
+		}, 20 * 60},
+		{"暂停中的会话计算到暂停时刻", []models.LearningSession{
+			{StartTime: at(100), PausedAt: ended(105)},
+		}, 5 * 60},
+		{"考试期间的练习只计一次", []models.LearningSession{
+			{StartTime: at(0), EndTime: ended(60)},
+			{StartTime: at(10), EndTime: ended(30)},
+			{StartTime: at(40), EndTime: ended(70)},
+		}, 70 * 60},
+		{"练习期间开始的考试只计一次", []models.LearningSession{
+			{StartTime: at(0), EndTime: ended(20)},
+			{StartTime: at(5), EndTime: ended(45)},
+		}, 45 * 60},
+		{"忽略时长为0的会话", []models.LearningSession{
+			{StartTime: at(0), EndTime: ended(10), PausedSeconds: 20 * 60},
+			{StartTime: at(30), EndTime: ended(40)},
+		}, 10 * 60},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := studySeconds(tt.sessions, now); got != tt.want {
+				t.Errorf("studySeconds = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+/*
+File: mastery_repository.go
+Author: lxp
+Description: 知识点掌握度数据访问层
+*/
+package mastery
+
+import (
+	"time"
+	"zhixue-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MasteryWithPoint 是附带知识点信息的掌握度记录
+type MasteryWithPoint struct {
+	models.UserKnowledgeMastery
+	Name       string
+	Code       string
+	GradeLevel int
+}
+
+// AnsweredPoint 是答题历史中的一次作答在某个知识点上的记录，用于重建掌握度
+type AnsweredPoint struct {
+	KnowledgePointID int64
+	IsCorrect        bool
+	CreatedAt        time.Time
+}
+
+// Repository 定义知识点掌握度数据仓库的接口
+type Repository interface {
+	FindKnowledgePointIDs(questionID int64) ([]int64, error)
+	FindParams(knowledgePointIDs []int64) (map[int64]models.KnowledgePointMasteryParam, error)
+	SaveParam(param *models.KnowledgePointMasteryParam) error
+	DeleteParam(knowledgePointID int64) (bool, error)
+
+	Update(userID int64, knowledgePointIDs []int64, apply func(m *models.UserKnowledgeMastery)) error
+	ListByUser(userID int64, gradeLevel *int) ([]MasteryWithPoint, error)
+
+	// 离线重建
+	AnswerHistory(userID int64) ([]AnsweredPoint, error)
+	Replace(userID int64, masteries []models.UserKnowledgeMastery) error
+	FindUsersWithAnswers(afterID int64, limit int) ([]int64, error)
+}
+
+// masteryRepository 实现了Repository接口
+type masteryRepository struct {
+	db *gorm.DB
+}
+
+// NewMasteryRepository 创建一个新的知识点掌握度数据仓库实例
+func NewMasteryRepository(db *gorm.DB) Repository {
+	return &masteryRepository{db: db}
+}
+
+// FindKnowledgePointIDs 获取题目关联的知识点ID
+func (r *masteryRepository) FindKnowledgePointIDs(questionID int64) ([]int64, error) {
+	var ids []int64
+	err := r.db.Model(&models.QuestionKnowledgePoint{}).
+		Where("question_id = ?", questionID).
+		Order("knowledge_point_id ASC").
+		Pluck("knowledge_point_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// FindParams 获取知识点的BKT参数，未单独配置的知识点不在结果中
+func (r *masteryRepository) FindParams(knowledgePointIDs []int64) (map[int64]models.KnowledgePointMasteryParam, error) {
+	result := make(map[int64]models.KnowledgePointMasteryParam, len(knowledgePointIDs))
+	if len(knowledgePointIDs) == 0 {
+		return result, nil
+	}
+
+	var params []models.KnowledgePointMasteryParam
+	if err := r.db.Where("knowledge_point_id IN ?", knowledgePointIDs).Find(&params).Error; err != nil {
+		return nil, err
+	}
+	for _, p := range params {
+		result[p.KnowledgePointID] = p
+	}
+	return result, nil
+}
+
+// SaveParam 创建或覆盖知识点的BKT参数
+func (r *masteryRepository) SaveParam(param *models.KnowledgePointMasteryParam) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "knowledge_point_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"p_init", "p_learn", "p_slip", "p_guess", "updated_by", "updated_at"}),
+	}).Create(param).Error
+}
+
+// DeleteParam 删除知识点的BKT参数，恢复使用默认值，返回 false 表示未单独配置
+func (r *masteryRepository) DeleteParam(knowledgePointID int64) (bool, error) {
+	result := r.db.Where("knowledge_point_id = ?", knowledgePointID).Delete(&models.KnowledgePointMasteryParam{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Update 在一个事务中锁定用户在这些知识点上的掌握度记录 (不存在时先创建空记录)，
+// 由 apply 修改后写回；尚未作答过的记录 AttemptCount 为 0
+func (r *masteryRepository) Update(userID int64, knowledgePointIDs []int64, apply func(m *models.UserKnowledgeMastery)) error {
+	if len(knowledgePointIDs) == 0 {
+		return nil
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		placeholders := make([]models.UserKnowledgeMastery, 0, len(knowledgePointIDs))
+		for _, id := range knowledgePointIDs {
+			placeholders = append(placeholders, models.UserKnowledgeMastery{UserID: userID, KnowledgePointID: id})
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&placeholders).Error; err != nil {
+			return err
+		}
+
+		var masteries []models.UserKnowledgeMastery
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND knowledge_point_id IN ?", userID, knowledgePointIDs).
+			Order("knowledge_point_id ASC").
+			Find(&masteries).Error
+		if err != nil {
+			return err
+		}
+
+		for i := range masteries {
+			m := &masteries[i]
+			apply(m)
+			err := tx.Model(m).Updates(map[string]interface{}{
+				"mastery":          m.Mastery,
+				"attempt_count":    m.AttemptCount,
+				"correct_count":    m.CorrectCount,
+				"last_answered_at": m.LastAnsweredAt,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListByUser 获取用户已作答过的启用知识点的掌握度，可按年级筛选，掌握概率低的在前
+func (r *masteryRepository) ListByUser(userID int64, gradeLevel *int) ([]MasteryWithPoint, error) {
+	query := r.db.Table("user_knowledge_masteries m").
+		Select("m.*, kp.name, kp.code, kp.grade_level").
+		Joins("JOIN knowledge_points kp ON kp.id = m.knowledge_point_id").
+		Where("m.user_id = ? AND m.attempt_count > 0 AND kp.is_active", userID)
+	if gradeLevel != nil {
+		query = query.Where("kp.grade_level = ?", *gradeLevel)
+	}
+
+	var rows []MasteryWithPoint
+	err := query.Order("m.mastery ASC, kp.grade_level ASC, kp.sort_order ASC, kp.id ASC").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// AnswerHistory 按答题时间顺序获取用户全部答题在各知识点上的记录
+func (r *masteryRepository) AnswerHistory(userID int64) ([]AnsweredPoint, error) {
+	var points []AnsweredPoint
+	err := r.db.Table("answer_records ar").
+		Select("qkp.knowledge_point_id, ar.is_correct, ar.created_at").
+		Joins("JOIN question_knowledge_points qkp ON qkp.question_id = ar.question_id").
+		Where("ar.user_id = ?", userID).
+		Order("ar.created_at ASC, ar.id ASC, qkp.knowledge_point_id ASC").
+		Scan(&points).Error
+	if err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// Replace 用重建结果覆盖用户的掌握度记录，并删除结果中不存在的知识点记录
+// 在线更新已计入更晚答题的记录不会被覆盖
+func (r *masteryRepository) Replace(userID int64, masteries []models.UserKnowledgeMastery) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		ids := make([]int64, 0, len(masteries))
+		for _, m := range masteries {
+			ids = append(ids, m.KnowledgePointID)
+		}
+
+		del := tx.Where("user_id = ?", userID)
+		if len(ids) > 0 {
+			del = del.Where("knowledge_point_id NOT IN ?", ids)
+		}
+		if err := del.Delete(&models.UserKnowledgeMastery{}).Error; err != nil {
+			return err
+		}
+		if len(masteries) == 0 {
+			return nil
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "knowledge_point_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"mastery", "attempt_count", "correct_count", "last_answered_at", "updated_at"}),
+			Where: clause.Where{Exprs: []clause.Expression{clause.Expr{
+				SQL: "user_knowledge_masteries.last_answered_at IS NULL OR user_knowledge_masteries.last_answered_at <= excluded.last_answered_at",
+			}}},
+		}).Create(&masteries).Error
+	})
+}
+
+// FindUsersWithAnswers 按ID顺序获取 afterID 之后有答题记录的用户，用于分批重建
+func (r *masteryRepository) FindUsersWithAnswers(afterID int64, limit int) ([]int64, error) {
+	var userIDs []int64
+	err := r.db.Model(&models.User{}).
+		Where("id > ?", afterID).
+		Where("EXISTS (SELECT 1 FROM answer_records ar WHERE ar.user_id = users.id)").
+		Order("id ASC").
+		Limit(limit).
+		Pluck("id", &userIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
@@ -0,0 +1,244 @@
+/*
+File: notebook_repository.go
+Author: lxp
+Description: 错题本数据访问层：由答题记录汇总错题、已掌握标记与错题练习题目
+*/
+package notebook
+
+import (
+	"time"
+	"zhixue-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 错题本条目状态筛选
+const (
+	StatusUnresolved = "unresolved"
+	StatusResolved   = "resolved"
+	StatusAll        = "all"
+)
+
+// Filter 是错题本条目的筛选条件
+// From/To/SessionID 限定参与汇总的错误答题记录，条目取范围内该题最近一次答错的记录
+type Filter struct {
+	UserID           int64
+	From             *time.Time // 含
+	To               *time.Time // 不含
+	SessionID        string
+	KnowledgePointID *int64
+	Tag              string // 题目 tags.labels 中的标签
+	Status           string // unresolved (默认) | resolved | all
+}
+
+// Entry 是一道错题及其作答统计，附带题目内容
+type Entry struct {
+	QuestionID   int64
+	SessionID    string    // 范围内最近一次答错所在的会话
+	UserAnswer   string    // 范围内最近一次答错的答案
+	WrongAt      time.Time // 范围内最近一次答错的时间
+	LastWrongAt  time.Time // 不限范围的最近一次答错时间
+	WrongCount   int
+	CorrectSince int // 最近一次答错之后答对的次数
+	ResolvedAt   *time.Time
+	Title        string
+	Content      string
+	QuestionType string
+	Difficulty   float64
+	Hints        models.JSONB
+	Choices      models.JSONB
+	Tags         models.JSONB
+}
+
+// Group 是错题本按知识点或标签的分组统计，Key 为空表示未归类
+type Group struct {
+	Key   *string
+	Name  *string
+	Count int64
+}
+
+// Repository 定义错题本数据仓库的接口
+type Repository interface {
+	ListEntries(filter Filter, page, pageSize int) ([]Entry, int64, error)
+	FindEntry(userID, questionID int64) (*Entry, error)
+	GroupByKnowledgePoint(filter Filter) ([]Group, error)
+	GroupByTag(filter Filter) ([]Group, error)
+	Resolve(userID, questionID int64, resolvedAt time.Time) error
+	CreatePractice(questions []models.NotebookPracticeQuestion) error
+	ListPracticeQuestions(sessionID string) ([]models.NotebookPracticeQuestion, error)
+	AnsweredQuestionIDs(userID int64, sessionID string) ([]int64, error)
+}
+
+// notebookRepository 实现了Repository接口
+type notebookRepository struct {
+	db *gorm.DB
+}
+
+// NewNotebookRepository 创建一个新的错题本数据仓库实例
+func NewNotebookRepository(db *gorm.DB) Repository {
+	return &notebookRepository{db: db}
+}
+
+// entries 返回符合筛选条件的错题本条目查询，只包含仍处于发布状态的题目
+// 已掌握标记早于该题最近一次答错时间时失效，题目按未掌握处理
+func (r *notebookRepository) entries(f Filter) *gorm.DB {
+	wrong := r.db.Table("answer_records").
+		Select("DISTINCT ON (question_id) question_id, session_id, user_answer, created_at AS wrong_at").
+		Where("user_id = ? AND NOT is_correct", f.UserID).
+		// 进行中考试的题目在交卷前不进入错题本，否则可以推断出对错
+		Where(`NOT EXISTS (SELECT 1 FROM exam_sessions e JOIN exam_questions eq ON eq.session_id = e.session_id
+			WHERE e.user_id = answer_records.user_id AND e.status = 'in_progress' AND eq.question_id = answer_records.question_id)`)
+	if f.From != nil {
+		wrong = wrong.Where("created_at >= ?", *f.From)
+	}
+	if f.To != nil {
+		wrong = wrong.Where("created_at < ?", *f.To)
+	}
+	if f.SessionID != "" {
+		wrong = wrong.Where("session_id = ?", f.SessionID)
+	}
+	wrong = wrong.Order("question_id, created_at DESC")
+
+	stats := r.db.Table("answer_records").
+		Select(`question_id,
+			MAX(created_at) FILTER (WHERE NOT is_correct) AS last_wrong_at,
+			COUNT(*) FILTER (WHERE NOT is_correct) AS wrong_count`).
+		Where("user_id = ?", f.UserID).
+		Group("question_id")
+
+	query := r.db.Table("(?) w", wrong).
+		Joins("JOIN (?) s ON s.question_id = w.question_id", stats).
+		Joins(`JOIN LATERAL (SELECT COUNT(*) AS correct_since FROM answer_records c
+			WHERE c.user_id = ? AND c.question_id = w.question_id AND c.is_correct AND c.created_at > s.last_wrong_at) cs ON TRUE`, f.UserID).
+		Joins("JOIN questions q ON q.id = w.question_id AND q.is_active AND q.review_status = 'approved'").
+		Joins("LEFT JOIN notebook_resolutions nr ON nr.user_id = ? AND nr.question_id = w.question_id AND nr.resolved_at > s.last_wrong_at", f.UserID)
+
+	switch f.Status {
+	case StatusAll:
+	case StatusResolved:
+		query = query.Where("nr.question_id IS NOT NULL")
+	default:
+		query = query.Where("nr.question_id IS NULL")
+	}
+	if f.KnowledgePointID != nil {
+		query = query.Where("EXISTS (SELECT 1 FROM question_knowledge_points qkp WHERE qkp.question_id = w.question_id AND qkp.knowledge_point_id = ?)",
+			*f.KnowledgePointID)
+	}
+	if f.Tag != "" {
+		query = query.Where("q.tags->'labels' @> jsonb_build_array(CAST(? AS TEXT))", f.Tag)
+	}
+	return query
+}
+
+const entryColumns = `w.question_id, w.session_id, w.user_answer, w.wrong_at,
+	s.last_wrong_at, s.wrong_count, cs.correct_since, nr.resolved_at,
+	q.title, q.content, q.question_type, q.difficulty, q.hints, q.choices, q.tags`
+
+// ListEntries 分页获取错题本条目，最近答错的在前
+func (r *notebookRepository) ListEntries(filter Filter, page, pageSize int) ([]Entry, int64, error) {
+	var total int64
+	if err := r.entries(filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []Entry
+	err := r.entries(filter).
+		Select(entryColumns).
+		Order("w.wrong_at DESC, w.question_id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Scan(&entries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// FindEntry 获取用户错题本中的某道题 (不论是否已掌握)，不在错题本中时返回 gorm.ErrRecordNotFound
+func (r *notebookRepository) FindEntry(userID, questionID int64) (*Entry, error) {
+	var entries []Entry
+	err := r.entries(Filter{UserID: userID, Status: StatusAll}).
+		Select(entryColumns).
+		Where("w.question_id = ?", questionID).
+		Scan(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &entries[0], nil
+}
+
+// GroupByKnowledgePoint 按知识点统计错题数，一道题关联多个知识点时计入每个知识点
+// 未关联有效知识点的题目归入 Key 为空的分组
+func (r *notebookRepository) GroupByKnowledgePoint(filter Filter) ([]Group, error) {
+	var groups []Group
+	err := r.db.Table("(?) e", r.entries(filter).Select("w.question_id")).
+		Joins(`LEFT JOIN (question_knowledge_points qkp
+			JOIN knowledge_points kp ON kp.id = qkp.knowledge_point_id AND kp.is_active) ON qkp.question_id = e.question_id`).
+		Select("CAST(kp.id AS TEXT) AS key, kp.name AS name, COUNT(DISTINCT e.question_id) AS count").
+		Group("kp.id, kp.name").
+		Order("count DESC, kp.id").
+		Scan(&groups).Error
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// GroupByTag 按题目 tags.labels 中的标签统计错题数，没有标签的题目归入 Key 为空的分组
+func (r *notebookRepository) GroupByTag(filter Filter) ([]Group, error) {
+	var groups []Group
+	err := r.db.Table("(?) e", r.entries(filter).Select("w.question_id, q.tags")).
+		Joins(`LEFT JOIN LATERAL jsonb_array_elements_text(
+			CASE WHEN jsonb_typeof(e.tags->'labels') = 'array' THEN e.tags->'labels' ELSE '[]'::jsonb END) AS t(label) ON TRUE`).
+		Select("t.label AS key, t.label AS name, COUNT(DISTINCT e.question_id) AS count").
+		Group("t.label").
+		Order("count DESC, t.label").
+		Scan(&groups).Error
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// Resolve 将题目标记为已掌握，重复标记时更新标记时间
+func (r *notebookRepository) Resolve(userID, questionID int64, resolvedAt time.Time) error {
+	resolution := models.NotebookResolution{UserID: userID, QuestionID: questionID, ResolvedAt: resolvedAt}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "question_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"resolved_at"}),
+	}).Create(&resolution).Error
+}
+
+// CreatePractice 保存错题练习会话的题目
+func (r *notebookRepository) CreatePractice(questions []models.NotebookPracticeQuestion) error {
+	return r.db.Create(&questions).Error
+}
+
+// ListPracticeQuestions 获取错题练习会话的题目，按生成时的题序排序，不是错题练习会话时返回空列表
+func (r *notebookRepository) ListPracticeQuestions(sessionID string) ([]models.NotebookPracticeQuestion, error) {
+	var questions []models.NotebookPracticeQuestion
+	err := r.db.Where("session_id = ?", sessionID).
+		Order("position ASC").
+		Find(&questions).Error
+	if err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+// AnsweredQuestionIDs 获取学生在会话中已作答过的题目ID
+func (r *notebookRepository) AnsweredQuestionIDs(userID int64, sessionID string) ([]int64, error) {
+	var ids []int64
+	err := r.db.Table("answer_records").
+		Where("user_id = ? AND session_id = ?", userID, sessionID).
+		Distinct().
+		Pluck("question_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,92 @@
+/*
+File: notification_repository.go
+Author: lxp
+Description: 站内通知数据访问层
+*/
+package notification
+
+import (
+	"time"
+	"zhixue-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// Repository 定义站内通知数据仓库的接口
+type Repository interface {
+	Create(n *models.Notification) error
+	List(userID int64, unreadOnly bool, page, pageSize int) ([]models.Notification, int64, error)
+	CountUnread(userID int64) (int64, error)
+	MarkRead(userID, id int64, now time.Time) error
+	MarkAllRead(userID int64, now time.Time) (int64, error)
+}
+
+// notificationRepository 实现了Repository接口
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository 创建一个新的站内通知数据仓库实例
+func NewNotificationRepository(db *gorm.DB) Repository {
+	return &notificationRepository{db: db}
+}
+
+// Create 创建一条通知
+func (r *notificationRepository) Create(n *models.Notification) error {
+	return r.db.Omit("ID").Create(n).Error
+}
+
+// List 分页获取用户的通知，最新的在前
+func (r *notificationRepository) List(userID int64, unreadOnly bool, page, pageSize int) ([]models.Notification, int64, error) {
+	query := r.db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var notifications []models.Notification
+	err := query.Order("id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&notifications).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return notifications, total, nil
+}
+
+// CountUnread 统计用户的未读通知数
+func (r *notificationRepository) CountUnread(userID int64) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRead 将用户的一条通知标记为已读，已读的通知保持原来的已读时间
+// 通知不存在或不属于该用户时返回 gorm.ErrRecordNotFound
+func (r *notificationRepository) MarkRead(userID, id int64, now time.Time) error {
+	result := r.db.Model(&models.Notification{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("read_at", gorm.Expr("COALESCE(read_at, ?)", now))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// MarkAllRead 将用户的全部未读通知标记为已读，返回标记的条数
+func (r *notificationRepository) MarkAllRead(userID int64, now time.Time) (int64, error) {
+	result := r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", now)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,115 @@
+/*
+File: parent_repository.go
+Author: lxp
+Description: 家长与孩子关联、家长管控数据访问层
+*/
+package parent
+
+import (
+	"errors"
+	"time"
+	"zhixue-backend/models"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrAlreadyLinked = errors.New("parent and child are already linked")
+)
+
+// LinkedUser 是附带关联时间的用户 (家长视角下的孩子，或孩子视角下的家长)
+type LinkedUser struct {
+	models.User
+	LinkedAt time.Time
+}
+
+// Repository 定义家长数据仓库的接口
+type Repository interface {
+	CreateLink(link *models.ParentChildLink) error
+	DeleteLink(parentID, childID int64) (bool, error)
+	IsLinked(parentID, childID int64) (bool, error)
+	ListChildren(parentID int64) ([]LinkedUser, error)
+	ListParents(childID int64) ([]LinkedUser, error)
+	FindControl(childID int64) (*models.ParentalControl, error)
+	SaveControl(control *models.ParentalControl) error
+}
+
+// parentRepository 实现了Repository接口
+type parentRepository struct {
+	db *gorm.DB
+}
+
+// NewParentRepository 创建一个新的家长数据仓库实例
+func NewParentRepository(db *gorm.DB) Repository {
+	return &parentRepository{db: db}
+}
+
+// CreateLink 创建家长与孩子的关联，已关联时返回 ErrAlreadyLinked
+func (r *parentRepository) CreateLink(link *models.ParentChildLink) error {
+	err := r.db.Create(link).Error
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrAlreadyLinked
+	}
+	return err
+}
+
+// DeleteLink 解除家长与孩子的关联，返回 false 表示两者原本未关联
+func (r *parentRepository) DeleteLink(parentID, childID int64) (bool, error) {
+	result := r.db.Where("parent_id = ? AND child_id = ?", parentID, childID).Delete(&models.ParentChildLink{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// IsLinked 判断家长与孩子是否已关联
+func (r *parentRepository) IsLinked(parentID, childID int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.ParentChildLink{}).
+		Where("parent_id = ? AND child_id = ?", parentID, childID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ListChildren 获取家长关联的所有孩子，按关联时间排序
+func (r *parentRepository) ListChildren(parentID int64) ([]LinkedUser, error) {
+	return r.listLinked("parent_child_links.child_id", "parent_child_links.parent_id", parentID)
+}
+
+// ListParents 获取孩子关联的所有家长，按关联时间排序
+func (r *parentRepository) ListParents(childID int64) ([]LinkedUser, error) {
+	return r.listLinked("parent_child_links.parent_id", "parent_child_links.child_id", childID)
+}
+
+// listLinked 通过关联表查询另一端的用户
+func (r *parentRepository) listLinked(joinColumn, whereColumn string, id int64) ([]LinkedUser, error) {
+	var users []LinkedUser
+	err := r.db.Model(&models.User{}).
+		Select("users.*, parent_child_links.created_at AS linked_at").
+		Joins("JOIN parent_child_links ON users.id = "+joinColumn).
+		Where(whereColumn+" = ?", id).
+		Order("parent_child_links.created_at").
+		Scan(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// FindControl 获取孩子的家长管控设置
+func (r *parentRepository) FindControl(childID int64) (*models.ParentalControl, error) {
+	var control models.ParentalControl
+	err := r.db.Where("child_id = ?", childID).First(&control).Error
+	if err != nil {
+		return nil, err
+	}
+	return &control, nil
+}
+
+// SaveControl 创建或覆盖孩子的家长管控设置
+func (r *parentRepository) SaveControl(control *models.ParentalControl) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "child_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"daily_limit_minutes", "allowed_session_types", "updated_by", "updated_at"}),
+	}).Create(control).Error
+}
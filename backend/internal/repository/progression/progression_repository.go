@@ -0,0 +1,154 @@
+/*
+File: progression_repository.go
+Author: lxp
+Description: 连续学习与等级成长数据访问层：学习日活动、学习档案的成长字段与连续学习保护卡
+*/
+package progression
+
+import (
+	"time"
+	"zhixue-backend/internal/repository/wallet"
+	"zhixue-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Activity 是一次学习活动在所属学习日上的增量
+type Activity struct {
+	Answers      int
+	StudySeconds int
+	XP           int
+}
+
+// Repository 定义连续学习与等级成长数据仓库的接口
+type Repository interface {
+	FindTimezone(userID int64) (string, error)
+	FindProfile(userID int64) (*models.UserProfile, error)
+	Record(userID int64, day time.Time, activity Activity, apply func(p *models.UserProfile) []time.Time) error
+	ListActivity(userID int64, from, to time.Time) ([]models.UserActivityDay, error)
+	PurchaseFreeze(userID int64, max int, payment *models.PointsTransaction) (bool, error)
+}
+
+// progressionRepository 实现了Repository接口
+type progressionRepository struct {
+	db *gorm.DB
+}
+
+// NewProgressionRepository 创建一个新的连续学习与等级成长数据仓库实例
+func NewProgressionRepository(db *gorm.DB) Repository {
+	return &progressionRepository{db: db}
+}
+
+// FindTimezone 获取用户设置的时区，未设置时为空字符串
+func (r *progressionRepository) FindTimezone(userID int64) (string, error) {
+	var user models.User
+	if err := r.db.Select("timezone").Where("id = ?", userID).Take(&user).Error; err != nil {
+		return "", err
+	}
+	return user.Timezone, nil
+}
+
+// FindProfile 获取用户的学习档案
+func (r *progressionRepository) FindProfile(userID int64) (*models.UserProfile, error) {
+	var profile models.UserProfile
+	if err := r.db.Where("user_id = ?", userID).Take(&profile).Error; err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// Record 在一个事务中锁定用户的学习档案，由 apply 更新连续学习与等级字段后写回，并累加学习日 day 的活动
+// apply 返回由保护卡保住的日期，这些日期记为 frozen 的学习日
+// 用户没有学习档案 (如家长账号) 时返回 gorm.ErrRecordNotFound
+func (r *progressionRepository) Record(userID int64, day time.Time, activity Activity, apply func(p *models.UserProfile) []time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var profile models.UserProfile
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).
+			Take(&profile).Error
+		if err != nil {
+			return err
+		}
+
+		frozen := apply(&profile)
+		err = tx.Model(&profile).Updates(map[string]interface{}{
+			"streak_days":      profile.StreakDays,
+			"max_streak_days":  profile.MaxStreakDays,
+			"last_active_date": profile.LastActiveDate,
+			"streak_freezes":   profile.StreakFreezes,
+			"level_score":      profile.LevelScore,
+			"user_level":       profile.UserLevel,
+			"total_study_time": gorm.Expr("total_study_time + ?", activity.StudySeconds),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		for _, d := range frozen {
+			err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&models.UserActivityDay{UserID: userID, ActivityDate: d, Frozen: true}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}, {Name: "activity_date"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"answers":       gorm.Expr("user_activity_days.answers + EXCLUDED.answers"),
+				"study_seconds": gorm.Expr("user_activity_days.study_seconds + EXCLUDED.study_seconds"),
+				"xp":            gorm.Expr("user_activity_days.xp + EXCLUDED.xp"),
+			}),
+		}).Create(&models.UserActivityDay{
+			UserID:       userID,
+			ActivityDate: day,
+			Answers:      activity.Answers,
+			StudySeconds: activity.StudySeconds,
+			XP:           activity.XP,
+		}).Error
+	})
+}
+
+// ListActivity 获取用户在 [from, to] 日期范围内的学习日活动，按日期升序
+func (r *progressionRepository) ListActivity(userID int64, from, to time.Time) ([]models.UserActivityDay, error) {
+	var days []models.UserActivityDay
+	err := r.db.Where("user_id = ? AND activity_date BETWEEN ? AND ?", userID, from, to).
+		Order("activity_date ASC").
+		Find(&days).Error
+	if err != nil {
+		return nil, err
+	}
+	return days, nil
+}
+
+// PurchaseFreeze 在一个事务中扣除积分 payment 并增加一张连续学习保护卡，已持有 max 张时返回 false 且不扣积分
+// 锁定学习档案后再写入积分流水，与 Record 消耗保护卡互斥；余额不足时返回 wallet.ErrInsufficientBalance，保护卡不变
+// 用户没有学习档案时返回 gorm.ErrRecordNotFound
+func (r *progressionRepository) PurchaseFreeze(userID int64, max int, payment *models.PointsTransaction) (bool, error) {
+	purchased := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var profile models.UserProfile
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).
+			Take(&profile).Error
+		if err != nil {
+			return err
+		}
+		if profile.StreakFreezes >= max {
+			return nil
+		}
+
+		if _, err := wallet.AppendTx(tx, payment); err != nil {
+			return err
+		}
+		err = tx.Model(&profile).
+			UpdateColumn("streak_freezes", gorm.Expr("streak_freezes + 1")).Error
+		if err != nil {
+			return err
+		}
+		purchased = true
+		return nil
+	})
+	return purchased, err
+}
@@ -0,0 +1,174 @@
+/*
+File: question_admin_repository.go
+Author: lxp
+Description: 题库后台管理数据访问层
+*/
+package question
+
+import (
+	"zhixue-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// 更新题目时允许写入的列，统计字段和作者不在其中
+var editableColumns = []string{
+	"title", "content", "question_type", "difficulty", "grade_level", "estimated_time",
+	"correct_answer", "answer_analysis", "hints", "choices", "tags", "source", "review_status",
+}
+
+// AdminList 按筛选条件分页获取题目，包含未发布和已停用的题目
+func (r *questionRepository) AdminList(filter AdminFilter) ([]models.Question, int64, error) {
+	query := applyFilter(r.db.Model(&models.Question{}), filter.ListFilter)
+	if filter.ReviewStatus != "" {
+		query = query.Where("questions.review_status = ?", filter.ReviewStatus)
+	}
+	if filter.QuestionType != "" {
+		query = query.Where("questions.question_type = ?", filter.QuestionType)
+	}
+	if filter.IsActive != nil {
+		query = query.Where("questions.is_active = ?", *filter.IsActive)
+	}
+	if filter.AuthorID != nil {
+		query = query.Where("questions.author_id = ?", *filter.AuthorID)
+	}
+	if filter.Keyword != "" {
+		query = query.Where("questions.title ILIKE ?", "%"+filter.Keyword+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var questions []models.Question
+	err := query.Scopes(paginate(filter.Page, filter.PageSize)).
+		Order("questions.updated_at DESC, questions.id DESC").
+		Find(&questions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return questions, total, nil
+}
+
+// FindByID 通过ID获取题目，不限定审核状态和启用状态
+func (r *questionRepository) FindByID(id int64) (*models.Question, error) {
+	var question models.Question
+	err := r.db.First(&question, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &question, nil
+}
+
+// FindByIDs 批量获取题目，不限定审核状态和启用状态
+func (r *questionRepository) FindByIDs(ids []int64) ([]models.Question, error) {
+	var questions []models.Question
+	if len(ids) == 0 {
+		return questions, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&questions).Error
+	if err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+// Create 在一个事务中创建题目并关联知识点
+func (r *questionRepository) Create(question *models.Question, knowledgePointIDs []int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(question).Error; err != nil {
+			return err
+		}
+		return replaceKnowledgePoints(tx, question.ID, knowledgePointIDs)
+	})
+}
+
+// Update 在一个事务中更新题目内容、替换关联知识点并写入审核记录
+// 更新以题目当前审核状态为条件，返回 false 表示状态已不在 fromStatuses 中，未做任何更新
+func (r *questionRepository) Update(question *models.Question, knowledgePointIDs []int64, fromStatuses []string, review *models.QuestionReview) (bool, error) {
+	updated := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(question).
+			Where("review_status IN ?", fromStatuses).
+			Select(editableColumns).
+			Updates(question)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		updated = true
+
+		if err := replaceKnowledgePoints(tx, question.ID, knowledgePointIDs); err != nil {
+			return err
+		}
+		if review != nil {
+			return tx.Create(review).Error
+		}
+		return nil
+	})
+	return updated, err
+}
+
+// TransitionReview 在一个事务中变更题目审核状态并写入审核记录
+// 返回 false 表示题目当前状态不在 fromStatuses 中，未做任何更新
+func (r *questionRepository) TransitionReview(id int64, fromStatuses []string, review *models.QuestionReview) (bool, error) {
+	updated := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Question{}).
+			Where("id = ? AND review_status IN ?", id, fromStatuses).
+			Update("review_status", review.ToStatus)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		updated = true
+		return tx.Create(review).Error
+	})
+	return updated, err
+}
+
+// SetActive 启用或停用题目 (软删除)
+func (r *questionRepository) SetActive(id int64, active bool) error {
+	return r.db.Model(&models.Question{}).Where("id = ?", id).Update("is_active", active).Error
+}
+
+// ListReviews 获取题目的审核记录，按时间正序
+func (r *questionRepository) ListReviews(questionID int64) ([]models.QuestionReview, error) {
+	var reviews []models.QuestionReview
+	err := r.db.Where("question_id = ?", questionID).Order("created_at ASC, id ASC").Find(&reviews).Error
+	if err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// CountKnowledgePoints 统计给定ID中存在且启用的知识点数量
+func (r *questionRepository) CountKnowledgePoints(ids []int64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	var count int64
+	err := r.db.Model(&models.KnowledgePoint{}).Where("id IN ? AND is_active = ?", ids, true).Count(&count).Error
+	return count, err
+}
+
+// replaceKnowledgePoints 用给定的知识点替换题目原有的关联
+func replaceKnowledgePoints(tx *gorm.DB, questionID int64, knowledgePointIDs []int64) error {
+	if err := tx.Where("question_id = ?", questionID).Delete(&models.QuestionKnowledgePoint{}).Error; err != nil {
+		return err
+	}
+	if len(knowledgePointIDs) == 0 {
+		return nil
+	}
+
+	links := make([]models.QuestionKnowledgePoint, 0, len(knowledgePointIDs))
+	for _, id := range knowledgePointIDs {
+		links = append(links, models.QuestionKnowledgePoint{QuestionID: questionID, KnowledgePointID: id})
+	}
+	return tx.Create(&links).Error
+}
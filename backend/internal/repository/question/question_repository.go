@@ -0,0 +1,236 @@
+/*
+File: question_repository.go
+Author: lxp
+Description: 题库数据访问层
+*/
+package question
+
+import (
+	"zhixue-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// ListFilter 定义题目列表的筛选条件
+type ListFilter struct {
+	Page             int
+	PageSize         int
+	KnowledgePointID *int64
+	GradeLevel       *int
+	MinDifficulty    *float64 // 闭区间下界
+	MaxDifficulty    *float64 // 开区间上界
+}
+
+// RecommendFilter 定义推荐题目的筛选条件
+type RecommendFilter struct {
+	ListFilter
+	UserID           int64
+	TargetDifficulty float64
+	Tolerance        float64
+	// PrerequisiteMinCorrect 大于0时跳过先修知识点尚未掌握的题目：
+	// 用户在先修知识点下答对的不同题目数达到该值 (知识点题目更少时为其题目数) 才算掌握
+	PrerequisiteMinCorrect int
+}
+
+// AdminFilter 定义后台题目列表的筛选条件，不限定审核状态和启用状态
+type AdminFilter struct {
+	ListFilter
+	ReviewStatus string
+	QuestionType string
+	IsActive     *bool
+	AuthorID     *int64
+	Keyword      string
+}
+
+// Repository 定义题库数据仓库的接口
+type Repository interface {
+	List(filter ListFilter) ([]models.Question, int64, error)
+	Recommend(filter RecommendFilter) ([]models.Question, int64, error)
+	FindPublishedByID(id int64) (*models.Question, error)
+	FindPublishedByIDs(ids []int64) ([]models.Question, error)
+	FindKnowledgePointsByQuestionIDs(questionIDs []int64) (map[int64][]models.KnowledgePoint, error)
+	ListKnowledgePoints(gradeLevel *int) ([]models.KnowledgePoint, error)
+	HasAnswered(userID, questionID int64) (bool, error)
+
+	// 后台管理
+	AdminList(filter AdminFilter) ([]models.Question, int64, error)
+	FindByID(id int64) (*models.Question, error)
+	FindByIDs(ids []int64) ([]models.Question, error)
+	Create(question *models.Question, knowledgePointIDs []int64) error
+	Update(question *models.Question, knowledgePointIDs []int64, fromStatuses []string, review *models.QuestionReview) (bool, error)
+	TransitionReview(id int64, fromStatuses []string, review *models.QuestionReview) (bool, error)
+	SetActive(id int64, active bool) error
+	ListReviews(questionID int64) ([]models.QuestionReview, error)
+	CountKnowledgePoints(ids []int64) (int64, error)
+}
+
+// questionRepository 实现了Repository接口
+type questionRepository struct {
+	db *gorm.DB
+}
+
+// NewQuestionRepository 创建一个新的题库数据仓库实例
+func NewQuestionRepository(db *gorm.DB) Repository {
+	return &questionRepository{db: db}
+}
+
+// published 限定查询范围为已审核通过且处于启用状态的题目
+// 所有面向学生的查询都必须经过该范围
+func published(db *gorm.DB) *gorm.DB {
+	return db.Where("questions.review_status = ? AND questions.is_active = ?", "approved", true)
+}
+
+// applyFilter 将通用筛选条件应用到查询上
+func applyFilter(db *gorm.DB, filter ListFilter) *gorm.DB {
+	if filter.KnowledgePointID != nil {
+		db = db.Where("EXISTS (SELECT 1 FROM question_knowledge_points qkp WHERE qkp.question_id = questions.id AND qkp.knowledge_point_id = ?)", *filter.KnowledgePointID)
+	}
+	if filter.GradeLevel != nil {
+		db = db.Where("questions.grade_level = ?", *filter.GradeLevel)
+	}
+	if filter.MinDifficulty != nil {
+		db = db.Where("questions.difficulty >= ?", *filter.MinDifficulty)
+	}
+	if filter.MaxDifficulty != nil {
+		db = db.Where("questions.difficulty < ?", *filter.MaxDifficulty)
+	}
+	return db
+}
+
+// paginate 返回分页作用域
+func paginate(page, pageSize int) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Offset((page - 1) * pageSize).Limit(pageSize)
+	}
+}
+
+// List 按筛选条件分页获取已发布的题目
+func (r *questionRepository) List(filter ListFilter) ([]models.Question, int64, error) {
+	query := applyFilter(r.db.Model(&models.Question{}).Scopes(published), filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var questions []models.Question
+	err := query.Scopes(paginate(filter.Page, filter.PageSize)).
+		Order("questions.difficulty ASC, questions.id ASC").
+		Find(&questions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return questions, total, nil
+}
+
+// Recommend 获取与用户当前难度相近、且用户尚未答对过的已发布题目
+// 结果按与目标难度的距离升序排列，使用次数少的题目优先
+func (r *questionRepository) Recommend(filter RecommendFilter) ([]models.Question, int64, error) {
+	query := applyFilter(r.db.Model(&models.Question{}).Scopes(published), filter.ListFilter).
+		Where("questions.difficulty BETWEEN ? AND ?", filter.TargetDifficulty-filter.Tolerance, filter.TargetDifficulty+filter.Tolerance).
+		Where("NOT EXISTS (SELECT 1 FROM answer_records ar WHERE ar.user_id = ? AND ar.question_id = questions.id AND ar.is_correct)", filter.UserID)
+	if filter.PrerequisiteMinCorrect > 0 {
+		query = query.Where(`NOT EXISTS (
+			SELECT 1 FROM question_knowledge_points qkp
+			JOIN knowledge_point_prerequisites pre ON pre.knowledge_point_id = qkp.knowledge_point_id
+			JOIN knowledge_points pk ON pk.id = pre.prerequisite_id AND pk.is_active
+			WHERE qkp.question_id = questions.id
+			AND (SELECT COUNT(DISTINCT ar.question_id) FROM answer_records ar
+				JOIN question_knowledge_points aq ON aq.question_id = ar.question_id
+				WHERE ar.user_id = ? AND ar.is_correct AND aq.knowledge_point_id = pre.prerequisite_id)
+			< LEAST(?, (SELECT COUNT(*) FROM question_knowledge_points pq WHERE pq.knowledge_point_id = pre.prerequisite_id))
+		)`, filter.UserID, filter.PrerequisiteMinCorrect)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var questions []models.Question
+	err := query.Scopes(paginate(filter.Page, filter.PageSize)).
+		Order(gorm.Expr("ABS(questions.difficulty - ?) ASC, questions.usage_count ASC, questions.id ASC", filter.TargetDifficulty)).
+		Find(&questions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return questions, total, nil
+}
+
+// FindPublishedByID 通过ID获取已发布的题目
+func (r *questionRepository) FindPublishedByID(id int64) (*models.Question, error) {
+	var question models.Question
+	err := r.db.Scopes(published).First(&question, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &question, nil
+}
+
+// FindPublishedByIDs 批量获取已发布的题目，不存在或未发布的题目不会出现在结果中
+func (r *questionRepository) FindPublishedByIDs(ids []int64) ([]models.Question, error) {
+	var questions []models.Question
+	if len(ids) == 0 {
+		return questions, nil
+	}
+	err := r.db.Scopes(published).Where("id IN ?", ids).Find(&questions).Error
+	if err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+// FindKnowledgePointsByQuestionIDs 批量获取题目关联的知识点，返回以题目ID为键的映射
+func (r *questionRepository) FindKnowledgePointsByQuestionIDs(questionIDs []int64) (map[int64][]models.KnowledgePoint, error) {
+	result := make(map[int64][]models.KnowledgePoint)
+	if len(questionIDs) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		QuestionID int64
+		models.KnowledgePoint
+	}
+	err := r.db.Table("knowledge_points").
+		Select("qkp.question_id, knowledge_points.*").
+		Joins("JOIN question_knowledge_points qkp ON qkp.knowledge_point_id = knowledge_points.id").
+		Where("qkp.question_id IN ?", questionIDs).
+		Order("knowledge_points.sort_order ASC, knowledge_points.id ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.QuestionID] = append(result[row.QuestionID], row.KnowledgePoint)
+	}
+	return result, nil
+}
+
+// ListKnowledgePoints 获取启用状态的知识点，可按年级筛选
+func (r *questionRepository) ListKnowledgePoints(gradeLevel *int) ([]models.KnowledgePoint, error) {
+	query := r.db.Where("is_active = ?", true)
+	if gradeLevel != nil {
+		query = query.Where("grade_level = ?", *gradeLevel)
+	}
+
+	var points []models.KnowledgePoint
+	err := query.Order("grade_level ASC, sort_order ASC, id ASC").Find(&points).Error
+	if err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// HasAnswered 判断用户是否已作答过某道题目
+func (r *questionRepository) HasAnswered(userID, questionID int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.AnswerRecord{}).
+		Where("user_id = ? AND question_id = ?", userID, questionID).
+		Limit(1).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
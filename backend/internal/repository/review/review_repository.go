@@ -0,0 +1,116 @@
+/*
+File: review_repository.go
+Author: lxp
+Description: 错题间隔复习数据访问层
+*/
+package review
+
+import (
+	"errors"
+	"time"
+	"zhixue-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DueItem 是附带题目内容的待复习项
+type DueItem struct {
+	models.ReviewItem
+	Title        string
+	Content      string
+	QuestionType string
+	Difficulty   float64
+	Hints        models.JSONB
+	Choices      models.JSONB
+}
+
+// Repository 定义错题间隔复习数据仓库的接口
+type Repository interface {
+	Schedule(userID, questionID int64, initial *models.ReviewItem, apply func(item *models.ReviewItem) bool) (bool, error)
+	ListDue(userID int64, before time.Time, page, pageSize int) ([]DueItem, int64, error)
+	CountDue(userID int64, before time.Time) (int64, error)
+}
+
+// reviewRepository 实现了Repository接口
+type reviewRepository struct {
+	db *gorm.DB
+}
+
+// NewReviewRepository 创建一个新的错题间隔复习数据仓库实例
+func NewReviewRepository(db *gorm.DB) Repository {
+	return &reviewRepository{db: db}
+}
+
+// Schedule 在一个事务中锁定用户对该题的复习状态，由 apply 修改后写回，apply 返回 false 表示无需修改
+// 记录不存在时：initial 不为空则以 initial 创建后再交给 apply，否则直接返回 false
+func (r *reviewRepository) Schedule(userID, questionID int64, initial *models.ReviewItem, apply func(item *models.ReviewItem) bool) (bool, error) {
+	changed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if initial != nil {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(initial).Error; err != nil {
+				return err
+			}
+		}
+
+		var item models.ReviewItem
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND question_id = ?", userID, questionID).
+			Take(&item).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if !apply(&item) {
+			return nil
+		}
+		changed = true
+		return tx.Model(&item).Updates(map[string]interface{}{
+			"repetitions":      item.Repetitions,
+			"interval_days":    item.IntervalDays,
+			"ease_factor":      item.EaseFactor,
+			"due_at":           item.DueAt,
+			"review_count":     item.ReviewCount,
+			"lapse_count":      item.LapseCount,
+			"last_reviewed_at": item.LastReviewedAt,
+		}).Error
+	})
+	return changed, err
+}
+
+// dueQuery 返回用户在 before 之前到期、且题目仍处于发布状态的复习项查询
+func (r *reviewRepository) dueQuery(userID int64, before time.Time) *gorm.DB {
+	return r.db.Table("review_items ri").
+		Joins("JOIN questions q ON q.id = ri.question_id AND q.is_active AND q.review_status = 'approved'").
+		Where("ri.user_id = ? AND ri.due_at <= ?", userID, before)
+}
+
+// ListDue 分页获取用户已到期的复习项，到期早的在前
+func (r *reviewRepository) ListDue(userID int64, before time.Time, page, pageSize int) ([]DueItem, int64, error) {
+	var total int64
+	if err := r.dueQuery(userID, before).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []DueItem
+	err := r.dueQuery(userID, before).
+		Select("ri.*, q.title, q.content, q.question_type, q.difficulty, q.hints, q.choices").
+		Order("ri.due_at ASC, ri.question_id ASC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Scan(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// CountDue 统计用户在 before 之前到期的复习项数量
+func (r *reviewRepository) CountDue(userID int64, before time.Time) (int64, error) {
+	var total int64
+	err := r.dueQuery(userID, before).Count(&total).Error
+	return total, err
+}
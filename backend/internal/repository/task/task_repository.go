@@ -0,0 +1,198 @@
+/*
+File: task_repository.go
+Author: lxp
+Description: 游戏化任务、任务进度与奖励领取数据访问层
+*/
+package task
+
+import (
+	"time"
+	"zhixue-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 任务类型，与数据库 task_type 枚举保持一致
+const (
+	TypeDaily       = "daily"
+	TypeWeekly      = "weekly"
+	TypeAchievement = "achievement"
+)
+
+// 任务进度状态，与数据库 task_status 枚举保持一致
+const (
+	StatusPending   = "pending"
+	StatusCompleted = "completed" // 已达成目标，待领取奖励
+	StatusClaimed   = "claimed"
+)
+
+// Repository 定义任务数据仓库的接口
+type Repository interface {
+	ListActive(taskType string) ([]models.Task, error)
+	FindActiveByID(id int64) (*models.Task, error)
+	FindRewards(ids []int64) (map[int64]models.Reward, error)
+	ListUserTasks(userID int64, periods []time.Time) ([]models.UserTask, error)
+	FindUserTask(userID, taskID int64, periodStart time.Time) (*models.UserTask, error)
+	Advance(userID, taskID int64, periodStart time.Time, apply func(ut *models.UserTask) bool) (bool, error)
+	Claim(userTaskID int64, rewardID *int64, now time.Time) (bool, error)
+	QuestionInKnowledgePoint(questionID int64, code string) (bool, error)
+}
+
+// taskRepository 实现了Repository接口
+type taskRepository struct {
+	db *gorm.DB
+}
+
+// NewTaskRepository 创建一个新的任务数据仓库实例
+func NewTaskRepository(db *gorm.DB) Repository {
+	return &taskRepository{db: db}
+}
+
+// ListActive 获取启用的任务，taskType 为空时不限类型
+func (r *taskRepository) ListActive(taskType string) ([]models.Task, error) {
+	query := r.db.Where("is_active")
+	if taskType != "" {
+		query = query.Where("type = ?", taskType)
+	}
+
+	var tasks []models.Task
+	if err := query.Order("id ASC").Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// FindActiveByID 通过ID获取启用的任务
+func (r *taskRepository) FindActiveByID(id int64) (*models.Task, error) {
+	var task models.Task
+	if err := r.db.Where("id = ? AND is_active", id).First(&task).Error; err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// FindRewards 批量获取奖励，以奖励ID为键
+func (r *taskRepository) FindRewards(ids []int64) (map[int64]models.Reward, error) {
+	result := make(map[int64]models.Reward, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	var rewards []models.Reward
+	if err := r.db.Where("id IN ?", ids).Find(&rewards).Error; err != nil {
+		return nil, err
+	}
+	for _, reward := range rewards {
+		result[reward.ID] = reward
+	}
+	return result, nil
+}
+
+// ListUserTasks 获取用户在指定周期内的任务进度
+func (r *taskRepository) ListUserTasks(userID int64, periods []time.Time) ([]models.UserTask, error) {
+	var userTasks []models.UserTask
+	err := r.db.Where("user_id = ? AND period_start IN ?", userID, periods).
+		Find(&userTasks).Error
+	if err != nil {
+		return nil, err
+	}
+	return userTasks, nil
+}
+
+// FindUserTask 获取用户在某个周期内的任务进度
+func (r *taskRepository) FindUserTask(userID, taskID int64, periodStart time.Time) (*models.UserTask, error) {
+	var userTask models.UserTask
+	err := r.db.Where("user_id = ? AND task_id = ? AND period_start = ?", userID, taskID, periodStart).
+		Take(&userTask).Error
+	if err != nil {
+		return nil, err
+	}
+	return &userTask, nil
+}
+
+// Advance 在一个事务中锁定 (必要时创建) 用户本周期的任务进度，由 apply 修改后写回
+// apply 返回 false 表示无需修改
+func (r *taskRepository) Advance(userID, taskID int64, periodStart time.Time, apply func(ut *models.UserTask) bool) (bool, error) {
+	changed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		initial := models.UserTask{UserID: userID, TaskID: taskID, PeriodStart: periodStart, Status: StatusPending}
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "task_id"}, {Name: "period_start"}},
+			DoNothing: true,
+		}).Create(&initial).Error
+		if err != nil {
+			return err
+		}
+
+		var userTask models.UserTask
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND task_id = ? AND period_start = ?", userID, taskID, periodStart).
+			Take(&userTask).Error
+		if err != nil {
+			return err
+		}
+
+		if !apply(&userTask) {
+			return nil
+		}
+		changed = true
+		return tx.Model(&userTask).Updates(map[string]interface{}{
+			"status":       userTask.Status,
+			"progress":     userTask.Progress,
+			"completed_at": userTask.CompletedAt,
+		}).Error
+	})
+	return changed, err
+}
+
+// Claim 在一个事务中将已完成的任务标记为已领取，并发放奖励 (rewardID 为空时不发放)
+// 返回 false 表示任务已不是待领取状态 (如被并发领取)，此时不会重复发放奖励
+func (r *taskRepository) Claim(userTaskID int64, rewardID *int64, now time.Time) (bool, error) {
+	claimed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var userTask models.UserTask
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", userTaskID).
+			Take(&userTask).Error
+		if err != nil {
+			return err
+		}
+		if userTask.Status != StatusCompleted {
+			return nil
+		}
+
+		err = tx.Model(&userTask).Updates(map[string]interface{}{
+			"status":     StatusClaimed,
+			"claimed_at": now,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if rewardID != nil {
+			err := tx.Create(&models.UserReward{UserID: userTask.UserID, RewardID: *rewardID, ObtainedAt: now}).Error
+			if err != nil {
+				return err
+			}
+		}
+		claimed = true
+		return nil
+	})
+	return claimed, err
+}
+
+// QuestionInKnowledgePoint 判断题目是否关联了指定编码的知识点或其子知识点 (只计启用的知识点)
+func (r *taskRepository) QuestionInKnowledgePoint(questionID int64, code string) (bool, error) {
+	var found bool
+	err := r.db.Raw(`WITH RECURSIVE subtree(id) AS (
+			SELECT id FROM knowledge_points WHERE code = ? AND is_active
+			UNION
+			SELECT kp.id FROM knowledge_points kp JOIN subtree s ON kp.parent_id = s.id WHERE kp.is_active
+		)
+		SELECT EXISTS (
+			SELECT 1 FROM question_knowledge_points qkp JOIN subtree s ON s.id = qkp.knowledge_point_id
+			WHERE qkp.question_id = ?
+		)`, code, questionID).
+		Scan(&found).Error
+	return found, err
+}
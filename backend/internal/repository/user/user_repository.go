@@ -26,6 +26,8 @@ type Repository interface {
 	GetByEmail(email string) (*models.User, error)
 	FindByID(id int64) (*models.User, error)
 	Update(user *models.User) error
+	FindProfileByUserID(userID int64) (*models.UserProfile, error)
+	CreateAuthAuditLog(log *models.AuthAuditLog) error
 }
 
 // userRepository 实现了Repository接口
@@ -112,3 +114,18 @@ func (r *userRepository) FindByID(id int64) (*models.User, error) {
 func (r *userRepository) Update(user *models.User) error {
 	return r.db.Save(user).Error
 }
+
+// FindProfileByUserID 通过用户ID获取用户学习档案
+func (r *userRepository) FindProfileByUserID(userID int64) (*models.UserProfile, error) {
+	var profile models.UserProfile
+	err := r.db.Where("user_id = ?", userID).First(&profile).Error
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// CreateAuthAuditLog 写入一条认证审计日志
+func (r *userRepository) CreateAuthAuditLog(log *models.AuthAuditLog) error {
+	return r.db.Create(log).Error
+}
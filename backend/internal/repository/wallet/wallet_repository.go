@@ -0,0 +1,134 @@
+/*
+File: wallet_repository.go
+Author: lxp
+Description: 积分账本数据访问层：追加流水、推导余额
+*/
+package wallet
+
+import (
+	"errors"
+	"zhixue-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 积分流水原因，与数据库 points_reason 枚举保持一致
+const (
+	ReasonTaskClaim   = "task_claim"
+	ReasonStreakBonus = "streak_bonus"
+	ReasonAdminGrant  = "admin_grant"
+	ReasonPurchase    = "purchase"
+)
+
+var (
+	ErrInsufficientBalance = errors.New("insufficient points balance")
+)
+
+// Repository 定义积分账本数据仓库的接口
+type Repository interface {
+	Append(tx *models.PointsTransaction) (bool, error)
+	Balance(userID int64) (balance int, version int64, err error)
+	List(userID int64, page, pageSize int) ([]models.PointsTransaction, int64, error)
+}
+
+// walletRepository 实现了Repository接口
+type walletRepository struct {
+	db *gorm.DB
+}
+
+// NewWalletRepository 创建一个新的积分账本数据仓库实例
+func NewWalletRepository(db *gorm.DB) Repository {
+	return &walletRepository{db: db}
+}
+
+// Append 追加一条积分流水，并写入追加后的余额
+// 设置了 Reference 且同一用户同一原因下已有该来源的流水时不重复追加，返回 false 并将已有流水写回 t
+func (r *walletRepository) Append(t *models.PointsTransaction) (bool, error) {
+	appended := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		appended, err = AppendTx(tx, t)
+		return err
+	})
+	return appended, err
+}
+
+// AppendTx 在调用方的事务中追加一条积分流水，供需要与其他数据同时提交的业务 (如购买道具) 使用
+// 锁定用户行，同一用户的入账与扣减串行执行，扣减后余额为负时返回 ErrInsufficientBalance，调用方应回滚事务
+func AppendTx(tx *gorm.DB, t *models.PointsTransaction) (bool, error) {
+	// NO KEY UPDATE 不阻塞其他表对 users 的外键引用
+	err := tx.Clauses(clause.Locking{Strength: "NO KEY UPDATE"}).
+		Select("id").
+		Where("id = ?", t.UserID).
+		Take(&models.User{}).Error
+	if err != nil {
+		return false, err
+	}
+
+	if t.Reference != nil {
+		var existing models.PointsTransaction
+		err := tx.Where("user_id = ? AND reason = ? AND reference = ?", t.UserID, t.Reason, *t.Reference).
+			Take(&existing).Error
+		if err == nil {
+			*t = existing
+			return false, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, err
+		}
+	}
+
+	balance, _, err := latestBalance(tx, t.UserID)
+	if err != nil {
+		return false, err
+	}
+	if balance+t.Amount < 0 {
+		return false, ErrInsufficientBalance
+	}
+	t.BalanceAfter = balance + t.Amount
+
+	if err := tx.Omit("ID").Create(t).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Balance 获取用户当前的积分余额 (最近一条流水的 balance_after)，没有流水时为0
+// version 为最近一条流水的ID，同一用户的流水串行追加，ID越大余额越新
+func (r *walletRepository) Balance(userID int64) (int, int64, error) {
+	return latestBalance(r.db, userID)
+}
+
+// List 分页获取用户的积分流水，最新的在前
+func (r *walletRepository) List(userID int64, page, pageSize int) ([]models.PointsTransaction, int64, error) {
+	query := r.db.Model(&models.PointsTransaction{}).Where("user_id = ?", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var transactions []models.PointsTransaction
+	err := query.Order("id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&transactions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return transactions, total, nil
+}
+
+func latestBalance(db *gorm.DB, userID int64) (int, int64, error) {
+	var latest []models.PointsTransaction
+	err := db.Select("id", "balance_after").
+		Where("user_id = ?", userID).
+		Order("id DESC").
+		Limit(1).
+		Find(&latest).Error
+	if err != nil || len(latest) == 0 {
+		return 0, 0, err
+	}
+	return latest[0].BalanceAfter, latest[0].ID, nil
+}
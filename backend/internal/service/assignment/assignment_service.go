@@ -0,0 +1,608 @@
+/*
+File: assignment_service.go
+Author: lxp
+Description: 班级作业业务逻辑：教师布置作业与查看批改概览、学生查看作业
+*/
+package assignment
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/rbac"
+	"zhixue-backend/internal/repository/assignment"
+	"zhixue-backend/internal/repository/class"
+	"zhixue-backend/internal/repository/question"
+	learning_service "zhixue-backend/internal/service/learning"
+	question_service "zhixue-backend/internal/service/question"
+	"zhixue-backend/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAssignmentForbidden     = errors.New("no permission to manage this assignment")
+	ErrClassArchived           = errors.New("class is archived")
+	ErrInvalidSchedule         = errors.New("due time must be after open time")
+	ErrInvalidQuestions        = errors.New("questions must be unique and published")
+	ErrAssignmentStarted       = errors.New("questions cannot be changed after students have started")
+	ErrAssignmentClosed        = errors.New("assignment is past due")
+	ErrNoAttemptsLeft          = errors.New("no attempts left")
+	ErrNoOpenAttempt           = errors.New("no attempt in progress")
+	ErrAttemptSubmitted        = errors.New("attempt has already been submitted")
+	ErrQuestionNotInAssignment = errors.New("question is not part of the assignment")
+	ErrQuestionAnswered        = errors.New("question already answered in this attempt")
+	ErrAttemptConflict         = assignment.ErrAttemptConflict
+)
+
+// 学生在作业上的完成状态
+const (
+	StatusNotStarted = "not_started"
+	StatusInProgress = "in_progress"
+	StatusSubmitted  = "submitted"
+)
+
+// 未填写时使用的默认值
+const (
+	defaultMaxAttempts = 1
+	defaultPoints      = 1
+)
+
+// canManage 判断用户能否管理班级的作业：教师只能管理自己班级的作业，管理员可管理全部
+func canManage(op rbac.Subject, c *models.Class) bool {
+	return op.CanAccess(&c.TeacherID, rbac.PermClassManage, rbac.PermClassManageAll)
+}
+
+// Service 定义作业服务的接口
+type Service interface {
+	// 教师端
+	ListAssignments(op rbac.Subject, query *dto.ListAssignmentsQuery) (*dto.PageResponse, error)
+	GetAssignment(op rbac.Subject, assignmentID int64) (*dto.AssignmentResponse, error)
+	CreateAssignment(op rbac.Subject, req *dto.CreateAssignmentRequest) (*dto.AssignmentResponse, error)
+	UpdateAssignment(op rbac.Subject, assignmentID int64, req *dto.UpdateAssignmentRequest) (*dto.AssignmentResponse, error)
+	DeleteAssignment(op rbac.Subject, assignmentID int64) error
+	GetOverview(op rbac.Subject, assignmentID int64) (*dto.AssignmentOverviewResponse, error)
+
+	// 学生端
+	ListMyAssignments(userID int64, query *dto.PageQuery) (*dto.PageResponse, error)
+	GetMyAssignment(userID, assignmentID int64) (*dto.StudentAssignmentResponse, error)
+	StartAttempt(userID, assignmentID int64) (*dto.AssignmentAttemptResponse, error)
+	SubmitAttempt(userID, assignmentID int64) (*dto.AssignmentAttemptResponse, error)
+	CheckAnswer(userID int64, sessionID string, questionID int64) (question_service.Feedback, error)
+}
+
+// assignmentService 实现了Service接口
+type assignmentService struct {
+	repo      assignment.Repository
+	classes   class.Repository
+	questions question.Repository
+	learning  learning_service.Service
+}
+
+// NewAssignmentService 创建一个新的作业服务实例
+func NewAssignmentService(repo assignment.Repository, classes class.Repository, questions question.Repository,
+	learning learning_service.Service) Service {
+	return &assignmentService{repo: repo, classes: classes, questions: questions, learning: learning}
+}
+
+// ListAssignments 分页获取作业列表
+// 没有 class:manage_all 权限时只能看到自己班级的作业
+func (s *assignmentService) ListAssignments(op rbac.Subject, query *dto.ListAssignmentsQuery) (*dto.PageResponse, error) {
+	query.Normalize()
+
+	filter := assignment.Filter{
+		Page:     query.Page,
+		PageSize: query.PageSize,
+		ClassID:  query.ClassID,
+	}
+	if !op.Can(rbac.PermClassManageAll) {
+		filter.TeacherID = &op.UserID
+	}
+
+	summaries, total, err := s.repo.List(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list assignments: %w", err)
+	}
+
+	items := make([]dto.AssignmentResponse, 0, len(summaries))
+	for i := range summaries {
+		resp := toAssignmentResponse(&summaries[i].Assignment, summaries[i].ClassName)
+		resp.QuestionCount = summaries[i].QuestionCount
+		resp.TotalPoints = summaries[i].TotalPoints
+		items = append(items, *resp)
+	}
+	return dto.NewPageResponse(items, filter.Page, filter.PageSize, total), nil
+}
+
+// GetAssignment 获取作业详情，包含题目与答案
+func (s *assignmentService) GetAssignment(op rbac.Subject, assignmentID int64) (*dto.AssignmentResponse, error) {
+	a, c, err := s.findManaged(op, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.detail(a, c)
+}
+
+// CreateAssignment 为班级布置作业，题目必须是已发布的题目
+func (s *assignmentService) CreateAssignment(op rbac.Subject, req *dto.CreateAssignmentRequest) (*dto.AssignmentResponse, error) {
+	c, err := s.classes.FindByID(req.ClassID)
+	if err != nil {
+		return nil, err // 错误可能是 gorm.ErrRecordNotFound
+	}
+	if !canManage(op, c) {
+		return nil, ErrAssignmentForbidden
+	}
+	if c.IsArchived {
+		return nil, ErrClassArchived
+	}
+
+	openAt := time.Now()
+	if req.OpenAt != nil {
+		openAt = *req.OpenAt
+	}
+	if !req.DueAt.After(openAt) {
+		return nil, ErrInvalidSchedule
+	}
+
+	questions, err := s.questionItems(req.Questions)
+	if err != nil {
+		return nil, err
+	}
+
+	maxAttempts := req.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	a := &models.Assignment{
+		ClassID:     c.ID,
+		CreatedBy:   &op.UserID,
+		Title:       strings.TrimSpace(req.Title),
+		Description: req.Description,
+		OpenAt:      openAt,
+		DueAt:       req.DueAt,
+		MaxAttempts: maxAttempts,
+		ShowAnswers: req.ShowAnswers,
+		AllowLate:   req.AllowLate,
+	}
+	if err := s.repo.Create(a, questions); err != nil {
+		return nil, fmt.Errorf("failed to create assignment: %w", err)
+	}
+	return s.detail(a, c)
+}
+
+// UpdateAssignment 更新作业信息，已有学生开始作答后不能再修改题目
+func (s *assignmentService) UpdateAssignment(op rbac.Subject, assignmentID int64, req *dto.UpdateAssignmentRequest) (*dto.AssignmentResponse, error) {
+	a, c, err := s.findManaged(op, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Title != nil {
+		a.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.Description != nil {
+		a.Description = *req.Description
+	}
+	if req.OpenAt != nil {
+		a.OpenAt = *req.OpenAt
+	}
+	if req.DueAt != nil {
+		a.DueAt = *req.DueAt
+	}
+	if req.MaxAttempts != nil {
+		a.MaxAttempts = *req.MaxAttempts
+	}
+	if req.ShowAnswers != nil {
+		a.ShowAnswers = *req.ShowAnswers
+	}
+	if req.AllowLate != nil {
+		a.AllowLate = *req.AllowLate
+	}
+	if !a.DueAt.After(a.OpenAt) {
+		return nil, ErrInvalidSchedule
+	}
+
+	var questions []models.AssignmentQuestion
+	if req.Questions != nil {
+		started, err := s.repo.CountAttempts(a.ID)
+		if err != nil {
+			return nil, err
+		}
+		if started > 0 {
+			return nil, ErrAssignmentStarted
+		}
+		if questions, err = s.questionItems(req.Questions); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.Update(a, questions); err != nil {
+		return nil, fmt.Errorf("failed to update assignment: %w", err)
+	}
+	return s.detail(a, c)
+}
+
+// DeleteAssignment 删除作业及学生的作答，答题记录保留在学习记录中
+func (s *assignmentService) DeleteAssignment(op rbac.Subject, assignmentID int64) error {
+	a, _, err := s.findManaged(op, assignmentID)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(a.ID)
+}
+
+// GetOverview 获取作业的批改概览：每个学生的完成情况与每道题的正确率
+// 不允许迟交的作业过了截止时间后，未提交的作答会按截止前的答题自动提交
+func (s *assignmentService) GetOverview(op rbac.Subject, assignmentID int64) (*dto.AssignmentOverviewResponse, error) {
+	a, c, err := s.findManaged(op, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.closeOverdue(assignment.AttemptFilter{AssignmentID: a.ID}); err != nil {
+		return nil, err
+	}
+
+	progress, err := s.repo.StudentProgress(a.ID, c.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load assignment progress: %w", err)
+	}
+	stats, err := s.repo.QuestionStats(a.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load assignment question stats: %w", err)
+	}
+
+	resp := &dto.AssignmentOverviewResponse{
+		AssignmentID: a.ID,
+		MemberCount:  len(progress),
+		Students:     make([]dto.AssignmentStudentResponse, 0, len(progress)),
+		Questions:    make([]dto.AssignmentQuestionStatResponse, 0, len(stats)),
+	}
+
+	var scoreSum float64
+	for _, p := range progress {
+		student := dto.AssignmentStudentResponse{
+			UserID:           p.UserID,
+			Username:         p.Username,
+			Nickname:         p.Nickname,
+			Status:           progressStatus(p.SubmittedCount, p.InProgress),
+			Attempts:         p.Attempts,
+			BestScore:        p.BestScore,
+			FirstSubmittedAt: p.FirstSubmittedAt,
+			IsLate:           p.SubmittedCount > 0 && p.OnTimeCount == 0,
+		}
+		switch student.Status {
+		case StatusSubmitted:
+			resp.SubmittedCount++
+		case StatusInProgress:
+			resp.InProgressCount++
+		default:
+			resp.NotStartedCount++
+		}
+		if student.IsLate {
+			resp.LateCount++
+		}
+		if p.BestScore != nil {
+			scoreSum += *p.BestScore
+		}
+		resp.Students = append(resp.Students, student)
+	}
+	if resp.SubmittedCount > 0 {
+		resp.AvgScore = math.Round(scoreSum*100/float64(resp.SubmittedCount)) / 100
+	}
+
+	for _, stat := range stats {
+		resp.Questions = append(resp.Questions, dto.AssignmentQuestionStatResponse{
+			QuestionID:   stat.QuestionID,
+			Title:        stat.Title,
+			Points:       stat.Points,
+			AnswerCount:  stat.AnswerCount,
+			CorrectCount: stat.CorrectCount,
+			Accuracy:     percentage(stat.CorrectCount, stat.AnswerCount),
+		})
+	}
+	return resp, nil
+}
+
+// ListMyAssignments 分页获取学生所在班级中已开放的作业及本人的完成情况
+func (s *assignmentService) ListMyAssignments(userID int64, query *dto.PageQuery) (*dto.PageResponse, error) {
+	query.Normalize()
+	if err := s.closeOverdue(assignment.AttemptFilter{UserID: userID}); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	assignments, total, err := s.repo.ListForStudent(userID, now, query.Page, query.PageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list assignments: %w", err)
+	}
+
+	items := make([]dto.StudentAssignmentResponse, 0, len(assignments))
+	for _, a := range assignments {
+		items = append(items, dto.StudentAssignmentResponse{
+			ID:            a.ID,
+			ClassID:       a.ClassID,
+			ClassName:     a.ClassName,
+			Title:         a.Title,
+			Description:   a.Description,
+			OpenAt:        a.OpenAt,
+			DueAt:         a.DueAt,
+			MaxAttempts:   a.MaxAttempts,
+			ShowAnswers:   a.ShowAnswers,
+			AllowLate:     a.AllowLate,
+			QuestionCount: a.QuestionCount,
+			TotalPoints:   a.TotalPoints,
+			Status:        progressStatus(a.SubmittedCount, a.InProgress),
+			AttemptsUsed:  a.Attempts,
+			BestScore:     a.BestScore,
+			IsOverdue:     now.After(a.DueAt),
+		})
+	}
+	return dto.NewPageResponse(items, query.Page, query.PageSize, total), nil
+}
+
+// GetMyAssignment 获取作业详情、本人的作答记录与题目
+// 作业允许展示答案且本人已提交过时，附带答案解析与最近一次提交的作答结果
+func (s *assignmentService) GetMyAssignment(userID, assignmentID int64) (*dto.StudentAssignmentResponse, error) {
+	now := time.Now()
+	a, err := s.findForStudent(userID, assignmentID, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.closeOverdue(assignment.AttemptFilter{AssignmentID: a.ID, UserID: userID}); err != nil {
+		return nil, err
+	}
+
+	c, err := s.classes.FindByID(a.ClassID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.repo.ListQuestions(a.ID)
+	if err != nil {
+		return nil, err
+	}
+	attempts, err := s.repo.ListAttempts(a.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.StudentAssignmentResponse{
+		ID:           a.ID,
+		ClassID:      a.ClassID,
+		ClassName:    c.Name,
+		Title:        a.Title,
+		Description:  a.Description,
+		OpenAt:       a.OpenAt,
+		DueAt:        a.DueAt,
+		MaxAttempts:  a.MaxAttempts,
+		ShowAnswers:  a.ShowAnswers,
+		AllowLate:    a.AllowLate,
+		AttemptsUsed: len(attempts),
+		IsOverdue:    now.After(a.DueAt),
+		Attempts:     make([]dto.AssignmentAttemptResponse, 0, len(attempts)),
+	}
+
+	var open, lastSubmitted *models.AssignmentAttempt
+	for i := range attempts {
+		attempt := &attempts[i]
+		resp.Attempts = append(resp.Attempts, *toAttemptResponse(attempt))
+		if attempt.Status == assignment.AttemptInProgress {
+			open = attempt
+			continue
+		}
+		lastSubmitted = attempt
+		if resp.BestScore == nil || attempt.Score > *resp.BestScore {
+			score := attempt.Score
+			resp.BestScore = &score
+		}
+	}
+	submitted := 0
+	if lastSubmitted != nil {
+		submitted = 1
+	}
+	resp.Status = progressStatus(submitted, open != nil)
+
+	// 还能继续或重新作答时不展示答案，避免照抄到下一次作答中
+	reveal := a.ShowAnswers && lastSubmitted != nil &&
+		(isClosed(a, now) || (open == nil && len(attempts) >= a.MaxAttempts))
+	if resp.Questions, err = s.buildQuestions(items, reveal); err != nil {
+		return nil, err
+	}
+	resp.QuestionCount = len(items)
+	for _, item := range items {
+		resp.TotalPoints += item.Points
+	}
+
+	if open != nil {
+		answers, err := s.answerMap(open)
+		if err != nil {
+			return nil, err
+		}
+		for i := range resp.Questions {
+			_, answered := answers[resp.Questions[i].QuestionID]
+			resp.Questions[i].Answered = &answered
+		}
+	}
+	if reveal {
+		answers, err := s.answerMap(lastSubmitted)
+		if err != nil {
+			return nil, err
+		}
+		for i := range resp.Questions {
+			if ans, ok := answers[resp.Questions[i].QuestionID]; ok && !ans.CreatedAt.After(*lastSubmitted.SubmittedAt) {
+				resp.Questions[i].MyAnswer = &ans.UserAnswer
+				resp.Questions[i].IsCorrect = &ans.IsCorrect
+			}
+		}
+	}
+	return resp, nil
+}
+
+// findManaged 获取作业及其班级，并校验当前用户有权管理
+func (s *assignmentService) findManaged(op rbac.Subject, assignmentID int64) (*models.Assignment, *models.Class, error) {
+	a, err := s.repo.FindByID(assignmentID)
+	if err != nil {
+		return nil, nil, err // 错误可能是 gorm.ErrRecordNotFound
+	}
+	c, err := s.classes.FindByID(a.ClassID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !canManage(op, c) {
+		return nil, nil, ErrAssignmentForbidden
+	}
+	return a, c, nil
+}
+
+// findForStudent 获取学生可见的作业：学生必须在作业所属班级中，且作业已开放
+// 不满足时按作业不存在处理，避免泄露其他班级或尚未开放的作业
+func (s *assignmentService) findForStudent(userID, assignmentID int64, now time.Time) (*models.Assignment, error) {
+	a, err := s.repo.FindByID(assignmentID)
+	if err != nil {
+		return nil, err // 错误可能是 gorm.ErrRecordNotFound
+	}
+	if now.Before(a.OpenAt) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	member, err := s.classes.IsMember(a.ClassID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !member {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return a, nil
+}
+
+// detail 构造包含题目与答案的教师端作业响应
+func (s *assignmentService) detail(a *models.Assignment, c *models.Class) (*dto.AssignmentResponse, error) {
+	items, err := s.repo.ListQuestions(a.ID)
+	if err != nil {
+		return nil, err
+	}
+	resp := toAssignmentResponse(a, c.Name)
+	if resp.Questions, err = s.buildQuestions(items, true); err != nil {
+		return nil, err
+	}
+	resp.QuestionCount = len(items)
+	for _, item := range items {
+		resp.TotalPoints += item.Points
+	}
+	return resp, nil
+}
+
+// questionItems 校验作业题目并转换为数据模型，题目不能重复且必须已发布
+func (s *assignmentService) questionItems(items []dto.AssignmentQuestionItem) ([]models.AssignmentQuestion, error) {
+	ids := make([]int64, 0, len(items))
+	seen := make(map[int64]bool, len(items))
+	for _, item := range items {
+		if seen[item.QuestionID] {
+			return nil, ErrInvalidQuestions
+		}
+		seen[item.QuestionID] = true
+		ids = append(ids, item.QuestionID)
+	}
+
+	found, err := s.questions.FindPublishedByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) != len(ids) {
+		return nil, ErrInvalidQuestions
+	}
+
+	questions := make([]models.AssignmentQuestion, 0, len(items))
+	for i, item := range items {
+		points := item.Points
+		if points <= 0 {
+			points = defaultPoints
+		}
+		questions = append(questions, models.AssignmentQuestion{
+			QuestionID: item.QuestionID,
+			SortOrder:  i + 1,
+			Points:     points,
+		})
+	}
+	return questions, nil
+}
+
+// buildQuestions 按作业题目顺序构造题目响应，withAnswers 为 true 时附带答案与解析
+func (s *assignmentService) buildQuestions(items []models.AssignmentQuestion, withAnswers bool) ([]dto.AssignmentQuestionResponse, error) {
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.QuestionID)
+	}
+	questions, err := s.questions.FindByIDs(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load assignment questions: %w", err)
+	}
+	byID := make(map[int64]*models.Question, len(questions))
+	for i := range questions {
+		byID[questions[i].ID] = &questions[i]
+	}
+
+	result := make([]dto.AssignmentQuestionResponse, 0, len(items))
+	for _, item := range items {
+		q, ok := byID[item.QuestionID]
+		if !ok {
+			continue
+		}
+		resp := dto.AssignmentQuestionResponse{
+			QuestionID:   q.ID,
+			Points:       item.Points,
+			Title:        q.Title,
+			Content:      q.Content,
+			QuestionType: q.QuestionType,
+			Difficulty:   q.Difficulty,
+			Hints:        q.Hints,
+			Choices:      q.Choices,
+		}
+		if withAnswers {
+			resp.CorrectAnswer = &q.CorrectAnswer
+			resp.AnswerAnalysis = &q.AnswerAnalysis
+		}
+		result = append(result, resp)
+	}
+	return result, nil
+}
+
+func toAssignmentResponse(a *models.Assignment, className string) *dto.AssignmentResponse {
+	return &dto.AssignmentResponse{
+		ID:          a.ID,
+		ClassID:     a.ClassID,
+		ClassName:   className,
+		CreatedBy:   a.CreatedBy,
+		Title:       a.Title,
+		Description: a.Description,
+		OpenAt:      a.OpenAt,
+		DueAt:       a.DueAt,
+		MaxAttempts: a.MaxAttempts,
+		ShowAnswers: a.ShowAnswers,
+		AllowLate:   a.AllowLate,
+		CreatedAt:   a.CreatedAt,
+		UpdatedAt:   a.UpdatedAt,
+	}
+}
+
+// progressStatus 根据提交次数与是否有进行中的作答得出完成状态，提交过即视为已完成
+func progressStatus(submittedCount int, inProgress bool) string {
+	switch {
+	case submittedCount > 0:
+		return StatusSubmitted
+	case inProgress:
+		return StatusInProgress
+	default:
+		return StatusNotStarted
+	}
+}
+
+// percentage 计算百分比并保留两位小数，分母为0时返回0
+func percentage(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)*10000/float64(total)) / 100
+}
@@ -0,0 +1,293 @@
+/*
+File: attempt.go
+Author: lxp
+Description: 学生作答作业：开始/继续作答、提交判分、截止后自动提交，以及作答期间的答题校验
+*/
+package assignment
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/repository/assignment"
+	learning_service "zhixue-backend/internal/service/learning"
+	question_service "zhixue-backend/internal/service/question"
+	"zhixue-backend/logger"
+	"zhixue-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// StartAttempt 开始作答作业，每次作答对应一个 homework 类型的学习会话
+// 已有进行中的作答时继续该作答：会话已暂停则恢复，已结束则为其创建新会话
+func (s *assignmentService) StartAttempt(userID, assignmentID int64) (*dto.AssignmentAttemptResponse, error) {
+	now := time.Now()
+	a, err := s.findForStudent(userID, assignmentID, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.closeOverdue(assignment.AttemptFilter{AssignmentID: a.ID, UserID: userID}); err != nil {
+		return nil, err
+	}
+
+	open, err := s.repo.FindOpenAttempt(a.ID, userID)
+	switch {
+	case err == nil:
+		return s.resumeAttempt(open)
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+
+	if isClosed(a, now) {
+		return nil, ErrAssignmentClosed
+	}
+	attempts, err := s.repo.ListAttempts(a.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(attempts) >= a.MaxAttempts {
+		return nil, ErrNoAttemptsLeft
+	}
+
+	session, err := s.learning.StartSession(userID, &dto.StartSessionRequest{SessionType: learning_service.SessionTypeHomework})
+	if err != nil {
+		return nil, err // 包括家长管控导致的 ErrDailyLimitReached
+	}
+
+	attempt := &models.AssignmentAttempt{
+		AssignmentID: a.ID,
+		UserID:       userID,
+		AttemptNo:    len(attempts) + 1,
+		SessionID:    session.SessionID,
+		SessionIDs:   models.StringList{session.SessionID},
+		Status:       assignment.AttemptInProgress,
+		StartedAt:    now,
+	}
+	if err := s.repo.CreateAttempt(attempt); err != nil {
+		_, _ = s.learning.InterruptSession(userID, session.SessionID)
+		return nil, err // 包括并发开始导致的 ErrAttemptConflict
+	}
+	return toAttemptResponse(attempt), nil
+}
+
+// SubmitAttempt 提交进行中的作答，按作答期间的答题记录判分
+// 不允许迟交的作业过了截止时间才提交时，只计算截止前的答题，提交时间记为截止时间
+func (s *assignmentService) SubmitAttempt(userID, assignmentID int64) (*dto.AssignmentAttemptResponse, error) {
+	now := time.Now()
+	a, err := s.findForStudent(userID, assignmentID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt, err := s.repo.FindOpenAttempt(a.ID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNoOpenAttempt
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	submittedAt := now
+	if isClosed(a, now) {
+		submittedAt = a.DueAt
+	}
+	if err := s.finalize(a, attempt, submittedAt); err != nil {
+		return nil, err
+	}
+	return toAttemptResponse(attempt), nil
+}
+
+// CheckAnswer 在答题前校验作业规则，并决定向学生返回的内容 (实现 question_service.AnswerPolicy)
+//   - 在作业会话中答题：题目必须属于该作业、本次作答未答过且作业未截止，判分后只返回对错
+//   - 其他答题：题目在学生进行中的作业里时不返回答案，避免先在练习中查看答案
+func (s *assignmentService) CheckAnswer(userID int64, sessionID string, questionID int64) (question_service.Feedback, error) {
+	if sessionID != "" {
+		attempt, err := s.repo.FindAttemptBySession(sessionID)
+		if err == nil && attempt.UserID == userID {
+			return question_service.FeedbackResult, s.checkAttemptAnswer(attempt, questionID)
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return question_service.FeedbackNone, err
+		}
+	}
+
+	open, err := s.repo.HasOpenAttemptWithQuestion(userID, questionID)
+	if err != nil {
+		return question_service.FeedbackNone, err
+	}
+	if open {
+		return question_service.FeedbackResult, nil
+	}
+	return question_service.FeedbackFull, nil
+}
+
+// checkAttemptAnswer 校验作答中的一次答题
+func (s *assignmentService) checkAttemptAnswer(attempt *models.AssignmentAttempt, questionID int64) error {
+	if attempt.Status != assignment.AttemptInProgress {
+		return ErrAttemptSubmitted
+	}
+	a, err := s.repo.FindByID(attempt.AssignmentID)
+	if err != nil {
+		return err
+	}
+	if isClosed(a, time.Now()) {
+		return ErrAssignmentClosed
+	}
+
+	items, err := s.repo.ListQuestions(a.ID)
+	if err != nil {
+		return err
+	}
+	included := false
+	for _, item := range items {
+		if item.QuestionID == questionID {
+			included = true
+			break
+		}
+	}
+	if !included {
+		return ErrQuestionNotInAssignment
+	}
+
+	answers, err := s.answerMap(attempt)
+	if err != nil {
+		return err
+	}
+	if _, ok := answers[questionID]; ok {
+		return ErrQuestionAnswered
+	}
+	return nil
+}
+
+// resumeAttempt 继续进行中的作答
+func (s *assignmentService) resumeAttempt(attempt *models.AssignmentAttempt) (*dto.AssignmentAttemptResponse, error) {
+	session, err := s.learning.GetSession(attempt.UserID, attempt.SessionID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	switch {
+	case session != nil && session.CompletionStatus == learning_service.StatusOngoing:
+		return toAttemptResponse(attempt), nil
+	case session != nil && session.CompletionStatus == learning_service.StatusPaused:
+		if _, err := s.learning.ResumeSession(attempt.UserID, attempt.SessionID); err != nil {
+			return nil, err
+		}
+		return toAttemptResponse(attempt), nil
+	}
+
+	// 会话已结束 (如空闲超时或开始了其他学习会话)，为本次作答创建新会话，之前的答题仍计入本次作答
+	next, err := s.learning.StartSession(attempt.UserID, &dto.StartSessionRequest{SessionType: learning_service.SessionTypeHomework})
+	if err != nil {
+		return nil, err
+	}
+	attempt.SessionID = next.SessionID
+	attempt.SessionIDs = append(attempt.SessionIDs, next.SessionID)
+	if err := s.repo.UpdateAttemptSession(attempt); err != nil {
+		return nil, fmt.Errorf("failed to update attempt session: %w", err)
+	}
+	return toAttemptResponse(attempt), nil
+}
+
+// finalize 按本次作答中每道题的首次答题判分并提交作答，submittedAt 之后的答题不计分
+func (s *assignmentService) finalize(a *models.Assignment, attempt *models.AssignmentAttempt, submittedAt time.Time) error {
+	items, err := s.repo.ListQuestions(a.ID)
+	if err != nil {
+		return err
+	}
+	answers, err := s.answerMap(attempt)
+	if err != nil {
+		return err
+	}
+
+	earned, total := 0, 0
+	attempt.AnsweredCount, attempt.CorrectCount = 0, 0
+	for _, item := range items {
+		total += item.Points
+		ans, ok := answers[item.QuestionID]
+		if !ok || ans.CreatedAt.After(submittedAt) {
+			continue
+		}
+		attempt.AnsweredCount++
+		if ans.IsCorrect {
+			attempt.CorrectCount++
+			earned += item.Points
+		}
+	}
+	attempt.Status = assignment.AttemptSubmitted
+	attempt.SubmittedAt = &submittedAt
+	attempt.IsLate = submittedAt.After(a.DueAt)
+	attempt.Score = percentage(earned, total)
+
+	ok, err := s.repo.SubmitAttempt(attempt)
+	if err != nil {
+		return fmt.Errorf("failed to submit attempt: %w", err)
+	}
+	if !ok {
+		return ErrNoOpenAttempt
+	}
+
+	// 结束作答所用的学习会话，会话已结束时忽略
+	_, err = s.learning.FinishSession(attempt.UserID, attempt.SessionID)
+	if err != nil && !errors.Is(err, learning_service.ErrInvalidTransition) && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.LogError("assignment", "finish_session", err, map[string]interface{}{"session_id": attempt.SessionID})
+	}
+	return nil
+}
+
+// closeOverdue 自动提交不允许迟交且已过截止时间的作答，只计算截止前的答题
+func (s *assignmentService) closeOverdue(filter assignment.AttemptFilter) error {
+	attempts, err := s.repo.ListOverdueAttempts(filter, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to list overdue attempts: %w", err)
+	}
+
+	assignments := make(map[int64]*models.Assignment)
+	for i := range attempts {
+		attempt := &attempts[i]
+		a, ok := assignments[attempt.AssignmentID]
+		if !ok {
+			if a, err = s.repo.FindByID(attempt.AssignmentID); err != nil {
+				return err
+			}
+			assignments[a.ID] = a
+		}
+		if err := s.finalize(a, attempt, a.DueAt); err != nil && !errors.Is(err, ErrNoOpenAttempt) {
+			return err
+		}
+	}
+	return nil
+}
+
+// answerMap 获取本次作答中每道题的首次答题记录，以题目ID为键
+func (s *assignmentService) answerMap(attempt *models.AssignmentAttempt) (map[int64]assignment.AttemptAnswer, error) {
+	answers, err := s.repo.AttemptAnswers(attempt.UserID, attempt.SessionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load attempt answers: %w", err)
+	}
+	result := make(map[int64]assignment.AttemptAnswer, len(answers))
+	for _, ans := range answers {
+		result[ans.QuestionID] = ans
+	}
+	return result, nil
+}
+
+// isClosed 判断作业是否已截止且不允许迟交
+func isClosed(a *models.Assignment, now time.Time) bool {
+	return !a.AllowLate && now.After(a.DueAt)
+}
+
+func toAttemptResponse(attempt *models.AssignmentAttempt) *dto.AssignmentAttemptResponse {
+	return &dto.AssignmentAttemptResponse{
+		AttemptNo:     attempt.AttemptNo,
+		SessionID:     attempt.SessionID,
+		Status:        attempt.Status,
+		StartedAt:     attempt.StartedAt,
+		SubmittedAt:   attempt.SubmittedAt,
+		IsLate:        attempt.IsLate,
+		AnsweredCount: attempt.AnsweredCount,
+		CorrectCount:  attempt.CorrectCount,
+		Score:         attempt.Score,
+	}
+}
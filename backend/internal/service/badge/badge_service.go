@@ -0,0 +1,280 @@
+/*
+File: badge_service.go
+Author: lxp
+Description: 成就徽章业务逻辑：事件发生后按数据中声明的规则增量判定并发放徽章、推送通知，以及为已有用户补发
+*/
+package badge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/event"
+	"zhixue-backend/internal/repository/badge"
+	notification_service "zhixue-backend/internal/service/notification"
+	"zhixue-backend/logger"
+	"zhixue-backend/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 补发徽章时每批处理的用户数
+const backfillBatchSize = 200
+
+// Service 定义成就徽章服务的接口
+type Service interface {
+	HandleAnswerSubmitted(ctx context.Context, e event.Event) error
+	HandleSessionEnded(ctx context.Context, e event.Event) error
+	HandleExamFinished(ctx context.Context, e event.Event) error
+	ListEarned(userID int64) ([]dto.BadgeResponse, error)
+
+	// 供离线补发任务使用，判定全部徽章，返回新发放的徽章数
+	Evaluate(ctx context.Context, userID int64) (int, error)
+	EvaluateAll(ctx context.Context) (int, int, error)
+}
+
+// badgeService 实现了Service接口
+type badgeService struct {
+	repo          badge.Repository
+	notifications notification_service.Service
+}
+
+// NewBadgeService 创建一个新的成就徽章服务实例
+func NewBadgeService(repo badge.Repository, notifications notification_service.Service) Service {
+	return &badgeService{repo: repo, notifications: notifications}
+}
+
+// HandleAnswerSubmitted 订阅答题事件，判定使用了答题与学习档案统计的徽章
+func (s *badgeService) HandleAnswerSubmitted(ctx context.Context, e event.Event) error {
+	answer, ok := e.(event.AnswerSubmitted)
+	if !ok {
+		return nil
+	}
+	_, err := s.evaluate(ctx, answer.UserID, triggers[event.TopicAnswerSubmitted])
+	return err
+}
+
+// HandleSessionEnded 订阅学习会话结束事件，判定使用了会话与学习时长统计的徽章
+func (s *badgeService) HandleSessionEnded(ctx context.Context, e event.Event) error {
+	ended, ok := e.(event.SessionEnded)
+	if !ok {
+		return nil
+	}
+	_, err := s.evaluate(ctx, ended.UserID, triggers[event.TopicSessionEnded])
+	return err
+}
+
+// HandleExamFinished 订阅考试交卷事件，只有全部答对时才判定考试类徽章
+func (s *badgeService) HandleExamFinished(ctx context.Context, e event.Event) error {
+	finished, ok := e.(event.ExamFinished)
+	if !ok || finished.QuestionCount == 0 || finished.CorrectCount < finished.QuestionCount {
+		return nil
+	}
+	_, err := s.evaluate(ctx, finished.UserID, triggers[event.TopicExamFinished])
+	return err
+}
+
+// ListEarned 获取用户已获得的徽章，先获得的在前
+func (s *badgeService) ListEarned(userID int64) ([]dto.BadgeResponse, error) {
+	earned, err := s.repo.ListEarned(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list badges: %w", err)
+	}
+	badges := make([]dto.BadgeResponse, 0, len(earned))
+	for _, b := range earned {
+		badges = append(badges, dto.BadgeResponse{
+			ID:          b.ID,
+			Name:        b.Name,
+			Description: b.Description,
+			ObtainedAt:  b.ObtainedAt,
+		})
+	}
+	return badges, nil
+}
+
+// Evaluate 判定用户尚未获得的全部徽章
+func (s *badgeService) Evaluate(ctx context.Context, userID int64) (int, error) {
+	return s.evaluate(ctx, userID, nil)
+}
+
+// EvaluateAll 分批为所有有学习档案的用户判定全部徽章，返回处理的用户数与新发放的徽章数
+// ctx 被取消时处理完当前用户后返回
+func (s *badgeService) EvaluateAll(ctx context.Context) (int, int, error) {
+	users, awarded := 0, 0
+	var afterID int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return users, awarded, err
+		}
+
+		userIDs, err := s.repo.FindUsersWithProfile(afterID, backfillBatchSize)
+		if err != nil {
+			return users, awarded, fmt.Errorf("failed to find users: %w", err)
+		}
+		if len(userIDs) == 0 {
+			return users, awarded, nil
+		}
+
+		for _, userID := range userIDs {
+			if ctx.Err() != nil {
+				return users, awarded, ctx.Err()
+			}
+			n, err := s.Evaluate(ctx, userID)
+			if err != nil {
+				logger.LogError("badge", "backfill", err, map[string]interface{}{"user_id": userID})
+				continue
+			}
+			users++
+			awarded += n
+		}
+		afterID = userIDs[len(userIDs)-1]
+
+		logger.Logger.Info("徽章补发进度", zap.Int("users", users), zap.Int("awarded", awarded), zap.Int64("last_user_id", afterID))
+	}
+}
+
+// evaluate 判定用户尚未获得、且获得条件使用了 stats 中某个统计的徽章 (stats 为空时判定全部)，满足条件的发放并通知
+// 单个徽章失败只记录日志，不影响其他徽章
+func (s *badgeService) evaluate(ctx context.Context, userID int64, stats []string) (int, error) {
+	rules, err := s.repo.ListRules()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list badge rules: %w", err)
+	}
+	if len(rules) == 0 {
+		return 0, nil
+	}
+	earned, err := s.repo.ListEarned(userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list badges: %w", err)
+	}
+	owned := make(map[int64]bool, len(earned))
+	for _, b := range earned {
+		owned[b.ID] = true
+	}
+
+	values := &userStats{repo: s.repo, userID: userID, counts: make(map[string]int64)}
+	awarded := 0
+	for i := range rules {
+		rule := &rules[i]
+		if owned[rule.ID] {
+			continue
+		}
+		criteria, ok := parseCriteria(rule.Criteria)
+		if !ok || (stats != nil && !criteria.uses(stats)) {
+			continue
+		}
+
+		met, err := values.satisfies(criteria)
+		if err != nil {
+			logger.LogError("badge", "evaluate", err, map[string]interface{}{"user_id": userID, "reward_id": rule.ID})
+			continue
+		}
+		if !met {
+			continue
+		}
+
+		ok, err = s.repo.Award(userID, rule.ID, time.Now())
+		if err != nil {
+			logger.LogError("badge", "award", err, map[string]interface{}{"user_id": userID, "reward_id": rule.ID})
+			continue
+		}
+		if !ok {
+			continue
+		}
+		awarded++
+		s.notify(ctx, userID, rule)
+	}
+	return awarded, nil
+}
+
+// notify 通知用户获得了新徽章，失败只记录日志
+func (s *badgeService) notify(ctx context.Context, userID int64, reward *models.Reward) {
+	err := s.notifications.Notify(ctx, userID, notification_service.TypeBadgeEarned,
+		"获得新徽章："+reward.Name, reward.Description,
+		models.JSONB{"reward_id": reward.ID, "name": reward.Name})
+	if err != nil {
+		logger.LogError("badge", "notify", err, map[string]interface{}{"user_id": userID, "reward_id": reward.ID})
+	}
+}
+
+// userStats 按需读取并缓存一次判定中用到的用户统计
+type userStats struct {
+	repo    badge.Repository
+	userID  int64
+	profile *models.UserProfile
+	counts  map[string]int64
+}
+
+// satisfies 判断用户统计是否满足获得条件中的全部条件
+func (u *userStats) satisfies(criteria Criteria) (bool, error) {
+	for _, cond := range criteria.All {
+		value, err := u.value(cond)
+		if err != nil {
+			return false, err
+		}
+		if !cond.holds(value) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// value 获取条件所用统计的当前值
+func (u *userStats) value(cond Condition) (int64, error) {
+	key := cond.Stat + ":" + cond.KnowledgePoint
+	if v, ok := u.counts[key]; ok {
+		return v, nil
+	}
+
+	var v int64
+	var err error
+	switch {
+	case cond.KnowledgePoint != "":
+		v, err = u.repo.CountAnswers(u.userID, cond.Stat == StatCorrectAnswers, cond.KnowledgePoint)
+	case cond.Stat == StatPerfectExams:
+		v, err = u.repo.CountPerfectExams(u.userID)
+	case cond.Stat == StatSessionsCompleted:
+		v, err = u.repo.CountCompletedSessions(u.userID)
+	default:
+		v, err = u.profileValue(cond.Stat)
+	}
+	if err != nil {
+		return 0, err
+	}
+	u.counts[key] = v
+	return v, nil
+}
+
+// profileValue 从学习档案中读取统计，没有学习档案时为0
+func (u *userStats) profileValue(stat string) (int64, error) {
+	if u.profile == nil {
+		profile, err := u.repo.FindProfile(u.userID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			profile = &models.UserProfile{}
+		} else if err != nil {
+			return 0, err
+		}
+		u.profile = profile
+	}
+
+	switch stat {
+	case StatStreakDays:
+		return int64(u.profile.StreakDays), nil
+	case StatMaxStreakDays:
+		return int64(u.profile.MaxStreakDays), nil
+	case StatUserLevel:
+		return int64(u.profile.UserLevel), nil
+	case StatLevelScore:
+		return int64(u.profile.LevelScore), nil
+	case StatTotalStudyTime:
+		return int64(u.profile.TotalStudyTime), nil
+	case StatAnswers:
+		return int64(u.profile.TotalQuestions), nil
+	case StatCorrectAnswers:
+		return int64(u.profile.CorrectAnswers), nil
+	}
+	return 0, nil
+}
@@ -0,0 +1,97 @@
+/*
+File: rule.go
+Author: lxp
+Description: 徽章获得条件的解析与判定
+*/
+package badge
+
+import (
+	"encoding/json"
+	"zhixue-backend/internal/event"
+	"zhixue-backend/models"
+)
+
+// 获得条件可使用的用户统计
+const (
+	StatStreakDays        = "streak_days"        // 当前连续学习天数
+	StatMaxStreakDays     = "max_streak_days"    // 最长连续学习天数
+	StatUserLevel         = "user_level"         // 等级
+	StatLevelScore        = "level_score"        // 经验值
+	StatTotalStudyTime    = "total_study_time"   // 累计学习时长
+	StatAnswers           = "answers"            // 累计答题数，可用 knowledge_point 限定
+	StatCorrectAnswers    = "correct_answers"    // 累计答对题数，可用 knowledge_point 限定
+	StatPerfectExams      = "perfect_exams"      // 全部答对的考试次数
+	StatSessionsCompleted = "sessions_completed" // 正常完成的学习会话数
+)
+
+// triggers 记录每种事件可能改变的统计，事件发生后只判定使用了这些统计的徽章
+var triggers = map[string][]string{
+	event.TopicAnswerSubmitted: {StatAnswers, StatCorrectAnswers, StatStreakDays, StatMaxStreakDays, StatUserLevel, StatLevelScore},
+	event.TopicSessionEnded:    {StatSessionsCompleted, StatTotalStudyTime, StatStreakDays, StatMaxStreakDays},
+	event.TopicExamFinished:    {StatPerfectExams},
+}
+
+// Condition 是一个统计比较条件，如 {"stat": "correct_answers", "knowledge_point": "MATH_ADD", "op": ">=", "value": 100}
+type Condition struct {
+	Stat           string `json:"stat"`
+	Op             string `json:"op"` // >= | > | ==
+	Value          int64  `json:"value"`
+	KnowledgePoint string `json:"knowledge_point,omitempty"` // 知识点编码，包含其子知识点，仅用于答题类统计
+}
+
+// Criteria 是徽章的获得条件，以JSON存储在 rewards.criteria 中，all 中的条件需全部满足
+type Criteria struct {
+	All []Condition `json:"all"`
+}
+
+// parseCriteria 解析徽章的获得条件，条件缺失或无法识别时返回 false，这样的徽章不会自动发放
+func parseCriteria(raw models.JSONB) (Criteria, bool) {
+	var criteria Criteria
+	data, err := json.Marshal(raw)
+	if err != nil || json.Unmarshal(data, &criteria) != nil || len(criteria.All) == 0 {
+		return criteria, false
+	}
+	for _, c := range criteria.All {
+		switch c.Op {
+		case ">=", ">", "==":
+		default:
+			return criteria, false
+		}
+		switch c.Stat {
+		case StatAnswers, StatCorrectAnswers:
+		case StatStreakDays, StatMaxStreakDays, StatUserLevel, StatLevelScore, StatTotalStudyTime,
+			StatPerfectExams, StatSessionsCompleted:
+			if c.KnowledgePoint != "" {
+				return criteria, false
+			}
+		default:
+			return criteria, false
+		}
+	}
+	return criteria, true
+}
+
+// uses 判断获得条件是否使用了 stats 中的某个统计
+func (c Criteria) uses(stats []string) bool {
+	for _, cond := range c.All {
+		for _, stat := range stats {
+			if cond.Stat == stat {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// holds 判断统计值是否满足条件
+func (c Condition) holds(value int64) bool {
+	switch c.Op {
+	case ">=":
+		return value >= c.Value
+	case ">":
+		return value > c.Value
+	case "==":
+		return value == c.Value
+	}
+	return false
+}
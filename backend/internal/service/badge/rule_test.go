@@ -0,0 +1,116 @@
+/*
+File: rule_test.go
+Author: lxp
+Description: 徽章获得条件解析与判定的单元测试
+*/
+package badge
+
+import (
+	"reflect"
+	"testing"
+	"zhixue-backend/internal/event"
+	"zhixue-backend/models"
+)
+
+func TestParseCriteria(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  models.JSONB
+		want []Condition
+		ok   bool
+	}{
+		{"单个条件", models.JSONB{"all": []interface{}{
+			map[string]interface{}{"stat": "streak_days", "op": ">=", "value": 7},
+		}}, []Condition{{Stat: StatStreakDays, Op: ">=", Value: 7}}, true},
+		{"限定知识点", models.JSONB{"all": []interface{}{
+			map[string]interface{}{"stat": "correct_answers", "op": ">", "value": 99, "knowledge_point": "MATH_ADD"},
+			map[string]interface{}{"stat": "user_level", "op": "==", "value": 5},
+		}}, []Condition{
+			{Stat: StatCorrectAnswers, Op: ">", Value: 99, KnowledgePoint: "MATH_ADD"},
+			{Stat: StatUserLevel, Op: "==", Value: 5},
+		}, true},
+		{"条件缺失", nil, nil, false},
+		{"条件为空", models.JSONB{"all": []interface{}{}}, nil, false},
+		{"未知统计", models.JSONB{"all": []interface{}{
+			map[string]interface{}{"stat": "logins", "op": ">=", "value": 1},
+		}}, nil, false},
+		{"未知运算符", models.JSONB{"all": []interface{}{
+			map[string]interface{}{"stat": "answers", "op": "<", "value": 1},
+		}}, nil, false},
+		{"非答题统计限定知识点", models.JSONB{"all": []interface{}{
+			map[string]interface{}{"stat": "perfect_exams", "op": ">=", "value": 1, "knowledge_point": "MATH_ADD"},
+		}}, nil, false},
+		{"值类型错误", models.JSONB{"all": []interface{}{
+			map[string]interface{}{"stat": "answers", "op": ">=", "value": "10"},
+		}}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseCriteria(tt.raw)
+			if ok != tt.ok {
+				t.Fatalf("parseCriteria(%v) ok = %v, want %v", tt.raw, ok, tt.ok)
+			}
+			if ok && !reflect.DeepEqual(got.All, tt.want) {
+				t.Errorf("parseCriteria(%v) = %+v, want %+v", tt.raw, got.All, tt.want)
+			}
+		})
+	}
+}
+
+func TestConditionHolds(t *testing.T) {
+	tests := []struct {
+		op    string
+		value int64
+		want  bool
+	}{
+		{">=", 9, false},
+		{">=", 10, true},
+		{">=", 11, true},
+		{">", 10, false},
+		{">", 11, true},
+		{"==", 10, true},
+		{"==", 11, false},
+		{"<", 1, false},
+	}
+
+	for _, tt := range tests {
+		c := Condition{Op: tt.op, Value: 10}
+		if got := c.holds(tt.value); got != tt.want {
+			t.Errorf("%d %s 10 = %v, want %v", tt.value, tt.op, got, tt.want)
+		}
+	}
+}
+
+func TestCriteriaUses(t *testing.T) {
+	criteria := Criteria{All: []Condition{{Stat: StatPerfectExams}, {Stat: StatUserLevel}}}
+	tests := []struct {
+		name  string
+		topic string
+		want  bool
+	}{
+		{"答题改变等级", event.TopicAnswerSubmitted, true},
+		{"考试结束改变满分次数", event.TopicExamFinished, true},
+		{"会话结束不影响", event.TopicSessionEnded, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := criteria.uses(triggers[tt.topic]); got != tt.want {
+				t.Errorf("uses(triggers[%s]) = %v, want %v", tt.topic, got, tt.want)
+			}
+		})
+	}
+}
+
+// triggers 中的统计都必须是 parseCriteria 能识别的统计
+func TestTriggersUseKnownStats(t *testing.T) {
+	for topic, stats := range triggers {
+		for _, stat := range stats {
+			raw := models.JSONB{"all": []interface{}{map[string]interface{}{"stat": stat, "op": ">=", "value": 1}}}
+			if _, ok := parseCriteria(raw); !ok {
+				t.Errorf("triggers[%s] contains unknown stat %q", topic, stat)
+			}
+		}
+	}
+}
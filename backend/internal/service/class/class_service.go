@@ -0,0 +1,419 @@
+/*
+File: class_service.go
+Author: lxp
+Description: 班级业务逻辑：教师管理班级与花名册、学生通过加入码加入班级、班级学习统计
+*/
+package class
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/randcode"
+	"zhixue-backend/internal/rbac"
+	"zhixue-backend/internal/repository/class"
+	"zhixue-backend/internal/repository/user"
+	"zhixue-backend/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrClassForbidden  = errors.New("no permission to manage this class")
+	ErrInvalidJoinCode = errors.New("invalid join code")
+	ErrClassArchived   = errors.New("class is archived")
+	ErrNotMember       = errors.New("user is not a member of the class")
+	ErrNotStudent      = errors.New("only students can join classes")
+	ErrSameClass       = errors.New("target class is the same as the source class")
+	ErrAlreadyMember   = class.ErrAlreadyMember
+)
+
+// 加入码长度与生成冲突时的重试次数
+const (
+	joinCodeLength   = 6
+	joinCodeAttempts = 5
+)
+
+// canManage 判断用户能否管理该班级：教师只能管理自己的班级，管理员可管理全部班级
+func canManage(op rbac.Subject, c *models.Class) bool {
+	return op.CanAccess(&c.TeacherID, rbac.PermClassManage, rbac.PermClassManageAll)
+}
+
+// Service 定义班级服务的接口
+type Service interface {
+	ListClasses(op rbac.Subject, query *dto.ListClassesQuery) (*dto.PageResponse, error)
+	GetClass(op rbac.Subject, classID int64) (*dto.ClassResponse, error)
+	CreateClass(op rbac.Subject, req *dto.CreateClassRequest) (*dto.ClassResponse, error)
+	UpdateClass(op rbac.Subject, classID int64, req *dto.UpdateClassRequest) (*dto.ClassResponse, error)
+	DeleteClass(op rbac.Subject, classID int64) error
+	RegenerateJoinCode(op rbac.Subject, classID int64) (*dto.ClassResponse, error)
+	ListMembers(op rbac.Subject, classID int64, query *dto.ClassStatsQuery) ([]dto.ClassMemberResponse, error)
+	RemoveMember(op rbac.Subject, classID, userID int64) error
+	TransferMember(op rbac.Subject, classID, userID, targetClassID int64) error
+	GetStats(op rbac.Subject, classID int64, query *dto.ClassStatsQuery) (*dto.ClassStatsResponse, error)
+	JoinClass(userID int64, joinCode string) (*dto.JoinedClassResponse, error)
+	LeaveClass(userID, classID int64) error
+	ListJoinedClasses(userID int64) ([]dto.JoinedClassResponse, error)
+}
+
+// classService 实现了Service接口
+type classService struct {
+	repo  class.Repository
+	users user.Repository
+}
+
+// NewClassService 创建一个新的班级服务实例
+func NewClassService(repo class.Repository, users user.Repository) Service {
+	return &classService{repo: repo, users: users}
+}
+
+// ListClasses 分页获取班级列表
+// 没有 class:manage_all 权限时只能看到自己的班级
+func (s *classService) ListClasses(op rbac.Subject, query *dto.ListClassesQuery) (*dto.PageResponse, error) {
+	query.Normalize()
+
+	filter := class.ClassFilter{
+		Page:       query.Page,
+		PageSize:   query.PageSize,
+		TeacherID:  query.TeacherID,
+		Keyword:    strings.TrimSpace(query.Keyword),
+		IsArchived: query.IsArchived,
+	}
+	if !op.Can(rbac.PermClassManageAll) {
+		filter.TeacherID = &op.UserID
+	}
+
+	classes, total, err := s.repo.List(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list classes: %w", err)
+	}
+
+	items := make([]dto.ClassResponse, 0, len(classes))
+	for i := range classes {
+		items = append(items, *toClassResponse(&classes[i].Class, classes[i].MemberCount))
+	}
+	return dto.NewPageResponse(items, filter.Page, filter.PageSize, total), nil
+}
+
+// GetClass 获取班级详情
+func (s *classService) GetClass(op rbac.Subject, classID int64) (*dto.ClassResponse, error) {
+	c, err := s.findManaged(op, classID)
+	if err != nil {
+		return nil, err
+	}
+	return s.detail(c)
+}
+
+// CreateClass 创建班级，创建者即为班级教师
+func (s *classService) CreateClass(op rbac.Subject, req *dto.CreateClassRequest) (*dto.ClassResponse, error) {
+	c := &models.Class{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		GradeLevel:  req.GradeLevel,
+		TeacherID:   op.UserID,
+	}
+	err := s.withNewJoinCode(c, func() error {
+		return s.repo.Create(c)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toClassResponse(c, 0), nil
+}
+
+// UpdateClass 更新班级信息，归档后学生不能再通过加入码加入
+func (s *classService) UpdateClass(op rbac.Subject, classID int64, req *dto.UpdateClassRequest) (*dto.ClassResponse, error) {
+	c, err := s.findManaged(op, classID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		c.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		c.Description = *req.Description
+	}
+	if req.GradeLevel != nil {
+		c.GradeLevel = *req.GradeLevel
+	}
+	if req.IsArchived != nil {
+		c.IsArchived = *req.IsArchived
+	}
+
+	if err := s.repo.Update(c); err != nil {
+		return nil, fmt.Errorf("failed to update class: %w", err)
+	}
+	return s.detail(c)
+}
+
+// DeleteClass 删除班级及其成员关系
+func (s *classService) DeleteClass(op rbac.Subject, classID int64) error {
+	c, err := s.findManaged(op, classID)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(c.ID)
+}
+
+// RegenerateJoinCode 重新生成加入码，旧加入码立即失效，已加入的学生不受影响
+func (s *classService) RegenerateJoinCode(op rbac.Subject, classID int64) (*dto.ClassResponse, error) {
+	c, err := s.findManaged(op, classID)
+	if err != nil {
+		return nil, err
+	}
+	err = s.withNewJoinCode(c, func() error {
+		return s.repo.Update(c)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.detail(c)
+}
+
+// ListMembers 获取班级花名册及每个学生的学习统计
+func (s *classService) ListMembers(op rbac.Subject, classID int64, query *dto.ClassStatsQuery) ([]dto.ClassMemberResponse, error) {
+	c, err := s.findManaged(op, classID)
+	if err != nil {
+		return nil, err
+	}
+	query.Normalize()
+
+	members, err := s.repo.ListMembers(c.ID, periodStart(query.Days))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list class members: %w", err)
+	}
+
+	items := make([]dto.ClassMemberResponse, 0, len(members))
+	for _, m := range members {
+		items = append(items, dto.ClassMemberResponse{
+			UserID:            m.UserID,
+			Username:          m.Username,
+			Nickname:          m.Nickname,
+			AvatarURL:         m.AvatarURL,
+			GradeLevel:        m.GradeLevel,
+			JoinedAt:          m.JoinedAt,
+			CurrentDifficulty: m.CurrentDifficulty,
+			TotalQuestions:    m.TotalQuestions,
+			CorrectAnswers:    m.CorrectAnswers,
+			StreakDays:        m.StreakDays,
+			PeriodAnswers:     m.PeriodAnswers,
+			PeriodAccuracy:    percentage(m.PeriodCorrect, m.PeriodAnswers),
+			LastAnsweredAt:    m.LastAnsweredAt,
+		})
+	}
+	return items, nil
+}
+
+// RemoveMember 将学生移出班级
+func (s *classService) RemoveMember(op rbac.Subject, classID, userID int64) error {
+	c, err := s.findManaged(op, classID)
+	if err != nil {
+		return err
+	}
+	ok, err := s.repo.RemoveMember(c.ID, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotMember
+	}
+	return nil
+}
+
+// TransferMember 将学生从一个班级转到另一个班级，两个班级都必须由当前用户管理
+func (s *classService) TransferMember(op rbac.Subject, classID, userID, targetClassID int64) error {
+	if classID == targetClassID {
+		return ErrSameClass
+	}
+	from, err := s.findManaged(op, classID)
+	if err != nil {
+		return err
+	}
+	to, err := s.findManaged(op, targetClassID)
+	if err != nil {
+		return err
+	}
+	if to.IsArchived {
+		return ErrClassArchived
+	}
+
+	ok, err := s.repo.TransferMember(from.ID, to.ID, userID)
+	if err != nil {
+		return err // 包括 ErrAlreadyMember
+	}
+	if !ok {
+		return ErrNotMember
+	}
+	return nil
+}
+
+// GetStats 获取班级整体的学习统计
+func (s *classService) GetStats(op rbac.Subject, classID int64, query *dto.ClassStatsQuery) (*dto.ClassStatsResponse, error) {
+	c, err := s.findManaged(op, classID)
+	if err != nil {
+		return nil, err
+	}
+	query.Normalize()
+
+	stats, err := s.repo.Stats(c.ID, periodStart(query.Days))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load class stats: %w", err)
+	}
+	return &dto.ClassStatsResponse{
+		ClassID:         c.ID,
+		Days:            query.Days,
+		MemberCount:     stats.MemberCount,
+		ActiveMembers:   stats.ActiveMembers,
+		AnswerCount:     stats.AnswerCount,
+		CorrectCount:    stats.CorrectCount,
+		Accuracy:        percentage(stats.CorrectCount, stats.AnswerCount),
+		AvgResponseTime: stats.AvgResponseTime,
+		AvgDifficulty:   stats.AvgDifficulty,
+		TotalQuestions:  stats.TotalQuestions,
+	}, nil
+}
+
+// JoinClass 学生通过加入码加入班级
+func (s *classService) JoinClass(userID int64, joinCode string) (*dto.JoinedClassResponse, error) {
+	student, err := s.users.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if student.Role != rbac.RoleUser {
+		return nil, ErrNotStudent
+	}
+
+	c, err := s.repo.FindByJoinCode(randcode.Normalize(joinCode))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidJoinCode
+		}
+		return nil, err
+	}
+	if c.IsArchived {
+		return nil, ErrClassArchived
+	}
+
+	member := &models.ClassMember{ClassID: c.ID, UserID: userID}
+	if err := s.repo.AddMember(member); err != nil {
+		return nil, err // 包括 ErrAlreadyMember
+	}
+
+	resp := &dto.JoinedClassResponse{
+		ID:          c.ID,
+		Name:        c.Name,
+		Description: c.Description,
+		GradeLevel:  c.GradeLevel,
+		IsArchived:  c.IsArchived,
+		JoinedAt:    member.JoinedAt,
+	}
+	if teacher, err := s.users.FindByID(c.TeacherID); err == nil {
+		resp.TeacherNickname = teacher.Nickname
+	}
+	return resp, nil
+}
+
+// LeaveClass 学生退出班级
+func (s *classService) LeaveClass(userID, classID int64) error {
+	ok, err := s.repo.RemoveMember(classID, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotMember
+	}
+	return nil
+}
+
+// ListJoinedClasses 获取学生已加入的班级
+func (s *classService) ListJoinedClasses(userID int64) ([]dto.JoinedClassResponse, error) {
+	classes, err := s.repo.ListJoinedClasses(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dto.JoinedClassResponse, 0, len(classes))
+	for _, c := range classes {
+		items = append(items, dto.JoinedClassResponse{
+			ID:              c.ID,
+			Name:            c.Name,
+			Description:     c.Description,
+			GradeLevel:      c.GradeLevel,
+			TeacherNickname: c.TeacherNickname,
+			IsArchived:      c.IsArchived,
+			JoinedAt:        c.JoinedAt,
+		})
+	}
+	return items, nil
+}
+
+// findManaged 获取班级并校验当前用户有权管理
+func (s *classService) findManaged(op rbac.Subject, classID int64) (*models.Class, error) {
+	c, err := s.repo.FindByID(classID)
+	if err != nil {
+		return nil, err // 错误可能是 gorm.ErrRecordNotFound
+	}
+	if !canManage(op, c) {
+		return nil, ErrClassForbidden
+	}
+	return c, nil
+}
+
+// detail 构造包含成员人数的班级响应
+func (s *classService) detail(c *models.Class) (*dto.ClassResponse, error) {
+	count, err := s.repo.CountMembers(c.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toClassResponse(c, count), nil
+}
+
+// withNewJoinCode 为班级生成新的加入码并执行保存，加入码冲突时重新生成
+func (s *classService) withNewJoinCode(c *models.Class, save func() error) error {
+	for i := 0; i < joinCodeAttempts; i++ {
+		code, err := randcode.New(joinCodeLength)
+		if err != nil {
+			return err
+		}
+		c.JoinCode = code
+
+		err = save()
+		if !errors.Is(err, class.ErrJoinCodeExists) {
+			return err
+		}
+	}
+	return fmt.Errorf("failed to generate a unique join code after %d attempts", joinCodeAttempts)
+}
+
+func toClassResponse(c *models.Class, memberCount int) *dto.ClassResponse {
+	return &dto.ClassResponse{
+		ID:          c.ID,
+		Name:        c.Name,
+		Description: c.Description,
+		GradeLevel:  c.GradeLevel,
+		TeacherID:   c.TeacherID,
+		JoinCode:    c.JoinCode,
+		IsArchived:  c.IsArchived,
+		MemberCount: memberCount,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+	}
+}
+
+// periodStart 返回最近 days 天统计周期的起点 (含今天，按服务器时区的自然日)
+func periodStart(days int) time.Time {
+	y, m, d := time.Now().Date()
+	return time.Date(y, m, d-days+1, 0, 0, 0, 0, time.Local)
+}
+
+// percentage 计算百分比并保留两位小数，分母为0时返回0
+func percentage(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)*10000/float64(total)) / 100
+}
@@ -0,0 +1,118 @@
+/*
+File: ai_engine.go
+Author: lxp
+Description: 调用 ai-service 的难度引擎
+*/
+package difficulty
+
+import (
+	"context"
+	"strconv"
+	"time"
+	"zhixue-backend/internal/aiclient"
+	"zhixue-backend/internal/config"
+	"zhixue-backend/logger"
+	"zhixue-backend/models"
+
+	"go.uber.org/zap"
+)
+
+// 答题触发时调用 AI 服务的默认时限
+const defaultAITimeout = 300 * time.Millisecond
+
+// aiEngine 将窗口统计交给 ai-service 计算推荐难度
+// 定时或手动触发时，AI 服务不可用会由客户端重试并使用与 ai-service 一致的本地规则；
+// 答题触发时位于答题请求的同步链路上，只在短时限内调用一次，失败则改用 elo 引擎
+type aiEngine struct {
+	client   *aiclient.Client
+	fallback Engine
+	timeout  time.Duration
+}
+
+// NewAIEngineFactory 返回使用指定客户端创建 ai 引擎的工厂，配合 RegisterEngine 使用
+func NewAIEngineFactory(client *aiclient.Client) Factory {
+	return func(cfg *config.DifficultyConfig) (Engine, error) {
+		fallback, err := newEloEngine(cfg)
+		if err != nil {
+			return nil, err
+		}
+		timeout := cfg.AITimeout
+		if timeout <= 0 {
+			timeout = defaultAITimeout
+		}
+		return &aiEngine{client: client, fallback: fallback, timeout: timeout}, nil
+	}
+}
+
+// Name 实现 Engine 接口
+func (e *aiEngine) Name() string { return "ai" }
+
+// Adjust 实现 Engine 接口
+func (e *aiEngine) Adjust(ctx context.Context, in *Input) (*Decision, error) {
+	w := in.Window
+	correct := 0
+	for _, o := range w.Observations {
+		if o.IsCorrect {
+			correct++
+		}
+	}
+
+	req := &aiclient.DifficultyRequest{
+		UserID:            strconv.FormatInt(in.UserID, 10),
+		CorrectAnswers:    correct,
+		TotalAnswers:      w.Size(),
+		AvgResponseTime:   w.AvgResponseTime(),
+		CurrentDifficulty: in.CurrentDifficulty,
+	}
+
+	if in.Trigger == TriggerAnswerCorrect || in.Trigger == TriggerAnswerWrong {
+		return e.adjustOnAnswer(ctx, in, req)
+	}
+
+	resp, err := e.client.AdjustDifficulty(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return toDecision(resp), nil
+}
+
+// adjustOnAnswer 在答题链路上调用 AI 服务，超时或失败时使用 elo 引擎的结果
+func (e *aiEngine) adjustOnAnswer(ctx context.Context, in *Input, req *aiclient.DifficultyRequest) (*Decision, error) {
+	callCtx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	resp, err := e.client.TryAdjustDifficulty(callCtx, req)
+	if err == nil {
+		return toDecision(resp), nil
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	logger.Logger.Warn("AI难度调节未及时响应，使用elo引擎",
+		zap.Int64("user_id", in.UserID),
+		zap.Error(err))
+	decision, err := e.fallback.Adjust(ctx, in)
+	if err != nil || decision == nil {
+		return decision, err
+	}
+	if decision.Details == nil {
+		decision.Details = models.JSONB{}
+	}
+	decision.Details["ai_fallback"] = true
+	return decision, nil
+}
+
+// toDecision 将 AI 服务的响应转换为引擎的调整结果
+func toDecision(resp *aiclient.DifficultyResponse) *Decision {
+	reason := resp.Reasoning
+	if resp.Fallback {
+		reason += "（AI服务暂不可用，已使用本地规则）"
+	}
+	return &Decision{
+		NewDifficulty: resp.RecommendedDifficulty,
+		Confidence:    resp.Confidence,
+		Reason:        reason,
+		Details:       models.JSONB{"ai_fallback": resp.Fallback},
+	}
+}
@@ -0,0 +1,102 @@
+/*
+File: ai_engine_test.go
+Author: lxp
+Description: ai 难度引擎在答题链路上的超时降级测试
+*/
+package difficulty
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+	"zhixue-backend/internal/aiclient"
+	"zhixue-backend/internal/config"
+	"zhixue-backend/logger"
+
+	"go.uber.org/zap"
+)
+
+func newTestAIEngine(t *testing.T, handler http.HandlerFunc, timeout time.Duration) Engine {
+	t.Helper()
+	logger.Logger = zap.NewNop()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := aiclient.NewClient(&config.AIServiceConfig{
+		URL:          server.URL,
+		Timeout:      30 * time.Second,
+		RetryCount:   3,
+		RetryBackoff: 200 * time.Millisecond,
+	})
+	engine, err := NewAIEngineFactory(client)(&config.DifficultyConfig{AITimeout: timeout})
+	if err != nil {
+		t.Fatalf("create ai engine: %v", err)
+	}
+	return engine
+}
+
+// 答题触发时 AI 服务响应过慢，应在时限内改用 elo 引擎，不重试
+func TestAIEngineFallsBackToEloOnAnswer(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	var calls atomic.Int32
+	engine := newTestAIEngine(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}, 50*time.Millisecond)
+
+	start := time.Now()
+	d, err := engine.Adjust(context.Background(), &Input{
+		CurrentDifficulty: 2.0,
+		Window:            window(2.0, true, true, true, true, true),
+		Trigger:           TriggerAnswerCorrect,
+	})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Adjust took %v on the answer path", elapsed)
+	}
+	if err != nil {
+		t.Fatalf("Adjust error: %v", err)
+	}
+	if d == nil || d.NewDifficulty <= 2.0 {
+		t.Fatalf("Adjust = %+v, want elo decision raising difficulty", d)
+	}
+	if d.Details["ai_fallback"] != true {
+		t.Errorf("Details[ai_fallback] = %v, want true", d.Details["ai_fallback"])
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("ai service called %d times, want 1", n)
+	}
+}
+
+// AI 服务及时响应时使用其结果
+func TestAIEngineUsesServiceResponse(t *testing.T) {
+	engine := newTestAIEngine(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(aiclient.DifficultyResponse{
+			UserID:                "1",
+			RecommendedDifficulty: 3.5,
+			Confidence:            0.8,
+			Reasoning:             "准确率高，增加难度",
+		})
+	}, time.Second)
+
+	d, err := engine.Adjust(context.Background(), &Input{
+		UserID:            1,
+		CurrentDifficulty: 2.0,
+		Window:            window(2.0, true, true, true, true, true),
+		Trigger:           TriggerAnswerCorrect,
+	})
+	if err != nil {
+		t.Fatalf("Adjust error: %v", err)
+	}
+	if d.NewDifficulty != 3.5 || d.Details["ai_fallback"] != false {
+		t.Errorf("Adjust = %+v, want ai service decision", d)
+	}
+}
@@ -0,0 +1,216 @@
+/*
+File: difficulty_service.go
+Author: lxp
+Description: 自适应难度服务业务逻辑
+*/
+package difficulty
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+	"zhixue-backend/internal/config"
+	"zhixue-backend/internal/event"
+	"zhixue-backend/internal/repository/difficulty"
+	"zhixue-backend/logger"
+	"zhixue-backend/models"
+
+	"go.uber.org/zap"
+)
+
+// 未配置时使用的默认值
+const (
+	defaultWindowSize          = 10
+	defaultMinAnswers          = 5
+	defaultMinStep             = 0.05
+	defaultRecalibrateInterval = time.Hour
+	defaultStaleAfter          = 24 * time.Hour
+
+	// 定时任务每轮最多处理的用户数
+	recalibrateBatchSize = 500
+)
+
+// Service 定义自适应难度服务的接口
+type Service interface {
+	HandleAnswerSubmitted(ctx context.Context, e event.Event) error
+	Recalibrate(ctx context.Context, userID int64, trigger string) (*models.DifficultyAdjustment, error)
+	StartScheduler(ctx context.Context)
+}
+
+// difficultyService 实现了Service接口
+type difficultyService struct {
+	repo   difficulty.Repository
+	engine Engine
+	config *config.DifficultyConfig
+}
+
+// NewDifficultyService 创建一个新的自适应难度服务实例
+func NewDifficultyService(repo difficulty.Repository, engine Engine, config *config.DifficultyConfig) Service {
+	return &difficultyService{repo: repo, engine: engine, config: config}
+}
+
+// HandleAnswerSubmitted 订阅答题事件，每次答题后重新评估用户难度
+func (s *difficultyService) HandleAnswerSubmitted(ctx context.Context, e event.Event) error {
+	answer, ok := e.(event.AnswerSubmitted)
+	if !ok {
+		return nil
+	}
+
+	trigger := TriggerAnswerWrong
+	if answer.IsCorrect {
+		trigger = TriggerAnswerCorrect
+	}
+	_, err := s.Recalibrate(ctx, answer.UserID, trigger)
+	return err
+}
+
+// Recalibrate 使用最近的答题窗口重新评估用户难度，发生变化时写入调整历史
+// 返回 nil 表示本次无需调整
+func (s *difficultyService) Recalibrate(ctx context.Context, userID int64, trigger string) (*models.DifficultyAdjustment, error) {
+	current, err := s.repo.FindCurrentDifficulty(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load current difficulty: %w", err)
+	}
+
+	records, err := s.repo.RecentAnswers(userID, s.windowSize())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load recent answers: %w", err)
+	}
+	window := NewPerformanceWindow(records)
+	if window.Size() < s.minAnswers() {
+		return nil, nil // 样本不足，不做调整
+	}
+
+	decision, err := s.engine.Adjust(ctx, &Input{
+		UserID:            userID,
+		CurrentDifficulty: current,
+		Window:            window,
+		Trigger:           trigger,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("difficulty engine %s failed: %w", s.engine.Name(), err)
+	}
+	if decision == nil || !s.significant(current, decision.NewDifficulty, trigger) {
+		return nil, nil
+	}
+
+	snapshot := window.ToJSONB()
+	snapshot["engine"] = s.engine.Name()
+	for k, v := range decision.Details {
+		snapshot[k] = v
+	}
+
+	adjustment := &models.DifficultyAdjustment{
+		UserID:            userID,
+		OldDifficulty:     current,
+		NewDifficulty:     clampDifficulty(decision.NewDifficulty),
+		AdjustmentReason:  decision.Reason,
+		Confidence:        round2(math.Max(0, math.Min(1, decision.Confidence))),
+		TriggerEvent:      trigger,
+		PerformanceWindow: snapshot,
+	}
+	if err := s.repo.SaveAdjustment(adjustment); err != nil {
+		return nil, fmt.Errorf("failed to save difficulty adjustment: %w", err)
+	}
+
+	logger.Logger.Info("用户难度已调整",
+		zap.Int64("user_id", userID),
+		zap.Float64("old_difficulty", adjustment.OldDifficulty),
+		zap.Float64("new_difficulty", adjustment.NewDifficulty),
+		zap.String("trigger", trigger),
+		zap.String("engine", s.engine.Name()))
+	return adjustment, nil
+}
+
+// significant 判断调整幅度是否值得落库
+// 答题触发时忽略小于 min_step 的波动；定时触发时接受任何变化，让微小的偏差也能逐步收敛
+func (s *difficultyService) significant(current, next float64, trigger string) bool {
+	diff := math.Abs(round2(next) - round2(current))
+	if trigger == TriggerTimeBased || trigger == TriggerManual {
+		return diff > 0
+	}
+	return diff >= s.minStep()
+}
+
+// StartScheduler 启动后台任务，定期重新评估长时间未调整的活跃用户，直到 ctx 被取消
+func (s *difficultyService) StartScheduler(ctx context.Context) {
+	interval := s.config.RecalibrateInterval
+	if interval <= 0 {
+		interval = defaultRecalibrateInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.recalibrateStaleUsers(ctx)
+			}
+		}
+	}()
+
+	logger.Logger.Info("难度定时评估任务已启动",
+		zap.String("engine", s.engine.Name()),
+		zap.Duration("interval", interval))
+}
+
+// recalibrateStaleUsers 对一批需要定时评估的用户执行重新评估
+func (s *difficultyService) recalibrateStaleUsers(ctx context.Context) {
+	staleAfter := s.config.StaleAfter
+	if staleAfter <= 0 {
+		staleAfter = defaultStaleAfter
+	}
+
+	userIDs, err := s.repo.FindUsersDueForRecalibration(time.Now().Add(-staleAfter), recalibrateBatchSize)
+	if err != nil {
+		logger.LogError("difficulty", "find_users_due_for_recalibration", err, nil)
+		return
+	}
+
+	adjusted := 0
+	for _, userID := range userIDs {
+		if ctx.Err() != nil {
+			return
+		}
+		adjustment, err := s.Recalibrate(ctx, userID, TriggerTimeBased)
+		if err != nil {
+			logger.LogError("difficulty", "recalibrate", err, map[string]interface{}{"user_id": userID})
+			continue
+		}
+		if adjustment != nil {
+			adjusted++
+		}
+	}
+
+	if len(userIDs) > 0 {
+		logger.Logger.Info("难度定时评估完成",
+			zap.Int("checked", len(userIDs)),
+			zap.Int("adjusted", adjusted))
+	}
+}
+
+func (s *difficultyService) windowSize() int {
+	if s.config.WindowSize > 0 {
+		return s.config.WindowSize
+	}
+	return defaultWindowSize
+}
+
+func (s *difficultyService) minAnswers() int {
+	if s.config.MinAnswers > 0 {
+		return s.config.MinAnswers
+	}
+	return defaultMinAnswers
+}
+
+func (s *difficultyService) minStep() float64 {
+	if s.config.MinStep > 0 {
+		return s.config.MinStep
+	}
+	return defaultMinStep
+}
@@ -0,0 +1,134 @@
+/*
+File: elo_engine.go
+Author: lxp
+Description: 基于 Elo/IRT 思想的内置难度引擎
+*/
+package difficulty
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"zhixue-backend/internal/config"
+	"zhixue-backend/models"
+)
+
+// 引擎默认参数
+const (
+	defaultKFactor = 0.6
+	defaultMaxStep = 0.3
+
+	// 用户能力与题目难度相等时期望的正确率，略高于50%可以让学习者保持信心
+	targetAccuracy = 0.7
+	// 能力差对正确率的影响程度 (IRT 区分度参数)
+	discrimination = 1.5
+	// 越早的答题权重越低
+	recencyDecay = 0.85
+	// 使用提示后答对只计一半得分
+	hintedScore = 0.5
+)
+
+// eloEngine 将用户当前难度视为能力值 θ，将题目难度视为 b，
+// 以 P = 1 / (1 + e^-(a(θ-b) + logit(target))) 作为答对的期望概率，
+// 按窗口内 "实际得分 - 期望得分" 的加权平均调整 θ
+type eloEngine struct {
+	kFactor float64
+	maxStep float64
+}
+
+func newEloEngine(cfg *config.DifficultyConfig) (Engine, error) {
+	e := &eloEngine{kFactor: cfg.KFactor, maxStep: cfg.MaxStep}
+	if e.kFactor <= 0 {
+		e.kFactor = defaultKFactor
+	}
+	if e.maxStep <= 0 {
+		e.maxStep = defaultMaxStep
+	}
+	return e, nil
+}
+
+// Name 实现 Engine 接口
+func (e *eloEngine) Name() string { return "elo" }
+
+// Adjust 实现 Engine 接口
+func (e *eloEngine) Adjust(_ context.Context, in *Input) (*Decision, error) {
+	n := in.Window.Size()
+	if n == 0 {
+		return nil, nil
+	}
+
+	theta := in.CurrentDifficulty
+	offset := math.Log(targetAccuracy / (1 - targetAccuracy))
+
+	residuals := make([]float64, n)
+	weights := make([]float64, n)
+	var weightSum, residualSum, expectedSum float64
+	for i, o := range in.Window.Observations {
+		expected := 1 / (1 + math.Exp(-(discrimination*(theta-o.Difficulty) + offset)))
+		residuals[i] = score(o) - expected
+		weights[i] = math.Pow(recencyDecay, float64(n-1-i))
+
+		weightSum += weights[i]
+		residualSum += weights[i] * residuals[i]
+		expectedSum += expected
+	}
+
+	delta := e.kFactor * residualSum / weightSum
+	delta = math.Max(-e.maxStep, math.Min(e.maxStep, delta))
+	newDifficulty := clampDifficulty(theta + delta)
+
+	// 置信度：样本量越大、窗口内表现方向越一致，置信度越高
+	var agreeWeight float64
+	for i, r := range residuals {
+		if r*delta > 0 {
+			agreeWeight += weights[i]
+		}
+	}
+	agreement := agreeWeight / weightSum
+	confidence := math.Min(0.99, float64(n)/float64(n+3)*(0.5+0.5*agreement))
+
+	expectedAccuracy := expectedSum / float64(n)
+	return &Decision{
+		NewDifficulty: newDifficulty,
+		Confidence:    round2(confidence),
+		Reason:        eloReason(in, expectedAccuracy, newDifficulty),
+		Details: models.JSONB{
+			"expected_accuracy": round2(expectedAccuracy),
+			"weighted_residual": round2(residualSum / weightSum),
+			"k_factor":          e.kFactor,
+		},
+	}, nil
+}
+
+// score 计算单次答题的实际得分
+func score(o Observation) float64 {
+	switch {
+	case !o.IsCorrect:
+		return 0
+	case o.HintUsedCount > 0:
+		return hintedScore
+	default:
+		return 1
+	}
+}
+
+// eloReason 生成可读的调整说明
+func eloReason(in *Input, expectedAccuracy, newDifficulty float64) string {
+	w := in.Window
+	summary := fmt.Sprintf("最近%d题正确率%.0f%%", w.Size(), w.Accuracy()*100)
+	if hints := w.HintCount(); hints > 0 {
+		summary += fmt.Sprintf("（其中%d题使用了提示）", hints)
+	}
+
+	switch {
+	case newDifficulty > in.CurrentDifficulty:
+		return fmt.Sprintf("%s，高于当前难度下的预期正确率%.0f%%，难度由%.2f上调至%.2f",
+			summary, expectedAccuracy*100, in.CurrentDifficulty, newDifficulty)
+	case newDifficulty < in.CurrentDifficulty:
+		return fmt.Sprintf("%s，低于当前难度下的预期正确率%.0f%%，难度由%.2f下调至%.2f",
+			summary, expectedAccuracy*100, in.CurrentDifficulty, newDifficulty)
+	default:
+		return fmt.Sprintf("%s，与当前难度下的预期正确率%.0f%%相符，难度保持%.2f",
+			summary, expectedAccuracy*100, in.CurrentDifficulty)
+	}
+}
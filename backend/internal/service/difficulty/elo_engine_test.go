@@ -0,0 +1,140 @@
+/*
+File: elo_engine_test.go
+Author: lxp
+Description: Elo/IRT 难度引擎与答题窗口的单元测试
+*/
+package difficulty
+
+import (
+	"context"
+	"testing"
+	"time"
+	"zhixue-backend/internal/config"
+	"zhixue-backend/models"
+)
+
+// window 按从旧到新的顺序构造答题窗口，所有题目难度为 difficulty
+func window(difficulty float64, results ...bool) PerformanceWindow {
+	observations := make([]Observation, len(results))
+	for i, correct := range results {
+		observations[i] = Observation{Difficulty: difficulty, IsCorrect: correct, ResponseTime: 10}
+	}
+	return PerformanceWindow{Observations: observations}
+}
+
+func adjust(t *testing.T, cfg *config.DifficultyConfig, in *Input) *Decision {
+	t.Helper()
+	engine, err := newEloEngine(cfg)
+	if err != nil {
+		t.Fatalf("newEloEngine error: %v", err)
+	}
+	decision, err := engine.Adjust(context.Background(), in)
+	if err != nil {
+		t.Fatalf("Adjust error: %v", err)
+	}
+	return decision
+}
+
+func TestEloEngineDirection(t *testing.T) {
+	tests := []struct {
+		name    string
+		current float64
+		window  PerformanceWindow
+		want    func(next float64) bool
+	}{
+		{"全部答对上调", 2.0, window(2.0, true, true, true, true, true), func(v float64) bool { return v > 2.0 }},
+		{"全部答错下调", 2.0, window(2.0, false, false, false, false, false), func(v float64) bool { return v < 2.0 }},
+		{"答对更难的题上调", 2.0, window(3.0, true, true, true, true, true), func(v float64) bool { return v > 2.0 }},
+		{"上限", 5.0, window(5.0, true, true, true, true, true), func(v float64) bool { return v == MaxDifficulty }},
+		{"下限", 1.0, window(1.0, false, false, false, false, false), func(v float64) bool { return v == MinDifficulty }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := adjust(t, &config.DifficultyConfig{}, &Input{CurrentDifficulty: tt.current, Window: tt.window})
+			if d == nil {
+				t.Fatal("Adjust returned nil decision")
+			}
+			if !tt.want(d.NewDifficulty) {
+				t.Errorf("NewDifficulty = %.2f from %.2f", d.NewDifficulty, tt.current)
+			}
+			if d.Confidence < 0 || d.Confidence > 1 {
+				t.Errorf("Confidence = %.2f, want within [0,1]", d.Confidence)
+			}
+		})
+	}
+}
+
+func TestEloEngineMaxStep(t *testing.T) {
+	d := adjust(t, &config.DifficultyConfig{KFactor: 10, MaxStep: 0.2},
+		&Input{CurrentDifficulty: 2.0, Window: window(4.0, true, true, true, true, true)})
+	if d.NewDifficulty != 2.2 {
+		t.Errorf("NewDifficulty = %.2f, want 2.20 (limited by max_step)", d.NewDifficulty)
+	}
+}
+
+func TestEloEngineEmptyWindow(t *testing.T) {
+	if d := adjust(t, &config.DifficultyConfig{}, &Input{CurrentDifficulty: 2.0}); d != nil {
+		t.Errorf("Adjust on empty window = %+v, want nil", d)
+	}
+}
+
+// 使用提示后答对只计一半得分，上调幅度应小于直接答对
+func TestEloEngineHintedAnswers(t *testing.T) {
+	direct := adjust(t, &config.DifficultyConfig{},
+		&Input{CurrentDifficulty: 2.0, Window: window(2.0, true, true, true, true, true)})
+
+	hinted := window(2.0, true, true, true, true, true)
+	for i := range hinted.Observations {
+		hinted.Observations[i].HintUsedCount = 1
+	}
+	withHints := adjust(t, &config.DifficultyConfig{}, &Input{CurrentDifficulty: 2.0, Window: hinted})
+
+	if withHints.NewDifficulty >= direct.NewDifficulty {
+		t.Errorf("hinted NewDifficulty = %.2f, want below direct %.2f", withHints.NewDifficulty, direct.NewDifficulty)
+	}
+}
+
+// 越近的答题权重越高：同样的对错数量，最近答对时应比最近答错时调得更高
+func TestEloEngineRecencyWeighting(t *testing.T) {
+	recentCorrect := adjust(t, &config.DifficultyConfig{},
+		&Input{CurrentDifficulty: 2.0, Window: window(2.0, false, false, false, true, true, true)})
+	recentWrong := adjust(t, &config.DifficultyConfig{},
+		&Input{CurrentDifficulty: 2.0, Window: window(2.0, true, true, true, false, false, false)})
+
+	if recentCorrect.NewDifficulty <= recentWrong.NewDifficulty {
+		t.Errorf("recent correct = %.2f, recent wrong = %.2f, want recent correct higher",
+			recentCorrect.NewDifficulty, recentWrong.NewDifficulty)
+	}
+}
+
+func TestNewPerformanceWindow(t *testing.T) {
+	now := time.Now()
+	records := []models.AnswerRecord{
+		{QuestionID: 3, IsCorrect: true, DifficultyAtTime: 3, ResponseTime: 30, CreatedAt: now},
+		{QuestionID: 2, IsCorrect: false, DifficultyAtTime: 2, ResponseTime: 20, HintUsedCount: 1, CreatedAt: now.Add(-time.Minute)},
+		{QuestionID: 1, IsCorrect: true, DifficultyAtTime: 1, ResponseTime: 10, CreatedAt: now.Add(-2 * time.Minute)},
+	}
+	w := NewPerformanceWindow(records)
+
+	for i, want := range []int64{1, 2, 3} {
+		if got := w.Observations[i].QuestionID; got != want {
+			t.Errorf("Observations[%d].QuestionID = %d, want %d (oldest first)", i, got, want)
+		}
+	}
+	if got := w.Size(); got != 3 {
+		t.Errorf("Size = %d, want 3", got)
+	}
+	if got := w.Accuracy(); got < 0.66 || got > 0.67 {
+		t.Errorf("Accuracy = %.4f, want 2/3", got)
+	}
+	if got := w.HintCount(); got != 1 {
+		t.Errorf("HintCount = %d, want 1", got)
+	}
+	if got := w.AvgDifficulty(); got != 2 {
+		t.Errorf("AvgDifficulty = %.2f, want 2", got)
+	}
+	if got := w.AvgResponseTime(); got != 20 {
+		t.Errorf("AvgResponseTime = %.2f, want 20", got)
+	}
+}
@@ -0,0 +1,87 @@
+/*
+File: engine.go
+Author: lxp
+Description: 可插拔的难度调整引擎接口与注册表
+*/
+package difficulty
+
+import (
+	"context"
+	"fmt"
+	"zhixue-backend/internal/config"
+	"zhixue-backend/models"
+)
+
+// 难度取值范围，与题目难度 (1.0-5.0) 保持一致
+const (
+	MinDifficulty = 1.0
+	MaxDifficulty = 5.0
+)
+
+// 触发难度调整的事件，与数据库 trigger_event 枚举保持一致
+const (
+	TriggerAnswerCorrect = "answer_correct"
+	TriggerAnswerWrong   = "answer_wrong"
+	TriggerTimeBased     = "time_based"
+	TriggerManual        = "manual"
+)
+
+// Input 是引擎计算所需的输入
+type Input struct {
+	UserID            int64
+	CurrentDifficulty float64
+	Window            PerformanceWindow
+	Trigger           string
+}
+
+// Decision 是引擎给出的调整结果
+type Decision struct {
+	NewDifficulty float64
+	Confidence    float64      // 0-1
+	Reason        string       // 面向用户和运营人员的可读说明
+	Details       models.JSONB // 引擎自身的中间结果，随窗口快照一起保存
+}
+
+// Engine 定义难度调整引擎的接口
+// 返回 nil Decision 表示引擎认为当前无需调整
+type Engine interface {
+	Name() string
+	Adjust(ctx context.Context, in *Input) (*Decision, error)
+}
+
+// Factory 根据配置创建引擎实例
+type Factory func(cfg *config.DifficultyConfig) (Engine, error)
+
+// engines 保存已注册的引擎
+var engines = map[string]Factory{
+	"elo": newEloEngine,
+}
+
+// RegisterEngine 注册一个新的难度引擎，同名引擎会被覆盖
+func RegisterEngine(name string, factory Factory) {
+	engines[name] = factory
+}
+
+// NewEngine 按配置中的名称创建引擎，未配置时使用内置的 elo 引擎
+func NewEngine(cfg *config.DifficultyConfig) (Engine, error) {
+	name := cfg.Engine
+	if name == "" {
+		name = "elo"
+	}
+	factory, ok := engines[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown difficulty engine: %s", name)
+	}
+	return factory(cfg)
+}
+
+// clampDifficulty 将难度限制在合法范围内并保留两位小数
+func clampDifficulty(v float64) float64 {
+	if v < MinDifficulty {
+		v = MinDifficulty
+	}
+	if v > MaxDifficulty {
+		v = MaxDifficulty
+	}
+	return round2(v)
+}
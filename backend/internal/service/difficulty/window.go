@@ -0,0 +1,129 @@
+/*
+File: window.go
+Author: lxp
+Description: 难度评估使用的滑动答题窗口
+*/
+package difficulty
+
+import (
+	"math"
+	"time"
+	"zhixue-backend/models"
+)
+
+// Observation 是窗口中的一次答题
+type Observation struct {
+	QuestionID    int64
+	Difficulty    float64 // 答题时的题目难度
+	IsCorrect     bool
+	ResponseTime  int // 秒
+	HintUsedCount int
+	AnsweredAt    time.Time
+}
+
+// PerformanceWindow 是用户最近若干次答题组成的滑动窗口，按答题时间从旧到新排列
+type PerformanceWindow struct {
+	Observations []Observation
+}
+
+// NewPerformanceWindow 由答题记录构造窗口，records 需按答题时间倒序 (最新的在前)
+func NewPerformanceWindow(records []models.AnswerRecord) PerformanceWindow {
+	observations := make([]Observation, len(records))
+	for i, record := range records {
+		observations[len(records)-1-i] = Observation{
+			QuestionID:    record.QuestionID,
+			Difficulty:    record.DifficultyAtTime,
+			IsCorrect:     record.IsCorrect,
+			ResponseTime:  record.ResponseTime,
+			HintUsedCount: record.HintUsedCount,
+			AnsweredAt:    record.CreatedAt,
+		}
+	}
+	return PerformanceWindow{Observations: observations}
+}
+
+// Size 返回窗口内的答题数
+func (w PerformanceWindow) Size() int {
+	return len(w.Observations)
+}
+
+// Accuracy 返回窗口内的正确率
+func (w PerformanceWindow) Accuracy() float64 {
+	return w.ratio(func(o Observation) bool { return o.IsCorrect })
+}
+
+// HintRate 返回窗口内使用过提示的答题比例
+func (w PerformanceWindow) HintRate() float64 {
+	return w.ratio(func(o Observation) bool { return o.HintUsedCount > 0 })
+}
+
+// HintCount 返回窗口内使用过提示的答题数
+func (w PerformanceWindow) HintCount() int {
+	count := 0
+	for _, o := range w.Observations {
+		if o.HintUsedCount > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// AvgDifficulty 返回窗口内题目的平均难度
+func (w PerformanceWindow) AvgDifficulty() float64 {
+	return w.mean(func(o Observation) float64 { return o.Difficulty })
+}
+
+// AvgResponseTime 返回窗口内的平均答题用时 (秒)
+func (w PerformanceWindow) AvgResponseTime() float64 {
+	return w.mean(func(o Observation) float64 { return float64(o.ResponseTime) })
+}
+
+// ToJSONB 生成写入 difficulty_adjustments.performance_window 的快照
+func (w PerformanceWindow) ToJSONB() models.JSONB {
+	questionIDs := make([]int64, 0, len(w.Observations))
+	for _, o := range w.Observations {
+		questionIDs = append(questionIDs, o.QuestionID)
+	}
+
+	snapshot := models.JSONB{
+		"size":              w.Size(),
+		"accuracy":          round2(w.Accuracy()),
+		"hint_rate":         round2(w.HintRate()),
+		"avg_difficulty":    round2(w.AvgDifficulty()),
+		"avg_response_time": round2(w.AvgResponseTime()),
+		"question_ids":      questionIDs,
+	}
+	if w.Size() > 0 {
+		snapshot["from"] = w.Observations[0].AnsweredAt
+		snapshot["to"] = w.Observations[w.Size()-1].AnsweredAt
+	}
+	return snapshot
+}
+
+func (w PerformanceWindow) ratio(pred func(Observation) bool) float64 {
+	if len(w.Observations) == 0 {
+		return 0
+	}
+	count := 0
+	for _, o := range w.Observations {
+		if pred(o) {
+			count++
+		}
+	}
+	return float64(count) / float64(len(w.Observations))
+}
+
+func (w PerformanceWindow) mean(value func(Observation) float64) float64 {
+	if len(w.Observations) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, o := range w.Observations {
+		sum += value(o)
+	}
+	return sum / float64(len(w.Observations))
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,406 @@
+/*
+File: exam_service.go
+Author: lxp
+Description: 限时考试业务逻辑：组卷开考、查看试卷与成绩单、考试记录
+*/
+package exam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/config"
+	"zhixue-backend/internal/event"
+	"zhixue-backend/internal/repository/exam"
+	"zhixue-backend/internal/repository/question"
+	"zhixue-backend/internal/repository/user"
+	learning_service "zhixue-backend/internal/service/learning"
+	question_service "zhixue-backend/internal/service/question"
+	"zhixue-backend/logger"
+	"zhixue-backend/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidQuestions  = errors.New("questions must be unique and published")
+	ErrNoQuestions       = errors.New("no questions available for the exam")
+	ErrExamFinished      = errors.New("exam has already been submitted")
+	ErrExamTimeUp        = errors.New("exam time is up")
+	ErrQuestionNotInExam = errors.New("question is not part of the exam")
+	ErrQuestionAnswered  = errors.New("question already answered in this exam")
+)
+
+// 未填写或未配置时使用的默认值
+const (
+	defaultQuestionCount = 10
+	defaultTimeLimit     = 30 * time.Minute
+	defaultSweepInterval = time.Minute
+	pickTolerance        = 0.5 // 自动组卷时与学生当前难度的最大偏差
+)
+
+// Service 定义考试服务的接口
+type Service interface {
+	StartExam(userID int64, req *dto.StartExamRequest) (*dto.ExamResponse, error)
+	ListExams(userID int64, query *dto.PageQuery) (*dto.PageResponse, error)
+	GetExam(userID int64, sessionID string) (*dto.ExamResponse, error)
+	SubmitExam(userID int64, sessionID string) (*dto.ExamResponse, error)
+	CheckAnswer(userID int64, sessionID string, questionID int64) (question_service.Feedback, error)
+	SweepDueExams() (int, error)
+	StartExpirySweeper(ctx context.Context)
+}
+
+// examService 实现了Service接口
+type examService struct {
+	repo      exam.Repository
+	questions question.Repository
+	users     user.Repository
+	learning  learning_service.Service
+	events    *event.Bus
+	config    *config.LearningConfig
+}
+
+// NewExamService 创建一个新的考试服务实例
+func NewExamService(repo exam.Repository, questions question.Repository, users user.Repository,
+	learning learning_service.Service, events *event.Bus, config *config.LearningConfig) Service {
+	return &examService{repo: repo, questions: questions, users: users, learning: learning, events: events, config: config}
+}
+
+// StartExam 组卷并开始考试，每场考试对应一个 test 类型的学习会话
+// 题序在开考时固定；需要打乱时使用随机种子打乱并记录该种子，便于复现
+func (s *examService) StartExam(userID int64, req *dto.StartExamRequest) (*dto.ExamResponse, error) {
+	ids, err := s.pickQuestions(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var seed *int64
+	if req.Shuffle {
+		v := rand.Int63()
+		seed = &v
+		rand.New(rand.NewSource(v)).Shuffle(len(ids), func(i, j int) {
+			ids[i], ids[j] = ids[j], ids[i]
+		})
+	}
+
+	session, err := s.learning.StartSession(userID, &dto.StartSessionRequest{SessionType: learning_service.SessionTypeTest})
+	if err != nil {
+		return nil, err // 包括家长管控导致的 ErrSessionTypeBlocked 与 ErrDailyLimitReached
+	}
+
+	limit := s.timeLimit(req.TimeLimitMinutes)
+	e := &models.ExamSession{
+		SessionID:        session.SessionID,
+		UserID:           userID,
+		TimeLimitSeconds: int(limit.Seconds()),
+		StartedAt:        session.StartTime,
+		DeadlineAt:       session.StartTime.Add(limit),
+		ShuffleSeed:      seed,
+		Status:           exam.StatusInProgress,
+		QuestionCount:    len(ids),
+	}
+	items := make([]models.ExamQuestion, 0, len(ids))
+	for i, id := range ids {
+		items = append(items, models.ExamQuestion{SessionID: e.SessionID, Position: i + 1, QuestionID: id})
+	}
+	if err := s.repo.Create(e, items); err != nil {
+		_, _ = s.learning.InterruptSession(userID, session.SessionID)
+		return nil, fmt.Errorf("failed to create exam: %w", err)
+	}
+
+	return s.detail(e, items)
+}
+
+// ListExams 分页获取我的考试记录，先自动交卷已超时的考试
+func (s *examService) ListExams(userID int64, query *dto.PageQuery) (*dto.PageResponse, error) {
+	query.Normalize()
+
+	if _, err := s.closeDue(userID); err != nil {
+		return nil, err
+	}
+	exams, total, err := s.repo.List(userID, query.Page, query.PageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exams: %w", err)
+	}
+
+	now := time.Now()
+	items := make([]dto.ExamResponse, 0, len(exams))
+	for i := range exams {
+		items = append(items, *toExamResponse(&exams[i], now))
+	}
+	return dto.NewPageResponse(items, query.Page, query.PageSize, total), nil
+}
+
+// GetExam 获取考试详情：进行中时为不含答案的试卷，交卷后为成绩单
+func (s *examService) GetExam(userID int64, sessionID string) (*dto.ExamResponse, error) {
+	if _, err := s.closeDue(userID); err != nil {
+		return nil, err
+	}
+	e, err := s.find(userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.repo.ListQuestions(e.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	return s.detail(e, items)
+}
+
+// SubmitExam 交卷并返回成绩单
+func (s *examService) SubmitExam(userID int64, sessionID string) (*dto.ExamResponse, error) {
+	if _, err := s.closeDue(userID); err != nil {
+		return nil, err
+	}
+	e, err := s.find(userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if e.Status != exam.StatusInProgress {
+		return nil, ErrExamFinished
+	}
+
+	submittedAt := time.Now()
+	if submittedAt.After(e.DeadlineAt) {
+		submittedAt = e.DeadlineAt
+	}
+	if err := s.finalize(e, exam.StatusSubmitted, submittedAt); err != nil {
+		return nil, err
+	}
+
+	items, err := s.repo.ListQuestions(e.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	return s.detail(e, items)
+}
+
+// SweepDueExams 自动交卷所有已超时或考试会话已结束的考试，返回交卷的考试数
+func (s *examService) SweepDueExams() (int, error) {
+	return s.closeDue(0)
+}
+
+// StartExpirySweeper 启动后台任务，定期自动交卷超时的考试，直到 ctx 被取消
+func (s *examService) StartExpirySweeper(ctx context.Context) {
+	interval := s.config.SweepInterval
+	if interval <= 0 {
+		interval = defaultSweepInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				count, err := s.SweepDueExams()
+				if err != nil {
+					logger.LogError("exam", "sweep_due_exams", err, nil)
+					continue
+				}
+				if count > 0 {
+					logger.Logger.Info("超时考试已自动交卷", zap.Int("count", count))
+				}
+			}
+		}
+	}()
+
+	logger.Logger.Info("考试超时交卷任务已启动", zap.Duration("interval", interval))
+}
+
+// find 获取学生本人的考试，不是本人的考试按不存在处理
+func (s *examService) find(userID int64, sessionID string) (*models.ExamSession, error) {
+	e, err := s.repo.FindBySession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if e.UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return e, nil
+}
+
+// pickQuestions 确定试卷题目：指定题目时校验后按给定顺序使用，否则按学生当前难度自动选题
+// 符合难度的题目不足时，用同一范围内的其他题目补足
+func (s *examService) pickQuestions(userID int64, req *dto.StartExamRequest) ([]int64, error) {
+	if len(req.QuestionIDs) > 0 {
+		seen := make(map[int64]bool, len(req.QuestionIDs))
+		for _, id := range req.QuestionIDs {
+			if seen[id] {
+				return nil, ErrInvalidQuestions
+			}
+			seen[id] = true
+		}
+		found, err := s.questions.FindPublishedByIDs(req.QuestionIDs)
+		if err != nil {
+			return nil, err
+		}
+		if len(found) != len(req.QuestionIDs) {
+			return nil, ErrInvalidQuestions
+		}
+		return append([]int64(nil), req.QuestionIDs...), nil
+	}
+
+	count := req.QuestionCount
+	if count <= 0 {
+		count = defaultQuestionCount
+	}
+	u, err := s.users.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := s.users.FindProfileByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	filter := question.ListFilter{
+		Page:             1,
+		PageSize:         count,
+		KnowledgePointID: req.KnowledgePointID,
+		GradeLevel:       req.GradeLevel,
+	}
+	if filter.GradeLevel == nil {
+		filter.GradeLevel = &u.GradeLevel
+	}
+
+	recommended, _, err := s.questions.Recommend(question.RecommendFilter{
+		ListFilter:       filter,
+		UserID:           userID,
+		TargetDifficulty: profile.CurrentDifficulty,
+		Tolerance:        pickTolerance,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to pick exam questions: %w", err)
+	}
+	ids := make([]int64, 0, count)
+	seen := make(map[int64]bool, count)
+	for _, q := range recommended {
+		ids = append(ids, q.ID)
+		seen[q.ID] = true
+	}
+
+	if len(ids) < count {
+		filter.PageSize = count + len(ids)
+		rest, _, err := s.questions.List(filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to pick exam questions: %w", err)
+		}
+		for _, q := range rest {
+			if len(ids) >= count {
+				break
+			}
+			if !seen[q.ID] {
+				ids = append(ids, q.ID)
+				seen[q.ID] = true
+			}
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, ErrNoQuestions
+	}
+	return ids, nil
+}
+
+// timeLimit 返回考试时长，未指定时使用配置的默认时长
+func (s *examService) timeLimit(minutes int) time.Duration {
+	if minutes > 0 {
+		return time.Duration(minutes) * time.Minute
+	}
+	if s.config.ExamTimeLimit > 0 {
+		return s.config.ExamTimeLimit
+	}
+	return defaultTimeLimit
+}
+
+// detail 构造考试详情：进行中只标记是否已答，交卷后附带作答结果与答案解析
+func (s *examService) detail(e *models.ExamSession, items []models.ExamQuestion) (*dto.ExamResponse, error) {
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.QuestionID)
+	}
+	questions, err := s.questions.FindByIDs(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load exam questions: %w", err)
+	}
+	byID := make(map[int64]*models.Question, len(questions))
+	for i := range questions {
+		byID[questions[i].ID] = &questions[i]
+	}
+	answers, err := s.answerMap(e)
+	if err != nil {
+		return nil, err
+	}
+
+	finished := e.Status != exam.StatusInProgress
+	resp := toExamResponse(e, time.Now())
+	resp.Questions = make([]dto.ExamQuestionResponse, 0, len(items))
+	for _, item := range items {
+		q, ok := byID[item.QuestionID]
+		if !ok {
+			continue
+		}
+		ans, answered := answers[q.ID]
+		qr := dto.ExamQuestionResponse{
+			Position:     item.Position,
+			QuestionID:   q.ID,
+			Title:        q.Title,
+			Content:      q.Content,
+			QuestionType: q.QuestionType,
+			Difficulty:   q.Difficulty,
+			Choices:      q.Choices,
+			Answered:     answered,
+		}
+		if finished {
+			if answered {
+				qr.MyAnswer = &ans.UserAnswer
+				qr.IsCorrect = &ans.IsCorrect
+			}
+			qr.CorrectAnswer = &q.CorrectAnswer
+			qr.AnswerAnalysis = &q.AnswerAnalysis
+		}
+		resp.Questions = append(resp.Questions, qr)
+	}
+	if !finished {
+		resp.AnsweredCount = len(answers)
+	}
+	return resp, nil
+}
+
+func toExamResponse(e *models.ExamSession, now time.Time) *dto.ExamResponse {
+	remaining := 0
+	if e.Status == exam.StatusInProgress && now.Before(e.DeadlineAt) {
+		remaining = int(math.Ceil(e.DeadlineAt.Sub(now).Seconds()))
+	}
+	return &dto.ExamResponse{
+		SessionID:        e.SessionID,
+		Status:           e.Status,
+		TimeLimitSeconds: e.TimeLimitSeconds,
+		StartedAt:        e.StartedAt,
+		DeadlineAt:       e.DeadlineAt,
+		RemainingSeconds: remaining,
+		Shuffled:         e.ShuffleSeed != nil,
+		ShuffleSeed:      e.ShuffleSeed,
+		SubmittedAt:      e.SubmittedAt,
+		QuestionCount:    e.QuestionCount,
+		AnsweredCount:    e.AnsweredCount,
+		CorrectCount:     e.CorrectCount,
+		Score:            e.Score,
+	}
+}
+
+// percentage 计算百分比并保留两位小数，分母为0时返回0
+func percentage(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)*10000/float64(total)) / 100
+}
@@ -0,0 +1,176 @@
+/*
+File: grading.go
+Author: lxp
+Description: 考试判分与交卷：超时或考试会话结束后自动交卷，以及考试期间的答题校验
+*/
+package exam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"zhixue-backend/internal/event"
+	"zhixue-backend/internal/repository/exam"
+	learning_service "zhixue-backend/internal/service/learning"
+	question_service "zhixue-backend/internal/service/question"
+	"zhixue-backend/logger"
+	"zhixue-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// CheckAnswer 在答题前校验考试规则，并决定向学生返回的内容 (实现 question_service.AnswerPolicy)
+//   - 在考试会话中答题：考试未交卷且未超时、题目属于试卷且未答过，交卷前不返回对错与答案
+//   - 其他答题：题目在学生进行中的考试里时同样不返回对错与答案，避免在练习中试出答案
+func (s *examService) CheckAnswer(userID int64, sessionID string, questionID int64) (question_service.Feedback, error) {
+	if sessionID != "" {
+		e, err := s.repo.FindBySession(sessionID)
+		if err == nil && e.UserID == userID {
+			return question_service.FeedbackNone, s.checkExamAnswer(e, questionID)
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return question_service.FeedbackNone, err
+		}
+	}
+
+	open, err := s.repo.HasOpenExamWithQuestion(userID, questionID)
+	if err != nil {
+		return question_service.FeedbackNone, err
+	}
+	if open {
+		return question_service.FeedbackNone, nil
+	}
+	return question_service.FeedbackFull, nil
+}
+
+// checkExamAnswer 校验考试中的一次答题，截止时间之后的答题被拒绝并自动交卷
+func (s *examService) checkExamAnswer(e *models.ExamSession, questionID int64) error {
+	if e.Status != exam.StatusInProgress {
+		return ErrExamFinished
+	}
+	if !time.Now().Before(e.DeadlineAt) {
+		if _, err := s.closeDue(e.UserID); err != nil {
+			return err
+		}
+		return ErrExamTimeUp
+	}
+
+	items, err := s.repo.ListQuestions(e.SessionID)
+	if err != nil {
+		return err
+	}
+	included := false
+	for _, item := range items {
+		if item.QuestionID == questionID {
+			included = true
+			break
+		}
+	}
+	if !included {
+		return ErrQuestionNotInExam
+	}
+
+	answers, err := s.answerMap(e)
+	if err != nil {
+		return err
+	}
+	if _, ok := answers[questionID]; ok {
+		return ErrQuestionAnswered
+	}
+	return nil
+}
+
+// finalize 按考试中每道题的首次答题判分并交卷，submittedAt 之后的答题不计分
+func (s *examService) finalize(e *models.ExamSession, status string, submittedAt time.Time) error {
+	items, err := s.repo.ListQuestions(e.SessionID)
+	if err != nil {
+		return err
+	}
+	e.SubmittedAt = &submittedAt
+	answers, err := s.answerMap(e)
+	if err != nil {
+		return err
+	}
+
+	e.AnsweredCount, e.CorrectCount = 0, 0
+	for _, item := range items {
+		ans, ok := answers[item.QuestionID]
+		if !ok {
+			continue
+		}
+		e.AnsweredCount++
+		if ans.IsCorrect {
+			e.CorrectCount++
+		}
+	}
+	e.Status = status
+	e.Score = percentage(e.CorrectCount, len(items))
+
+	ok, err := s.repo.Finish(e)
+	if err != nil {
+		return fmt.Errorf("failed to finish exam: %w", err)
+	}
+	if !ok {
+		return ErrExamFinished
+	}
+	s.events.Publish(context.Background(), event.ExamFinished{
+		UserID:        e.UserID,
+		SessionID:     e.SessionID,
+		Status:        e.Status,
+		QuestionCount: len(items),
+		CorrectCount:  e.CorrectCount,
+		Score:         e.Score,
+		SubmittedAt:   submittedAt,
+	})
+
+	// 结束考试所用的学习会话，会话已结束时忽略
+	_, err = s.learning.FinishSession(e.UserID, e.SessionID)
+	if err != nil && !errors.Is(err, learning_service.ErrInvalidTransition) && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.LogError("exam", "finish_session", err, map[string]interface{}{"session_id": e.SessionID})
+	}
+	return nil
+}
+
+// closeDue 自动交卷已超时或考试会话已提前结束的考试，userID 为0时处理全部学生
+// 交卷时间取截止时间与会话结束时间中较早者，只计算此前的答题
+func (s *examService) closeDue(userID int64) (int, error) {
+	exams, err := s.repo.ListDue(userID, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to list due exams: %w", err)
+	}
+
+	closed := 0
+	for i := range exams {
+		due := &exams[i]
+		submittedAt := due.DeadlineAt
+		if due.SessionEndedAt != nil && due.SessionEndedAt.Before(submittedAt) {
+			submittedAt = *due.SessionEndedAt
+		}
+		err := s.finalize(&due.ExamSession, exam.StatusExpired, submittedAt)
+		if errors.Is(err, ErrExamFinished) {
+			continue
+		}
+		if err != nil {
+			return closed, err
+		}
+		closed++
+	}
+	return closed, nil
+}
+
+// answerMap 获取考试中每道题的首次答题记录，以题目ID为键；已交卷时不含交卷之后的答题
+func (s *examService) answerMap(e *models.ExamSession) (map[int64]exam.Answer, error) {
+	answers, err := s.repo.SessionAnswers(e.UserID, e.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load exam answers: %w", err)
+	}
+	result := make(map[int64]exam.Answer, len(answers))
+	for _, ans := range answers {
+		if e.SubmittedAt != nil && ans.CreatedAt.After(*e.SubmittedAt) {
+			continue
+		}
+		result[ans.QuestionID] = ans
+	}
+	return result, nil
+}
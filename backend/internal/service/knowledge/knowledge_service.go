@@ -0,0 +1,233 @@
+/*
+File: knowledge_service.go
+Author: lxp
+Description: 知识点业务逻辑：知识点树、子树与面包屑，以及先修关系的维护
+*/
+package knowledge
+
+import (
+	"errors"
+	"fmt"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/repository/knowledge"
+	"zhixue-backend/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPrerequisiteNotFound = errors.New("prerequisite knowledge point not found")
+	ErrPrerequisiteExists   = knowledge.ErrPrerequisiteExists
+	ErrPrerequisiteCycle    = knowledge.ErrPrerequisiteCycle
+)
+
+// Service 定义知识点服务的接口
+type Service interface {
+	GetTree(gradeLevel *int) ([]dto.KnowledgePointNode, error)
+	GetSubtree(id int64) (*dto.KnowledgePointNode, error)
+	GetBreadcrumbs(id int64) ([]dto.KnowledgePointBrief, error)
+	GetPrerequisites(id int64) (*dto.KnowledgePointPrerequisitesResponse, error)
+
+	// 后台管理
+	AddPrerequisite(id int64, req *dto.AddPrerequisiteRequest) (*dto.KnowledgePointPrerequisitesResponse, error)
+	RemovePrerequisite(id, prerequisiteID int64) error
+}
+
+// knowledgeService 实现了Service接口
+type knowledgeService struct {
+	repo knowledge.Repository
+}
+
+// NewKnowledgeService 创建一个新的知识点服务实例
+func NewKnowledgeService(repo knowledge.Repository) Service {
+	return &knowledgeService{repo: repo}
+}
+
+// GetTree 获取启用状态的知识点树，可按年级筛选
+// 父知识点未启用或不在筛选范围内的知识点作为根节点
+func (s *knowledgeService) GetTree(gradeLevel *int) ([]dto.KnowledgePointNode, error) {
+	points, err := s.repo.ListActive(gradeLevel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list knowledge points: %w", err)
+	}
+	h := newHierarchy(points)
+
+	roots := make([]dto.KnowledgePointNode, 0)
+	for i := range points {
+		p := &points[i]
+		if p.ParentID == nil || h.byID[*p.ParentID] == nil {
+			roots = append(roots, h.node(p, map[int64]bool{}))
+		}
+	}
+	return roots, nil
+}
+
+// GetSubtree 获取以某个知识点为根的子树
+func (s *knowledgeService) GetSubtree(id int64) (*dto.KnowledgePointNode, error) {
+	h, err := s.hierarchy()
+	if err != nil {
+		return nil, err
+	}
+	p, ok := h.byID[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	node := h.node(p, map[int64]bool{})
+	return &node, nil
+}
+
+// GetBreadcrumbs 获取从根知识点到该知识点的路径，遇到未启用的父知识点时停止
+func (s *knowledgeService) GetBreadcrumbs(id int64) ([]dto.KnowledgePointBrief, error) {
+	h, err := s.hierarchy()
+	if err != nil {
+		return nil, err
+	}
+	p, ok := h.byID[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var path []dto.KnowledgePointBrief
+	visited := make(map[int64]bool)
+	for p != nil && !visited[p.ID] {
+		visited[p.ID] = true
+		path = append(path, dto.KnowledgePointBrief{ID: p.ID, Name: p.Name, Code: p.Code})
+		if p.ParentID == nil {
+			break
+		}
+		p = h.byID[*p.ParentID]
+	}
+
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, nil
+}
+
+// GetPrerequisites 获取启用知识点的直接先修与后续知识点
+func (s *knowledgeService) GetPrerequisites(id int64) (*dto.KnowledgePointPrerequisitesResponse, error) {
+	p, err := s.repo.FindActiveByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.prerequisites(p)
+}
+
+// AddPrerequisite 为知识点添加先修知识点，先修关系不能成环
+func (s *knowledgeService) AddPrerequisite(id int64, req *dto.AddPrerequisiteRequest) (*dto.KnowledgePointPrerequisitesResponse, error) {
+	p, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.PrerequisiteID == id {
+		return nil, ErrPrerequisiteCycle
+	}
+	if _, err := s.repo.FindByID(req.PrerequisiteID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPrerequisiteNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.repo.AddPrerequisite(id, req.PrerequisiteID); err != nil {
+		return nil, err // 包括 ErrPrerequisiteExists 与 ErrPrerequisiteCycle
+	}
+	return s.prerequisites(p)
+}
+
+// RemovePrerequisite 删除知识点的一个先修知识点
+func (s *knowledgeService) RemovePrerequisite(id, prerequisiteID int64) error {
+	ok, err := s.repo.RemovePrerequisite(id, prerequisiteID)
+	if err != nil {
+		return fmt.Errorf("failed to remove prerequisite: %w", err)
+	}
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// prerequisites 构造知识点的先修关系响应
+func (s *knowledgeService) prerequisites(p *models.KnowledgePoint) (*dto.KnowledgePointPrerequisitesResponse, error) {
+	prerequisites, err := s.repo.ListPrerequisites(p.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prerequisites: %w", err)
+	}
+	dependents, err := s.repo.ListDependents(p.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dependents: %w", err)
+	}
+	return &dto.KnowledgePointPrerequisitesResponse{
+		KnowledgePoint: toKnowledgePointResponse(p),
+		Prerequisites:  toKnowledgePointResponses(prerequisites),
+		Dependents:     toKnowledgePointResponses(dependents),
+	}, nil
+}
+
+// hierarchy 加载全部启用的知识点并建立层级索引
+func (s *knowledgeService) hierarchy() (*hierarchy, error) {
+	points, err := s.repo.ListActive(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list knowledge points: %w", err)
+	}
+	return newHierarchy(points), nil
+}
+
+// hierarchy 是按父子关系索引的知识点集合，子节点保持加载时的排序
+type hierarchy struct {
+	byID     map[int64]*models.KnowledgePoint
+	children map[int64][]*models.KnowledgePoint
+}
+
+func newHierarchy(points []models.KnowledgePoint) *hierarchy {
+	h := &hierarchy{
+		byID:     make(map[int64]*models.KnowledgePoint, len(points)),
+		children: make(map[int64][]*models.KnowledgePoint),
+	}
+	for i := range points {
+		h.byID[points[i].ID] = &points[i]
+	}
+	for i := range points {
+		p := &points[i]
+		if p.ParentID != nil && h.byID[*p.ParentID] != nil {
+			h.children[*p.ParentID] = append(h.children[*p.ParentID], p)
+		}
+	}
+	return h
+}
+
+// node 递归构造知识点节点，visited 用于在父子关系异常成环时停止递归
+func (h *hierarchy) node(p *models.KnowledgePoint, visited map[int64]bool) dto.KnowledgePointNode {
+	visited[p.ID] = true
+	node := dto.KnowledgePointNode{
+		KnowledgePointResponse: toKnowledgePointResponse(p),
+		Children:               make([]dto.KnowledgePointNode, 0, len(h.children[p.ID])),
+	}
+	for _, child := range h.children[p.ID] {
+		if !visited[child.ID] {
+			node.Children = append(node.Children, h.node(child, visited))
+		}
+	}
+	return node
+}
+
+func toKnowledgePointResponses(points []models.KnowledgePoint) []dto.KnowledgePointResponse {
+	result := make([]dto.KnowledgePointResponse, 0, len(points))
+	for i := range points {
+		result = append(result, toKnowledgePointResponse(&points[i]))
+	}
+	return result
+}
+
+func toKnowledgePointResponse(p *models.KnowledgePoint) dto.KnowledgePointResponse {
+	return dto.KnowledgePointResponse{
+		ID:              p.ID,
+		Name:            p.Name,
+		Code:            p.Code,
+		ParentID:        p.ParentID,
+		GradeLevel:      p.GradeLevel,
+		DifficultyRange: p.DifficultyRange,
+		Description:     p.Description,
+		SortOrder:       p.SortOrder,
+	}
+}
@@ -0,0 +1,432 @@
+/*
+File: learning_service.go
+Author: lxp
+Description: 学习会话服务业务逻辑
+*/
+package learning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/config"
+	"zhixue-backend/internal/event"
+	"zhixue-backend/internal/repository/learning"
+	"zhixue-backend/logger"
+	"zhixue-backend/models"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSessionNotFound      = errors.New("learning session not found")
+	ErrInvalidTransition    = errors.New("invalid session state transition")
+	ErrPauseNotAllowed      = errors.New("session type does not allow pausing")
+	ErrHintsNotAllowed      = errors.New("session type does not allow hints")
+	ErrQuestionLimitReached = errors.New("session question limit reached")
+	ErrSessionTypeBlocked   = errors.New("session type is not allowed")
+	ErrDailyLimitReached    = errors.New("daily study time limit reached")
+	ErrExamInProgress       = errors.New("exam is still in progress")
+	ErrSessionNotActive     = learning.ErrSessionNotActive
+	ErrDuplicateAnswer      = learning.ErrDuplicateAnswer
+)
+
+// 未配置时使用的默认值
+const (
+	defaultIdleTimeout   = 30 * time.Minute
+	defaultSweepInterval = time.Minute
+)
+
+// Service 定义学习会话服务的接口
+type Service interface {
+	StartSession(userID int64, req *dto.StartSessionRequest) (*dto.LearningSessionResponse, error)
+	ListSessions(userID int64, query *dto.ListSessionsQuery) (*dto.PageResponse, error)
+	ListAnswers(userID int64, query *dto.ListAnswersQuery) (*dto.PageResponse, error)
+	GetSession(userID int64, sessionID string) (*dto.LearningSessionResponse, error)
+	PauseSession(userID int64, sessionID string) (*dto.LearningSessionResponse, error)
+	ResumeSession(userID int64, sessionID string) (*dto.LearningSessionResponse, error)
+	FinishSession(userID int64, sessionID string) (*dto.LearningSessionResponse, error)
+	InterruptSession(userID int64, sessionID string) (*dto.LearningSessionResponse, error)
+	ValidateAnswer(userID int64, sessionID string, hintUsedCount int) error
+	SweepIdleSessions() (int64, error)
+	StartIdleSweeper(ctx context.Context)
+}
+
+// SessionPolicy 在开始学习会话前校验额外的限制 (如家长设置的每日时长与可用会话类型)
+// 不允许开始时返回 ErrSessionTypeBlocked 或 ErrDailyLimitReached
+type SessionPolicy interface {
+	CheckStart(userID int64, sessionType string) error
+}
+
+// learningService 实现了Service接口
+type learningService struct {
+	repo   learning.Repository
+	policy SessionPolicy
+	events *event.Bus
+	config *config.LearningConfig
+}
+
+// NewLearningService 创建一个新的学习会话服务实例，policy 为空时不做额外限制
+func NewLearningService(repo learning.Repository, policy SessionPolicy, events *event.Bus, config *config.LearningConfig) Service {
+	return &learningService{repo: repo, policy: policy, events: events, config: config}
+}
+
+// StartSession 开始一个新的学习会话
+// 同一用户同时只保留一个未结束的会话，之前未结束的会话 (考试会话除外) 会被标记为中断
+func (s *learningService) StartSession(userID int64, req *dto.StartSessionRequest) (*dto.LearningSessionResponse, error) {
+	sessionType := req.SessionType
+	if sessionType == "" {
+		sessionType = SessionTypePractice
+	}
+	if s.policy != nil {
+		if err := s.policy.CheckStart(userID, sessionType); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	interrupted, err := s.repo.InterruptUserSessions(userID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to interrupt previous sessions: %w", err)
+	}
+	s.publishEnded(interrupted, now)
+
+	session := &models.LearningSession{
+		SessionID:        uuid.New().String(),
+		UserID:           userID,
+		StartTime:        now,
+		LastActiveAt:     &now,
+		SessionType:      sessionType,
+		CompletionStatus: StatusOngoing,
+	}
+	if err := s.repo.CreateSession(session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return toSessionResponse(session, now), nil
+}
+
+// ListSessions 分页查询用户的学习会话记录
+func (s *learningService) ListSessions(userID int64, query *dto.ListSessionsQuery) (*dto.PageResponse, error) {
+	query.Normalize()
+
+	sessions, total, err := s.repo.ListSessions(userID, learning.SessionFilter{
+		Page:        query.Page,
+		PageSize:    query.PageSize,
+		Status:      query.Status,
+		SessionType: query.SessionType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	items := make([]dto.LearningSessionResponse, 0, len(sessions))
+	for i := range sessions {
+		items = append(items, *toSessionResponse(&sessions[i], now))
+	}
+	return dto.NewPageResponse(items, query.Page, query.PageSize, total), nil
+}
+
+// ListAnswers 分页查询用户的答题记录
+// 用户进行中考试的题目在交卷前不返回对错，也不能按对错筛选进行中的考试会话
+func (s *learningService) ListAnswers(userID int64, query *dto.ListAnswersQuery) (*dto.PageResponse, error) {
+	query.Normalize()
+
+	if query.IsCorrect != nil && query.SessionID != "" {
+		session, err := s.repo.FindSession(userID, query.SessionID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if err == nil && examOpen(session) {
+			return nil, ErrExamInProgress
+		}
+	}
+
+	records, total, err := s.repo.ListAnswers(userID, learning.AnswerFilter{
+		Page:      query.Page,
+		PageSize:  query.PageSize,
+		SessionID: query.SessionID,
+		IsCorrect: query.IsCorrect,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dto.AnswerRecordResponse, 0, len(records))
+	for _, r := range records {
+		var isCorrect *bool
+		if !r.InOpenExam {
+			isCorrect = &r.IsCorrect
+		}
+		items = append(items, dto.AnswerRecordResponse{
+			QuestionID:    r.QuestionID,
+			QuestionTitle: r.QuestionTitle,
+			SessionID:     r.SessionID,
+			UserAnswer:    r.UserAnswer,
+			IsCorrect:     isCorrect,
+			ResponseTime:  r.ResponseTime,
+			HintUsedCount: r.HintUsedCount,
+			Difficulty:    r.DifficultyAtTime,
+			AnsweredAt:    r.CreatedAt,
+		})
+	}
+	return dto.NewPageResponse(items, query.Page, query.PageSize, total), nil
+}
+
+// GetSession 获取单个学习会话详情
+func (s *learningService) GetSession(userID int64, sessionID string) (*dto.LearningSessionResponse, error) {
+	session, err := s.repo.FindSession(userID, sessionID)
+	if err != nil {
+		return nil, err // 错误可能是 gorm.ErrRecordNotFound
+	}
+	return toSessionResponse(session, time.Now()), nil
+}
+
+// PauseSession 暂停进行中的会话
+func (s *learningService) PauseSession(userID int64, sessionID string) (*dto.LearningSessionResponse, error) {
+	return s.transition(userID, sessionID, []string{StatusOngoing}, func(session *models.LearningSession, now time.Time) (map[string]interface{}, error) {
+		if !rulesFor(session.SessionType).AllowPause {
+			return nil, ErrPauseNotAllowed
+		}
+		return map[string]interface{}{
+			"completion_status": StatusPaused,
+			"paused_at":         now,
+			"last_active_at":    now,
+		}, nil
+	})
+}
+
+// ResumeSession 恢复已暂停的会话，并累计本次暂停时长
+func (s *learningService) ResumeSession(userID int64, sessionID string) (*dto.LearningSessionResponse, error) {
+	return s.transition(userID, sessionID, []string{StatusPaused}, func(session *models.LearningSession, now time.Time) (map[string]interface{}, error) {
+		return map[string]interface{}{
+			"completion_status": StatusOngoing,
+			"paused_seconds":    session.PausedSeconds + pausedSince(session, now),
+			"paused_at":         nil,
+			"last_active_at":    now,
+		}, nil
+	})
+}
+
+// FinishSession 正常结束会话
+func (s *learningService) FinishSession(userID int64, sessionID string) (*dto.LearningSessionResponse, error) {
+	return s.end(userID, sessionID, StatusCompleted)
+}
+
+// InterruptSession 主动中断会话
+func (s *learningService) InterruptSession(userID int64, sessionID string) (*dto.LearningSessionResponse, error) {
+	return s.end(userID, sessionID, StatusInterrupted)
+}
+
+// end 将进行中或已暂停的会话结束为指定状态
+func (s *learningService) end(userID int64, sessionID, status string) (*dto.LearningSessionResponse, error) {
+	resp, err := s.transition(userID, sessionID, []string{StatusOngoing, StatusPaused}, func(session *models.LearningSession, now time.Time) (map[string]interface{}, error) {
+		return map[string]interface{}{
+			"completion_status": status,
+			"end_time":          now,
+			"paused_seconds":    session.PausedSeconds + pausedSince(session, now),
+			"paused_at":         nil,
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.events.Publish(context.Background(), sessionEnded(userID, resp))
+	return resp, nil
+}
+
+// publishEnded 为批量结束的会话发布会话结束事件
+func (s *learningService) publishEnded(sessions []models.LearningSession, now time.Time) {
+	for i := range sessions {
+		s.events.Publish(context.Background(), sessionEnded(sessions[i].UserID, toSessionResponse(&sessions[i], now)))
+	}
+}
+
+// transition 执行一次会话状态迁移
+// 更新以当前状态为条件，并发请求导致状态已变化时返回 ErrInvalidTransition
+func (s *learningService) transition(userID int64, sessionID string, from []string,
+	build func(session *models.LearningSession, now time.Time) (map[string]interface{}, error)) (*dto.LearningSessionResponse, error) {
+	session, err := s.repo.FindSession(userID, sessionID)
+	if err != nil {
+		return nil, err // 错误可能是 gorm.ErrRecordNotFound
+	}
+	if !contains(from, session.CompletionStatus) {
+		return nil, ErrInvalidTransition
+	}
+
+	now := time.Now()
+	updates, err := build(session, now)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.repo.TransitionSession(session.ID, from, updates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update session: %w", err)
+	}
+	if !ok {
+		return nil, ErrInvalidTransition
+	}
+
+	return s.GetSession(userID, sessionID)
+}
+
+// ValidateAnswer 校验答题是否符合所属会话的规则
+// 未关联会话的答题不做校验
+func (s *learningService) ValidateAnswer(userID int64, sessionID string, hintUsedCount int) error {
+	if sessionID == "" {
+		return nil
+	}
+
+	session, err := s.repo.FindSession(userID, sessionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrSessionNotFound // 与题目不存在区分开
+	}
+	if err != nil {
+		return err
+	}
+	if session.CompletionStatus != StatusOngoing {
+		return ErrSessionNotActive
+	}
+
+	rules := rulesFor(session.SessionType)
+	if !rules.AllowHints && hintUsedCount > 0 {
+		return ErrHintsNotAllowed
+	}
+	if rules.MaxQuestions > 0 && session.QuestionsCount >= rules.MaxQuestions {
+		return ErrQuestionLimitReached
+	}
+	return nil
+}
+
+// SweepIdleSessions 将超过空闲时长仍处于进行中的会话标记为中断，返回中断的会话数
+func (s *learningService) SweepIdleSessions() (int64, error) {
+	now := time.Now()
+	sessions, err := s.repo.InterruptIdleSessions(now.Add(-s.idleTimeout()))
+	if err != nil {
+		return 0, err
+	}
+	s.publishEnded(sessions, now)
+	return int64(len(sessions)), nil
+}
+
+// StartIdleSweeper 启动后台任务，定期清理空闲会话，直到 ctx 被取消
+func (s *learningService) StartIdleSweeper(ctx context.Context) {
+	interval := s.config.SweepInterval
+	if interval <= 0 {
+		interval = defaultSweepInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				count, err := s.SweepIdleSessions()
+				if err != nil {
+					logger.LogError("learning", "sweep_idle_sessions", err, nil)
+					continue
+				}
+				if count > 0 {
+					logger.Logger.Info("空闲学习会话已中断", zap.Int64("count", count))
+				}
+			}
+		}
+	}()
+
+	logger.Logger.Info("空闲会话清理任务已启动",
+		zap.Duration("interval", interval),
+		zap.Duration("idle_timeout", s.idleTimeout()))
+}
+
+func (s *learningService) idleTimeout() time.Duration {
+	if s.config.SessionIdleTimeout > 0 {
+		return s.config.SessionIdleTimeout
+	}
+	return defaultIdleTimeout
+}
+
+// pausedSince 返回会话本次暂停已持续的秒数，未暂停时为0
+func pausedSince(session *models.LearningSession, now time.Time) int {
+	if session.PausedAt == nil {
+		return 0
+	}
+	return int(now.Sub(*session.PausedAt).Seconds())
+}
+
+// toSessionResponse 构造学习会话响应，有效时长不含暂停时间
+// 考试会话结束前不返回答对题数，避免逐题推断对错
+func toSessionResponse(session *models.LearningSession, now time.Time) *dto.LearningSessionResponse {
+	end := now
+	switch {
+	case session.EndTime != nil:
+		end = *session.EndTime
+	case session.PausedAt != nil:
+		end = *session.PausedAt
+	}
+
+	duration := int(end.Sub(session.StartTime).Seconds()) - session.PausedSeconds
+	if duration < 0 {
+		duration = 0
+	}
+
+	correctCount := session.CorrectCount
+	if examOpen(session) {
+		correctCount = 0
+	}
+
+	return &dto.LearningSessionResponse{
+		SessionID:        session.SessionID,
+		SessionType:      session.SessionType,
+		CompletionStatus: session.CompletionStatus,
+		StartTime:        session.StartTime,
+		EndTime:          session.EndTime,
+		LastActiveAt:     session.LastActiveAt,
+		QuestionsCount:   session.QuestionsCount,
+		CorrectCount:     correctCount,
+		AvgDifficulty:    session.AvgDifficulty,
+		DurationSeconds:  duration,
+	}
+}
+
+// examOpen 判断会话是否为尚未结束的考试会话
+func examOpen(session *models.LearningSession) bool {
+	return session.SessionType == SessionTypeTest && session.EndTime == nil
+}
+
+// sessionEnded 由已结束会话的响应构造会话结束事件
+func sessionEnded(userID int64, session *dto.LearningSessionResponse) event.SessionEnded {
+	e := event.SessionEnded{
+		UserID:          userID,
+		SessionID:       session.SessionID,
+		SessionType:     session.SessionType,
+		Status:          session.CompletionStatus,
+		StartedAt:       session.StartTime,
+		DurationSeconds: session.DurationSeconds,
+		QuestionsCount:  session.QuestionsCount,
+		CorrectCount:    session.CorrectCount,
+	}
+	if session.EndTime != nil {
+		e.EndedAt = *session.EndTime
+	}
+	return e
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
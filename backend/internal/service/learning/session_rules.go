@@ -0,0 +1,49 @@
+/*
+File: session_rules.go
+Author: lxp
+Description: 不同会话类型的规则定义
+*/
+package learning
+
+// 会话类型，与数据库 session_type 枚举保持一致
+const (
+	SessionTypePractice  = "practice"
+	SessionTypeTest      = "test" // 由限时考试创建，不能通过学习会话接口直接开始
+	SessionTypeChallenge = "challenge"
+	SessionTypeHomework  = "homework" // 由班级作业创建，不能通过学习会话接口直接开始
+)
+
+// 会话状态，与数据库 completion_status 枚举保持一致
+const (
+	StatusOngoing     = "ongoing"
+	StatusPaused      = "paused"
+	StatusCompleted   = "completed"
+	StatusInterrupted = "interrupted"
+)
+
+// sessionRules 描述某种会话类型下允许的操作
+type sessionRules struct {
+	AllowPause   bool // 是否允许暂停
+	AllowHints   bool // 答题时是否允许使用提示
+	MaxQuestions int  // 单个会话最多可作答的题数，0 表示不限制
+}
+
+// rulesByType 定义各会话类型的规则
+//   - practice: 自由练习，可暂停、可使用提示
+//   - test:     限时考试，不可暂停、不可使用提示，题目范围与交卷由考试决定
+//   - challenge: 闯关挑战，不可暂停，每轮最多20题
+//   - homework: 班级作业，可暂停、可使用提示，题目范围由作业决定
+var rulesByType = map[string]sessionRules{
+	SessionTypePractice:  {AllowPause: true, AllowHints: true},
+	SessionTypeTest:      {AllowPause: false, AllowHints: false},
+	SessionTypeChallenge: {AllowPause: false, AllowHints: true, MaxQuestions: 20},
+	SessionTypeHomework:  {AllowPause: true, AllowHints: true},
+}
+
+// rulesFor 返回会话类型对应的规则，未知类型按练习处理
+func rulesFor(sessionType string) sessionRules {
+	if rules, ok := rulesByType[sessionType]; ok {
+		return rules
+	}
+	return rulesByType[SessionTypePractice]
+}
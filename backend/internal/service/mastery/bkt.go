@@ -0,0 +1,79 @@
+/*
+File: bkt.go
+Author: lxp
+Description: 贝叶斯知识追踪 (BKT) 的掌握概率更新
+*/
+package mastery
+
+import (
+	"math"
+	"zhixue-backend/internal/config"
+	"zhixue-backend/models"
+)
+
+// 未配置时使用的默认BKT参数
+const (
+	defaultPInit             = 0.2
+	defaultPLearn            = 0.15
+	defaultPSlip             = 0.1
+	defaultPGuess            = 0.2
+	defaultMasteredThreshold = 0.95
+)
+
+// Params 是单个知识点的BKT参数
+type Params struct {
+	Init  float64 // 初始掌握概率 P(L0)
+	Learn float64 // 每次作答后从未掌握转为掌握的概率 P(T)
+	Slip  float64 // 已掌握但答错的概率 P(S)
+	Guess float64 // 未掌握但猜对的概率 P(G)
+}
+
+// defaultParams 从配置读取默认参数，未配置或不合法的项使用内置默认值
+func defaultParams(cfg *config.MasteryConfig) Params {
+	return Params{
+		Init:  probabilityOr(cfg.PInit, defaultPInit),
+		Learn: probabilityOr(cfg.PLearn, defaultPLearn),
+		Slip:  probabilityOr(cfg.PSlip, defaultPSlip),
+		Guess: probabilityOr(cfg.PGuess, defaultPGuess),
+	}
+}
+
+// paramsFromModel 将知识点单独配置的参数转换为 Params
+func paramsFromModel(p *models.KnowledgePointMasteryParam) Params {
+	return Params{Init: p.PInit, Learn: p.PLearn, Slip: p.PSlip, Guess: p.PGuess}
+}
+
+// Valid 判断参数是否合法：各项在 (0,1) 之间，且 Slip+Guess<1 以保证答对总是提高掌握概率
+func (p Params) Valid() bool {
+	for _, v := range []float64{p.Init, p.Learn, p.Slip, p.Guess} {
+		if v <= 0 || v >= 1 {
+			return false
+		}
+	}
+	return p.Slip+p.Guess < 1
+}
+
+// Update 根据一次作答结果更新掌握概率：先按作答结果求后验，再叠加本次作答带来的学习
+func (p Params) Update(prior float64, correct bool) float64 {
+	var posterior float64
+	if correct {
+		known := prior * (1 - p.Slip)
+		posterior = known / (known + (1-prior)*p.Guess)
+	} else {
+		known := prior * p.Slip
+		posterior = known / (known + (1-prior)*(1-p.Guess))
+	}
+	return math.Max(0, math.Min(1, posterior+(1-posterior)*p.Learn))
+}
+
+func probabilityOr(v, fallback float64) float64 {
+	if v > 0 && v < 1 {
+		return v
+	}
+	return fallback
+}
+
+// round4 保留四位小数，与数据库 DECIMAL(5,4) 一致
+func round4(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
@@ -0,0 +1,83 @@
+/*
+File: bkt_test.go
+Author: lxp
+Description: 贝叶斯知识追踪参数与更新公式的单元测试
+*/
+package mastery
+
+import (
+	"testing"
+	"zhixue-backend/internal/config"
+)
+
+var testParams = Params{Init: defaultPInit, Learn: defaultPLearn, Slip: defaultPSlip, Guess: defaultPGuess}
+
+func TestParamsUpdate(t *testing.T) {
+	tests := []struct {
+		name    string
+		prior   float64
+		correct bool
+		want    float64
+	}{
+		{"初始答对", 0.2, true, 0.6},
+		{"初始答错", 0.2, false, 0.1758},
+		{"已掌握答对", 0.99, true, 0.9981},
+		{"已掌握答错", 0.99, false, 0.9364},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := round4(testParams.Update(tt.prior, tt.correct)); got != tt.want {
+				t.Errorf("Update(%.2f, %v) = %.4f, want %.4f", tt.prior, tt.correct, got, tt.want)
+			}
+		})
+	}
+}
+
+// 合法参数下答对总是提高掌握概率，答错后的概率总低于答对
+func TestParamsUpdateDirection(t *testing.T) {
+	for _, prior := range []float64{0.01, 0.2, 0.5, 0.8, 0.99} {
+		correct := testParams.Update(prior, true)
+		wrong := testParams.Update(prior, false)
+		if correct <= prior {
+			t.Errorf("Update(%.2f, true) = %.4f, want above prior", prior, correct)
+		}
+		if wrong >= correct {
+			t.Errorf("Update(%.2f, false) = %.4f, want below correct %.4f", prior, wrong, correct)
+		}
+		if wrong < 0 || correct > 1 {
+			t.Errorf("Update(%.2f) = [%.4f, %.4f], want within [0,1]", prior, wrong, correct)
+		}
+	}
+}
+
+func TestParamsValid(t *testing.T) {
+	tests := []struct {
+		name   string
+		params Params
+		want   bool
+	}{
+		{"默认参数", testParams, true},
+		{"初始概率为0", Params{Init: 0, Learn: 0.1, Slip: 0.1, Guess: 0.2}, false},
+		{"学习概率为1", Params{Init: 0.2, Learn: 1, Slip: 0.1, Guess: 0.2}, false},
+		{"负数", Params{Init: 0.2, Learn: 0.1, Slip: -0.1, Guess: 0.2}, false},
+		{"失误与猜测之和为1", Params{Init: 0.2, Learn: 0.1, Slip: 0.5, Guess: 0.5}, false},
+		{"失误与猜测之和小于1", Params{Init: 0.2, Learn: 0.1, Slip: 0.4, Guess: 0.5}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.params.Valid(); got != tt.want {
+				t.Errorf("Valid(%+v) = %v, want %v", tt.params, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultParams(t *testing.T) {
+	got := defaultParams(&config.MasteryConfig{PInit: 0.3, PLearn: 0, PSlip: 1.5, PGuess: 0.25})
+	want := Params{Init: 0.3, Learn: defaultPLearn, Slip: defaultPSlip, Guess: 0.25}
+	if got != want {
+		t.Errorf("defaultParams = %+v, want %+v", got, want)
+	}
+}
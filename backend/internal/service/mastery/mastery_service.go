@@ -0,0 +1,289 @@
+/*
+File: mastery_service.go
+Author: lxp
+Description: 知识点掌握度业务逻辑：答题后按BKT更新、查询、参数维护与离线重建
+*/
+package mastery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/config"
+	"zhixue-backend/internal/event"
+	"zhixue-backend/internal/repository/knowledge"
+	"zhixue-backend/internal/repository/mastery"
+	"zhixue-backend/logger"
+	"zhixue-backend/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidParams = errors.New("invalid mastery params")
+)
+
+// 离线重建时每批处理的用户数
+const rebuildBatchSize = 200
+
+// Service 定义知识点掌握度服务的接口
+type Service interface {
+	HandleAnswerSubmitted(ctx context.Context, e event.Event) error
+	ListMastery(userID int64, query *dto.ListMasteryQuery) ([]dto.KnowledgeMasteryResponse, error)
+
+	// 后台管理
+	GetParams(knowledgePointID int64) (*dto.MasteryParamsResponse, error)
+	UpdateParams(operatorID, knowledgePointID int64, req *dto.UpdateMasteryParamsRequest) (*dto.MasteryParamsResponse, error)
+	ResetParams(knowledgePointID int64) error
+
+	// 离线重建
+	Rebuild(userID int64) error
+	RebuildAll(ctx context.Context) (int, error)
+}
+
+// masteryService 实现了Service接口
+type masteryService struct {
+	repo          mastery.Repository
+	knowledgeRepo knowledge.Repository
+	defaults      Params
+	threshold     float64
+}
+
+// NewMasteryService 创建一个新的知识点掌握度服务实例
+func NewMasteryService(repo mastery.Repository, knowledgeRepo knowledge.Repository, cfg *config.MasteryConfig) Service {
+	defaults := defaultParams(cfg)
+	if !defaults.Valid() {
+		logger.Logger.Warn("掌握度默认参数不合法，使用内置默认值",
+			zap.Float64("p_slip", defaults.Slip),
+			zap.Float64("p_guess", defaults.Guess))
+		defaults = Params{Init: defaultPInit, Learn: defaultPLearn, Slip: defaultPSlip, Guess: defaultPGuess}
+	}
+
+	return &masteryService{
+		repo:          repo,
+		knowledgeRepo: knowledgeRepo,
+		defaults:      defaults,
+		threshold:     probabilityOr(cfg.MasteredThreshold, defaultMasteredThreshold),
+	}
+}
+
+// HandleAnswerSubmitted 订阅答题事件，更新题目关联的每个知识点的掌握概率
+func (s *masteryService) HandleAnswerSubmitted(ctx context.Context, e event.Event) error {
+	answer, ok := e.(event.AnswerSubmitted)
+	if !ok {
+		return nil
+	}
+
+	pointIDs, err := s.repo.FindKnowledgePointIDs(answer.QuestionID)
+	if err != nil {
+		return fmt.Errorf("failed to load knowledge points: %w", err)
+	}
+	if len(pointIDs) == 0 {
+		return nil
+	}
+	params, err := s.paramsFor(pointIDs)
+	if err != nil {
+		return err
+	}
+
+	answeredAt := answer.AnsweredAt
+	err = s.repo.Update(answer.UserID, pointIDs, func(m *models.UserKnowledgeMastery) {
+		apply(m, params[m.KnowledgePointID], answer.IsCorrect, answeredAt)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update mastery: %w", err)
+	}
+	return nil
+}
+
+// ListMastery 获取用户已作答过的知识点的掌握情况，掌握概率低的在前
+func (s *masteryService) ListMastery(userID int64, query *dto.ListMasteryQuery) ([]dto.KnowledgeMasteryResponse, error) {
+	rows, err := s.repo.ListByUser(userID, query.GradeLevel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mastery: %w", err)
+	}
+
+	result := make([]dto.KnowledgeMasteryResponse, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, dto.KnowledgeMasteryResponse{
+			KnowledgePoint: dto.KnowledgePointBrief{ID: row.KnowledgePointID, Name: row.Name, Code: row.Code},
+			GradeLevel:     row.GradeLevel,
+			Mastery:        row.Mastery,
+			Mastered:       row.Mastery >= s.threshold,
+			AttemptCount:   row.AttemptCount,
+			CorrectCount:   row.CorrectCount,
+			LastAnsweredAt: row.LastAnsweredAt,
+		})
+	}
+	return result, nil
+}
+
+// GetParams 获取知识点当前生效的BKT参数
+func (s *masteryService) GetParams(knowledgePointID int64) (*dto.MasteryParamsResponse, error) {
+	if _, err := s.knowledgeRepo.FindByID(knowledgePointID); err != nil {
+		return nil, err // 错误可能是 gorm.ErrRecordNotFound
+	}
+
+	stored, err := s.repo.FindParams([]int64{knowledgePointID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mastery params: %w", err)
+	}
+	if p, ok := stored[knowledgePointID]; ok {
+		return toParamsResponse(knowledgePointID, paramsFromModel(&p), false), nil
+	}
+	return toParamsResponse(knowledgePointID, s.defaults, true), nil
+}
+
+// UpdateParams 为知识点单独设置BKT参数，只影响之后的答题，历史掌握度需要通过离线重建重新计算
+func (s *masteryService) UpdateParams(operatorID, knowledgePointID int64, req *dto.UpdateMasteryParamsRequest) (*dto.MasteryParamsResponse, error) {
+	params := Params{Init: req.PInit, Learn: req.PLearn, Slip: req.PSlip, Guess: req.PGuess}
+	if !params.Valid() {
+		return nil, ErrInvalidParams
+	}
+	if _, err := s.knowledgeRepo.FindByID(knowledgePointID); err != nil {
+		return nil, err
+	}
+
+	err := s.repo.SaveParam(&models.KnowledgePointMasteryParam{
+		KnowledgePointID: knowledgePointID,
+		PInit:            params.Init,
+		PLearn:           params.Learn,
+		PSlip:            params.Slip,
+		PGuess:           params.Guess,
+		UpdatedBy:        &operatorID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save mastery params: %w", err)
+	}
+	return toParamsResponse(knowledgePointID, params, false), nil
+}
+
+// ResetParams 删除知识点单独设置的BKT参数，恢复使用默认参数
+func (s *masteryService) ResetParams(knowledgePointID int64) error {
+	ok, err := s.repo.DeleteParam(knowledgePointID)
+	if err != nil {
+		return fmt.Errorf("failed to delete mastery params: %w", err)
+	}
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Rebuild 按时间顺序重放用户的全部答题记录，重新计算其所有知识点的掌握度
+func (s *masteryService) Rebuild(userID int64) error {
+	history, err := s.repo.AnswerHistory(userID)
+	if err != nil {
+		return fmt.Errorf("failed to load answer history: %w", err)
+	}
+
+	pointIDs := make([]int64, 0)
+	byPoint := make(map[int64]*models.UserKnowledgeMastery)
+	for _, h := range history {
+		if byPoint[h.KnowledgePointID] == nil {
+			byPoint[h.KnowledgePointID] = &models.UserKnowledgeMastery{UserID: userID, KnowledgePointID: h.KnowledgePointID}
+			pointIDs = append(pointIDs, h.KnowledgePointID)
+		}
+	}
+	params, err := s.paramsFor(pointIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, h := range history {
+		apply(byPoint[h.KnowledgePointID], params[h.KnowledgePointID], h.IsCorrect, h.CreatedAt)
+	}
+
+	sort.Slice(pointIDs, func(i, j int) bool { return pointIDs[i] < pointIDs[j] })
+	masteries := make([]models.UserKnowledgeMastery, 0, len(pointIDs))
+	for _, id := range pointIDs {
+		masteries = append(masteries, *byPoint[id])
+	}
+	if err := s.repo.Replace(userID, masteries); err != nil {
+		return fmt.Errorf("failed to save rebuilt mastery: %w", err)
+	}
+	return nil
+}
+
+// RebuildAll 分批重建所有有答题记录的用户的掌握度，单个用户失败只记录日志，返回成功重建的用户数
+func (s *masteryService) RebuildAll(ctx context.Context) (int, error) {
+	rebuilt := 0
+	var afterID int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return rebuilt, err
+		}
+
+		userIDs, err := s.repo.FindUsersWithAnswers(afterID, rebuildBatchSize)
+		if err != nil {
+			return rebuilt, fmt.Errorf("failed to find users with answers: %w", err)
+		}
+		if len(userIDs) == 0 {
+			return rebuilt, nil
+		}
+
+		for _, userID := range userIDs {
+			if ctx.Err() != nil {
+				return rebuilt, ctx.Err()
+			}
+			if err := s.Rebuild(userID); err != nil {
+				logger.LogError("mastery", "rebuild", err, map[string]interface{}{"user_id": userID})
+				continue
+			}
+			rebuilt++
+		}
+		afterID = userIDs[len(userIDs)-1]
+
+		logger.Logger.Info("掌握度重建进度", zap.Int("rebuilt", rebuilt), zap.Int64("last_user_id", afterID))
+	}
+}
+
+// paramsFor 获取一组知识点生效的BKT参数，未单独配置的使用默认参数
+func (s *masteryService) paramsFor(pointIDs []int64) (map[int64]Params, error) {
+	stored, err := s.repo.FindParams(pointIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mastery params: %w", err)
+	}
+
+	params := make(map[int64]Params, len(pointIDs))
+	for _, id := range pointIDs {
+		params[id] = s.defaults
+		if p, ok := stored[id]; ok {
+			params[id] = paramsFromModel(&p)
+		}
+	}
+	return params, nil
+}
+
+// apply 将一次作答计入掌握度记录，不晚于已计入的最近答题时间的作答会被忽略，避免重复计入
+func apply(m *models.UserKnowledgeMastery, params Params, correct bool, answeredAt time.Time) {
+	if m.LastAnsweredAt != nil && !answeredAt.After(*m.LastAnsweredAt) {
+		return
+	}
+
+	prior := m.Mastery
+	if m.AttemptCount == 0 {
+		prior = params.Init
+	}
+	m.Mastery = round4(params.Update(prior, correct))
+	m.AttemptCount++
+	if correct {
+		m.CorrectCount++
+	}
+	m.LastAnsweredAt = &answeredAt
+}
+
+func toParamsResponse(knowledgePointID int64, p Params, isDefault bool) *dto.MasteryParamsResponse {
+	return &dto.MasteryParamsResponse{
+		KnowledgePointID: knowledgePointID,
+		PInit:            p.Init,
+		PLearn:           p.Learn,
+		PSlip:            p.Slip,
+		PGuess:           p.Guess,
+		IsDefault:        isDefault,
+	}
+}
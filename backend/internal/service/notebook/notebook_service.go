@@ -0,0 +1,309 @@
+/*
+File: notebook_service.go
+Author: lxp
+Description: 错题本业务逻辑：错题汇总与分组、标记已掌握、由错题生成练习会话
+*/
+package notebook
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/repository/notebook"
+	"zhixue-backend/internal/repository/question"
+	learning_service "zhixue-backend/internal/service/learning"
+	question_service "zhixue-backend/internal/service/question"
+	"zhixue-backend/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidDateRange      = errors.New("invalid date range")
+	ErrAlreadyResolved       = errors.New("notebook entry is already resolved")
+	ErrNotEnoughCorrect      = errors.New("question must be answered correctly twice before resolving")
+	ErrNoEntries             = errors.New("no notebook entries available for practice")
+	ErrQuestionNotInPractice = errors.New("question is not part of the notebook practice")
+)
+
+const (
+	// 最近一次答错之后需要答对的次数，达到后才能标记为已掌握
+	resolveCorrectCount = 2
+	// 错题练习未指定题数时的默认题数
+	defaultPracticeCount = 10
+)
+
+// 分组方式
+const (
+	GroupByKnowledgePoint = "knowledge_point"
+	GroupByTag            = "tag"
+)
+
+// Service 定义错题本服务的接口
+type Service interface {
+	ListEntries(userID int64, query *dto.ListNotebookQuery) (*dto.PageResponse, error)
+	ListGroups(userID int64, query *dto.NotebookGroupsQuery) (*dto.NotebookGroupsResponse, error)
+	Resolve(userID, questionID int64) (*dto.NotebookEntryResponse, error)
+	StartPractice(userID int64, req *dto.StartNotebookPracticeRequest) (*dto.NotebookPracticeResponse, error)
+	GetPractice(userID int64, sessionID string) (*dto.NotebookPracticeResponse, error)
+	CheckAnswer(userID int64, sessionID string, questionID int64) (question_service.Feedback, error)
+}
+
+// notebookService 实现了Service接口
+type notebookService struct {
+	repo      notebook.Repository
+	questions question.Repository
+	learning  learning_service.Service
+}
+
+// NewNotebookService 创建一个新的错题本服务实例
+func NewNotebookService(repo notebook.Repository, questions question.Repository, learning learning_service.Service) Service {
+	return &notebookService{repo: repo, questions: questions, learning: learning}
+}
+
+// ListEntries 分页获取错题本，默认只返回未掌握的错题
+func (s *notebookService) ListEntries(userID int64, query *dto.ListNotebookQuery) (*dto.PageResponse, error) {
+	query.Normalize()
+
+	filter, err := toFilter(userID, &query.NotebookFilter)
+	if err != nil {
+		return nil, err
+	}
+	filter.Status = query.Status
+
+	entries, total, err := s.repo.ListEntries(filter, query.Page, query.PageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notebook entries: %w", err)
+	}
+
+	items := make([]dto.NotebookEntryResponse, 0, len(entries))
+	for i := range entries {
+		items = append(items, *toEntryResponse(&entries[i]))
+	}
+	return dto.NewPageResponse(items, query.Page, query.PageSize, total), nil
+}
+
+// ListGroups 按知识点或标签统计未掌握的错题数
+func (s *notebookService) ListGroups(userID int64, query *dto.NotebookGroupsQuery) (*dto.NotebookGroupsResponse, error) {
+	filter, err := toFilter(userID, &query.NotebookFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	by := query.By
+	var groups []notebook.Group
+	if by == GroupByTag {
+		groups, err = s.repo.GroupByTag(filter)
+	} else {
+		by = GroupByKnowledgePoint
+		groups, err = s.repo.GroupByKnowledgePoint(filter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to group notebook entries: %w", err)
+	}
+
+	resp := &dto.NotebookGroupsResponse{By: by, Groups: make([]dto.NotebookGroupResponse, 0, len(groups))}
+	for _, g := range groups {
+		name := "未归类"
+		if g.Name != nil {
+			name = *g.Name
+		}
+		resp.Groups = append(resp.Groups, dto.NotebookGroupResponse{Key: g.Key, Name: name, Count: g.Count})
+	}
+	return resp, nil
+}
+
+// Resolve 将错题标记为已掌握，要求最近一次答错之后已答对两次；之后再答错时题目重新回到错题本
+func (s *notebookService) Resolve(userID, questionID int64) (*dto.NotebookEntryResponse, error) {
+	entry, err := s.repo.FindEntry(userID, questionID)
+	if err != nil {
+		return nil, err // 错误可能是 gorm.ErrRecordNotFound
+	}
+	if entry.ResolvedAt != nil {
+		return nil, ErrAlreadyResolved
+	}
+	if entry.CorrectSince < resolveCorrectCount {
+		return nil, ErrNotEnoughCorrect
+	}
+
+	now := time.Now()
+	if err := s.repo.Resolve(userID, questionID, now); err != nil {
+		return nil, fmt.Errorf("failed to resolve notebook entry: %w", err)
+	}
+	entry.ResolvedAt = &now
+	return toEntryResponse(entry), nil
+}
+
+// StartPractice 从未掌握的错题中选题 (最近答错的优先)，开始一个 practice 类型的学习会话
+func (s *notebookService) StartPractice(userID int64, req *dto.StartNotebookPracticeRequest) (*dto.NotebookPracticeResponse, error) {
+	filter, err := toFilter(userID, &req.NotebookFilter)
+	if err != nil {
+		return nil, err
+	}
+	filter.Status = notebook.StatusUnresolved
+
+	count := req.QuestionCount
+	if count <= 0 {
+		count = defaultPracticeCount
+	}
+	entries, _, err := s.repo.ListEntries(filter, 1, count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pick notebook questions: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, ErrNoEntries
+	}
+
+	session, err := s.learning.StartSession(userID, &dto.StartSessionRequest{SessionType: learning_service.SessionTypePractice})
+	if err != nil {
+		return nil, err // 包括家长管控导致的 ErrSessionTypeBlocked 与 ErrDailyLimitReached
+	}
+
+	items := make([]models.NotebookPracticeQuestion, 0, len(entries))
+	resp := &dto.NotebookPracticeResponse{Session: session, Questions: make([]dto.NotebookPracticeQuestionResponse, 0, len(entries))}
+	for i, entry := range entries {
+		items = append(items, models.NotebookPracticeQuestion{SessionID: session.SessionID, Position: i + 1, QuestionID: entry.QuestionID})
+		resp.Questions = append(resp.Questions, dto.NotebookPracticeQuestionResponse{
+			Position:     i + 1,
+			QuestionID:   entry.QuestionID,
+			Title:        entry.Title,
+			Content:      entry.Content,
+			QuestionType: entry.QuestionType,
+			Difficulty:   entry.Difficulty,
+			Hints:        entry.Hints,
+			Choices:      entry.Choices,
+		})
+	}
+	if err := s.repo.CreatePractice(items); err != nil {
+		_, _ = s.learning.InterruptSession(userID, session.SessionID)
+		return nil, fmt.Errorf("failed to create notebook practice: %w", err)
+	}
+	return resp, nil
+}
+
+// GetPractice 获取错题练习的会话与题目，标记已作答的题目
+func (s *notebookService) GetPractice(userID int64, sessionID string) (*dto.NotebookPracticeResponse, error) {
+	session, err := s.learning.GetSession(userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.repo.ListPracticeQuestions(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, gorm.ErrRecordNotFound // 不是错题练习会话
+	}
+
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.QuestionID)
+	}
+	questions, err := s.questions.FindByIDs(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load practice questions: %w", err)
+	}
+	byID := make(map[int64]*models.Question, len(questions))
+	for i := range questions {
+		byID[questions[i].ID] = &questions[i]
+	}
+	answeredIDs, err := s.repo.AnsweredQuestionIDs(userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	answered := make(map[int64]bool, len(answeredIDs))
+	for _, id := range answeredIDs {
+		answered[id] = true
+	}
+
+	resp := &dto.NotebookPracticeResponse{Session: session, Questions: make([]dto.NotebookPracticeQuestionResponse, 0, len(items))}
+	for _, item := range items {
+		q, ok := byID[item.QuestionID]
+		if !ok {
+			continue
+		}
+		resp.Questions = append(resp.Questions, dto.NotebookPracticeQuestionResponse{
+			Position:     item.Position,
+			QuestionID:   q.ID,
+			Title:        q.Title,
+			Content:      q.Content,
+			QuestionType: q.QuestionType,
+			Difficulty:   q.Difficulty,
+			Hints:        q.Hints,
+			Choices:      q.Choices,
+			Answered:     answered[q.ID],
+		})
+	}
+	return resp, nil
+}
+
+// CheckAnswer 实现 question.AnswerPolicy：错题练习会话中只能作答练习内的题目，练习不隐藏答案
+func (s *notebookService) CheckAnswer(userID int64, sessionID string, questionID int64) (question_service.Feedback, error) {
+	if sessionID == "" {
+		return question_service.FeedbackFull, nil
+	}
+	items, err := s.repo.ListPracticeQuestions(sessionID)
+	if err != nil {
+		return question_service.FeedbackNone, err
+	}
+	if len(items) == 0 {
+		return question_service.FeedbackFull, nil
+	}
+	for _, item := range items {
+		if item.QuestionID == questionID {
+			return question_service.FeedbackFull, nil
+		}
+	}
+	return question_service.FeedbackNone, ErrQuestionNotInPractice
+}
+
+// toFilter 将请求中的筛选条件转换为仓库筛选条件，结束日期当天包含在内
+func toFilter(userID int64, f *dto.NotebookFilter) (notebook.Filter, error) {
+	filter := notebook.Filter{
+		UserID:           userID,
+		SessionID:        f.SessionID,
+		KnowledgePointID: f.KnowledgePointID,
+		Tag:              f.Tag,
+	}
+	if f.From != "" {
+		from, err := time.ParseInLocation(time.DateOnly, f.From, time.Local)
+		if err != nil {
+			return filter, ErrInvalidDateRange
+		}
+		filter.From = &from
+	}
+	if f.To != "" {
+		to, err := time.ParseInLocation(time.DateOnly, f.To, time.Local)
+		if err != nil {
+			return filter, ErrInvalidDateRange
+		}
+		to = to.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return filter, ErrInvalidDateRange
+	}
+	return filter, nil
+}
+
+func toEntryResponse(e *notebook.Entry) *dto.NotebookEntryResponse {
+	return &dto.NotebookEntryResponse{
+		QuestionID:   e.QuestionID,
+		Title:        e.Title,
+		Content:      e.Content,
+		QuestionType: e.QuestionType,
+		Difficulty:   e.Difficulty,
+		Hints:        e.Hints,
+		Choices:      e.Choices,
+		Tags:         e.Tags,
+		MyAnswer:     e.UserAnswer,
+		WrongAt:      e.WrongAt,
+		SessionID:    e.SessionID,
+		WrongCount:   e.WrongCount,
+		CorrectSince: e.CorrectSince,
+		CanResolve:   e.ResolvedAt == nil && e.CorrectSince >= resolveCorrectCount,
+		Resolved:     e.ResolvedAt != nil,
+		ResolvedAt:   e.ResolvedAt,
+	}
+}
@@ -0,0 +1,137 @@
+/*
+File: notification_service.go
+Author: lxp
+Description: 站内通知业务逻辑：保存通知并通过Redis频道推送，查询与标记已读
+*/
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/event"
+	"zhixue-backend/internal/repository/notification"
+	"zhixue-backend/logger"
+	"zhixue-backend/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 通知类型
+const (
+	TypeBadgeEarned = "badge_earned"
+	TypeLevelUp     = "level_up"
+)
+
+// Redis 频道前缀，频道中包含用户ID (如 notifications:42)，消息为 NotificationResponse 的JSON
+// 网关或长连接服务订阅该频道，将通知实时转发给在线的客户端
+const notificationChannelPrefix = "notifications:"
+
+// Service 定义站内通知服务的接口
+type Service interface {
+	HandleLevelUp(ctx context.Context, e event.Event) error
+	Notify(ctx context.Context, userID int64, notificationType, title, content string, data models.JSONB) error
+	List(userID int64, query *dto.ListNotificationsQuery) (*dto.NotificationListResponse, error)
+	MarkRead(userID, id int64) error
+	MarkAllRead(userID int64) (*dto.MarkAllReadResponse, error)
+}
+
+// notificationService 实现了Service接口
+type notificationService struct {
+	repo  notification.Repository
+	redis *redis.Client
+}
+
+// NewNotificationService 创建一个新的站内通知服务实例
+func NewNotificationService(repo notification.Repository, redisClient *redis.Client) Service {
+	return &notificationService{repo: repo, redis: redisClient}
+}
+
+// HandleLevelUp 订阅升级事件，通知用户升到了新的等级
+func (s *notificationService) HandleLevelUp(ctx context.Context, e event.Event) error {
+	levelUp, ok := e.(event.LevelUp)
+	if !ok {
+		return nil
+	}
+	level := strconv.Itoa(levelUp.NewLevel)
+	return s.Notify(ctx, levelUp.UserID, TypeLevelUp, "升到 "+level+" 级", "继续加油，解锁更多成就！",
+		models.JSONB{"old_level": levelUp.OldLevel, "new_level": levelUp.NewLevel, "level_score": levelUp.LevelScore})
+}
+
+// Notify 保存一条通知并推送给用户，推送失败只记录日志，用户仍可在通知列表中看到
+func (s *notificationService) Notify(ctx context.Context, userID int64, notificationType, title, content string, data models.JSONB) error {
+	if data == nil {
+		data = models.JSONB{}
+	}
+	n := &models.Notification{
+		UserID:  userID,
+		Type:    notificationType,
+		Title:   title,
+		Content: content,
+		Data:    data,
+	}
+	if err := s.repo.Create(n); err != nil {
+		return fmt.Errorf("failed to save notification: %w", err)
+	}
+
+	payload, err := json.Marshal(toNotificationResponse(n))
+	if err == nil {
+		err = s.redis.Publish(ctx, notificationChannelPrefix+strconv.FormatInt(userID, 10), payload).Err()
+	}
+	if err != nil {
+		logger.LogError("notification", "publish", err, map[string]interface{}{"user_id": userID, "notification_id": n.ID})
+	}
+	return nil
+}
+
+// List 分页获取用户的通知，最新的在前
+func (s *notificationService) List(userID int64, query *dto.ListNotificationsQuery) (*dto.NotificationListResponse, error) {
+	query.Normalize()
+
+	notifications, total, err := s.repo.List(userID, query.UnreadOnly, query.Page, query.PageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	unread, err := s.repo.CountUnread(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+
+	items := make([]dto.NotificationResponse, 0, len(notifications))
+	for i := range notifications {
+		items = append(items, *toNotificationResponse(&notifications[i]))
+	}
+	return &dto.NotificationListResponse{
+		PageResponse: dto.NewPageResponse(items, query.Page, query.PageSize, total),
+		UnreadCount:  unread,
+	}, nil
+}
+
+// MarkRead 将一条通知标记为已读
+func (s *notificationService) MarkRead(userID, id int64) error {
+	return s.repo.MarkRead(userID, id, time.Now()) // 错误可能是 gorm.ErrRecordNotFound
+}
+
+// MarkAllRead 将全部未读通知标记为已读
+func (s *notificationService) MarkAllRead(userID int64) (*dto.MarkAllReadResponse, error) {
+	marked, err := s.repo.MarkAllRead(userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+	return &dto.MarkAllReadResponse{Marked: marked}, nil
+}
+
+func toNotificationResponse(n *models.Notification) *dto.NotificationResponse {
+	return &dto.NotificationResponse{
+		ID:        n.ID,
+		Type:      n.Type,
+		Title:     n.Title,
+		Content:   n.Content,
+		Data:      n.Data,
+		ReadAt:    n.ReadAt,
+		CreatedAt: n.CreatedAt,
+	}
+}
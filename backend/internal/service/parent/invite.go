@@ -0,0 +1,79 @@
+/*
+File: invite.go
+Author: lxp
+Description: 家长关联邀请码 (基于Redis)
+*/
+package parent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"zhixue-backend/internal/randcode"
+	redis_pkg "zhixue-backend/internal/redis"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis 键前缀
+const (
+	inviteKeyPrefix     = "parent_invite:"      // 邀请码 -> 孩子用户ID
+	inviteUserKeyPrefix = "parent_invite_user:" // 孩子用户ID -> 当前有效的邀请码
+)
+
+// 邀请码长度，孩子可以口头或手写告知家长
+const inviteCodeLength = 8
+
+// issueInvite 为孩子生成一个新的邀请码，旧邀请码立即失效
+func issueInvite(ctx context.Context, childID int64, ttl time.Duration) (string, error) {
+	code, err := randcode.New(inviteCodeLength)
+	if err != nil {
+		return "", err
+	}
+	uid := strconv.FormatInt(childID, 10)
+	userKey := inviteUserKeyPrefix + uid
+
+	previous, err := redis_pkg.Client.Get(ctx, userKey).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return "", fmt.Errorf("failed to issue invite code: %w", err)
+	}
+	_, err = redis_pkg.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if previous != "" {
+			pipe.Del(ctx, inviteKeyPrefix+previous)
+		}
+		pipe.Set(ctx, inviteKeyPrefix+code, uid, ttl)
+		pipe.Set(ctx, userKey, code, ttl)
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to issue invite code: %w", err)
+	}
+	return code, nil
+}
+
+// consumeInvite 消费一个邀请码，返回生成该邀请码的孩子用户ID
+func consumeInvite(ctx context.Context, code string) (int64, error) {
+	key := inviteKeyPrefix + randcode.Normalize(code)
+
+	var get *redis.StringCmd
+	_, err := redis_pkg.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pipe.Del(ctx, key)
+		return nil
+	})
+	if errors.Is(err, redis.Nil) {
+		return 0, ErrInvalidInviteCode
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to consume invite code: %w", err)
+	}
+
+	childID, err := strconv.ParseInt(get.Val(), 10, 64)
+	if err != nil {
+		return 0, ErrInvalidInviteCode
+	}
+	redis_pkg.Client.Del(ctx, inviteUserKeyPrefix+get.Val())
+	return childID, nil
+}
@@ -0,0 +1,329 @@
+/*
+File: parent_service.go
+Author: lxp
+Description: 家长账号业务逻辑：关联孩子、查看孩子学习情况与错题本、设置学习管控
+*/
+package parent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/config"
+	"zhixue-backend/internal/rbac"
+	"zhixue-backend/internal/repository/learning"
+	"zhixue-backend/internal/repository/parent"
+	"zhixue-backend/internal/repository/user"
+	learning_service "zhixue-backend/internal/service/learning"
+	notebook_service "zhixue-backend/internal/service/notebook"
+	"zhixue-backend/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidInviteCode = errors.New("invalid or expired invite code")
+	ErrNotLinked         = errors.New("child is not linked to this parent")
+	ErrAlreadyLinked     = parent.ErrAlreadyLinked
+)
+
+// 未配置时使用的默认值
+const (
+	defaultInviteExpires = 24 * time.Hour
+)
+
+// allSessionTypes 未设置管控时允许的会话类型
+var allSessionTypes = []string{
+	learning_service.SessionTypePractice,
+	learning_service.SessionTypeTest,
+	learning_service.SessionTypeChallenge,
+}
+
+// Service 定义家长服务的接口
+type Service interface {
+	CreateInvite(childID int64) (*dto.ParentInviteResponse, error)
+	LinkChild(parentID int64, inviteCode string) (*dto.LinkedUserResponse, error)
+	UnlinkChild(parentID, childID int64) error
+	ListChildren(parentID int64) ([]dto.LinkedUserResponse, error)
+	ListParents(childID int64) ([]dto.LinkedUserResponse, error)
+	GetChildProfile(parentID, childID int64) (*dto.ChildProfileResponse, error)
+	ListChildSessions(parentID, childID int64, query *dto.ListSessionsQuery) (*dto.PageResponse, error)
+	ListChildAnswers(parentID, childID int64, query *dto.ListAnswersQuery) (*dto.PageResponse, error)
+	ListChildNotebook(parentID, childID int64, query *dto.ListNotebookQuery) (*dto.PageResponse, error)
+	ListChildNotebookGroups(parentID, childID int64, query *dto.NotebookGroupsQuery) (*dto.NotebookGroupsResponse, error)
+	GetControls(parentID, childID int64) (*dto.ParentalControlResponse, error)
+	UpdateControls(parentID, childID int64, req *dto.UpdateParentalControlRequest) (*dto.ParentalControlResponse, error)
+}
+
+// parentService 实现了Service接口
+type parentService struct {
+	repo         parent.Repository
+	users        user.Repository
+	learningRepo learning.Repository
+	learning     learning_service.Service
+	notebook     notebook_service.Service
+	config       *config.ParentConfig
+}
+
+// NewParentService 创建一个新的家长服务实例
+func NewParentService(repo parent.Repository, users user.Repository, learningRepo learning.Repository,
+	learningService learning_service.Service, notebookService notebook_service.Service, config *config.ParentConfig) Service {
+	return &parentService{repo: repo, users: users, learningRepo: learningRepo, learning: learningService,
+		notebook: notebookService, config: config}
+}
+
+// CreateInvite 由孩子生成家长关联邀请码，孩子把邀请码告诉家长即表示同意关联
+func (s *parentService) CreateInvite(childID int64) (*dto.ParentInviteResponse, error) {
+	ttl := s.inviteExpires()
+	code, err := issueInvite(context.Background(), childID, ttl)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.ParentInviteResponse{InviteCode: code, ExpiresAt: time.Now().Add(ttl)}, nil
+}
+
+// LinkChild 家长使用孩子生成的邀请码完成关联，邀请码只能使用一次
+func (s *parentService) LinkChild(parentID int64, inviteCode string) (*dto.LinkedUserResponse, error) {
+	childID, err := consumeInvite(context.Background(), inviteCode)
+	if err != nil {
+		return nil, err
+	}
+
+	child, err := s.users.FindByID(childID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidInviteCode
+		}
+		return nil, err
+	}
+	// 邀请码生成后孩子账号可能已变更为其他角色
+	if child.Role != rbac.RoleUser {
+		return nil, ErrInvalidInviteCode
+	}
+
+	link := &models.ParentChildLink{ParentID: parentID, ChildID: childID}
+	if err := s.repo.CreateLink(link); err != nil {
+		return nil, err // 包括 ErrAlreadyLinked
+	}
+	return toLinkedUserResponse(child, link.CreatedAt), nil
+}
+
+// UnlinkChild 家长解除与孩子的关联
+func (s *parentService) UnlinkChild(parentID, childID int64) error {
+	ok, err := s.repo.DeleteLink(parentID, childID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotLinked
+	}
+	return nil
+}
+
+// ListChildren 获取家长关联的孩子列表
+func (s *parentService) ListChildren(parentID int64) ([]dto.LinkedUserResponse, error) {
+	return s.listLinked(s.repo.ListChildren(parentID))
+}
+
+// ListParents 获取孩子关联的家长列表
+func (s *parentService) ListParents(childID int64) ([]dto.LinkedUserResponse, error) {
+	return s.listLinked(s.repo.ListParents(childID))
+}
+
+func (s *parentService) listLinked(users []parent.LinkedUser, err error) ([]dto.LinkedUserResponse, error) {
+	if err != nil {
+		return nil, err
+	}
+	items := make([]dto.LinkedUserResponse, 0, len(users))
+	for i := range users {
+		items = append(items, *toLinkedUserResponse(&users[i].User, users[i].LinkedAt))
+	}
+	return items, nil
+}
+
+// GetChildProfile 获取孩子的资料与学习概况
+func (s *parentService) GetChildProfile(parentID, childID int64) (*dto.ChildProfileResponse, error) {
+	if err := s.checkLinked(parentID, childID); err != nil {
+		return nil, err
+	}
+
+	child, err := s.users.FindByID(childID)
+	if err != nil {
+		return nil, err // 错误可能是 gorm.ErrRecordNotFound
+	}
+	profile, err := s.users.FindProfileByUserID(childID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	resp := &dto.ChildProfileResponse{
+		ID:          child.ID,
+		Username:    child.Username,
+		Nickname:    child.Nickname,
+		AvatarURL:   child.AvatarURL,
+		GradeLevel:  child.GradeLevel,
+		BirthDate:   child.BirthDate,
+		Gender:      child.Gender,
+		LastLoginAt: child.LastLoginAt,
+	}
+	if profile != nil {
+		resp.CurrentDifficulty = profile.CurrentDifficulty
+		resp.TotalStudyTime = profile.TotalStudyTime
+		resp.TotalQuestions = profile.TotalQuestions
+		resp.CorrectAnswers = profile.CorrectAnswers
+		resp.StreakDays = profile.StreakDays
+		resp.UserLevel = profile.UserLevel
+	}
+	return resp, nil
+}
+
+// ListChildSessions 分页查询孩子的学习会话记录
+func (s *parentService) ListChildSessions(parentID, childID int64, query *dto.ListSessionsQuery) (*dto.PageResponse, error) {
+	if err := s.checkLinked(parentID, childID); err != nil {
+		return nil, err
+	}
+	return s.learning.ListSessions(childID, query)
+}
+
+// ListChildAnswers 分页查询孩子的答题记录
+func (s *parentService) ListChildAnswers(parentID, childID int64, query *dto.ListAnswersQuery) (*dto.PageResponse, error) {
+	if err := s.checkLinked(parentID, childID); err != nil {
+		return nil, err
+	}
+	return s.learning.ListAnswers(childID, query)
+}
+
+// ListChildNotebook 分页查询孩子的错题本
+func (s *parentService) ListChildNotebook(parentID, childID int64, query *dto.ListNotebookQuery) (*dto.PageResponse, error) {
+	if err := s.checkLinked(parentID, childID); err != nil {
+		return nil, err
+	}
+	return s.notebook.ListEntries(childID, query)
+}
+
+// ListChildNotebookGroups 按知识点或标签统计孩子未掌握的错题数
+func (s *parentService) ListChildNotebookGroups(parentID, childID int64, query *dto.NotebookGroupsQuery) (*dto.NotebookGroupsResponse, error) {
+	if err := s.checkLinked(parentID, childID); err != nil {
+		return nil, err
+	}
+	return s.notebook.ListGroups(childID, query)
+}
+
+// GetControls 获取孩子当前的学习管控设置及今日用量
+func (s *parentService) GetControls(parentID, childID int64) (*dto.ParentalControlResponse, error) {
+	if err := s.checkLinked(parentID, childID); err != nil {
+		return nil, err
+	}
+
+	control, err := s.repo.FindControl(childID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		control = &models.ParentalControl{ChildID: childID, AllowedSessionTypes: allSessionTypes}
+	} else if err != nil {
+		return nil, err
+	}
+	return s.toControlResponse(control)
+}
+
+// UpdateControls 更新孩子的学习管控设置，多位家长共享同一份设置
+func (s *parentService) UpdateControls(parentID, childID int64, req *dto.UpdateParentalControlRequest) (*dto.ParentalControlResponse, error) {
+	if err := s.checkLinked(parentID, childID); err != nil {
+		return nil, err
+	}
+
+	control, err := s.repo.FindControl(childID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		control = &models.ParentalControl{ChildID: childID, AllowedSessionTypes: allSessionTypes}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if req.DailyLimitMinutes != nil {
+		control.DailyLimitMinutes = *req.DailyLimitMinutes
+	}
+	if len(req.AllowedSessionTypes) > 0 {
+		control.AllowedSessionTypes = uniqueStrings(req.AllowedSessionTypes)
+	}
+	control.UpdatedBy = &parentID
+	control.UpdatedAt = time.Now()
+
+	if err := s.repo.SaveControl(control); err != nil {
+		return nil, fmt.Errorf("failed to save parental control: %w", err)
+	}
+	return s.toControlResponse(control)
+}
+
+// checkLinked 校验家长与孩子已关联
+func (s *parentService) checkLinked(parentID, childID int64) error {
+	ok, err := s.repo.IsLinked(parentID, childID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotLinked
+	}
+	return nil
+}
+
+// toControlResponse 构造管控设置响应，并计算孩子今日已学习的时长
+func (s *parentService) toControlResponse(control *models.ParentalControl) (*dto.ParentalControlResponse, error) {
+	now := time.Now()
+	used, err := s.learningRepo.StudySecondsSince(control.ChildID, startOfDay(now), now)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.ParentalControlResponse{
+		DailyLimitMinutes:   control.DailyLimitMinutes,
+		AllowedSessionTypes: control.AllowedSessionTypes,
+		UsedTodayMinutes:    used / 60,
+	}
+	if control.DailyLimitMinutes > 0 {
+		remaining := control.DailyLimitMinutes - used/60
+		if remaining < 0 {
+			remaining = 0
+		}
+		resp.RemainingTodayMinutes = &remaining
+	}
+	if !control.UpdatedAt.IsZero() {
+		resp.UpdatedAt = &control.UpdatedAt
+	}
+	return resp, nil
+}
+
+func (s *parentService) inviteExpires() time.Duration {
+	if s.config.InviteExpires > 0 {
+		return s.config.InviteExpires
+	}
+	return defaultInviteExpires
+}
+
+func toLinkedUserResponse(u *models.User, linkedAt time.Time) *dto.LinkedUserResponse {
+	return &dto.LinkedUserResponse{
+		ID:         u.ID,
+		Username:   u.Username,
+		Nickname:   u.Nickname,
+		AvatarURL:  u.AvatarURL,
+		GradeLevel: u.GradeLevel,
+		LinkedAt:   linkedAt,
+	}
+}
+
+// startOfDay 返回 t 所在自然日的零点 (服务器时区)
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func uniqueStrings(list []string) []string {
+	seen := make(map[string]bool, len(list))
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		if !seen[item] {
+			seen[item] = true
+			result = append(result, item)
+		}
+	}
+	return result
+}
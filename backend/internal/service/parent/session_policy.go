@@ -0,0 +1,66 @@
+/*
+File: session_policy.go
+Author: lxp
+Description: 开始学习会话时执行家长设置的管控 (可用会话类型、每日学习时长)
+*/
+package parent
+
+import (
+	"errors"
+	"time"
+	"zhixue-backend/internal/repository/learning"
+	"zhixue-backend/internal/repository/parent"
+	learning_service "zhixue-backend/internal/service/learning"
+
+	"gorm.io/gorm"
+)
+
+// sessionPolicy 实现了 learning_service.SessionPolicy 接口
+// 只依赖数据仓库，因此可以在学习会话服务之前创建，避免与家长服务循环依赖
+type sessionPolicy struct {
+	repo         parent.Repository
+	learningRepo learning.Repository
+}
+
+// NewSessionPolicy 创建按家长管控校验学习会话的策略
+func NewSessionPolicy(repo parent.Repository, learningRepo learning.Repository) learning_service.SessionPolicy {
+	return &sessionPolicy{repo: repo, learningRepo: learningRepo}
+}
+
+// CheckStart 校验孩子能否开始指定类型的学习会话，未设置管控的用户不受限制
+func (p *sessionPolicy) CheckStart(userID int64, sessionType string) error {
+	control, err := p.repo.FindControl(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// 老师布置的作业不受可用会话类型限制，但仍计入每日学习时长
+	if sessionType != learning_service.SessionTypeHomework &&
+		len(control.AllowedSessionTypes) > 0 && !contains(control.AllowedSessionTypes, sessionType) {
+		return learning_service.ErrSessionTypeBlocked
+	}
+
+	if control.DailyLimitMinutes > 0 {
+		now := time.Now()
+		used, err := p.learningRepo.StudySecondsSince(userID, startOfDay(now), now)
+		if err != nil {
+			return err
+		}
+		if used >= control.DailyLimitMinutes*60 {
+			return learning_service.ErrDailyLimitReached
+		}
+	}
+	return nil
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
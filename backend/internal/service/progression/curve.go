@@ -0,0 +1,121 @@
+/*
+File: curve.go
+Author: lxp
+Description: 等级曲线与连续学习天数的计算
+*/
+package progression
+
+import (
+	"math"
+	"time"
+	"zhixue-backend/internal/config"
+	"zhixue-backend/models"
+)
+
+// 未配置时使用的默认等级曲线
+const (
+	defaultLevelBaseXP   = 100
+	defaultLevelExponent = 1.5
+	defaultMaxLevel      = 100
+)
+
+// levelCurve 是等级曲线：升到 n 级需要的累计经验值为 base * (n-1)^exponent
+type levelCurve struct {
+	base     float64
+	exponent float64
+	max      int
+}
+
+// newLevelCurve 根据配置创建等级曲线，未配置的参数使用默认值
+func newLevelCurve(cfg *config.ProgressionConfig) levelCurve {
+	c := levelCurve{base: defaultLevelBaseXP, exponent: defaultLevelExponent, max: defaultMaxLevel}
+	if cfg.LevelBaseXP > 0 {
+		c.base = float64(cfg.LevelBaseXP)
+	}
+	if cfg.LevelExponent > 0 {
+		c.exponent = cfg.LevelExponent
+	}
+	if cfg.MaxLevel > 0 {
+		c.max = cfg.MaxLevel
+	}
+	return c
+}
+
+// threshold 返回升到 level 级需要的累计经验值
+func (c levelCurve) threshold(level int) int {
+	if level <= 1 {
+		return 0
+	}
+	return int(math.Round(c.base * math.Pow(float64(level-1), c.exponent)))
+}
+
+// levelFor 返回累计经验值 score 对应的等级
+func (c levelCurve) levelFor(score int) int {
+	level := 1
+	for level < c.max && score >= c.threshold(level+1) {
+		level++
+	}
+	return level
+}
+
+// localDay 返回 t 在时区 loc 下的日期，只保留日期部分，与数据库中 DATE 类型的字段对应
+func localDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// daysBetween 返回两个 localDay 日期之间相差的天数
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// advanceStreak 将学习日 day 计入连续学习天数，返回由保护卡保住的日期及连续天数是否增加
+//   - 前一天学习过：连续天数加1
+//   - 中间缺了几天且持有足够的保护卡：每缺一天消耗一张，缺的日期记为保住，连续天数加1
+//   - 否则连续天数从1重新开始 (不消耗保护卡)
+//
+// 同一天再次学习，或迟到的更早日期的活动 (如空闲会话被清理) 不影响连续天数
+func advanceStreak(p *models.UserProfile, day time.Time) ([]time.Time, bool) {
+	var frozen []time.Time
+	switch {
+	case p.LastActiveDate == nil:
+		p.StreakDays = 1
+	default:
+		gap := daysBetween(*p.LastActiveDate, day)
+		if gap <= 0 {
+			return nil, false
+		}
+		missed := gap - 1
+		switch {
+		case missed == 0:
+			p.StreakDays++
+		case missed <= p.StreakFreezes:
+			p.StreakFreezes -= missed
+			for i := 1; i <= missed; i++ {
+				frozen = append(frozen, p.LastActiveDate.AddDate(0, 0, i))
+			}
+			p.StreakDays++
+		default:
+			p.StreakDays = 1
+		}
+	}
+
+	p.LastActiveDate = &day
+	if p.StreakDays > p.MaxStreakDays {
+		p.MaxStreakDays = p.StreakDays
+	}
+	return frozen, true
+}
+
+// currentStreak 返回截至 today 仍然有效的连续学习天数：昨天之前中断且保护卡不足以保住时为0
+// 只用于展示，保护卡在下次学习时才会消耗
+func currentStreak(p *models.UserProfile, today time.Time) int {
+	if p.LastActiveDate == nil {
+		return 0
+	}
+	missed := daysBetween(*p.LastActiveDate, today) - 1
+	if missed > p.StreakFreezes {
+		return 0
+	}
+	return p.StreakDays
+}
@@ -0,0 +1,153 @@
+/*
+File: curve_test.go
+Author: lxp
+Description: 等级曲线与连续学习天数计算的单元测试
+*/
+package progression
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"zhixue-backend/internal/config"
+	"zhixue-backend/models"
+)
+
+func day(month time.Month, d int) time.Time {
+	return time.Date(2024, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestLevelCurveThreshold(t *testing.T) {
+	c := newLevelCurve(&config.ProgressionConfig{})
+	for level, want := range map[int]int{0: 0, 1: 0, 2: 100, 3: 283, 4: 520, 5: 800} {
+		if got := c.threshold(level); got != want {
+			t.Errorf("threshold(%d) = %d, want %d", level, got, want)
+		}
+	}
+}
+
+func TestLevelFor(t *testing.T) {
+	c := newLevelCurve(&config.ProgressionConfig{})
+	tests := []struct {
+		score int
+		want  int
+	}{
+		{0, 1},
+		{99, 1},
+		{100, 2},
+		{282, 2},
+		{283, 3},
+		{519, 3},
+		{520, 4},
+		{1 << 30, defaultMaxLevel},
+	}
+
+	for _, tt := range tests {
+		if got := c.levelFor(tt.score); got != tt.want {
+			t.Errorf("levelFor(%d) = %d, want %d", tt.score, got, tt.want)
+		}
+	}
+}
+
+func TestNewLevelCurveConfig(t *testing.T) {
+	c := newLevelCurve(&config.ProgressionConfig{LevelBaseXP: 50, LevelExponent: 1, MaxLevel: 3})
+	if got := c.threshold(3); got != 100 {
+		t.Errorf("threshold(3) = %d, want 100", got)
+	}
+	if got := c.levelFor(1000); got != 3 {
+		t.Errorf("levelFor(1000) = %d, want max level 3", got)
+	}
+}
+
+func TestLocalDay(t *testing.T) {
+	shanghai := time.FixedZone("UTC+8", 8*3600)
+	if got := localDay(time.Date(2024, 3, 10, 20, 0, 0, 0, time.UTC), shanghai); !got.Equal(day(3, 11)) {
+		t.Errorf("localDay = %v, want 2024-03-11", got)
+	}
+}
+
+func TestAdvanceStreak(t *testing.T) {
+	tests := []struct {
+		name        string
+		last        *time.Time
+		streak      int
+		maxStreak   int
+		freezes     int
+		day         time.Time
+		wantFrozen  []time.Time
+		wantChanged bool
+		wantStreak  int
+		wantMax     int
+		wantFreezes int
+	}{
+		{"首次学习", nil, 0, 0, 0, day(3, 10), nil, true, 1, 1, 0},
+		{"连续学习", ptrDay(3, 9), 4, 4, 0, day(3, 10), nil, true, 5, 5, 0},
+		{"同一天再次学习", ptrDay(3, 10), 4, 4, 1, day(3, 10), nil, false, 4, 4, 1},
+		{"迟到的更早日期", ptrDay(3, 10), 4, 4, 1, day(3, 8), nil, false, 4, 4, 1},
+		{"缺一天消耗一张保护卡", ptrDay(3, 8), 4, 4, 2, day(3, 10), []time.Time{day(3, 9)}, true, 5, 5, 1},
+		{"缺两天消耗两张保护卡", ptrDay(3, 7), 4, 6, 2, day(3, 10), []time.Time{day(3, 8), day(3, 9)}, true, 5, 6, 0},
+		{"跨月缺一天", ptrDay(2, 28), 4, 4, 1, day(3, 1), []time.Time{day(2, 29)}, true, 5, 5, 0},
+		{"保护卡不足时重置且不消耗", ptrDay(3, 6), 4, 6, 2, day(3, 10), nil, true, 1, 6, 2},
+		{"没有保护卡时重置", ptrDay(3, 8), 4, 4, 0, day(3, 10), nil, true, 1, 4, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &models.UserProfile{StreakDays: tt.streak, MaxStreakDays: tt.maxStreak, StreakFreezes: tt.freezes}
+			p.LastActiveDate = tt.last
+
+			frozen, changed := advanceStreak(p, tt.day)
+			if changed != tt.wantChanged {
+				t.Fatalf("changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if !reflect.DeepEqual(frozen, tt.wantFrozen) {
+				t.Errorf("frozen = %v, want %v", frozen, tt.wantFrozen)
+			}
+			if p.StreakDays != tt.wantStreak || p.MaxStreakDays != tt.wantMax || p.StreakFreezes != tt.wantFreezes {
+				t.Errorf("streak %d, max %d, freezes %d, want %d, %d, %d",
+					p.StreakDays, p.MaxStreakDays, p.StreakFreezes, tt.wantStreak, tt.wantMax, tt.wantFreezes)
+			}
+			wantLast := tt.day
+			if !changed {
+				wantLast = *tt.last
+			}
+			if !p.LastActiveDate.Equal(wantLast) {
+				t.Errorf("LastActiveDate = %v, want %v", p.LastActiveDate, wantLast)
+			}
+		})
+	}
+}
+
+func TestCurrentStreak(t *testing.T) {
+	tests := []struct {
+		name    string
+		last    *time.Time
+		freezes int
+		want    int
+	}{
+		{"从未学习", nil, 0, 0},
+		{"今天学习过", ptrDay(3, 10), 0, 5},
+		{"昨天学习过", ptrDay(3, 9), 0, 5},
+		{"前天学习过且无保护卡", ptrDay(3, 8), 0, 0},
+		{"前天学习过且有保护卡", ptrDay(3, 8), 1, 5},
+		{"保护卡不足", ptrDay(3, 6), 2, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &models.UserProfile{StreakDays: 5, StreakFreezes: tt.freezes}
+			p.LastActiveDate = tt.last
+			if got := currentStreak(p, day(3, 10)); got != tt.want {
+				t.Errorf("currentStreak = %d, want %d", got, tt.want)
+			}
+			if p.StreakFreezes != tt.freezes {
+				t.Errorf("currentStreak consumed freezes: %d, want %d", p.StreakFreezes, tt.freezes)
+			}
+		})
+	}
+}
+
+func ptrDay(month time.Month, d int) *time.Time {
+	t := day(month, d)
+	return &t
+}
@@ -0,0 +1,266 @@
+/*
+File: progression_service.go
+Author: lxp
+Description: 连续学习与等级成长业务逻辑：按用户时区的学习日计算连续天数、答题获得经验值升级、累计学习时长，以及购买连续学习保护卡
+*/
+package progression
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/config"
+	"zhixue-backend/internal/event"
+	"zhixue-backend/internal/repository/progression"
+	"zhixue-backend/internal/repository/wallet"
+	wallet_service "zhixue-backend/internal/service/wallet"
+	"zhixue-backend/logger"
+	"zhixue-backend/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrFreezeLimitReached = errors.New("streak freeze limit reached")
+)
+
+// 未填写或未配置时使用的默认值
+const (
+	defaultActivityDays = 30
+	defaultFreezePrice  = 200
+	defaultMaxFreezes   = 2
+)
+
+// Service 定义连续学习与等级成长服务的接口
+type Service interface {
+	HandleAnswerSubmitted(ctx context.Context, e event.Event) error
+	HandleSessionEnded(ctx context.Context, e event.Event) error
+	GetProgress(userID int64, query *dto.ProgressQuery) (*dto.ProgressResponse, error)
+	PurchaseFreeze(ctx context.Context, userID int64) (*dto.ProgressResponse, error)
+	Location(userID int64) *time.Location
+}
+
+// progressionService 实现了Service接口
+type progressionService struct {
+	repo            progression.Repository
+	wallet          wallet_service.Service
+	events          *event.Bus
+	config          *config.ProgressionConfig
+	curve           levelCurve
+	defaultLocation *time.Location
+	locations       sync.Map // 时区名 -> *time.Location，避免每次事件重新加载时区数据
+}
+
+// NewProgressionService 创建一个新的连续学习与等级成长服务实例
+// 默认时区无法识别时使用服务器时区
+func NewProgressionService(repo progression.Repository, walletService wallet_service.Service, events *event.Bus, config *config.ProgressionConfig) Service {
+	loc := time.Local
+	if config.DefaultTimezone != "" {
+		if l, err := time.LoadLocation(config.DefaultTimezone); err == nil {
+			loc = l
+		} else {
+			logger.LogError("progression", "load_default_timezone", err, map[string]interface{}{"timezone": config.DefaultTimezone})
+		}
+	}
+	return &progressionService{
+		repo:            repo,
+		wallet:          walletService,
+		events:          events,
+		config:          config,
+		curve:           newLevelCurve(config),
+		defaultLocation: loc,
+	}
+}
+
+// HandleAnswerSubmitted 订阅答题事件：计入当天的学习活动并获得经验值
+func (s *progressionService) HandleAnswerSubmitted(ctx context.Context, e event.Event) error {
+	answer, ok := e.(event.AnswerSubmitted)
+	if !ok {
+		return nil
+	}
+
+	xp := s.config.AnswerXP
+	if answer.IsCorrect {
+		xp += s.config.CorrectXP + int(math.Round(s.config.DifficultyXP*math.Max(answer.Difficulty-1, 0)))
+	}
+	return s.record(ctx, answer.UserID, answer.AnsweredAt, progression.Activity{Answers: 1, XP: xp})
+}
+
+// HandleSessionEnded 订阅学习会话结束事件：累计有效学习时长，并计入会话结束当天的学习活动
+func (s *progressionService) HandleSessionEnded(ctx context.Context, e event.Event) error {
+	ended, ok := e.(event.SessionEnded)
+	if !ok || ended.DurationSeconds <= 0 {
+		return nil
+	}
+	return s.record(ctx, ended.UserID, ended.EndedAt, progression.Activity{StudySeconds: ended.DurationSeconds})
+}
+
+// record 将一次学习活动计入用户时区下 at 所在的学习日，更新连续天数与等级
+// 升级时发布 LevelUp 事件，连续天数达到奖励天数时发放积分；没有学习档案的用户忽略
+func (s *progressionService) record(ctx context.Context, userID int64, at time.Time, activity progression.Activity) error {
+	loc, _ := s.location(userID)
+	day := localDay(at, loc)
+
+	var oldLevel int
+	var profile models.UserProfile
+	extended := false
+	err := s.repo.Record(userID, day, activity, func(p *models.UserProfile) []time.Time {
+		oldLevel = p.UserLevel
+		frozen, ok := advanceStreak(p, day)
+		extended = ok
+		p.LevelScore += activity.XP
+		if level := s.curve.levelFor(p.LevelScore); level > p.UserLevel {
+			p.UserLevel = level
+		}
+		profile = *p
+		return frozen
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record activity: %w", err)
+	}
+
+	if profile.UserLevel > oldLevel {
+		s.events.Publish(ctx, event.LevelUp{
+			UserID:     userID,
+			OldLevel:   oldLevel,
+			NewLevel:   profile.UserLevel,
+			LevelScore: profile.LevelScore,
+			At:         at,
+		})
+	}
+	if extended {
+		s.streakBonus(ctx, userID, profile.StreakDays, day)
+	}
+	return nil
+}
+
+// streakBonus 连续天数每满 streak_bonus_every 天发放一次积分，以学习日为来源，同一天只发放一次
+// 失败只记录日志，不影响学习活动的记录
+func (s *progressionService) streakBonus(ctx context.Context, userID int64, streak int, day time.Time) {
+	every := s.config.StreakBonusEvery
+	if every <= 0 || s.config.StreakBonusPoints <= 0 || streak%every != 0 {
+		return
+	}
+	reference := "streak:" + day.Format(time.DateOnly)
+	note := "连续学习" + strconv.Itoa(streak) + "天"
+	if _, err := s.wallet.Credit(ctx, userID, s.config.StreakBonusPoints, wallet.ReasonStreakBonus, reference, note, nil); err != nil {
+		logger.LogError("progression", "streak_bonus", err, map[string]interface{}{"user_id": userID, "streak": streak})
+	}
+}
+
+// GetProgress 获取用户的连续学习与等级成长进度，以及最近的学习日活动
+func (s *progressionService) GetProgress(userID int64, query *dto.ProgressQuery) (*dto.ProgressResponse, error) {
+	profile, err := s.repo.FindProfile(userID)
+	if err != nil {
+		return nil, err // 错误可能是 gorm.ErrRecordNotFound
+	}
+
+	days := query.Days
+	if days <= 0 {
+		days = defaultActivityDays
+	}
+	loc, tz := s.location(userID)
+	today := localDay(time.Now(), loc)
+	activity, err := s.repo.ListActivity(userID, today.AddDate(0, 0, 1-days), today)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list activity: %w", err)
+	}
+
+	resp := &dto.ProgressResponse{
+		Timezone:       tz,
+		StreakDays:     currentStreak(profile, today),
+		MaxStreakDays:  profile.MaxStreakDays,
+		StudiedToday:   profile.LastActiveDate != nil && profile.LastActiveDate.Equal(today),
+		StreakFreezes:  profile.StreakFreezes,
+		MaxFreezes:     s.maxFreezes(),
+		FreezePrice:    s.freezePrice(),
+		Level:          profile.UserLevel,
+		LevelScore:     profile.LevelScore,
+		LevelStartXP:   s.curve.threshold(profile.UserLevel),
+		TotalStudyTime: profile.TotalStudyTime,
+		Activity:       make([]dto.ActivityDayResponse, 0, len(activity)),
+	}
+	if profile.UserLevel < s.curve.max {
+		next := s.curve.threshold(profile.UserLevel + 1)
+		resp.NextLevelXP = &next
+	}
+	for _, d := range activity {
+		resp.Activity = append(resp.Activity, dto.ActivityDayResponse{
+			Date:         d.ActivityDate.Format(time.DateOnly),
+			Answers:      d.Answers,
+			StudySeconds: d.StudySeconds,
+			XP:           d.XP,
+			Frozen:       d.Frozen,
+		})
+	}
+	return resp, nil
+}
+
+// PurchaseFreeze 用积分购买一张连续学习保护卡
+// 扣减积分与增加保护卡在同一事务中完成，余额不足或已达上限时两者都不发生
+func (s *progressionService) PurchaseFreeze(ctx context.Context, userID int64) (*dto.ProgressResponse, error) {
+	payment := &models.PointsTransaction{
+		UserID: userID,
+		Amount: -s.freezePrice(),
+		Reason: wallet.ReasonPurchase,
+		Note:   "连续学习保护卡",
+	}
+	purchased, err := s.repo.PurchaseFreeze(userID, s.maxFreezes(), payment)
+	if err != nil {
+		return nil, err // 错误可能是 gorm.ErrRecordNotFound 或 wallet.ErrInsufficientBalance
+	}
+	if !purchased {
+		return nil, ErrFreezeLimitReached
+	}
+	s.wallet.CacheBalance(ctx, payment)
+
+	return s.GetProgress(userID, &dto.ProgressQuery{})
+}
+
+// Location 返回划分用户学习日所用的时区，供任务等按用户日期重置的业务使用
+func (s *progressionService) Location(userID int64) *time.Location {
+	loc, _ := s.location(userID)
+	return loc
+}
+
+// location 返回划分用户学习日所用的时区及其名称，用户未设置或设置无法识别时使用默认时区
+func (s *progressionService) location(userID int64) (*time.Location, string) {
+	tz, err := s.repo.FindTimezone(userID)
+	if err != nil {
+		logger.LogError("progression", "find_timezone", err, map[string]interface{}{"user_id": userID})
+	}
+	if tz == "" {
+		return s.defaultLocation, s.defaultLocation.String()
+	}
+	if loc, ok := s.locations.Load(tz); ok {
+		return loc.(*time.Location), tz
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return s.defaultLocation, s.defaultLocation.String()
+	}
+	s.locations.Store(tz, loc)
+	return loc, tz
+}
+
+func (s *progressionService) freezePrice() int {
+	if s.config.FreezePrice > 0 {
+		return s.config.FreezePrice
+	}
+	return defaultFreezePrice
+}
+
+func (s *progressionService) maxFreezes() int {
+	if s.config.MaxFreezes > 0 {
+		return s.config.MaxFreezes
+	}
+	return defaultMaxFreezes
+}
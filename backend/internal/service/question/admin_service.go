@@ -0,0 +1,440 @@
+/*
+File: admin_service.go
+Author: lxp
+Description: 题库后台管理业务逻辑 (题目增删改与审核流程)
+*/
+package question
+
+import (
+	"errors"
+	"fmt"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/rbac"
+	"zhixue-backend/internal/repository/question"
+	"zhixue-backend/models"
+)
+
+var (
+	ErrQuestionForbidden      = errors.New("no permission to manage this question")
+	ErrInvalidReviewAction    = errors.New("invalid review status transition")
+	ErrReviewCommentRequired  = errors.New("review comment is required")
+	ErrSelfReview             = errors.New("authors cannot review their own questions")
+	ErrInvalidQuestionContent = errors.New("invalid question content")
+	ErrKnowledgePointNotFound = errors.New("knowledge point not found")
+)
+
+// 审核状态，与数据库 review_status 枚举保持一致
+const (
+	ReviewDraft     = "draft"
+	ReviewReviewing = "reviewing"
+	ReviewApproved  = "approved"
+	ReviewRejected  = "rejected"
+)
+
+// 审核动作，与数据库 review_action 枚举保持一致
+const (
+	ActionSubmit  = "submit"
+	ActionApprove = "approve"
+	ActionReject  = "reject"
+	ActionRevise  = "revise"
+)
+
+// reviewTransitions 定义审核状态机：动作 -> 允许的起始状态与目标状态
+//
+//	draft/rejected --submit--> reviewing --approve--> approved
+//	                                     --reject---> rejected
+//	approved/rejected --编辑--> draft (revise)
+var reviewTransitions = map[string]struct {
+	From []string
+	To   string
+}{
+	ActionSubmit:  {From: []string{ReviewDraft, ReviewRejected}, To: ReviewReviewing},
+	ActionApprove: {From: []string{ReviewReviewing}, To: ReviewApproved},
+	ActionReject:  {From: []string{ReviewReviewing}, To: ReviewRejected},
+}
+
+// editableStatuses 允许编辑的审核状态，审核中的题目需等待审核结果
+var editableStatuses = []string{ReviewDraft, ReviewRejected, ReviewApproved}
+
+// canManage 判断用户能否编辑/提交/删除该题目：教师只能管理自己创建的题目，管理员可管理全部题目
+func canManage(op rbac.Subject, q *models.Question) bool {
+	return op.CanAccess(q.AuthorID, rbac.PermQuestionManage, rbac.PermQuestionManageAll)
+}
+
+// AdminService 定义题库后台管理服务的接口
+type AdminService interface {
+	ListQuestions(op rbac.Subject, query *dto.AdminListQuestionsQuery) (*dto.PageResponse, error)
+	GetQuestion(op rbac.Subject, questionID int64) (*dto.AdminQuestionResponse, error)
+	CreateQuestion(op rbac.Subject, req *dto.SaveQuestionRequest) (*dto.AdminQuestionResponse, error)
+	UpdateQuestion(op rbac.Subject, questionID int64, req *dto.SaveQuestionRequest) (*dto.AdminQuestionResponse, error)
+	DeleteQuestion(op rbac.Subject, questionID int64) error
+	SubmitForReview(op rbac.Subject, questionID int64, comment string) (*dto.AdminQuestionResponse, error)
+	ApproveQuestion(op rbac.Subject, questionID int64, comment string) (*dto.AdminQuestionResponse, error)
+	RejectQuestion(op rbac.Subject, questionID int64, comment string) (*dto.AdminQuestionResponse, error)
+}
+
+// adminService 实现了AdminService接口
+type adminService struct {
+	repo question.Repository
+}
+
+// NewAdminService 创建一个新的题库后台管理服务实例
+func NewAdminService(repo question.Repository) AdminService {
+	return &adminService{repo: repo}
+}
+
+// ListQuestions 分页获取题目列表
+// 没有 question:manage_all 权限时只能看到自己创建的题目；审核人按 reviewing 状态筛选时可以看到全部待审核题目
+func (s *adminService) ListQuestions(op rbac.Subject, query *dto.AdminListQuestionsQuery) (*dto.PageResponse, error) {
+	query.Normalize()
+
+	filter := question.AdminFilter{
+		ListFilter: question.ListFilter{
+			Page:             query.Page,
+			PageSize:         query.PageSize,
+			KnowledgePointID: query.KnowledgePointID,
+			GradeLevel:       query.GradeLevel,
+		},
+		ReviewStatus: query.ReviewStatus,
+		QuestionType: query.QuestionType,
+		IsActive:     query.IsActive,
+		AuthorID:     query.AuthorID,
+		Keyword:      query.Keyword,
+	}
+	reviewQueue := query.ReviewStatus == ReviewReviewing && op.Can(rbac.PermQuestionReview)
+	if !op.Can(rbac.PermQuestionManageAll) && !reviewQueue {
+		filter.AuthorID = &op.UserID
+	}
+
+	questions, total, err := s.repo.AdminList(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list questions: %w", err)
+	}
+
+	ids := make([]int64, 0, len(questions))
+	for _, q := range questions {
+		ids = append(ids, q.ID)
+	}
+	pointsByQuestion, err := s.repo.FindKnowledgePointsByQuestionIDs(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load knowledge points: %w", err)
+	}
+
+	items := make([]dto.AdminQuestionResponse, 0, len(questions))
+	for i := range questions {
+		items = append(items, toAdminQuestionResponse(&questions[i], pointsByQuestion[questions[i].ID]))
+	}
+	return dto.NewPageResponse(items, filter.Page, filter.PageSize, total), nil
+}
+
+// GetQuestion 获取题目详情及审核记录
+func (s *adminService) GetQuestion(op rbac.Subject, questionID int64) (*dto.AdminQuestionResponse, error) {
+	q, err := s.repo.FindByID(questionID)
+	if err != nil {
+		return nil, err // 错误可能是 gorm.ErrRecordNotFound
+	}
+	// 审核人可以查看待审核的题目，其余状态只能查看自己有权管理的题目
+	if !canManage(op, q) && !(op.Can(rbac.PermQuestionReview) && q.ReviewStatus == ReviewReviewing) {
+		return nil, ErrQuestionForbidden
+	}
+	return s.detail(q)
+}
+
+// CreateQuestion 创建题目，新题目为草稿状态
+func (s *adminService) CreateQuestion(op rbac.Subject, req *dto.SaveQuestionRequest) (*dto.AdminQuestionResponse, error) {
+	if err := s.validate(req); err != nil {
+		return nil, err
+	}
+
+	q := &models.Question{AuthorID: &op.UserID, IsActive: true}
+	applySaveRequest(q, req)
+	q.ReviewStatus = ReviewDraft
+
+	if err := s.repo.Create(q, uniqueIDs(req.KnowledgePointIDs)); err != nil {
+		return nil, fmt.Errorf("failed to create question: %w", err)
+	}
+	return s.reload(q.ID)
+}
+
+// UpdateQuestion 整体更新题目内容
+// 已通过或已驳回的题目被修改后回到草稿状态，需要重新提交审核；审核中的题目不允许修改
+func (s *adminService) UpdateQuestion(op rbac.Subject, questionID int64, req *dto.SaveQuestionRequest) (*dto.AdminQuestionResponse, error) {
+	q, err := s.repo.FindByID(questionID)
+	if err != nil {
+		return nil, err // 错误可能是 gorm.ErrRecordNotFound
+	}
+	if !canManage(op, q) {
+		return nil, ErrQuestionForbidden
+	}
+	if !containsStatus(editableStatuses, q.ReviewStatus) {
+		return nil, ErrInvalidReviewAction
+	}
+	if err := s.validate(req); err != nil {
+		return nil, err
+	}
+
+	var review *models.QuestionReview
+	if q.ReviewStatus != ReviewDraft {
+		review = &models.QuestionReview{
+			QuestionID: q.ID,
+			ReviewerID: &op.UserID,
+			Action:     ActionRevise,
+			FromStatus: q.ReviewStatus,
+			ToStatus:   ReviewDraft,
+		}
+	}
+
+	applySaveRequest(q, req)
+	q.ReviewStatus = ReviewDraft
+
+	ok, err := s.repo.Update(q, uniqueIDs(req.KnowledgePointIDs), editableStatuses, review)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update question: %w", err)
+	}
+	if !ok {
+		return nil, ErrInvalidReviewAction // 并发请求已将题目提交审核
+	}
+	return s.reload(q.ID)
+}
+
+// DeleteQuestion 软删除题目 (停用)，历史答题记录仍然保留
+func (s *adminService) DeleteQuestion(op rbac.Subject, questionID int64) error {
+	q, err := s.repo.FindByID(questionID)
+	if err != nil {
+		return err // 错误可能是 gorm.ErrRecordNotFound
+	}
+	if !canManage(op, q) {
+		return ErrQuestionForbidden
+	}
+	return s.repo.SetActive(q.ID, false)
+}
+
+// SubmitForReview 作者将草稿或被驳回的题目提交审核
+func (s *adminService) SubmitForReview(op rbac.Subject, questionID int64, comment string) (*dto.AdminQuestionResponse, error) {
+	return s.review(op, questionID, ActionSubmit, comment)
+}
+
+// ApproveQuestion 审核通过题目，通过后学生即可看到 (启用状态下)
+func (s *adminService) ApproveQuestion(op rbac.Subject, questionID int64, comment string) (*dto.AdminQuestionResponse, error) {
+	return s.review(op, questionID, ActionApprove, comment)
+}
+
+// RejectQuestion 驳回题目，必须填写审核意见
+func (s *adminService) RejectQuestion(op rbac.Subject, questionID int64, comment string) (*dto.AdminQuestionResponse, error) {
+	if comment == "" {
+		return nil, ErrReviewCommentRequired
+	}
+	return s.review(op, questionID, ActionReject, comment)
+}
+
+// review 执行一次审核状态迁移并记录审核意见
+func (s *adminService) review(op rbac.Subject, questionID int64, action, comment string) (*dto.AdminQuestionResponse, error) {
+	q, err := s.repo.FindByID(questionID)
+	if err != nil {
+		return nil, err // 错误可能是 gorm.ErrRecordNotFound
+	}
+
+	if action == ActionSubmit {
+		if !canManage(op, q) {
+			return nil, ErrQuestionForbidden
+		}
+	} else {
+		// 审核人不能审核自己的题目，拥有 question:manage_all 的管理员除外
+		if !op.Can(rbac.PermQuestionReview) {
+			return nil, ErrQuestionForbidden
+		}
+		if op.Owns(q.AuthorID) && !op.Can(rbac.PermQuestionManageAll) {
+			return nil, ErrSelfReview
+		}
+	}
+
+	transition := reviewTransitions[action]
+	if !containsStatus(transition.From, q.ReviewStatus) {
+		return nil, ErrInvalidReviewAction
+	}
+
+	ok, err := s.repo.TransitionReview(q.ID, transition.From, &models.QuestionReview{
+		QuestionID: q.ID,
+		ReviewerID: &op.UserID,
+		Action:     action,
+		FromStatus: q.ReviewStatus,
+		ToStatus:   transition.To,
+		Comment:    comment,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update review status: %w", err)
+	}
+	if !ok {
+		return nil, ErrInvalidReviewAction
+	}
+	return s.reload(q.ID)
+}
+
+// validate 校验题目内容是否能被自动判分，以及关联的知识点是否存在
+func (s *adminService) validate(req *dto.SaveQuestionRequest) error {
+	if err := validateAnswerKey(req); err != nil {
+		return err
+	}
+
+	ids := uniqueIDs(req.KnowledgePointIDs)
+	count, err := s.repo.CountKnowledgePoints(ids)
+	if err != nil {
+		return err
+	}
+	if count != int64(len(ids)) {
+		return ErrKnowledgePointNotFound
+	}
+	return nil
+}
+
+// validateAnswerKey 按题型校验选项与标准答案的格式，约定见 Grade
+func validateAnswerKey(req *dto.SaveQuestionRequest) error {
+	switch req.QuestionType {
+	case TypeSingleChoice, TypeMultipleChoice:
+		options := 0
+		for key := range req.Choices {
+			if key != "grading" {
+				options++
+			}
+		}
+		if options < 2 {
+			return fmt.Errorf("%w: 选择题至少需要两个选项", ErrInvalidQuestionContent)
+		}
+
+		keys := parseChoiceKeys(req.CorrectAnswer, req.Choices)
+		if len(keys) == 0 {
+			return fmt.Errorf("%w: 缺少正确选项", ErrInvalidQuestionContent)
+		}
+		for _, key := range keys {
+			if _, ok := req.Choices[key]; !ok || key == "grading" {
+				return fmt.Errorf("%w: 正确选项 %s 不在选项中", ErrInvalidQuestionContent, key)
+			}
+		}
+		if req.QuestionType == TypeSingleChoice && len(keys) != 1 {
+			return fmt.Errorf("%w: 单选题只能有一个正确选项", ErrInvalidQuestionContent)
+		}
+	case TypeFillBlank:
+		for i, accepted := range parseBlankAnswerKey(req.CorrectAnswer) {
+			if len(accepted) == 0 {
+				return fmt.Errorf("%w: 第%d个空缺少答案", ErrInvalidQuestionContent, i+1)
+			}
+			for _, answer := range accepted {
+				if normalizeText(answer) == "" {
+					return fmt.Errorf("%w: 第%d个空存在空答案", ErrInvalidQuestionContent, i+1)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// reload 重新读取题目并构造详情响应
+func (s *adminService) reload(questionID int64) (*dto.AdminQuestionResponse, error) {
+	q, err := s.repo.FindByID(questionID)
+	if err != nil {
+		return nil, err
+	}
+	return s.detail(q)
+}
+
+// detail 构造包含知识点与审核记录的题目详情
+func (s *adminService) detail(q *models.Question) (*dto.AdminQuestionResponse, error) {
+	pointsByQuestion, err := s.repo.FindKnowledgePointsByQuestionIDs([]int64{q.ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load knowledge points: %w", err)
+	}
+	reviews, err := s.repo.ListReviews(q.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load reviews: %w", err)
+	}
+
+	resp := toAdminQuestionResponse(q, pointsByQuestion[q.ID])
+	resp.Reviews = make([]dto.QuestionReviewItem, 0, len(reviews))
+	for _, r := range reviews {
+		resp.Reviews = append(resp.Reviews, dto.QuestionReviewItem{
+			ID:         r.ID,
+			ReviewerID: r.ReviewerID,
+			Action:     r.Action,
+			FromStatus: r.FromStatus,
+			ToStatus:   r.ToStatus,
+			Comment:    r.Comment,
+			CreatedAt:  r.CreatedAt,
+		})
+	}
+	return &resp, nil
+}
+
+// applySaveRequest 将请求中的可编辑字段写入题目模型
+func applySaveRequest(q *models.Question, req *dto.SaveQuestionRequest) {
+	q.Title = req.Title
+	q.Content = req.Content
+	q.QuestionType = req.QuestionType
+	q.Difficulty = req.Difficulty
+	q.GradeLevel = req.GradeLevel
+	q.EstimatedTime = req.EstimatedTime
+	if q.EstimatedTime == 0 {
+		q.EstimatedTime = 60
+	}
+	q.CorrectAnswer = req.CorrectAnswer
+	q.AnswerAnalysis = req.AnswerAnalysis
+	q.Hints = req.Hints
+	q.Choices = req.Choices
+	q.Tags = req.Tags
+	q.Source = req.Source
+}
+
+// toAdminQuestionResponse 构造后台题目响应
+func toAdminQuestionResponse(q *models.Question, points []models.KnowledgePoint) dto.AdminQuestionResponse {
+	briefs := make([]dto.KnowledgePointBrief, 0, len(points))
+	for _, p := range points {
+		briefs = append(briefs, dto.KnowledgePointBrief{ID: p.ID, Name: p.Name, Code: p.Code})
+	}
+
+	return dto.AdminQuestionResponse{
+		ID:              q.ID,
+		Title:           q.Title,
+		Content:         q.Content,
+		QuestionType:    q.QuestionType,
+		Difficulty:      q.Difficulty,
+		DifficultyLevel: DifficultyLabel(q.Difficulty),
+		GradeLevel:      q.GradeLevel,
+		EstimatedTime:   q.EstimatedTime,
+		CorrectAnswer:   q.CorrectAnswer,
+		AnswerAnalysis:  q.AnswerAnalysis,
+		Hints:           q.Hints,
+		Choices:         q.Choices,
+		Tags:            q.Tags,
+		Source:          q.Source,
+		AuthorID:        q.AuthorID,
+		ReviewStatus:    q.ReviewStatus,
+		IsActive:        q.IsActive,
+		UsageCount:      q.UsageCount,
+		CorrectRate:     q.CorrectRate,
+		AvgResponseTime: q.AvgResponseTime,
+		KnowledgePoints: briefs,
+		CreatedAt:       q.CreatedAt,
+		UpdatedAt:       q.UpdatedAt,
+	}
+}
+
+func containsStatus(list []string, status string) bool {
+	for _, s := range list {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueIDs 对ID列表去重并保持原有顺序
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	result := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
@@ -0,0 +1,274 @@
+/*
+File: grader.go
+Author: lxp
+Description: 题目自动判分引擎
+*/
+package question
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"zhixue-backend/models"
+	"zhixue-backend/pkg/mathexpr"
+)
+
+var (
+	ErrInvalidAnswer           = errors.New("invalid answer format")
+	ErrManualGradingRequired   = errors.New("question type requires manual grading")
+	ErrUnsupportedQuestionType = errors.New("unsupported question type")
+)
+
+// 题目类型，与数据库 question_type 枚举保持一致
+const (
+	TypeSingleChoice   = "single_choice"
+	TypeMultipleChoice = "multiple_choice"
+	TypeFillBlank      = "fill_blank"
+	TypeCalculation    = "calculation"
+	TypeProof          = "proof"
+)
+
+// 默认的数值误差容忍度，可通过 Tags 中的 tolerance 字段覆盖
+const defaultTolerance = 1e-6
+
+// GradeResult 描述一次判分的结果
+type GradeResult struct {
+	IsCorrect    bool
+	BlankResults []bool // 仅填空题有效，逐空判分结果
+}
+
+// Grade 根据题目类型对用户答案进行判分
+//
+// 各题型的答案约定：
+//   - single_choice / multiple_choice: Choices 为 {"A": "选项内容", ...}，
+//     CorrectAnswer 为选项键，多选以逗号分隔，如 "A,C"
+//   - fill_blank: CorrectAnswer 为JSON数组，每个元素为一个空的答案，
+//     元素也可以是可接受答案的数组，如 [["1/2", "x=2"], "3"]；非JSON时视为单空
+//   - calculation: CorrectAnswer 为数值、表达式或等式，按数学等价与误差容忍度比较
+//
+// fill_blank 与 calculation 的判分选项见 gradingOptions
+//   - proof: 需要人工批改，不支持自动判分
+func Grade(q *models.Question, answer string) (*GradeResult, error) {
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return nil, ErrInvalidAnswer
+	}
+
+	switch q.QuestionType {
+	case TypeSingleChoice:
+		return gradeChoice(q, answer, false)
+	case TypeMultipleChoice:
+		return gradeChoice(q, answer, true)
+	case TypeFillBlank:
+		return gradeFillBlank(q, answer)
+	case TypeCalculation:
+		return gradeCalculation(q, answer)
+	case TypeProof:
+		return nil, ErrManualGradingRequired
+	default:
+		return nil, ErrUnsupportedQuestionType
+	}
+}
+
+// ================== 选择题 ==================
+
+// gradeChoice 比较用户所选选项集合与正确选项集合
+func gradeChoice(q *models.Question, answer string, multiple bool) (*GradeResult, error) {
+	selected := parseChoiceKeys(answer, q.Choices)
+	if len(selected) == 0 || (!multiple && len(selected) != 1) {
+		return nil, ErrInvalidAnswer
+	}
+
+	// 所选选项必须存在于题目的选项中
+	if len(q.Choices) > 0 {
+		for _, key := range selected {
+			if _, ok := q.Choices[key]; !ok {
+				return nil, ErrInvalidAnswer
+			}
+		}
+	}
+
+	expected := parseChoiceKeys(q.CorrectAnswer, q.Choices)
+	return &GradeResult{IsCorrect: equalStringSets(selected, expected)}, nil
+}
+
+// parseChoiceKeys 将选项答案解析为去重排序后的选项键列表
+// 支持JSON数组、逗号/空格分隔以及 "AC" 这类连写形式
+func parseChoiceKeys(raw string, choices models.JSONB) []string {
+	raw = strings.TrimSpace(raw)
+
+	var parts []string
+	if err := json.Unmarshal([]byte(raw), &parts); err != nil {
+		parts = strings.FieldsFunc(raw, func(r rune) bool {
+			return r == ',' || r == '，' || r == '、' || unicode.IsSpace(r)
+		})
+	}
+
+	// 连写形式：整体不是选项键，但每个字符都是选项键
+	if len(parts) == 1 && len(choices) > 0 {
+		if _, ok := choices[strings.ToUpper(parts[0])]; !ok && len([]rune(parts[0])) > 1 {
+			parts = strings.Split(parts[0], "")
+		}
+	}
+
+	seen := make(map[string]bool)
+	keys := make([]string, 0, len(parts))
+	for _, p := range parts {
+		key := strings.ToUpper(strings.TrimSpace(p))
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// equalStringSets 判断两个已排序的字符串列表是否相同
+func equalStringSets(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ================== 填空题 ==================
+
+// gradeFillBlank 逐空比较用户答案，所有空都正确才算答对
+func gradeFillBlank(q *models.Question, answer string) (*GradeResult, error) {
+	expected := parseBlankAnswerKey(q.CorrectAnswer)
+	given := parseBlankAnswers(answer)
+	if len(given) != len(expected) {
+		return nil, ErrInvalidAnswer
+	}
+
+	result := &GradeResult{IsCorrect: true, BlankResults: make([]bool, len(expected))}
+	for i, accepted := range expected {
+		for _, candidate := range accepted {
+			if blankEqual(q, given[i], candidate) {
+				result.BlankResults[i] = true
+				break
+			}
+		}
+		if !result.BlankResults[i] {
+			result.IsCorrect = false
+		}
+	}
+	return result, nil
+}
+
+// parseBlankAnswerKey 解析填空题的标准答案，返回每个空可接受的答案列表
+func parseBlankAnswerKey(raw string) [][]string {
+	var items []interface{}
+	if err := json.Unmarshal([]byte(raw), &items); err != nil {
+		return [][]string{{raw}}
+	}
+
+	key := make([][]string, 0, len(items))
+	for _, item := range items {
+		switch v := item.(type) {
+		case []interface{}:
+			accepted := make([]string, 0, len(v))
+			for _, alt := range v {
+				accepted = append(accepted, toString(alt))
+			}
+			key = append(key, accepted)
+		default:
+			key = append(key, []string{toString(v)})
+		}
+	}
+	return key
+}
+
+// parseBlankAnswers 解析用户提交的填空答案，支持JSON数组或单个字符串
+func parseBlankAnswers(raw string) []string {
+	var items []interface{}
+	if err := json.Unmarshal([]byte(raw), &items); err != nil {
+		return []string{raw}
+	}
+
+	answers := make([]string, 0, len(items))
+	for _, item := range items {
+		answers = append(answers, toString(item))
+	}
+	return answers
+}
+
+// blankEqual 比较单个空的答案：标准答案是数学表达式时按数学等价判断，否则按归一化文本比较
+func blankEqual(q *models.Question, given, expected string) bool {
+	if mathexpr.LooksLikeMath(expected) {
+		if equal, err := mathexpr.Equivalent(given, expected, gradingOptions(q)); err == nil {
+			return equal
+		}
+	}
+	return normalizeText(given) == normalizeText(expected)
+}
+
+// ================== 计算题 ==================
+
+// gradeCalculation 按数学等价与误差容忍度比较计算结果，无法解析的答案按归一化文本比较
+func gradeCalculation(q *models.Question, answer string) (*GradeResult, error) {
+	return &GradeResult{IsCorrect: blankEqual(q, answer, q.CorrectAnswer)}, nil
+}
+
+// ================== 工具函数 ==================
+
+// gradingOptions 读取题目的判分选项
+// 选项可配置在 Tags 或 Choices 的 grading 字段中 (后者优先)，
+// Tags 顶层的 tolerance 字段作为数值误差容忍度的默认值
+func gradingOptions(q *models.Question) mathexpr.Options {
+	merged := map[string]interface{}{"tolerance": defaultTolerance}
+	if v, ok := q.Tags["tolerance"].(float64); ok && v >= 0 {
+		merged["tolerance"] = v
+	}
+	for _, source := range []models.JSONB{q.Tags, q.Choices} {
+		if grading, ok := source["grading"].(map[string]interface{}); ok {
+			for k, v := range grading {
+				merged[k] = v
+			}
+		}
+	}
+	return mathexpr.OptionsFromMap(merged)
+}
+
+// normalizeText 对答案文本做归一化：全角转半角、去除空白、统一小写
+func normalizeText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '　':
+			continue
+		case r >= '！' && r <= '～':
+			r -= 0xfee0
+		}
+		if unicode.IsSpace(r) {
+			continue
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// toString 将JSON解析出的任意值转换为字符串
+func toString(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case nil:
+		return ""
+	default:
+		b, _ := json.Marshal(val)
+		return string(b)
+	}
+}
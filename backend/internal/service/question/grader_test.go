@@ -0,0 +1,152 @@
+/*
+File: grader_test.go
+Author: lxp
+Description: 题目自动判分引擎的单元测试
+*/
+package question
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"zhixue-backend/models"
+)
+
+func TestGradeChoice(t *testing.T) {
+	choices := models.JSONB{"A": "1", "B": "2", "C": "3", "D": "4"}
+	tests := []struct {
+		name     string
+		qType    string
+		expected string
+		answer   string
+		want     bool
+		wantErr  error
+	}{
+		{"单选正确", TypeSingleChoice, "B", "B", true, nil},
+		{"单选小写", TypeSingleChoice, "B", "b", true, nil},
+		{"单选错误", TypeSingleChoice, "B", "C", false, nil},
+		{"单选多个选项", TypeSingleChoice, "B", "B,C", false, ErrInvalidAnswer},
+		{"单选不存在的选项", TypeSingleChoice, "B", "E", false, ErrInvalidAnswer},
+		{"多选逗号分隔", TypeMultipleChoice, "A,C", "C,A", true, nil},
+		{"多选连写", TypeMultipleChoice, "A,C", "ac", true, nil},
+		{"多选JSON数组", TypeMultipleChoice, "A,C", `["A","C"]`, true, nil},
+		{"多选中文顿号", TypeMultipleChoice, "A,C", "A、C", true, nil},
+		{"多选重复选项", TypeMultipleChoice, "A,C", "A,A,C", true, nil},
+		{"多选少选", TypeMultipleChoice, "A,C", "A", false, nil},
+		{"多选多选", TypeMultipleChoice, "A,C", "A,B,C", false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &models.Question{QuestionType: tt.qType, Choices: choices, CorrectAnswer: tt.expected}
+			result, err := Grade(q, tt.answer)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Grade(%q) error = %v, want %v", tt.answer, err, tt.wantErr)
+			}
+			if err == nil && result.IsCorrect != tt.want {
+				t.Errorf("Grade(%q) = %v, want %v", tt.answer, result.IsCorrect, tt.want)
+			}
+		})
+	}
+}
+
+func TestGradeFillBlank(t *testing.T) {
+	tests := []struct {
+		name       string
+		expected   string
+		answer     string
+		wantBlanks []bool
+		wantErr    error
+	}{
+		{"单空文本", "三角形", "三角形", []bool{true}, nil},
+		{"全角与大小写", `["ABC"]`, `["ａｂｃ"]`, []bool{true}, nil},
+		{"多空部分正确", `["3", "5"]`, `["3", "4"]`, []bool{true, false}, nil},
+		{"可接受答案列表", `[["1/2", "0.5"], "3"]`, `["0.5", "3"]`, []bool{true, true}, nil},
+		{"数学等价", `["x^2+2x+1"]`, `["(x+1)^2"]`, []bool{true}, nil},
+		{"数字比较", `[4]`, `["4.0"]`, []bool{true}, nil},
+		{"单字母变量", `["x"]`, `["X"]`, []bool{true}, nil},
+		{"变位词不视为等价", `["no"]`, `["on"]`, []bool{false}, nil},
+		{"单位变位词", `["cm"]`, `["mc"]`, []bool{false}, nil},
+		{"单词变位词", `["stop"]`, `["pots"]`, []bool{false}, nil},
+		{"单词相同", `["stop"]`, `["Stop"]`, []bool{true}, nil},
+		{"空数不符", `["1", "2"]`, `["1"]`, nil, ErrInvalidAnswer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &models.Question{QuestionType: TypeFillBlank, CorrectAnswer: tt.expected}
+			result, err := Grade(q, tt.answer)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Grade(%q) error = %v, want %v", tt.answer, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(result.BlankResults, tt.wantBlanks) {
+				t.Errorf("Grade(%q) blanks = %v, want %v", tt.answer, result.BlankResults, tt.wantBlanks)
+			}
+			allCorrect := true
+			for _, ok := range tt.wantBlanks {
+				allCorrect = allCorrect && ok
+			}
+			if result.IsCorrect != allCorrect {
+				t.Errorf("Grade(%q) = %v, want %v", tt.answer, result.IsCorrect, allCorrect)
+			}
+		})
+	}
+}
+
+func TestGradeCalculation(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		answer   string
+		tags     models.JSONB
+		want     bool
+	}{
+		{"精确相等", "12", "12", nil, true},
+		{"分数与小数", "3/4", "0.75", nil, true},
+		{"等式", "x=2", "2=x", nil, true},
+		{"默认容忍度", "0.3333333", "1/3", nil, true},
+		{"容忍度配置", "3.14159", "3.14", models.JSONB{"tolerance": 0.01}, true},
+		{"超出容忍度", "3.14159", "3.1", models.JSONB{"tolerance": 0.01}, false},
+		{"最简分数要求", "1/2", "2/4", models.JSONB{"grading": map[string]interface{}{"simplest_fraction": true}}, false},
+		{"裸值匹配等式", "x=5", "5", models.JSONB{"grading": map[string]interface{}{"accept_bare_value": true}}, true},
+		{"无法解析时按文本比较", "无解", "无解", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &models.Question{QuestionType: TypeCalculation, CorrectAnswer: tt.expected, Tags: tt.tags}
+			result, err := Grade(q, tt.answer)
+			if err != nil {
+				t.Fatalf("Grade(%q) error: %v", tt.answer, err)
+			}
+			if result.IsCorrect != tt.want {
+				t.Errorf("Grade(%q) = %v, want %v", tt.answer, result.IsCorrect, tt.want)
+			}
+		})
+	}
+}
+
+func TestGradeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		qType   string
+		answer  string
+		wantErr error
+	}{
+		{"空答案", TypeCalculation, "   ", ErrInvalidAnswer},
+		{"证明题", TypeProof, "证明如下", ErrManualGradingRequired},
+		{"未知题型", "essay", "答案", ErrUnsupportedQuestionType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &models.Question{QuestionType: tt.qType, CorrectAnswer: "1"}
+			if _, err := Grade(q, tt.answer); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Grade(%q) error = %v, want %v", tt.answer, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,397 @@
+/*
+File: question_service.go
+Author: lxp
+Description: 题库服务业务逻辑
+*/
+package question
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/event"
+	"zhixue-backend/internal/repository/learning"
+	"zhixue-backend/internal/repository/question"
+	"zhixue-backend/internal/repository/user"
+	"zhixue-backend/models"
+)
+
+var (
+	ErrInvalidDifficulty = errors.New("invalid difficulty level")
+)
+
+// 推荐题目时允许偏离用户当前难度的范围
+const recommendTolerance = 0.5
+
+// prerequisiteMinCorrect 推荐题目时，先修知识点至少答对的不同题目数，未达到时不推荐依赖它的知识点的题目
+const prerequisiteMinCorrect = 3
+
+// difficultyBand 描述一个难度档位对应的难度区间 [Min, Max)
+type difficultyBand struct {
+	Label string
+	Min   float64
+	Max   *float64
+}
+
+var (
+	bandEasyMax   = 2.0
+	bandMediumMax = 3.5
+
+	bandEasy   = difficultyBand{Label: "简单", Min: 1.0, Max: &bandEasyMax}
+	bandMedium = difficultyBand{Label: "中等", Min: 2.0, Max: &bandMediumMax}
+	bandHard   = difficultyBand{Label: "困难", Min: 3.5, Max: nil}
+
+	// difficultyBands 将查询参数中的难度名称映射为难度区间，同时支持中英文
+	difficultyBands = map[string]difficultyBand{
+		"简单":     bandEasy,
+		"easy":   bandEasy,
+		"中等":     bandMedium,
+		"medium": bandMedium,
+		"困难":     bandHard,
+		"hard":   bandHard,
+	}
+)
+
+// DifficultyLabel 返回难度数值对应的档位名称
+func DifficultyLabel(difficulty float64) string {
+	switch {
+	case difficulty < bandEasyMax:
+		return bandEasy.Label
+	case difficulty < bandMediumMax:
+		return bandMedium.Label
+	default:
+		return bandHard.Label
+	}
+}
+
+// Service 定义题库服务的接口
+type Service interface {
+	ListQuestions(userID int64, query *dto.ListQuestionsQuery) (*dto.PageResponse, error)
+	GetQuestion(userID, questionID int64) (*dto.QuestionResponse, error)
+	ListKnowledgePoints(gradeLevel *int) ([]dto.KnowledgePointResponse, error)
+	SubmitAnswer(userID, questionID int64, req *dto.SubmitAnswerRequest, clientIP string) (*dto.SubmitAnswerResponse, error)
+}
+
+// SessionGuard 在答题前校验答题是否符合所属学习会话的规则 (由学习会话服务实现)
+type SessionGuard interface {
+	ValidateAnswer(userID int64, sessionID string, hintUsedCount int) error
+}
+
+// Feedback 表示判分后向学生返回的内容，取值越大越严格
+type Feedback int
+
+const (
+	FeedbackFull   Feedback = iota // 返回对错、正确答案与解析
+	FeedbackResult                 // 只返回对错，不返回答案 (如作业作答中)
+	FeedbackNone                   // 对错与答案都不返回，题目详情也不返回提示 (如考试进行中)
+)
+
+// AnswerPolicy 在答题前做额外校验，并决定判分结果与题目详情中返回的内容 (由作业、考试服务实现)
+type AnswerPolicy interface {
+	CheckAnswer(userID int64, sessionID string, questionID int64) (Feedback, error)
+}
+
+// AnswerPolicies 组合多个答案策略：依次校验，任一策略拒绝答题即返回其错误，返回内容取最严格的策略
+type AnswerPolicies []AnswerPolicy
+
+// CheckAnswer 实现 AnswerPolicy
+func (p AnswerPolicies) CheckAnswer(userID int64, sessionID string, questionID int64) (Feedback, error) {
+	feedback := FeedbackFull
+	for _, policy := range p {
+		f, err := policy.CheckAnswer(userID, sessionID, questionID)
+		if err != nil {
+			return FeedbackNone, err
+		}
+		if f > feedback {
+			feedback = f
+		}
+	}
+	return feedback, nil
+}
+
+// questionService 实现了Service接口
+type questionService struct {
+	repo         question.Repository
+	userRepo     user.Repository
+	learningRepo learning.Repository
+	sessionGuard SessionGuard
+	answerPolicy AnswerPolicy
+	events       *event.Bus
+}
+
+// NewQuestionService 创建一个新的题库服务实例，answerPolicy 为空时总是返回答案
+func NewQuestionService(repo question.Repository, userRepo user.Repository, learningRepo learning.Repository,
+	sessionGuard SessionGuard, answerPolicy AnswerPolicy, events *event.Bus) Service {
+	return &questionService{
+		repo:         repo,
+		userRepo:     userRepo,
+		learningRepo: learningRepo,
+		sessionGuard: sessionGuard,
+		answerPolicy: answerPolicy,
+		events:       events,
+	}
+}
+
+// ListQuestions 分页获取题目列表，recommend=true 时按用户当前难度推荐题目
+func (s *questionService) ListQuestions(userID int64, query *dto.ListQuestionsQuery) (*dto.PageResponse, error) {
+	query.Normalize()
+
+	filter := question.ListFilter{
+		Page:             query.Page,
+		PageSize:         query.PageSize,
+		KnowledgePointID: query.KnowledgePointID,
+		GradeLevel:       query.GradeLevel,
+	}
+
+	if query.Difficulty != "" {
+		band, ok := difficultyBands[query.Difficulty]
+		if !ok {
+			return nil, ErrInvalidDifficulty
+		}
+		filter.MinDifficulty = &band.Min
+		filter.MaxDifficulty = band.Max
+	}
+
+	var (
+		questions []models.Question
+		total     int64
+		err       error
+	)
+	if query.Recommend {
+		questions, total, err = s.recommend(userID, filter)
+	} else {
+		questions, total, err = s.repo.List(filter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list questions: %w", err)
+	}
+
+	items, err := s.buildQuestionResponses(questions)
+	if err != nil {
+		return nil, err
+	}
+	// 进行中考试里的题目不返回提示，与题目详情一致
+	for i := range items {
+		feedback, err := s.feedback(userID, "", items[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		if feedback == FeedbackNone {
+			items[i].Hints = nil
+		}
+	}
+
+	return dto.NewPageResponse(items, filter.Page, filter.PageSize, total), nil
+}
+
+// recommend 根据用户学习档案推荐题目
+// 未指定年级时默认使用用户自身年级
+func (s *questionService) recommend(userID int64, filter question.ListFilter) ([]models.Question, int64, error) {
+	u, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	profile, err := s.userRepo.FindProfileByUserID(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if filter.GradeLevel == nil {
+		filter.GradeLevel = &u.GradeLevel
+	}
+
+	return s.repo.Recommend(question.RecommendFilter{
+		ListFilter:       filter,
+		UserID:           userID,
+		TargetDifficulty: profile.CurrentDifficulty,
+		Tolerance:        recommendTolerance,
+
+		PrerequisiteMinCorrect: prerequisiteMinCorrect,
+	})
+}
+
+// GetQuestion 获取题目详情，仅当用户已作答过该题且答案策略允许时才返回答案和解析
+// 题目在用户进行中的考试里时不返回提示
+func (s *questionService) GetQuestion(userID, questionID int64) (*dto.QuestionResponse, error) {
+	q, err := s.repo.FindPublishedByID(questionID)
+	if err != nil {
+		return nil, err // 错误可能是 gorm.ErrRecordNotFound
+	}
+
+	responses, err := s.buildQuestionResponses([]models.Question{*q})
+	if err != nil {
+		return nil, err
+	}
+	resp := &responses[0]
+
+	feedback, err := s.feedback(userID, "", questionID)
+	if err != nil {
+		return nil, err
+	}
+	if feedback == FeedbackNone {
+		// 题目在进行中的考试里，考试不能使用提示
+		resp.Hints = nil
+		return resp, nil
+	}
+
+	answered, err := s.repo.HasAnswered(userID, questionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check answer history: %w", err)
+	}
+	if answered && feedback == FeedbackFull {
+		resp.CorrectAnswer = &q.CorrectAnswer
+		resp.AnswerAnalysis = &q.AnswerAnalysis
+	}
+
+	return resp, nil
+}
+
+// SubmitAnswer 对用户答案进行服务端判分，并在同一事务中写入答题记录、更新题目与用户统计
+func (s *questionService) SubmitAnswer(userID, questionID int64, req *dto.SubmitAnswerRequest, clientIP string) (*dto.SubmitAnswerResponse, error) {
+	q, err := s.repo.FindPublishedByID(questionID)
+	if err != nil {
+		return nil, err // 错误可能是 gorm.ErrRecordNotFound
+	}
+
+	if err := s.sessionGuard.ValidateAnswer(userID, req.SessionID, req.HintUsedCount); err != nil {
+		return nil, err
+	}
+	feedback, err := s.feedback(userID, req.SessionID, questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := Grade(q, req.Answer)
+	if err != nil {
+		return nil, err
+	}
+
+	method := req.AnswerMethod
+	if method == "" {
+		method = "direct"
+		if req.HintUsedCount > 0 {
+			method = "hint"
+		}
+	}
+
+	record := &models.AnswerRecord{
+		UserID:           userID,
+		QuestionID:       questionID,
+		SessionID:        req.SessionID,
+		UserAnswer:       req.Answer,
+		IsCorrect:        result.IsCorrect,
+		ResponseTime:     req.ResponseTime,
+		HintUsedCount:    req.HintUsedCount,
+		DifficultyAtTime: q.Difficulty,
+		ConfidenceScore:  req.ConfidenceScore,
+		AnswerMethod:     method,
+		IPAddress:        clientIP,
+		DeviceInfo:       req.DeviceInfo,
+		CreatedAt:        time.Now(),
+	}
+	if err := s.learningRepo.SaveAnswer(record); err != nil {
+		return nil, fmt.Errorf("failed to save answer record: %w", err)
+	}
+
+	s.events.Publish(context.Background(), event.AnswerSubmitted{
+		UserID:        userID,
+		QuestionID:    questionID,
+		SessionID:     req.SessionID,
+		IsCorrect:     result.IsCorrect,
+		Difficulty:    q.Difficulty,
+		ResponseTime:  req.ResponseTime,
+		HintUsedCount: req.HintUsedCount,
+		AnsweredAt:    record.CreatedAt,
+	})
+
+	resp := &dto.SubmitAnswerResponse{QuestionID: questionID}
+	if feedback <= FeedbackResult {
+		resp.IsCorrect = &result.IsCorrect
+		resp.BlankResults = result.BlankResults
+	}
+	if feedback == FeedbackFull {
+		resp.CorrectAnswer = q.CorrectAnswer
+		resp.AnswerAnalysis = q.AnswerAnalysis
+	}
+	return resp, nil
+}
+
+// feedback 通过答案策略校验答题并决定返回的内容，未配置策略时全部返回
+func (s *questionService) feedback(userID int64, sessionID string, questionID int64) (Feedback, error) {
+	if s.answerPolicy == nil {
+		return FeedbackFull, nil
+	}
+	return s.answerPolicy.CheckAnswer(userID, sessionID, questionID)
+}
+
+// ListKnowledgePoints 获取知识点列表
+func (s *questionService) ListKnowledgePoints(gradeLevel *int) ([]dto.KnowledgePointResponse, error) {
+	points, err := s.repo.ListKnowledgePoints(gradeLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.KnowledgePointResponse, 0, len(points))
+	for _, p := range points {
+		result = append(result, toKnowledgePointResponse(&p))
+	}
+	return result, nil
+}
+
+// buildQuestionResponses 将题目模型转换为不含答案的响应结构，并附带关联知识点
+func (s *questionService) buildQuestionResponses(questions []models.Question) ([]dto.QuestionResponse, error) {
+	ids := make([]int64, 0, len(questions))
+	for _, q := range questions {
+		ids = append(ids, q.ID)
+	}
+
+	pointsByQuestion, err := s.repo.FindKnowledgePointsByQuestionIDs(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load knowledge points: %w", err)
+	}
+
+	result := make([]dto.QuestionResponse, 0, len(questions))
+	for i := range questions {
+		result = append(result, toQuestionResponse(&questions[i], pointsByQuestion[questions[i].ID]))
+	}
+	return result, nil
+}
+
+// toQuestionResponse 构造面向学生的题目响应，永远不包含答案和解析
+func toQuestionResponse(q *models.Question, points []models.KnowledgePoint) dto.QuestionResponse {
+	briefs := make([]dto.KnowledgePointBrief, 0, len(points))
+	for _, p := range points {
+		briefs = append(briefs, dto.KnowledgePointBrief{ID: p.ID, Name: p.Name, Code: p.Code})
+	}
+
+	return dto.QuestionResponse{
+		ID:              q.ID,
+		Title:           q.Title,
+		Content:         q.Content,
+		QuestionType:    q.QuestionType,
+		Difficulty:      q.Difficulty,
+		DifficultyLevel: DifficultyLabel(q.Difficulty),
+		GradeLevel:      q.GradeLevel,
+		EstimatedTime:   q.EstimatedTime,
+		Hints:           q.Hints,
+		Choices:         q.Choices,
+		Tags:            q.Tags,
+		KnowledgePoints: briefs,
+	}
+}
+
+// toKnowledgePointResponse 构造知识点响应
+func toKnowledgePointResponse(p *models.KnowledgePoint) dto.KnowledgePointResponse {
+	return dto.KnowledgePointResponse{
+		ID:              p.ID,
+		Name:            p.Name,
+		Code:            p.Code,
+		ParentID:        p.ParentID,
+		GradeLevel:      p.GradeLevel,
+		DifficultyRange: p.DifficultyRange,
+		Description:     p.Description,
+		SortOrder:       p.SortOrder,
+	}
+}
@@ -0,0 +1,216 @@
+/*
+File: review_service.go
+Author: lxp
+Description: 错题间隔复习业务逻辑：答错入队、再次作答后重新安排，以及基于Redis的每日复习统计
+*/
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/event"
+	"zhixue-backend/internal/repository/review"
+	"zhixue-backend/logger"
+	"zhixue-backend/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis 键前缀，键中包含用户ID与用户时区的日期 (如 review_due:42:2024-03-01)
+const (
+	reviewDueKeyPrefix  = "review_due:"  // 当天结束前到期的题目数缓存
+	reviewDoneKeyPrefix = "review_done:" // 当天已复习的题目次数
+)
+
+const (
+	// 到期数缓存的有效期，复习状态变化时也会主动清除
+	dueCountCacheTTL = 10 * time.Minute
+	// 已复习次数保留8天，便于任务系统汇总本周 (以及跨零点时前一天) 的数据
+	doneCountTTL = 8 * 24 * time.Hour
+)
+
+// Service 定义错题间隔复习服务的接口
+type Service interface {
+	HandleAnswerSubmitted(ctx context.Context, e event.Event) error
+	ListDue(userID int64, query *dto.ListDueReviewsQuery) (*dto.PageResponse, error)
+	GetStats(ctx context.Context, userID int64) (*dto.ReviewStatsResponse, error)
+
+	// 供任务系统使用的每日统计
+	DueToday(ctx context.Context, userID int64) (int, error)
+	ReviewedOn(ctx context.Context, userID int64, day time.Time) (int, error)
+}
+
+// Locator 返回划分用户日期所用的时区 (由连续学习服务实现)，每日统计按用户时区的自然日计算
+type Locator interface {
+	Location(userID int64) *time.Location
+}
+
+// reviewService 实现了Service接口
+type reviewService struct {
+	repo    review.Repository
+	redis   *redis.Client
+	locator Locator
+}
+
+// NewReviewService 创建一个新的错题间隔复习服务实例，locator 为空时按服务器时区划分日期
+func NewReviewService(repo review.Repository, redisClient *redis.Client, locator Locator) Service {
+	return &reviewService{repo: repo, redis: redisClient, locator: locator}
+}
+
+// HandleAnswerSubmitted 订阅答题事件：答错的题目进入复习队列，已在队列中的题目按本次作答重新安排
+func (s *reviewService) HandleAnswerSubmitted(ctx context.Context, e event.Event) error {
+	answer, ok := e.(event.AnswerSubmitted)
+	if !ok {
+		return nil
+	}
+
+	var initial *models.ReviewItem
+	if !answer.IsCorrect {
+		initial = newReviewItem(answer.UserID, answer.QuestionID, answer.AnsweredAt)
+	}
+
+	quality := answerQuality(answer.IsCorrect, answer.HintUsedCount)
+	changed, err := s.repo.Schedule(answer.UserID, answer.QuestionID, initial, func(item *models.ReviewItem) bool {
+		// 刚以本次作答创建的复习项，或已计入过的作答，不再重复安排
+		if item.LastReviewedAt != nil && !answer.AnsweredAt.After(*item.LastReviewedAt) {
+			return false
+		}
+		schedule(item, quality, answer.AnsweredAt)
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("failed to schedule review: %w", err)
+	}
+	// 新入队的题目第二天才到期，不影响当天的统计
+	if !changed {
+		return nil
+	}
+
+	loc := s.location(answer.UserID)
+	s.invalidateDueCount(ctx, answer.UserID, answer.AnsweredAt, loc)
+	s.incrReviewed(ctx, answer.UserID, answer.AnsweredAt, loc)
+	return nil
+}
+
+// ListDue 分页获取已到期的复习题目，到期早的在前
+func (s *reviewService) ListDue(userID int64, query *dto.ListDueReviewsQuery) (*dto.PageResponse, error) {
+	query.Normalize()
+
+	items, total, err := s.repo.ListDue(userID, time.Now(), query.Page, query.PageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due reviews: %w", err)
+	}
+
+	result := make([]dto.ReviewItemResponse, 0, len(items))
+	for _, item := range items {
+		result = append(result, dto.ReviewItemResponse{
+			QuestionID:     item.QuestionID,
+			Title:          item.Title,
+			Content:        item.Content,
+			QuestionType:   item.QuestionType,
+			Difficulty:     item.Difficulty,
+			Hints:          item.Hints,
+			Choices:        item.Choices,
+			DueAt:          item.DueAt,
+			IntervalDays:   item.IntervalDays,
+			EaseFactor:     item.EaseFactor,
+			Repetitions:    item.Repetitions,
+			LapseCount:     item.LapseCount,
+			LastReviewedAt: item.LastReviewedAt,
+		})
+	}
+	return dto.NewPageResponse(result, query.Page, query.PageSize, total), nil
+}
+
+// GetStats 获取当天的复习统计
+func (s *reviewService) GetStats(ctx context.Context, userID int64) (*dto.ReviewStatsResponse, error) {
+	due, err := s.DueToday(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	reviewed, err := s.ReviewedOn(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &dto.ReviewStatsResponse{DueToday: due, ReviewedToday: reviewed}, nil
+}
+
+// DueToday 获取用户在今天结束前到期的复习题目数，优先读取Redis缓存，Redis不可用时直接查询数据库
+func (s *reviewService) DueToday(ctx context.Context, userID int64) (int, error) {
+	now := time.Now()
+	loc := s.location(userID)
+	key := dailyKey(reviewDueKeyPrefix, userID, now, loc)
+
+	cached, err := s.redis.Get(ctx, key).Int()
+	if err == nil {
+		return cached, nil
+	}
+	if !errors.Is(err, redis.Nil) {
+		logger.LogError("review", "get_due_count", err, map[string]interface{}{"user_id": userID})
+	}
+
+	count, err := s.repo.CountDue(userID, endOfDay(now, loc))
+	if err != nil {
+		return 0, fmt.Errorf("failed to count due reviews: %w", err)
+	}
+	if err := s.redis.Set(ctx, key, count, dueCountCacheTTL).Err(); err != nil {
+		logger.LogError("review", "set_due_count", err, map[string]interface{}{"user_id": userID})
+	}
+	return int(count), nil
+}
+
+// ReviewedOn 获取用户在 day 所在的一天 (用户时区) 已复习的题目次数，只保留最近8天的数据
+func (s *reviewService) ReviewedOn(ctx context.Context, userID int64, day time.Time) (int, error) {
+	key := dailyKey(reviewDoneKeyPrefix, userID, day, s.location(userID))
+	count, err := s.redis.Get(ctx, key).Int()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get reviewed count: %w", err)
+	}
+	return count, nil
+}
+
+// location 返回划分用户日期所用的时区
+func (s *reviewService) location(userID int64) *time.Location {
+	if s.locator == nil {
+		return time.Local
+	}
+	return s.locator.Location(userID)
+}
+
+// invalidateDueCount 清除到期数缓存，失败只记录日志，缓存会在有效期后自然过期
+func (s *reviewService) invalidateDueCount(ctx context.Context, userID int64, at time.Time, loc *time.Location) {
+	if err := s.redis.Del(ctx, dailyKey(reviewDueKeyPrefix, userID, at, loc)).Err(); err != nil {
+		logger.LogError("review", "invalidate_due_count", err, map[string]interface{}{"user_id": userID})
+	}
+}
+
+// incrReviewed 累加当天的已复习次数
+func (s *reviewService) incrReviewed(ctx context.Context, userID int64, at time.Time, loc *time.Location) {
+	key := dailyKey(reviewDoneKeyPrefix, userID, at, loc)
+	_, err := s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, doneCountTTL)
+		return nil
+	})
+	if err != nil {
+		logger.LogError("review", "incr_reviewed_count", err, map[string]interface{}{"user_id": userID})
+	}
+}
+
+// dailyKey 构造按用户与用户时区 loc 下的日期区分的Redis键
+func dailyKey(prefix string, userID int64, day time.Time, loc *time.Location) string {
+	return prefix + strconv.FormatInt(userID, 10) + ":" + day.In(loc).Format(time.DateOnly)
+}
+
+// endOfDay 返回时区 loc 下当天结束的时刻 (即次日零点)
+func endOfDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,77 @@
+/*
+File: sm2.go
+Author: lxp
+Description: SM-2 间隔复习调度算法
+*/
+package review
+
+import (
+	"math"
+	"time"
+	"zhixue-backend/models"
+)
+
+const (
+	defaultEaseFactor = 2.5
+	minEaseFactor     = 1.3
+	maxIntervalDays   = 365
+)
+
+// SM-2 的作答质量 (0-5)，3 及以上视为记住
+const (
+	qualityWrong    = 1 // 答错
+	qualityWithHint = 3 // 使用提示后答对
+	qualityCorrect  = 4 // 直接答对
+)
+
+// answerQuality 将一次作答转换为 SM-2 作答质量
+func answerQuality(correct bool, hintUsedCount int) int {
+	switch {
+	case !correct:
+		return qualityWrong
+	case hintUsedCount > 0:
+		return qualityWithHint
+	default:
+		return qualityCorrect
+	}
+}
+
+// newReviewItem 创建因答错而进入复习队列的复习项，第二天到期
+func newReviewItem(userID, questionID int64, answeredAt time.Time) *models.ReviewItem {
+	return &models.ReviewItem{
+		UserID:         userID,
+		QuestionID:     questionID,
+		IntervalDays:   1,
+		EaseFactor:     defaultEaseFactor,
+		DueAt:          answeredAt.AddDate(0, 0, 1),
+		LastReviewedAt: &answeredAt,
+	}
+}
+
+// schedule 按 SM-2 根据一次复习的作答质量更新复习间隔、难易系数与下次到期时间
+func schedule(item *models.ReviewItem, quality int, reviewedAt time.Time) {
+	if quality < qualityWithHint {
+		item.Repetitions = 0
+		item.IntervalDays = 1
+		item.LapseCount++
+	} else {
+		switch item.Repetitions {
+		case 0:
+			item.IntervalDays = 1
+		case 1:
+			item.IntervalDays = 6
+		default:
+			item.IntervalDays = int(math.Round(float64(item.IntervalDays) * item.EaseFactor))
+		}
+		item.IntervalDays = min(max(item.IntervalDays, 1), maxIntervalDays)
+		item.Repetitions++
+	}
+
+	q := float64(5 - quality)
+	ease := item.EaseFactor + 0.1 - q*(0.08+q*0.02)
+	item.EaseFactor = math.Round(math.Max(minEaseFactor, ease)*100) / 100
+
+	item.ReviewCount++
+	item.DueAt = reviewedAt.AddDate(0, 0, item.IntervalDays)
+	item.LastReviewedAt = &reviewedAt
+}
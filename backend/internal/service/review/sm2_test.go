@@ -0,0 +1,119 @@
+/*
+File: sm2_test.go
+Author: lxp
+Description: SM-2 间隔复习调度算法的单元测试
+*/
+package review
+
+import (
+	"testing"
+	"time"
+	"zhixue-backend/models"
+)
+
+func TestAnswerQuality(t *testing.T) {
+	tests := []struct {
+		name    string
+		correct bool
+		hints   int
+		want    int
+	}{
+		{"答错", false, 0, qualityWrong},
+		{"使用提示仍答错", false, 2, qualityWrong},
+		{"使用提示答对", true, 1, qualityWithHint},
+		{"直接答对", true, 0, qualityCorrect},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := answerQuality(tt.correct, tt.hints); got != tt.want {
+				t.Errorf("answerQuality(%v, %d) = %d, want %d", tt.correct, tt.hints, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewReviewItem(t *testing.T) {
+	answeredAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	item := newReviewItem(1, 2, answeredAt)
+	if item.IntervalDays != 1 || item.EaseFactor != defaultEaseFactor {
+		t.Errorf("newReviewItem = interval %d, ease %.2f, want 1, %.2f", item.IntervalDays, item.EaseFactor, defaultEaseFactor)
+	}
+	if want := answeredAt.AddDate(0, 0, 1); !item.DueAt.Equal(want) {
+		t.Errorf("DueAt = %v, want %v", item.DueAt, want)
+	}
+}
+
+// 连续直接答对时间隔依次为 1、6、6×难易系数……，难易系数保持不变
+func TestScheduleCorrectSequence(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	item := &models.ReviewItem{EaseFactor: defaultEaseFactor}
+
+	for i, want := range []int{1, 6, 15, 38, 95} {
+		schedule(item, qualityCorrect, now)
+		if item.IntervalDays != want {
+			t.Fatalf("review %d: IntervalDays = %d, want %d", i+1, item.IntervalDays, want)
+		}
+		if item.Repetitions != i+1 {
+			t.Errorf("review %d: Repetitions = %d, want %d", i+1, item.Repetitions, i+1)
+		}
+	}
+	if item.EaseFactor != defaultEaseFactor {
+		t.Errorf("EaseFactor = %.2f, want %.2f", item.EaseFactor, defaultEaseFactor)
+	}
+	if item.ReviewCount != 5 {
+		t.Errorf("ReviewCount = %d, want 5", item.ReviewCount)
+	}
+	if want := now.AddDate(0, 0, 95); !item.DueAt.Equal(want) {
+		t.Errorf("DueAt = %v, want %v", item.DueAt, want)
+	}
+}
+
+func TestScheduleEaseFactor(t *testing.T) {
+	tests := []struct {
+		name    string
+		ease    float64
+		quality int
+		want    float64
+	}{
+		{"直接答对不变", 2.5, qualityCorrect, 2.5},
+		{"使用提示答对下降", 2.5, qualityWithHint, 2.36},
+		{"答错下降", 2.5, qualityWrong, 1.96},
+		{"不低于下限", 1.4, qualityWrong, minEaseFactor},
+		{"处于下限时保持", minEaseFactor, qualityWithHint, minEaseFactor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := &models.ReviewItem{EaseFactor: tt.ease, Repetitions: 2, IntervalDays: 6}
+			schedule(item, tt.quality, time.Now())
+			if item.EaseFactor != tt.want {
+				t.Errorf("EaseFactor = %.2f, want %.2f", item.EaseFactor, tt.want)
+			}
+		})
+	}
+}
+
+// 答错时重新开始：间隔回到 1 天，重复次数清零，遗忘次数加一
+func TestScheduleLapse(t *testing.T) {
+	item := &models.ReviewItem{EaseFactor: 2.5, Repetitions: 4, IntervalDays: 38, LapseCount: 1}
+	schedule(item, qualityWrong, time.Now())
+
+	if item.IntervalDays != 1 || item.Repetitions != 0 || item.LapseCount != 2 {
+		t.Errorf("after lapse: interval %d, repetitions %d, lapses %d, want 1, 0, 2",
+			item.IntervalDays, item.Repetitions, item.LapseCount)
+	}
+
+	schedule(item, qualityCorrect, time.Now())
+	if item.IntervalDays != 1 || item.Repetitions != 1 {
+		t.Errorf("after relearning: interval %d, repetitions %d, want 1, 1", item.IntervalDays, item.Repetitions)
+	}
+}
+
+func TestScheduleMaxInterval(t *testing.T) {
+	item := &models.ReviewItem{EaseFactor: 2.5, Repetitions: 6, IntervalDays: 300}
+	schedule(item, qualityCorrect, time.Now())
+	if item.IntervalDays != maxIntervalDays {
+		t.Errorf("IntervalDays = %d, want %d", item.IntervalDays, maxIntervalDays)
+	}
+}
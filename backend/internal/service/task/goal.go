@@ -0,0 +1,82 @@
+/*
+File: goal.go
+Author: lxp
+Description: 任务目标的解析与任务周期的计算
+*/
+package task
+
+import (
+	"encoding/json"
+	"time"
+	"zhixue-backend/internal/repository/task"
+	"zhixue-backend/models"
+)
+
+// 任务目标的统计指标
+const (
+	MetricAnswers           = "answers"            // 答题数
+	MetricCorrectAnswers    = "correct_answers"    // 答对题数
+	MetricSessionsCompleted = "sessions_completed" // 正常完成的学习会话数
+	MetricStudyMinutes      = "study_minutes"      // 有效学习时长 (分钟，不含暂停时间)
+	MetricReviews           = "reviews"            // 错题复习次数，仅支持每日/每周任务
+)
+
+// Goal 是任务的目标，以JSON存储在 tasks.goal 中
+// 如 {"metric": "answers", "target": 5} 或 {"metric": "correct_answers", "target": 3, "knowledge_point": "MATH_FRACTION"}
+type Goal struct {
+	Metric         string `json:"metric"`
+	Target         int    `json:"target"`
+	KnowledgePoint string `json:"knowledge_point,omitempty"` // 知识点编码，包含其子知识点，仅用于答题类指标
+	SessionType    string `json:"session_type,omitempty"`    // 会话类型，仅用于会话类指标
+}
+
+// parseGoal 解析任务目标，目标缺失或无法识别时返回 false，这样的任务不会推进进度
+func parseGoal(raw models.JSONB) (Goal, bool) {
+	var goal Goal
+	data, err := json.Marshal(raw)
+	if err != nil || json.Unmarshal(data, &goal) != nil {
+		return goal, false
+	}
+	if goal.Target <= 0 {
+		return goal, false
+	}
+	switch goal.Metric {
+	case MetricAnswers, MetricCorrectAnswers, MetricSessionsCompleted, MetricStudyMinutes, MetricReviews:
+		return goal, true
+	}
+	return goal, false
+}
+
+// achievementPeriod 成就任务不按周期重置，所有进度记录在同一个固定周期内
+var achievementPeriod = time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// periodStart 返回 t 所在的任务周期的开始日期：每日任务为当天零点，每周任务为本周一零点 (用户时区 loc)
+// 返回值只保留日期部分，与数据库中 DATE 类型的 period_start 对应
+func periodStart(taskType string, t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	switch taskType {
+	case task.TypeDaily:
+		return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	case task.TypeWeekly:
+		offset := (int(local.Weekday()) + 6) % 7 // 周一为0
+		monday := local.AddDate(0, 0, -offset)
+		return time.Date(monday.Year(), monday.Month(), monday.Day(), 0, 0, 0, 0, time.UTC)
+	default:
+		return achievementPeriod
+	}
+}
+
+// periodBounds 返回任务周期在用户时区 loc 下的起止时间，成就任务没有结束时间
+func periodBounds(taskType string, start time.Time, loc *time.Location) (time.Time, *time.Time) {
+	from := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	var to time.Time
+	switch taskType {
+	case task.TypeDaily:
+		to = from.AddDate(0, 0, 1)
+	case task.TypeWeekly:
+		to = from.AddDate(0, 0, 7)
+	default:
+		return from, nil
+	}
+	return from, &to
+}
@@ -0,0 +1,133 @@
+/*
+File: goal_test.go
+Author: lxp
+Description: 任务目标解析与任务周期计算的单元测试
+*/
+package task
+
+import (
+	"testing"
+	"time"
+	"zhixue-backend/internal/repository/task"
+	"zhixue-backend/models"
+)
+
+var (
+	shanghai = time.FixedZone("UTC+8", 8*3600)
+	newYork  = time.FixedZone("UTC-5", -5*3600)
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseGoal(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  models.JSONB
+		want Goal
+		ok   bool
+	}{
+		{"答题数", models.JSONB{"metric": "answers", "target": 5}, Goal{Metric: MetricAnswers, Target: 5}, true},
+		{"限定知识点", models.JSONB{"metric": "correct_answers", "target": 3, "knowledge_point": "MATH_FRACTION"},
+			Goal{Metric: MetricCorrectAnswers, Target: 3, KnowledgePoint: "MATH_FRACTION"}, true},
+		{"限定会话类型", models.JSONB{"metric": "sessions_completed", "target": 1, "session_type": "practice"},
+			Goal{Metric: MetricSessionsCompleted, Target: 1, SessionType: "practice"}, true},
+		{"目标缺失", nil, Goal{}, false},
+		{"目标为0", models.JSONB{"metric": "answers", "target": 0}, Goal{Metric: MetricAnswers}, false},
+		{"未知指标", models.JSONB{"metric": "logins", "target": 1}, Goal{Metric: "logins", Target: 1}, false},
+		{"目标类型错误", models.JSONB{"metric": "answers", "target": "5"}, Goal{Metric: MetricAnswers}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseGoal(tt.raw)
+			if ok != tt.ok {
+				t.Fatalf("parseGoal(%v) ok = %v, want %v", tt.raw, ok, tt.ok)
+			}
+			if ok && got != tt.want {
+				t.Errorf("parseGoal(%v) = %+v, want %+v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPeriodStart(t *testing.T) {
+	// 2024-03-04 与 2024-03-11 均为周一
+	sundayNightUTC := time.Date(2024, 3, 10, 20, 0, 0, 0, time.UTC)
+	mondayEarlyUTC := time.Date(2024, 3, 11, 2, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		taskType string
+		t        time.Time
+		loc      *time.Location
+		want     time.Time
+	}{
+		{"每日任务", task.TypeDaily, sundayNightUTC, time.UTC, date(2024, 3, 10)},
+		{"每日任务按用户时区跨日", task.TypeDaily, sundayNightUTC, shanghai, date(2024, 3, 11)},
+		{"每日任务按用户时区回退一日", task.TypeDaily, mondayEarlyUTC, newYork, date(2024, 3, 10)},
+		{"周日属于本周", task.TypeWeekly, sundayNightUTC, time.UTC, date(2024, 3, 4)},
+		{"UTC周日在东八区已是下周一", task.TypeWeekly, sundayNightUTC, shanghai, date(2024, 3, 11)},
+		{"周一零点", task.TypeWeekly, time.Date(2024, 3, 11, 0, 0, 0, 0, shanghai), shanghai, date(2024, 3, 11)},
+		{"周一零点前一刻", task.TypeWeekly, time.Date(2024, 3, 10, 23, 59, 59, 0, shanghai), shanghai, date(2024, 3, 4)},
+		{"UTC周一在西五区仍是上周日", task.TypeWeekly, mondayEarlyUTC, newYork, date(2024, 3, 4)},
+		{"跨月", task.TypeWeekly, time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), time.UTC, date(2024, 2, 26)},
+		{"跨年", task.TypeWeekly, time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC), time.UTC, date(2024, 12, 30)},
+		{"成就任务", task.TypeAchievement, sundayNightUTC, shanghai, achievementPeriod},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := periodStart(tt.taskType, tt.t, tt.loc); !got.Equal(tt.want) {
+				t.Errorf("periodStart(%s, %v) = %v, want %v", tt.taskType, tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPeriodBounds(t *testing.T) {
+	start := date(2024, 3, 11)
+	tests := []struct {
+		name     string
+		taskType string
+		wantTo   *time.Time
+	}{
+		{"每日任务", task.TypeDaily, ptrTime(time.Date(2024, 3, 12, 0, 0, 0, 0, shanghai))},
+		{"每周任务", task.TypeWeekly, ptrTime(time.Date(2024, 3, 18, 0, 0, 0, 0, shanghai))},
+		{"成就任务", task.TypeAchievement, nil},
+	}
+
+	wantFrom := time.Date(2024, 3, 11, 0, 0, 0, 0, shanghai)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to := periodBounds(tt.taskType, start, shanghai)
+			if !from.Equal(wantFrom) {
+				t.Errorf("from = %v, want %v", from, wantFrom)
+			}
+			if (to == nil) != (tt.wantTo == nil) || (to != nil && !to.Equal(*tt.wantTo)) {
+				t.Errorf("to = %v, want %v", to, tt.wantTo)
+			}
+		})
+	}
+}
+
+// 任意时刻都落在其所在周期的起止时间之内
+func TestPeriodStartWithinBounds(t *testing.T) {
+	base := time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC)
+	for _, loc := range []*time.Location{time.UTC, shanghai, newYork} {
+		for h := 0; h < 24*8; h += 5 {
+			now := base.Add(time.Duration(h) * time.Hour)
+			for _, taskType := range []string{task.TypeDaily, task.TypeWeekly} {
+				from, to := periodBounds(taskType, periodStart(taskType, now, loc), loc)
+				if now.Before(from) || !now.Before(*to) {
+					t.Errorf("%s %v in %s: bounds [%v, %v) do not contain it", taskType, now, loc, from, *to)
+				}
+			}
+		}
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,375 @@
+/*
+File: task_service.go
+Author: lxp
+Description: 游戏化任务业务逻辑：由答题与学习会话事件推进任务进度、按周期重置、领取奖励
+*/
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/event"
+	"zhixue-backend/internal/repository/learning"
+	"zhixue-backend/internal/repository/task"
+	"zhixue-backend/internal/repository/wallet"
+	review_service "zhixue-backend/internal/service/review"
+	wallet_service "zhixue-backend/internal/service/wallet"
+	"zhixue-backend/logger"
+	"zhixue-backend/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTaskNotCompleted = errors.New("task is not completed yet")
+)
+
+// Service 定义任务服务的接口
+type Service interface {
+	HandleAnswerSubmitted(ctx context.Context, e event.Event) error
+	HandleSessionEnded(ctx context.Context, e event.Event) error
+	ListTasks(userID int64, query *dto.ListTasksQuery) ([]dto.TaskResponse, error)
+	GetTask(userID, taskID int64) (*dto.TaskResponse, error)
+	ClaimTask(ctx context.Context, userID, taskID int64) (*dto.TaskResponse, bool, error)
+}
+
+// Locator 返回划分用户日期所用的时区 (由连续学习服务实现)，每日与每周任务在用户时区的零点重置
+type Locator interface {
+	Location(userID int64) *time.Location
+}
+
+// taskService 实现了Service接口
+type taskService struct {
+	repo         task.Repository
+	learningRepo learning.Repository
+	reviews      review_service.Service
+	wallet       wallet_service.Service
+	locator      Locator
+}
+
+// NewTaskService 创建一个新的任务服务实例，locator 为空时按服务器时区划分任务周期
+func NewTaskService(repo task.Repository, learningRepo learning.Repository, reviews review_service.Service,
+	walletService wallet_service.Service, locator Locator) Service {
+	return &taskService{repo: repo, learningRepo: learningRepo, reviews: reviews, wallet: walletService, locator: locator}
+}
+
+// HandleAnswerSubmitted 订阅答题事件，推进答题类与复习类任务的进度
+func (s *taskService) HandleAnswerSubmitted(ctx context.Context, e event.Event) error {
+	answer, ok := e.(event.AnswerSubmitted)
+	if !ok {
+		return nil
+	}
+
+	return s.advanceAll(answer.UserID, answer.AnsweredAt, func(t *models.Task, goal Goal, period time.Time, loc *time.Location) (int, bool, error) {
+		switch goal.Metric {
+		case MetricAnswers, MetricCorrectAnswers:
+			if goal.Metric == MetricCorrectAnswers && !answer.IsCorrect {
+				return 0, false, nil
+			}
+			if goal.KnowledgePoint != "" {
+				in, err := s.repo.QuestionInKnowledgePoint(answer.QuestionID, goal.KnowledgePoint)
+				if err != nil || !in {
+					return 0, false, err
+				}
+			}
+			return 1, false, nil
+		case MetricReviews:
+			count, err := s.reviewedInPeriod(ctx, answer.UserID, t.Type, period, answer.AnsweredAt, loc)
+			return count, true, err
+		}
+		return 0, false, nil
+	})
+}
+
+// HandleSessionEnded 订阅学习会话结束事件，推进会话类与学习时长类任务的进度
+func (s *taskService) HandleSessionEnded(ctx context.Context, e event.Event) error {
+	ended, ok := e.(event.SessionEnded)
+	if !ok {
+		return nil
+	}
+
+	return s.advanceAll(ended.UserID, ended.EndedAt, func(t *models.Task, goal Goal, period time.Time, loc *time.Location) (int, bool, error) {
+		if goal.SessionType != "" && goal.SessionType != ended.SessionType {
+			return 0, false, nil
+		}
+		switch goal.Metric {
+		case MetricSessionsCompleted:
+			if ended.Status != "completed" {
+				return 0, false, nil
+			}
+			return 1, false, nil
+		case MetricStudyMinutes:
+			from, _ := periodBounds(t.Type, period, loc)
+			if t.Type == task.TypeAchievement {
+				from = time.Time{}
+			}
+			seconds, err := s.learningRepo.StudySecondsSince(ended.UserID, from, ended.EndedAt)
+			return seconds / 60, true, err
+		}
+		return 0, false, nil
+	})
+}
+
+// measure 计算一次事件对任务进度的影响：absolute 为 false 时 value 为增量，否则为本周期的最新总量
+// period 为事件在用户时区 loc 下所属周期的开始日期
+type measure func(t *models.Task, goal Goal, period time.Time, loc *time.Location) (value int, absolute bool, err error)
+
+// advanceAll 对所有启用的任务按事件推进用户在事件所属周期内的进度，达到目标时标记为已完成
+// 单个任务失败只记录日志，不影响其他任务
+func (s *taskService) advanceAll(userID int64, at time.Time, measure measure) error {
+	tasks, err := s.repo.ListActive("")
+	if err != nil {
+		return fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	loc := s.location(userID)
+	for i := range tasks {
+		t := &tasks[i]
+		goal, ok := parseGoal(t.Goal)
+		if !ok {
+			continue
+		}
+		period := periodStart(t.Type, at, loc)
+		value, absolute, err := measure(t, goal, period, loc)
+		if err != nil {
+			logger.LogError("task", "measure_progress", err, map[string]interface{}{"user_id": userID, "task_id": t.ID})
+			continue
+		}
+		if value <= 0 {
+			continue
+		}
+
+		_, err = s.repo.Advance(userID, t.ID, period, func(ut *models.UserTask) bool {
+			if ut.Status != task.StatusPending {
+				return false
+			}
+			progress := ut.Progress + value
+			if absolute {
+				progress = value
+			}
+			if progress > goal.Target {
+				progress = goal.Target
+			}
+			if progress <= ut.Progress {
+				return false
+			}
+			ut.Progress = progress
+			if progress >= goal.Target {
+				ut.Status = task.StatusCompleted
+				ut.CompletedAt = &at
+			}
+			return true
+		})
+		if err != nil {
+			logger.LogError("task", "advance_progress", err, map[string]interface{}{"user_id": userID, "task_id": t.ID})
+		}
+	}
+	return nil
+}
+
+// reviewedInPeriod 汇总用户本周期截至 until 当天的复习次数，成就任务不支持复习类指标
+// 复习次数与任务周期都按用户时区 loc 的自然日划分
+func (s *taskService) reviewedInPeriod(ctx context.Context, userID int64, taskType string, period, until time.Time, loc *time.Location) (int, error) {
+	if taskType == task.TypeAchievement {
+		return 0, nil
+	}
+	day, _ := periodBounds(taskType, period, loc)
+	total := 0
+	for !day.After(until) {
+		count, err := s.reviews.ReviewedOn(ctx, userID, day)
+		if err != nil {
+			return 0, err
+		}
+		total += count
+		day = day.AddDate(0, 0, 1)
+	}
+	return total, nil
+}
+
+// ListTasks 获取启用的任务及用户在当前周期的进度
+func (s *taskService) ListTasks(userID int64, query *dto.ListTasksQuery) ([]dto.TaskResponse, error) {
+	tasks, err := s.repo.ListActive(query.Type)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	now := time.Now()
+	loc := s.location(userID)
+	periods := []time.Time{
+		periodStart(task.TypeDaily, now, loc),
+		periodStart(task.TypeWeekly, now, loc),
+		achievementPeriod,
+	}
+	userTasks, err := s.repo.ListUserTasks(userID, periods)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user tasks: %w", err)
+	}
+	progress := make(map[int64]map[string]*models.UserTask, len(userTasks))
+	for i := range userTasks {
+		ut := &userTasks[i]
+		if progress[ut.TaskID] == nil {
+			progress[ut.TaskID] = make(map[string]*models.UserTask)
+		}
+		progress[ut.TaskID][ut.PeriodStart.Format(time.DateOnly)] = ut
+	}
+
+	rewards, err := s.rewards(tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dto.TaskResponse, 0, len(tasks))
+	for i := range tasks {
+		t := &tasks[i]
+		period := periodStart(t.Type, now, loc)
+		items = append(items, *toTaskResponse(t, progress[t.ID][period.Format(time.DateOnly)], period, loc, rewards))
+	}
+	return items, nil
+}
+
+// GetTask 获取任务详情及用户在当前周期的进度
+func (s *taskService) GetTask(userID, taskID int64) (*dto.TaskResponse, error) {
+	t, err := s.repo.FindActiveByID(taskID)
+	if err != nil {
+		return nil, err // 错误可能是 gorm.ErrRecordNotFound
+	}
+	return s.current(userID, t, time.Now())
+}
+
+// ClaimTask 领取当前周期已完成任务的奖励，重复领取不会重复发放，返回本次是否发放了奖励
+// 积分奖励以任务进度记录为来源记入积分账本，任务已领取时会补记之前未成功入账的积分
+// 未领取的奖励在周期结束后失效
+func (s *taskService) ClaimTask(ctx context.Context, userID, taskID int64) (*dto.TaskResponse, bool, error) {
+	t, err := s.repo.FindActiveByID(taskID)
+	if err != nil {
+		return nil, false, err // 错误可能是 gorm.ErrRecordNotFound
+	}
+
+	now := time.Now()
+	ut, err := s.repo.FindUserTask(userID, t.ID, periodStart(t.Type, now, s.location(userID)))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, ErrTaskNotCompleted
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	claimed := false
+	switch ut.Status {
+	case task.StatusPending:
+		return nil, false, ErrTaskNotCompleted
+	case task.StatusCompleted:
+		claimed, err = s.repo.Claim(ut.ID, t.RewardID, now)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to claim task: %w", err)
+		}
+	}
+
+	resp, err := s.current(userID, t, now)
+	if err != nil {
+		return nil, false, err
+	}
+	if resp.Status == task.StatusClaimed {
+		if err := s.creditPoints(ctx, ut, resp.Reward); err != nil {
+			return nil, false, err
+		}
+	}
+	return resp, claimed, nil
+}
+
+// creditPoints 将任务的积分奖励记入积分账本，同一任务进度记录只入账一次
+func (s *taskService) creditPoints(ctx context.Context, ut *models.UserTask, reward *dto.RewardResponse) error {
+	if reward == nil || reward.RewardType != "points" || reward.Value <= 0 {
+		return nil
+	}
+	reference := "user_task:" + strconv.FormatInt(ut.ID, 10)
+	_, err := s.wallet.Credit(ctx, ut.UserID, reward.Value, wallet.ReasonTaskClaim, reference, reward.Name, nil)
+	if err != nil {
+		return fmt.Errorf("failed to credit task reward: %w", err)
+	}
+	return nil
+}
+
+// current 构造任务在 now 所在周期的详情
+func (s *taskService) current(userID int64, t *models.Task, now time.Time) (*dto.TaskResponse, error) {
+	loc := s.location(userID)
+	period := periodStart(t.Type, now, loc)
+	ut, err := s.repo.FindUserTask(userID, t.ID, period)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ut = nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	rewards, err := s.rewards([]models.Task{*t})
+	if err != nil {
+		return nil, err
+	}
+	return toTaskResponse(t, ut, period, loc, rewards), nil
+}
+
+// location 返回划分用户任务周期所用的时区
+func (s *taskService) location(userID int64) *time.Location {
+	if s.locator == nil {
+		return time.Local
+	}
+	return s.locator.Location(userID)
+}
+
+// rewards 批量获取任务的奖励
+func (s *taskService) rewards(tasks []models.Task) (map[int64]models.Reward, error) {
+	ids := make([]int64, 0, len(tasks))
+	for _, t := range tasks {
+		if t.RewardID != nil {
+			ids = append(ids, *t.RewardID)
+		}
+	}
+	rewards, err := s.repo.FindRewards(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rewards: %w", err)
+	}
+	return rewards, nil
+}
+
+// toTaskResponse 构造任务响应，用户本周期尚无进度记录时 ut 为空
+func toTaskResponse(t *models.Task, ut *models.UserTask, period time.Time, loc *time.Location, rewards map[int64]models.Reward) *dto.TaskResponse {
+	goal, _ := parseGoal(t.Goal)
+	from, to := periodBounds(t.Type, period, loc)
+	resp := &dto.TaskResponse{
+		ID:          t.ID,
+		Name:        t.Name,
+		Description: t.Description,
+		Type:        t.Type,
+		Goal: dto.TaskGoalResponse{
+			Metric:         goal.Metric,
+			Target:         goal.Target,
+			KnowledgePoint: goal.KnowledgePoint,
+			SessionType:    goal.SessionType,
+		},
+		Status:      task.StatusPending,
+		PeriodStart: from,
+		ResetsAt:    to,
+	}
+	if ut != nil {
+		resp.Status = ut.Status
+		resp.Progress = ut.Progress
+		resp.CompletedAt = ut.CompletedAt
+		resp.ClaimedAt = ut.ClaimedAt
+	}
+	if t.RewardID != nil {
+		if reward, ok := rewards[*t.RewardID]; ok {
+			resp.Reward = &dto.RewardResponse{
+				ID:          reward.ID,
+				Name:        reward.Name,
+				Description: reward.Description,
+				RewardType:  reward.RewardType,
+				Value:       reward.Value,
+			}
+		}
+	}
+	return resp
+}
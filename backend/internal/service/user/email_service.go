@@ -0,0 +1,192 @@
+/*
+File: email_service.go
+Author: lxp
+Description: 邮箱验证与找回密码业务逻辑
+*/
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"zhixue-backend/internal/mail"
+	"zhixue-backend/logger"
+	"zhixue-backend/models"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEmailAlreadyVerified = errors.New("email already verified")
+)
+
+// 未配置时使用的默认值
+const (
+	defaultVerifyTokenExpires = 24 * time.Hour
+	defaultResetTokenExpires  = 30 * time.Minute
+	defaultMailCooldown       = time.Minute
+)
+
+// 邮件中链接指向的前端页面
+const (
+	verifyEmailPath   = "/verify-email"
+	resetPasswordPath = "/reset-password"
+)
+
+// SendVerificationEmail 向用户当前邮箱发送验证邮件
+func (s *userService) SendVerificationEmail(userID int64, lang string) error {
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return err // 包括 gorm.ErrRecordNotFound
+	}
+	if user.EmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+	return s.sendTokenMail(context.Background(), purposeVerifyEmail, mail.TemplateVerifyEmail, verifyEmailPath, user, lang, s.verifyTokenExpires())
+}
+
+// VerifyEmail 使用验证邮件中的令牌完成邮箱验证
+func (s *userService) VerifyEmail(token string) error {
+	user, err := s.userForEmailToken(purposeVerifyEmail, token)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return nil
+	}
+
+	markEmailVerified(user)
+	return s.repo.Update(user)
+}
+
+// ForgotPassword 向邮箱对应的用户发送重置密码邮件
+// 为避免泄露邮箱是否注册，邮箱不存在或发送过于频繁时同样返回成功
+func (s *userService) ForgotPassword(email, lang string) error {
+	user, err := s.repo.GetByEmail(strings.TrimSpace(email))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	err = s.sendTokenMail(context.Background(), purposeResetPassword, mail.TemplateResetPassword, resetPasswordPath, user, lang, s.resetTokenExpires())
+	if errors.Is(err, ErrMailRateLimited) {
+		return nil
+	}
+	return err
+}
+
+// ResetPassword 使用重置邮件中的令牌设置新密码，并使该用户所有设备上的登录失效
+func (s *userService) ResetPassword(token, newPassword string) error {
+	user, err := s.userForEmailToken(purposeResetPassword, token)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	user.PasswordHash = string(hashedPassword)
+	// 能收到重置邮件即证明拥有该邮箱
+	if !user.EmailVerified {
+		markEmailVerified(user)
+	}
+	if err := s.repo.Update(user); err != nil {
+		return err
+	}
+
+	return s.sessions.RevokeAll(context.Background(), user.ID)
+}
+
+// IsEmailVerified 查询用户邮箱是否已验证
+func (s *userService) IsEmailVerified(userID int64) (bool, error) {
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return false, err
+	}
+	return user.EmailVerified, nil
+}
+
+// sendTokenMail 签发一次性令牌并异步发送带链接的邮件
+// 发送失败只记录日志，用户可在冷却时间过后重新请求
+func (s *userService) sendTokenMail(ctx context.Context, purpose, template, path string, user *models.User, lang string, ttl time.Duration) error {
+	token, err := s.issueEmailToken(ctx, purpose, user, ttl)
+	if err != nil {
+		return err
+	}
+
+	msg, err := mail.Render(template, lang, user.Email, &mail.TemplateData{
+		Nickname:      user.Nickname,
+		Link:          strings.TrimRight(s.config.Mail.LinkBaseURL, "/") + path + "?token=" + url.QueryEscape(token),
+		ExpiresInMins: int(ttl.Minutes()),
+	})
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if err := s.mailer.Send(context.Background(), msg); err != nil {
+			logger.LogError("mail", template, err, map[string]interface{}{
+				"user_id": user.ID,
+			})
+		}
+	}()
+	return nil
+}
+
+// userForEmailToken 消费令牌并返回对应的用户，用户邮箱已变更时令牌视为无效
+func (s *userService) userForEmailToken(purpose, token string) (*models.User, error) {
+	userID, email, err := s.consumeEmailToken(context.Background(), purpose, token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidEmailToken
+		}
+		return nil, err
+	}
+	if user.Email != email {
+		return nil, ErrInvalidEmailToken
+	}
+	return user, nil
+}
+
+// markEmailVerified 将用户邮箱标记为已验证
+func markEmailVerified(user *models.User) {
+	now := time.Now()
+	user.EmailVerified = true
+	user.EmailVerifiedAt = &now
+}
+
+// verifyTokenExpires 返回邮箱验证链接的有效期
+func (s *userService) verifyTokenExpires() time.Duration {
+	if s.config.Mail.VerifyTokenExpires > 0 {
+		return s.config.Mail.VerifyTokenExpires
+	}
+	return defaultVerifyTokenExpires
+}
+
+// resetTokenExpires 返回重置密码链接的有效期
+func (s *userService) resetTokenExpires() time.Duration {
+	if s.config.Mail.ResetTokenExpires > 0 {
+		return s.config.Mail.ResetTokenExpires
+	}
+	return defaultResetTokenExpires
+}
+
+// mailCooldown 返回同类邮件的最小发送间隔
+func (s *userService) mailCooldown() time.Duration {
+	if s.config.Mail.ResendCooldown > 0 {
+		return s.config.Mail.ResendCooldown
+	}
+	return defaultMailCooldown
+}
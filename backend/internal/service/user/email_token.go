@@ -0,0 +1,99 @@
+/*
+File: email_token.go
+Author: lxp
+Description: 邮箱验证与重置密码使用的一次性令牌 (基于Redis)
+*/
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	redis_pkg "zhixue-backend/internal/redis"
+	"zhixue-backend/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrInvalidEmailToken = errors.New("invalid or expired email token")
+	ErrMailRateLimited   = errors.New("mail sent too frequently")
+)
+
+// 令牌用途
+const (
+	purposeVerifyEmail   = "verify_email"
+	purposeResetPassword = "reset_password"
+)
+
+// Redis 键前缀
+const (
+	emailTokenKeyPrefix     = "email_token:"      // <用途>:<令牌摘要> -> "<user_id>:<email>"
+	emailTokenUserKeyPrefix = "email_token_user:" // <用途>:<user_id> -> 当前有效令牌的摘要
+	emailCooldownKeyPrefix  = "email_cooldown:"   // <用途>:<user_id>，存在期间不再发送同类邮件
+)
+
+// issueEmailToken 为用户签发一个一次性令牌，同一用途下新令牌签发后旧令牌立即失效
+// 令牌绑定签发时的邮箱，邮箱变更后令牌不再可用
+func (s *userService) issueEmailToken(ctx context.Context, purpose string, user *models.User, ttl time.Duration) (string, error) {
+	uid := strconv.FormatInt(user.ID, 10)
+	ok, err := redis_pkg.Client.SetNX(ctx, emailCooldownKeyPrefix+purpose+":"+uid, "1", s.mailCooldown()).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to check mail cooldown: %w", err)
+	}
+	if !ok {
+		return "", ErrMailRateLimited
+	}
+
+	token, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	digest := hashToken(token)
+	userKey := emailTokenUserKeyPrefix + purpose + ":" + uid
+
+	previous, err := redis_pkg.Client.Get(ctx, userKey).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return "", fmt.Errorf("failed to issue email token: %w", err)
+	}
+	_, err = redis_pkg.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if previous != "" {
+			pipe.Del(ctx, emailTokenKeyPrefix+purpose+":"+previous)
+		}
+		pipe.Set(ctx, emailTokenKeyPrefix+purpose+":"+digest, uid+":"+user.Email, ttl)
+		pipe.Set(ctx, userKey, digest, ttl)
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to issue email token: %w", err)
+	}
+	return token, nil
+}
+
+// consumeEmailToken 消费一个一次性令牌，返回令牌绑定的用户ID与邮箱
+func (s *userService) consumeEmailToken(ctx context.Context, purpose, token string) (int64, string, error) {
+	key := emailTokenKeyPrefix + purpose + ":" + hashToken(token)
+
+	var get *redis.StringCmd
+	_, err := redis_pkg.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pipe.Del(ctx, key)
+		return nil
+	})
+	if errors.Is(err, redis.Nil) {
+		return 0, "", ErrInvalidEmailToken
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to consume email token: %w", err)
+	}
+
+	uid, email, found := strings.Cut(get.Val(), ":")
+	userID, err := strconv.ParseInt(uid, 10, 64)
+	if !found || err != nil {
+		return 0, "", ErrInvalidEmailToken
+	}
+	return userID, email, nil
+}
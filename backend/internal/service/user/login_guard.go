@@ -0,0 +1,306 @@
+/*
+File: login_guard.go
+Author: lxp
+Description: 登录防暴力破解：失败计数、递增等待、临时锁定与审计 (基于Redis)
+*/
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	redis_pkg "zhixue-backend/internal/redis"
+	"zhixue-backend/logger"
+	"zhixue-backend/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrAccountBanned      = errors.New("account is banned")
+	ErrAccountInactive    = errors.New("account is inactive")
+	ErrLoginThrottled     = errors.New("too many login attempts")
+	ErrAccountLocked      = errors.New("account is temporarily locked")
+)
+
+// 用户状态 (对应数据库 user_status 枚举)
+const (
+	StatusActive   = "active"
+	StatusInactive = "inactive"
+	StatusBanned   = "banned"
+)
+
+// 认证审计事件 (对应数据库 auth_audit_event 枚举)
+const (
+	AuditAccountLocked   = "account_locked"
+	AuditIPLocked        = "ip_locked"
+	AuditAccountUnlocked = "account_unlocked"
+)
+
+// Redis 键前缀
+const (
+	loginFailUserKeyPrefix = "login_fail:user:" // 用户名 -> 窗口内失败次数
+	loginFailIPKeyPrefix   = "login_fail:ip:"   // IP -> 窗口内失败次数
+	loginWaitKeyPrefix     = "login_wait:"      // 用户名，存在期间需等待后再尝试
+	loginLockUserKeyPrefix = "login_lock:user:" // 用户名，存在期间账号被锁定
+	loginLockIPKeyPrefix   = "login_lock:ip:"   // IP，存在期间该IP不能登录
+)
+
+// 未配置时使用的默认值
+const (
+	defaultFailureWindow   = 15 * time.Minute
+	defaultDelayAfter      = 3
+	defaultBaseDelay       = time.Second
+	defaultMaxDelay        = 30 * time.Second
+	defaultLockThreshold   = 10
+	defaultLockDuration    = 15 * time.Minute
+	defaultIPLockThreshold = 50
+	defaultIPLockDuration  = 15 * time.Minute
+)
+
+// LoginBlockedError 表示登录被限流或锁定，RetryAfter 为建议的重试等待时间
+type LoginBlockedError struct {
+	Reason     error // ErrLoginThrottled 或 ErrAccountLocked
+	RetryAfter time.Duration
+}
+
+func (e *LoginBlockedError) Error() string {
+	return fmt.Sprintf("%v, retry after %s", e.Reason, e.RetryAfter)
+}
+
+func (e *LoginBlockedError) Unwrap() error {
+	return e.Reason
+}
+
+// loginLimits 是补全默认值后的登录保护参数
+type loginLimits struct {
+	window          time.Duration
+	delayAfter      int
+	baseDelay       time.Duration
+	maxDelay        time.Duration
+	lockThreshold   int
+	lockDuration    time.Duration
+	ipLockThreshold int
+	ipLockDuration  time.Duration
+}
+
+// loginLimits 读取登录保护配置
+func (s *userService) loginLimits() loginLimits {
+	cfg := s.config.Auth.LoginProtection
+	l := loginLimits{
+		window:          cfg.FailureWindow,
+		delayAfter:      cfg.DelayAfter,
+		baseDelay:       cfg.BaseDelay,
+		maxDelay:        cfg.MaxDelay,
+		lockThreshold:   cfg.LockThreshold,
+		lockDuration:    cfg.LockDuration,
+		ipLockThreshold: cfg.IPLockThreshold,
+		ipLockDuration:  cfg.IPLockDuration,
+	}
+	if l.window <= 0 {
+		l.window = defaultFailureWindow
+	}
+	if l.delayAfter <= 0 {
+		l.delayAfter = defaultDelayAfter
+	}
+	if l.baseDelay <= 0 {
+		l.baseDelay = defaultBaseDelay
+	}
+	if l.maxDelay <= 0 {
+		l.maxDelay = defaultMaxDelay
+	}
+	if l.lockThreshold <= 0 {
+		l.lockThreshold = defaultLockThreshold
+	}
+	if l.lockDuration <= 0 {
+		l.lockDuration = defaultLockDuration
+	}
+	if l.ipLockThreshold <= 0 {
+		l.ipLockThreshold = defaultIPLockThreshold
+	}
+	if l.ipLockDuration <= 0 {
+		l.ipLockDuration = defaultIPLockDuration
+	}
+	return l
+}
+
+// checkLoginAllowed 在校验密码前检查账号、IP是否被锁定，以及是否仍处于递增等待期
+func (s *userService) checkLoginAllowed(ctx context.Context, username, ip string) error {
+	var userLock, ipLock, wait *redis.DurationCmd
+	_, err := redis_pkg.Client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		userLock = pipe.PTTL(ctx, loginLockUserKeyPrefix+username)
+		ipLock = pipe.PTTL(ctx, loginLockIPKeyPrefix+ip)
+		wait = pipe.PTTL(ctx, loginWaitKeyPrefix+username)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check login attempts: %w", err)
+	}
+
+	// 键不存在时 PTTL 返回负值
+	if d := userLock.Val(); d > 0 {
+		return &LoginBlockedError{Reason: ErrAccountLocked, RetryAfter: d}
+	}
+	if d := ipLock.Val(); ip != "" && d > 0 {
+		return &LoginBlockedError{Reason: ErrLoginThrottled, RetryAfter: d}
+	}
+	if d := wait.Val(); d > 0 {
+		return &LoginBlockedError{Reason: ErrLoginThrottled, RetryAfter: d}
+	}
+	return nil
+}
+
+// recordLoginFailure 记录一次登录失败，按失败次数设置递增等待或锁定
+// user 为空表示用户名不存在，此时同样计数，避免通过响应差异探测用户名
+func (s *userService) recordLoginFailure(ctx context.Context, username, ip string, user *models.User) {
+	limits := s.loginLimits()
+	userKey := loginFailUserKeyPrefix + username
+	ipKey := loginFailIPKeyPrefix + ip
+
+	var userFails, ipFails *redis.IntCmd
+	_, err := redis_pkg.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		userFails = pipe.Incr(ctx, userKey)
+		pipe.Expire(ctx, userKey, limits.window)
+		if ip != "" {
+			ipFails = pipe.Incr(ctx, ipKey)
+			pipe.Expire(ctx, ipKey, limits.window)
+		}
+		return nil
+	})
+	if err != nil {
+		logger.LogError("user", "record_login_failure", err, map[string]interface{}{"username": username})
+		return
+	}
+
+	fails := int(userFails.Val())
+	switch {
+	case fails >= limits.lockThreshold:
+		s.lockLogin(ctx, loginLockUserKeyPrefix+username, limits.lockDuration, userKey, loginWaitKeyPrefix+username)
+		s.audit(&models.AuthAuditLog{
+			UserID:    userIDOf(user),
+			Username:  username,
+			Event:     AuditAccountLocked,
+			IPAddress: optionalString(ip),
+			Detail:    models.JSONB{"failures": fails, "lock_seconds": int(limits.lockDuration.Seconds())},
+		})
+	case fails >= limits.delayAfter:
+		delay := limits.baseDelay << (fails - limits.delayAfter)
+		if delay <= 0 || delay > limits.maxDelay {
+			delay = limits.maxDelay
+		}
+		if err := redis_pkg.Client.Set(ctx, loginWaitKeyPrefix+username, "1", delay).Err(); err != nil {
+			logger.LogError("user", "record_login_failure", err, map[string]interface{}{"username": username})
+		}
+	}
+
+	if ipFails != nil && int(ipFails.Val()) >= limits.ipLockThreshold {
+		s.lockLogin(ctx, loginLockIPKeyPrefix+ip, limits.ipLockDuration, ipKey)
+		s.audit(&models.AuthAuditLog{
+			Username:  username,
+			Event:     AuditIPLocked,
+			IPAddress: optionalString(ip),
+			Detail:    models.JSONB{"failures": ipFails.Val(), "lock_seconds": int(limits.ipLockDuration.Seconds())},
+		})
+	}
+}
+
+// resetLoginFailures 登录成功后清空该用户名的失败计数与等待
+func (s *userService) resetLoginFailures(ctx context.Context, username string) {
+	if err := redis_pkg.Client.Del(ctx, loginFailUserKeyPrefix+username, loginWaitKeyPrefix+username).Err(); err != nil {
+		logger.LogError("user", "reset_login_failures", err, map[string]interface{}{"username": username})
+	}
+}
+
+// UnlockUser 由管理员解除账号的登录锁定并清空失败计数
+func (s *userService) UnlockUser(operatorID, userID int64, ip string) error {
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return err // 包括 gorm.ErrRecordNotFound
+	}
+
+	ctx := context.Background()
+	removed, err := redis_pkg.Client.Del(ctx,
+		loginLockUserKeyPrefix+user.Username,
+		loginFailUserKeyPrefix+user.Username,
+		loginWaitKeyPrefix+user.Username).Result()
+	if err != nil {
+		return fmt.Errorf("failed to unlock user: %w", err)
+	}
+
+	s.audit(&models.AuthAuditLog{
+		UserID:     &user.ID,
+		Username:   user.Username,
+		Event:      AuditAccountUnlocked,
+		IPAddress:  optionalString(ip),
+		OperatorID: &operatorID,
+		Detail:     models.JSONB{"cleared_keys": removed},
+	})
+	return nil
+}
+
+// checkAccountStatus 拒绝已封禁或未激活的账号
+func checkAccountStatus(user *models.User) error {
+	switch user.Status {
+	case StatusBanned:
+		return ErrAccountBanned
+	case StatusInactive:
+		return ErrAccountInactive
+	default:
+		return nil
+	}
+}
+
+// lockLogin 设置锁定键并清空对应的失败计数
+func (s *userService) lockLogin(ctx context.Context, lockKey string, ttl time.Duration, clearKeys ...string) {
+	_, err := redis_pkg.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, lockKey, "1", ttl)
+		pipe.Del(ctx, clearKeys...)
+		return nil
+	})
+	if err != nil {
+		logger.LogError("user", "lock_login", err, map[string]interface{}{"key": lockKey})
+	}
+}
+
+// audit 写入认证审计日志，写入失败不影响主流程
+func (s *userService) audit(log *models.AuthAuditLog) {
+	logger.LogUserAction(strconv.FormatInt(valueOf(log.UserID), 10), log.Event, map[string]interface{}{
+		"username": log.Username,
+		"ip":       valueOrEmpty(log.IPAddress),
+		"detail":   log.Detail,
+	})
+	if err := s.repo.CreateAuthAuditLog(log); err != nil {
+		logger.LogError("user", "auth_audit", err, map[string]interface{}{"event": log.Event, "username": log.Username})
+	}
+}
+
+func userIDOf(user *models.User) *int64 {
+	if user == nil {
+		return nil
+	}
+	return &user.ID
+}
+
+func valueOf(p *int64) int64 {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func valueOrEmpty(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
@@ -0,0 +1,132 @@
+/*
+File: refresh_token.go
+Author: lxp
+Description: 刷新令牌的签发、轮换与令牌族吊销 (基于Redis)
+*/
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	redis_pkg "zhixue-backend/internal/redis"
+	"zhixue-backend/internal/session"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+)
+
+// refreshTokenKeyPrefix 刷新令牌摘要 -> {user_id, family_id, ver, used}
+const refreshTokenKeyPrefix = "refresh_token:"
+
+// rotateScript 原子地读取并标记刷新令牌为已使用
+// 返回 {-1}: 令牌不存在或所属令牌族已吊销；{0, uid, fid, ver}: 令牌已被使用过 (重用)；{1, uid, fid, ver}: 轮换成功
+var rotateScript = redis.NewScript(`
+local fields = redis.call('HMGET', KEYS[1], 'user_id', 'family_id', 'ver', 'used')
+if not fields[1] then
+	return {-1}
+end
+if redis.call('EXISTS', ARGV[1] .. fields[2]) == 1 then
+	return {-1}
+end
+if fields[4] == '1' then
+	return {0, fields[1], fields[2], fields[3]}
+end
+redis.call('HSET', KEYS[1], 'used', '1')
+return {1, fields[1], fields[2], fields[3]}
+`)
+
+// refreshRecord 是刷新令牌在Redis中保存的信息
+type refreshRecord struct {
+	UserID   int64
+	FamilyID string
+	Version  int64 // 签发时的令牌版本号
+}
+
+// issueRefreshToken 为指定令牌族签发一个新的刷新令牌
+// 令牌本身是不透明的随机串，Redis 中只保存其 SHA-256 摘要
+func (s *userService) issueRefreshToken(ctx context.Context, userID int64, familyID string, version int64) (string, error) {
+	token, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	key := refreshTokenKeyPrefix + hashToken(token)
+	_, err = redis_pkg.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, "user_id", userID, "family_id", familyID, "ver", version, "used", "0")
+		pipe.Expire(ctx, key, session.RefreshExpires(&s.config.Auth))
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to store refresh token: %w", err)
+	}
+	return token, nil
+}
+
+// rotateRefreshToken 消费一个刷新令牌
+// 已使用过的令牌再次出现说明令牌可能被窃取，此时吊销整个令牌族并返回 ErrRefreshTokenReused
+func (s *userService) rotateRefreshToken(ctx context.Context, token string) (*refreshRecord, error) {
+	key := refreshTokenKeyPrefix + hashToken(token)
+	res, err := rotateScript.Run(ctx, redis_pkg.Client, []string{key}, session.FamilyRevokedKeyPrefix).Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+
+	record, reused, err := parseRotateResult(res)
+	if err != nil {
+		return nil, err
+	}
+
+	if reused {
+		if err := s.sessions.RevokeFamily(ctx, record.UserID, record.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+	return record, nil
+}
+
+// parseRotateResult 解析 rotateScript 的返回值，reused 为 true 表示令牌已被使用过
+func parseRotateResult(res []interface{}) (record *refreshRecord, reused bool, err error) {
+	if len(res) < 4 {
+		return nil, false, ErrInvalidRefreshToken
+	}
+	status, _ := res[0].(int64)
+	if status < 0 {
+		return nil, false, ErrInvalidRefreshToken
+	}
+	uid, _ := res[1].(string)
+	userID, err := strconv.ParseInt(uid, 10, 64)
+	if err != nil {
+		return nil, false, ErrInvalidRefreshToken
+	}
+	record = &refreshRecord{UserID: userID}
+	record.FamilyID, _ = res[2].(string)
+	ver, _ := res[3].(string)
+	record.Version, _ = strconv.ParseInt(ver, 10, 64)
+	return record, status == 0, nil
+}
+
+// newOpaqueToken 生成一个不透明的随机令牌
+func newOpaqueToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashToken 计算令牌的摘要，避免Redis中出现可直接使用的令牌
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
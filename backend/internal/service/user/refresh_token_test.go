@@ -0,0 +1,72 @@
+/*
+File: refresh_token_test.go
+Author: lxp
+Description: 刷新令牌轮换结果解析与令牌工具函数的单元测试
+*/
+package user
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseRotateResult(t *testing.T) {
+	tests := []struct {
+		name       string
+		res        []interface{}
+		want       *refreshRecord
+		wantReused bool
+		wantErr    error
+	}{
+		{"轮换成功", []interface{}{int64(1), "42", "fam-1", "3"}, &refreshRecord{UserID: 42, FamilyID: "fam-1", Version: 3}, false, nil},
+		{"令牌重用", []interface{}{int64(0), "42", "fam-1", "3"}, &refreshRecord{UserID: 42, FamilyID: "fam-1", Version: 3}, true, nil},
+		{"令牌不存在或已吊销", []interface{}{int64(-1)}, nil, false, ErrInvalidRefreshToken},
+		{"空结果", []interface{}{}, nil, false, ErrInvalidRefreshToken},
+		{"用户ID无法解析", []interface{}{int64(1), "abc", "fam-1", "3"}, nil, false, ErrInvalidRefreshToken},
+		{"缺少版本号按0处理", []interface{}{int64(1), "42", "fam-1", nil}, &refreshRecord{UserID: 42, FamilyID: "fam-1"}, false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record, reused, err := parseRotateResult(tt.res)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("parseRotateResult error = %v, want %v", err, tt.wantErr)
+			}
+			if reused != tt.wantReused {
+				t.Errorf("reused = %v, want %v", reused, tt.wantReused)
+			}
+			if !reflect.DeepEqual(record, tt.want) {
+				t.Errorf("record = %+v, want %+v", record, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewOpaqueToken(t *testing.T) {
+	a, err := newOpaqueToken()
+	if err != nil {
+		t.Fatalf("newOpaqueToken error: %v", err)
+	}
+	b, _ := newOpaqueToken()
+	if a == b {
+		t.Error("newOpaqueToken returned the same token twice")
+	}
+	// 32 字节随机数经 RawURLEncoding 编码后为 43 个字符
+	if len(a) != 43 {
+		t.Errorf("len(token) = %d, want 43", len(a))
+	}
+}
+
+func TestHashToken(t *testing.T) {
+	h := hashToken("token")
+	if h != hashToken("token") {
+		t.Error("hashToken is not deterministic")
+	}
+	if h == hashToken("token2") {
+		t.Error("hashToken collides for different tokens")
+	}
+	if len(h) != 64 || h == "token" {
+		t.Errorf("hashToken = %q, want 64 hex characters", h)
+	}
+}
@@ -13,8 +13,14 @@ import (
 	"time"
 	"zhixue-backend/internal/api/dto"
 	"zhixue-backend/internal/config"
+	"zhixue-backend/internal/jwtkeys"
+	"zhixue-backend/internal/mail"
+	"zhixue-backend/internal/rbac"
 	redis_pkg "zhixue-backend/internal/redis"
 	"zhixue-backend/internal/repository/user"
+	badge_service "zhixue-backend/internal/service/badge"
+	"zhixue-backend/internal/session"
+	"zhixue-backend/logger"
 	"zhixue-backend/models"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -23,28 +29,57 @@ import (
 	"gorm.io/gorm"
 )
 
+var (
+	ErrInvalidTimezone = errors.New("invalid timezone")
+)
+
+// dummyPasswordHash 用于用户名不存在时的密码比对，使其与用户名存在时耗时一致，避免通过响应时间枚举用户名
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("zhixue-dummy-password"), bcrypt.DefaultCost)
+
+// 注册时可选的账号类型
+const (
+	AccountTypeStudent = "student"
+	AccountTypeParent  = "parent"
+)
+
 // Service 定义用户服务的接口
 type Service interface {
-	Register(username, password, email, nickname string) (*dto.RegisterResponse, error)
-	Login(username, password string) (string, *dto.UserResponse, error)
+	Register(username, password, email, nickname, accountType, lang string) (*dto.RegisterResponse, error)
+	Login(username, password string, device session.Device) (*dto.LoginResponse, error)
+	Refresh(refreshToken string, device session.Device) (*dto.RefreshResponse, error)
 	GetMe(userID int64) (*dto.UserResponse, error)
 	UpdateMe(userID int64, req *dto.UpdateUserRequest) (*dto.UserResponse, error)
 	Logout(authHeader string) error
+	ListSessions(userID int64, currentSessionID string) ([]dto.SessionResponse, error)
+	RevokeSession(userID int64, sessionID string) error
+	RevokeAllSessions(userID int64) error
+	SendVerificationEmail(userID int64, lang string) error
+	VerifyEmail(token string) error
+	ForgotPassword(email, lang string) error
+	ResetPassword(token, newPassword string) error
+	IsEmailVerified(userID int64) (bool, error)
+	UnlockUser(operatorID, userID int64, ip string) error
 }
 
 // userService 实现了Service接口
 type userService struct {
-	repo   user.Repository
-	config *config.Config
+	repo     user.Repository
+	sessions *session.Registry
+	keys     *jwtkeys.KeySet
+	mailer   mail.Sender
+	badges   badge_service.Service
+	config   *config.Config
 }
 
 // NewUserService 创建一个新的用户服务实例
-func NewUserService(repo user.Repository, config *config.Config) Service {
-	return &userService{repo: repo, config: config}
+func NewUserService(repo user.Repository, sessions *session.Registry, keys *jwtkeys.KeySet, mailer mail.Sender,
+	badges badge_service.Service, config *config.Config) Service {
+	return &userService{repo: repo, sessions: sessions, keys: keys, mailer: mailer, badges: badges, config: config}
 }
 
 // Register 处理用户注册逻辑
-func (s *userService) Register(username, password, email, nickname string) (*dto.RegisterResponse, error) {
+// accountType 为 parent 时注册家长账号，否则注册学生账号
+func (s *userService) Register(username, password, email, nickname, accountType, lang string) (*dto.RegisterResponse, error) {
 	// 哈希密码
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -57,7 +92,7 @@ func (s *userService) Register(username, password, email, nickname string) (*dto
 		PasswordHash: string(hashedPassword),
 		Email:        email,
 		Nickname:     nickname,
-		Role:         "user", // 默认角色
+		Role:         roleForAccountType(accountType),
 	}
 
 	// 直接尝试创建用户，由仓库层处理唯一键冲突和事务
@@ -71,69 +106,188 @@ func (s *userService) Register(username, password, email, nickname string) (*dto
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
+	// 发送验证邮件，失败不影响注册结果，用户之后可以重新发送
+	err = s.sendTokenMail(context.Background(), purposeVerifyEmail, mail.TemplateVerifyEmail, verifyEmailPath, newUser, lang, s.verifyTokenExpires())
+	if err != nil {
+		logger.LogError("user", "send_verification_email", err, map[string]interface{}{
+			"user_id": newUser.ID,
+		})
+	}
+
 	return &dto.RegisterResponse{
 		ID:       newUser.ID,
 		Username: newUser.Username,
 		Email:    newUser.Email,
 		Nickname: newUser.Nickname,
+		Role:     newUser.Role,
 	}, nil
 }
 
+// roleForAccountType 将注册时选择的账号类型映射为角色，教师与管理员不能自行注册
+func roleForAccountType(accountType string) string {
+	if accountType == AccountTypeParent {
+		return rbac.RoleParent
+	}
+	return rbac.RoleUser
+}
+
 // Login 处理用户登录逻辑
-func (s *userService) Login(username, password string) (string, *dto.UserResponse, error) {
+func (s *userService) Login(username, password string, device session.Device) (*dto.LoginResponse, error) {
+	// 账号或IP被锁定、仍处于等待期时直接拒绝，不校验密码
+	ctx := context.Background()
+	if err := s.checkLoginAllowed(ctx, username, device.IP); err != nil {
+		return nil, err
+	}
+
 	// 获取用户信息
 	user, err := s.repo.GetByUsername(username)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return "", nil, errors.New("用户不存在或密码错误")
+			_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+			s.recordLoginFailure(ctx, username, device.IP, nil)
+			return nil, ErrInvalidCredentials
 		}
-		return "", nil, err
+		return nil, err
 	}
 
 	// 验证密码
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
 	if err != nil {
-		return "", nil, errors.New("用户不存在或密码错误")
+		s.recordLoginFailure(ctx, username, device.IP, user)
+		return nil, ErrInvalidCredentials
+	}
+	s.resetLoginFailures(ctx, username)
+
+	// 密码正确后再检查账号状态，避免向猜测密码者暴露账号状态
+	if err := checkAccountStatus(user); err != nil {
+		return nil, err
 	}
 
 	// 更新登录时间
 	now := time.Now()
 	user.LastLoginAt = &now
 	if s.repo.Update(user) != nil {
-		return "", nil, errors.New("更新登录时间失败")
+		return nil, errors.New("更新登录时间失败")
+	}
+
+	// 每次登录开启一个新的令牌族 (即一个会话)，之后轮换出的刷新令牌都属于该族
+	familyID := uuid.New().String()
+	version, err := s.sessions.TokenVersion(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	tokenString, jti, err := s.signAccessToken(user, familyID, version)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := s.issueRefreshToken(ctx, user.ID, familyID, version)
+	if err != nil {
+		return nil, err
+	}
+	err = s.sessions.Register(ctx, &session.Session{ID: familyID, UserID: user.ID, Device: device, JTI: jti})
+	if err != nil {
+		return nil, err
+	}
+
+	userResponse := &dto.UserResponse{
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		Nickname:      user.Nickname,
+		AvatarURL:     user.AvatarURL,
+		GradeLevel:    user.GradeLevel,
+		BirthDate:     user.BirthDate,
+		Gender:        user.Gender,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerified,
+		Timezone:      user.Timezone,
+		CreatedAt:     user.CreatedAt,
+		LastLoginAt:   user.LastLoginAt,
 	}
 
-	// 生成JWT
+	return &dto.LoginResponse{
+		Token:        tokenString,
+		RefreshToken: refreshToken,
+		User:         userResponse,
+	}, nil
+}
+
+// Refresh 使用刷新令牌换取新的访问令牌和刷新令牌 (旧的刷新令牌随即失效)
+func (s *userService) Refresh(refreshToken string, device session.Device) (*dto.RefreshResponse, error) {
+	ctx := context.Background()
+	record, err := s.rotateRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// 执行过"全部登出"后，此前签发的刷新令牌一并失效
+	version, err := s.sessions.TokenVersion(ctx, record.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if record.Version < version {
+		if err := s.sessions.RevokeFamily(ctx, record.UserID, record.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// 重新读取用户，使角色变更在刷新后生效
+	user, err := s.repo.FindByID(record.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := s.sessions.RevokeFamily(ctx, record.UserID, record.FamilyID); err != nil {
+				return nil, err
+			}
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	// 账号被封禁或停用后不再续期
+	if statusErr := checkAccountStatus(user); statusErr != nil {
+		if err := s.sessions.RevokeFamily(ctx, record.UserID, record.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, statusErr
+	}
+
+	tokenString, jti, err := s.signAccessToken(user, record.FamilyID, version)
+	if err != nil {
+		return nil, err
+	}
+	newRefreshToken, err := s.issueRefreshToken(ctx, user.ID, record.FamilyID, version)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessions.Touch(ctx, user.ID, record.FamilyID, jti, device.IP); err != nil {
+		return nil, err
+	}
+
+	return &dto.RefreshResponse{
+		Token:        tokenString,
+		RefreshToken: newRefreshToken,
+	}, nil
+}
+
+// signAccessToken 签发访问令牌并返回其jti
+// fid 记录其所属的令牌族以便整族吊销，ver 为签发时的令牌版本号以便全部吊销
+func (s *userService) signAccessToken(user *models.User, familyID string, version int64) (string, string, error) {
 	jti := uuid.New().String()
 	claims := jwt.MapClaims{
 		"jti":       jti, // JWT唯一ID
+		"fid":       familyID,
+		"ver":       version,
 		"user_id":   user.ID,
 		"user_role": user.Role,
 		"exp":       time.Now().Add(s.config.Auth.JWTExpires).Unix(),
 		"iat":       time.Now().Unix(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(s.config.Auth.JWTSecret))
+	tokenString, err := s.keys.Sign(claims)
 	if err != nil {
-		return "", nil, err
+		return "", "", err
 	}
-
-	userResponse := &dto.UserResponse{
-		ID:          user.ID,
-		Username:    user.Username,
-		Email:       user.Email,
-		Nickname:    user.Nickname,
-		AvatarURL:   user.AvatarURL,
-		GradeLevel:  user.GradeLevel,
-		BirthDate:   user.BirthDate,
-		Gender:      user.Gender,
-		Role:        user.Role,
-		CreatedAt:   user.CreatedAt,
-		LastLoginAt: user.LastLoginAt, 
-	}
-
-	return tokenString, userResponse, nil
+	return tokenString, jti, nil
 }
 
 // GetMe 获取当前登录用户的信息
@@ -144,18 +298,26 @@ func (s *userService) GetMe(userID int64) (*dto.UserResponse, error) {
 	}
 
 	userResponse := &dto.UserResponse{
-		ID:          user.ID,
-		Username:    user.Username,
-		Email:       user.Email,
-		Nickname:    user.Nickname,
-		AvatarURL:   user.AvatarURL,
-		GradeLevel:  user.GradeLevel,
-		BirthDate:   user.BirthDate,
-		Gender:      user.Gender,
-		Role:        user.Role,
-		CreatedAt:   user.CreatedAt,
-		LastLoginAt: user.LastLoginAt, 
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		Nickname:      user.Nickname,
+		AvatarURL:     user.AvatarURL,
+		GradeLevel:    user.GradeLevel,
+		BirthDate:     user.BirthDate,
+		Gender:        user.Gender,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerified,
+		Timezone:      user.Timezone,
+		CreatedAt:     user.CreatedAt,
+		LastLoginAt:   user.LastLoginAt,
+	}
+
+	badges, err := s.badges.ListEarned(userID)
+	if err != nil {
+		return nil, err
 	}
+	userResponse.Badges = badges
 
 	return userResponse, nil
 }
@@ -184,6 +346,14 @@ func (s *userService) UpdateMe(userID int64, req *dto.UpdateUserRequest) (*dto.U
 	if req.Gender != nil {
 		user.Gender = *req.Gender
 	}
+	if req.Timezone != nil {
+		if *req.Timezone != "" {
+			if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "Local" {
+				return nil, ErrInvalidTimezone
+			}
+		}
+		user.Timezone = *req.Timezone
+	}
 
 	// 3. 将更新后的用户保存到数据库
 	if err := s.repo.Update(user); err != nil {
@@ -192,23 +362,25 @@ func (s *userService) UpdateMe(userID int64, req *dto.UpdateUserRequest) (*dto.U
 
 	// 4. 返回更新后的用户信息
 	updatedUserResponse := &dto.UserResponse{
-		ID:          user.ID,
-		Username:    user.Username,
-		Email:       user.Email,
-		Nickname:    user.Nickname,
-		AvatarURL:   user.AvatarURL,
-		GradeLevel:  user.GradeLevel,
-		BirthDate:   user.BirthDate,
-		Gender:      user.Gender,
-		Role:        user.Role,
-		CreatedAt:   user.CreatedAt,
-		LastLoginAt: user.LastLoginAt, 
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		Nickname:      user.Nickname,
+		AvatarURL:     user.AvatarURL,
+		GradeLevel:    user.GradeLevel,
+		BirthDate:     user.BirthDate,
+		Gender:        user.Gender,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerified,
+		Timezone:      user.Timezone,
+		CreatedAt:     user.CreatedAt,
+		LastLoginAt:   user.LastLoginAt,
 	}
 
 	return updatedUserResponse, nil
 }
 
-// Logout 处理用户登出逻辑，将JWT加入黑名单
+// Logout 处理用户登出逻辑，将JWT加入黑名单并吊销其所属的令牌族 (包括刷新令牌)
 func (s *userService) Logout(authHeader string) error {
 	// 1. 从 "Bearer <token>" 中提取 token
 	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
@@ -228,7 +400,16 @@ func (s *userService) Logout(authHeader string) error {
 		return errors.New("无法读取token claims")
 	}
 
-	// 3. 获取 jti 和 exp
+	// 3. 吊销令牌族 (即当前会话)，使该次登录签发的刷新令牌全部失效
+	fid, okFid := claims["fid"].(string)
+	userIDFloat, okUID := claims["user_id"].(float64)
+	if okFid && okUID && fid != "" {
+		if err := s.sessions.RevokeFamily(context.Background(), int64(userIDFloat), fid); err != nil {
+			return err
+		}
+	}
+
+	// 4. 获取 jti 和 exp
 	jti, ok := claims["jti"].(string)
 	if !ok {
 		return errors.New("token中缺少jti")
@@ -239,15 +420,15 @@ func (s *userService) Logout(authHeader string) error {
 	}
 	exp := time.Unix(int64(expFloat), 0)
 
-	// 4. 计算剩余过期时间
+	// 5. 计算剩余过期时间
 	// 如果token已经过期，duration会是负数，Redis的SetEX会自动处理，不会设置或立即过期
 	duration := time.Until(exp)
 	if duration <= 0 {
 		return nil // Token已过期，无需拉黑
 	}
 
-	// 5. 将jti加入Redis黑名单
-	blacklistKey := "jwt_blacklist:" + jti
+	// 6. 将jti加入Redis黑名单
+	blacklistKey := session.BlacklistKeyPrefix + jti
 	err = redis_pkg.Client.Set(context.Background(), blacklistKey, "true", duration).Err()
 	if err != nil {
 		return fmt.Errorf("无法将token加入黑名单: %v", err)
@@ -255,3 +436,35 @@ func (s *userService) Logout(authHeader string) error {
 
 	return nil
 }
+
+// ListSessions 获取用户的全部活跃会话 (登录设备)
+func (s *userService) ListSessions(userID int64, currentSessionID string) ([]dto.SessionResponse, error) {
+	sessions, err := s.sessions.List(context.Background(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dto.SessionResponse, 0, len(sessions))
+	for _, sess := range sessions {
+		items = append(items, dto.SessionResponse{
+			ID:         sess.ID,
+			DeviceName: sess.Device.Name,
+			UserAgent:  sess.Device.UserAgent,
+			IP:         sess.Device.IP,
+			Current:    sess.ID == currentSessionID,
+			CreatedAt:  sess.CreatedAt,
+			LastSeenAt: sess.LastSeenAt,
+		})
+	}
+	return items, nil
+}
+
+// RevokeSession 吊销用户的某个会话，该设备需要重新登录
+func (s *userService) RevokeSession(userID int64, sessionID string) error {
+	return s.sessions.Revoke(context.Background(), userID, sessionID)
+}
+
+// RevokeAllSessions 吊销用户的全部会话 (包括当前会话)
+func (s *userService) RevokeAllSessions(userID int64) error {
+	return s.sessions.RevokeAll(context.Background(), userID)
+}
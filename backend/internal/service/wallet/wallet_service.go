@@ -0,0 +1,203 @@
+/*
+File: wallet_service.go
+Author: lxp
+Description: 积分钱包业务逻辑：入账、扣减、余额缓存 (基于Redis) 与积分流水查询
+*/
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"zhixue-backend/internal/api/dto"
+	"zhixue-backend/internal/repository/wallet"
+	"zhixue-backend/logger"
+	"zhixue-backend/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis 键前缀，键中包含用户ID (如 wallet_balance:42)
+const walletBalanceKeyPrefix = "wallet_balance:"
+
+// 余额缓存的有效期，每次入账或扣减后会写入最新余额
+const balanceCacheTTL = 10 * time.Minute
+
+// setBalanceScript 写入余额缓存 "版本:余额"，缓存中已有相同或更新版本时不覆盖
+// 版本为最近一条流水的ID，避免并发读取时把提交前查到的旧余额写回缓存
+var setBalanceScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current then
+	local version = tonumber(string.match(current, '^(%d+):'))
+	if version and version >= tonumber(ARGV[1]) then
+		return 0
+	end
+end
+redis.call('SET', KEYS[1], ARGV[1] .. ':' .. ARGV[2], 'PX', ARGV[3])
+return 1
+`)
+
+var (
+	ErrInvalidAmount = errors.New("points amount must be positive")
+)
+
+// Service 定义积分钱包服务的接口
+type Service interface {
+	// Credit 与 Debit 供其他业务 (任务领奖、连续学习奖励、购买道具等) 使用
+	// reference 标识积分的来源 (如 user_task:42)，同一用户同一原因下相同来源只记账一次，为空时不去重
+	Credit(ctx context.Context, userID int64, amount int, reason, reference, note string, operatorID *int64) (*models.PointsTransaction, error)
+	Debit(ctx context.Context, userID int64, amount int, reason, reference, note string) (*models.PointsTransaction, error)
+	GetBalance(ctx context.Context, userID int64) (int, error)
+	// CacheBalance 供通过 wallet.AppendTx 在其他事务中写入积分流水的业务在提交后调用
+	CacheBalance(ctx context.Context, t *models.PointsTransaction)
+	GetWallet(ctx context.Context, userID int64, query *dto.WalletQuery) (*dto.WalletResponse, error)
+	GrantPoints(ctx context.Context, operatorID, userID int64, req *dto.GrantPointsRequest) (*dto.PointsTransactionResponse, error)
+}
+
+// walletService 实现了Service接口
+type walletService struct {
+	repo  wallet.Repository
+	redis *redis.Client
+}
+
+// NewWalletService 创建一个新的积分钱包服务实例
+func NewWalletService(repo wallet.Repository, redisClient *redis.Client) Service {
+	return &walletService{repo: repo, redis: redisClient}
+}
+
+// Credit 为用户入账积分
+func (s *walletService) Credit(ctx context.Context, userID int64, amount int, reason, reference, note string, operatorID *int64) (*models.PointsTransaction, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	return s.append(ctx, &models.PointsTransaction{
+		UserID:     userID,
+		Amount:     amount,
+		Reason:     reason,
+		Reference:  optional(reference),
+		Note:       note,
+		OperatorID: operatorID,
+	})
+}
+
+// Debit 扣减用户的积分，余额不足时返回 wallet.ErrInsufficientBalance，并发扣减不会使余额为负
+func (s *walletService) Debit(ctx context.Context, userID int64, amount int, reason, reference, note string) (*models.PointsTransaction, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	return s.append(ctx, &models.PointsTransaction{
+		UserID:    userID,
+		Amount:    -amount,
+		Reason:    reason,
+		Reference: optional(reference),
+		Note:      note,
+	})
+}
+
+// append 追加积分流水并更新余额缓存，重复来源的流水返回已有的记录
+func (s *walletService) append(ctx context.Context, t *models.PointsTransaction) (*models.PointsTransaction, error) {
+	appended, err := s.repo.Append(t)
+	if err != nil {
+		return nil, err // 错误可能是 gorm.ErrRecordNotFound 或 wallet.ErrInsufficientBalance
+	}
+	if appended {
+		s.CacheBalance(ctx, t)
+	}
+	return t, nil
+}
+
+// GetBalance 获取用户的积分余额，优先读取Redis缓存，Redis不可用时直接查询数据库
+func (s *walletService) GetBalance(ctx context.Context, userID int64) (int, error) {
+	key := balanceKey(userID)
+
+	cached, err := s.redis.Get(ctx, key).Result()
+	if err == nil {
+		if _, balance, ok := strings.Cut(cached, ":"); ok {
+			if v, err := strconv.Atoi(balance); err == nil {
+				return v, nil
+			}
+		}
+	} else if !errors.Is(err, redis.Nil) {
+		logger.LogError("wallet", "get_balance", err, map[string]interface{}{"user_id": userID})
+	}
+
+	balance, version, err := s.repo.Balance(userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get balance: %w", err)
+	}
+	s.setBalance(ctx, userID, balance, version)
+	return balance, nil
+}
+
+// GetWallet 获取用户的积分余额与分页的积分流水，最新的在前
+func (s *walletService) GetWallet(ctx context.Context, userID int64, query *dto.WalletQuery) (*dto.WalletResponse, error) {
+	query.Normalize()
+
+	balance, err := s.GetBalance(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, total, err := s.repo.List(userID, query.Page, query.PageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list points transactions: %w", err)
+	}
+	items := make([]dto.PointsTransactionResponse, 0, len(transactions))
+	for i := range transactions {
+		items = append(items, *toTransactionResponse(&transactions[i]))
+	}
+
+	return &dto.WalletResponse{
+		Balance: balance,
+		History: dto.NewPageResponse(items, query.Page, query.PageSize, total),
+	}, nil
+}
+
+// GrantPoints 管理员为用户发放积分
+func (s *walletService) GrantPoints(ctx context.Context, operatorID, userID int64, req *dto.GrantPointsRequest) (*dto.PointsTransactionResponse, error) {
+	t, err := s.Credit(ctx, userID, req.Amount, wallet.ReasonAdminGrant, "", req.Note, &operatorID)
+	if err != nil {
+		return nil, err
+	}
+	return toTransactionResponse(t), nil
+}
+
+// CacheBalance 将刚提交的积分流水的 BalanceAfter 写入余额缓存
+func (s *walletService) CacheBalance(ctx context.Context, t *models.PointsTransaction) {
+	s.setBalance(ctx, t.UserID, t.BalanceAfter, t.ID)
+}
+
+// setBalance 按版本写入余额缓存，失败只记录日志，缓存会在有效期后自然过期
+func (s *walletService) setBalance(ctx context.Context, userID int64, balance int, version int64) {
+	err := setBalanceScript.Run(ctx, s.redis, []string{balanceKey(userID)},
+		version, balance, balanceCacheTTL.Milliseconds()).Err()
+	if err != nil {
+		logger.LogError("wallet", "set_balance", err, map[string]interface{}{"user_id": userID})
+	}
+}
+
+func balanceKey(userID int64) string {
+	return walletBalanceKeyPrefix + strconv.FormatInt(userID, 10)
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func toTransactionResponse(t *models.PointsTransaction) *dto.PointsTransactionResponse {
+	return &dto.PointsTransactionResponse{
+		ID:           t.ID,
+		Amount:       t.Amount,
+		BalanceAfter: t.BalanceAfter,
+		Reason:       t.Reason,
+		Reference:    t.Reference,
+		Note:         t.Note,
+		CreatedAt:    t.CreatedAt,
+	}
+}
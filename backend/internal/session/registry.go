@@ -0,0 +1,323 @@
+/*
+File: registry.go
+Author: lxp
+Description: 用户登录会话注册表 (基于Redis)，支持多设备会话查看、单个吊销与全部吊销
+*/
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+	"zhixue-backend/internal/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrSessionNotFound   = errors.New("session not found")
+	ErrTokenBlacklisted  = errors.New("token has been logged out")
+	ErrSessionRevoked    = errors.New("session has been revoked")
+	ErrTokenVersionStale = errors.New("token version is stale")
+)
+
+// Redis 键前缀
+const (
+	BlacklistKeyPrefix     = "jwt_blacklist:"      // 已登出的访问令牌 jti
+	FamilyRevokedKeyPrefix = "jwt_family_revoked:" // 被吊销的令牌族 (即会话)
+	sessionKeyPrefix       = "user_session:"       // 会话ID -> 会话详情
+	userSessionsKeyPrefix  = "user_sessions:"      // 用户ID -> 会话ID集合
+	tokenVersionKeyPrefix  = "user_token_version:" // 用户ID -> 令牌版本号
+)
+
+// SessionHeader 网关注入的当前会话ID请求头
+const SessionHeader = "X-Session-ID"
+
+// DefaultRefreshExpires 未配置 RefreshExpires 时刷新令牌 (即会话) 的有效期
+const DefaultRefreshExpires = 7 * 24 * time.Hour
+
+// RefreshExpires 返回刷新令牌 (即会话) 的有效期
+func RefreshExpires(cfg *config.AuthConfig) time.Duration {
+	if cfg.RefreshExpires > 0 {
+		return cfg.RefreshExpires
+	}
+	return DefaultRefreshExpires
+}
+
+// checkScript 在一次往返中完成访问令牌的吊销校验，并刷新会话的最后活跃信息
+// KEYS: 黑名单键, 令牌族吊销键, 令牌版本键, 会话键
+// ARGV: 令牌版本号, 当前时间戳, 客户端IP, 是否携带令牌族 ("1"/"0")
+// 返回 0: 通过；1: 已登出；2: 会话已吊销；3: 令牌版本过期
+var checkScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 1
+end
+if ARGV[4] == '1' and redis.call('EXISTS', KEYS[2]) == 1 then
+	return 2
+end
+local current = tonumber(redis.call('GET', KEYS[3]) or '0')
+if current > tonumber(ARGV[1]) then
+	return 3
+end
+if ARGV[4] == '1' and redis.call('EXISTS', KEYS[4]) == 1 then
+	redis.call('HSET', KEYS[4], 'last_seen_at', ARGV[2], 'ip', ARGV[3])
+end
+return 0
+`)
+
+// Device 描述发起登录的客户端
+type Device struct {
+	Name      string // 客户端上报的设备名称，如 "客厅的平板"
+	UserAgent string
+	IP        string
+}
+
+// Session 是一次登录产生的会话，会话ID即刷新令牌的令牌族ID
+type Session struct {
+	ID         string
+	UserID     int64
+	Device     Device
+	JTI        string // 当前访问令牌的 jti
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+}
+
+// TokenRef 是网关校验访问令牌时使用的令牌信息
+type TokenRef struct {
+	JTI       string
+	SessionID string // 令牌族ID，旧令牌可能为空
+	UserID    int64
+	Version   int64
+	IP        string
+}
+
+// Registry 管理用户的活跃会话
+type Registry struct {
+	client     *redis.Client
+	ttl        time.Duration // 会话有效期，与刷新令牌一致
+	revokedTTL time.Duration // 吊销标记需要覆盖会话内任一令牌的剩余寿命
+}
+
+// NewRegistry 创建会话注册表
+func NewRegistry(client *redis.Client, cfg *config.AuthConfig) *Registry {
+	ttl := RefreshExpires(cfg)
+	revokedTTL := ttl
+	if cfg.JWTExpires > revokedTTL {
+		revokedTTL = cfg.JWTExpires
+	}
+	return &Registry{client: client, ttl: ttl, revokedTTL: revokedTTL}
+}
+
+// Register 登记一个新会话
+func (r *Registry) Register(ctx context.Context, s *Session) error {
+	now := time.Now()
+	s.CreatedAt = now
+	s.LastSeenAt = now
+
+	key := sessionKeyPrefix + s.ID
+	listKey := userSessionsKeyPrefix + strconv.FormatInt(s.UserID, 10)
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key,
+			"user_id", s.UserID,
+			"device_name", s.Device.Name,
+			"user_agent", s.Device.UserAgent,
+			"ip", s.Device.IP,
+			"jti", s.JTI,
+			"created_at", now.Unix(),
+			"last_seen_at", now.Unix())
+		pipe.Expire(ctx, key, r.ttl)
+		pipe.SAdd(ctx, listKey, s.ID)
+		pipe.Expire(ctx, listKey, r.ttl)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register session: %w", err)
+	}
+	return nil
+}
+
+// Touch 在刷新令牌轮换后更新会话的当前访问令牌并续期
+func (r *Registry) Touch(ctx context.Context, userID int64, sessionID, jti, ip string) error {
+	key := sessionKeyPrefix + sessionID
+	listKey := userSessionsKeyPrefix + strconv.FormatInt(userID, 10)
+
+	n, err := r.client.Exists(ctx, key).Result()
+	if err != nil {
+		return fmt.Errorf("failed to touch session: %w", err)
+	}
+	if n == 0 {
+		// 会话记录可能已被清理，但令牌族仍然有效时不影响刷新
+		return nil
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, "jti", jti, "ip", ip, "last_seen_at", time.Now().Unix())
+		pipe.Expire(ctx, key, r.ttl)
+		pipe.SAdd(ctx, listKey, sessionID)
+		pipe.Expire(ctx, listKey, r.ttl)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to touch session: %w", err)
+	}
+	return nil
+}
+
+// List 返回用户的全部活跃会话，按最后活跃时间倒序
+func (r *Registry) List(ctx context.Context, userID int64) ([]*Session, error) {
+	listKey := userSessionsKeyPrefix + strconv.FormatInt(userID, 10)
+	ids, err := r.client.SMembers(ctx, listKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	if len(ids) == 0 {
+		return []*Session{}, nil
+	}
+
+	cmds := make([]*redis.MapStringStringCmd, len(ids))
+	_, err = r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, id := range ids {
+			cmds[i] = pipe.HGetAll(ctx, sessionKeyPrefix+id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	sessions := make([]*Session, 0, len(ids))
+	var stale []interface{}
+	for i, cmd := range cmds {
+		s := parseSession(ids[i], cmd.Val())
+		if s == nil {
+			stale = append(stale, ids[i])
+			continue
+		}
+		sessions = append(sessions, s)
+	}
+	// 清理已过期的会话ID
+	if len(stale) > 0 {
+		r.client.SRem(ctx, listKey, stale...)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+	return sessions, nil
+}
+
+// Revoke 吊销用户的某个会话：会话内的刷新令牌不能再轮换，访问令牌会被网关拒绝
+func (r *Registry) Revoke(ctx context.Context, userID int64, sessionID string) error {
+	owner, err := r.client.HGet(ctx, sessionKeyPrefix+sessionID, "user_id").Result()
+	if errors.Is(err, redis.Nil) || (err == nil && owner != strconv.FormatInt(userID, 10)) {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return r.RevokeFamily(ctx, userID, sessionID)
+}
+
+// RevokeFamily 吊销一个令牌族并移除对应的会话记录，不校验会话是否存在
+func (r *Registry) RevokeFamily(ctx context.Context, userID int64, sessionID string) error {
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, FamilyRevokedKeyPrefix+sessionID, "true", r.revokedTTL)
+		pipe.Del(ctx, sessionKeyPrefix+sessionID)
+		pipe.SRem(ctx, userSessionsKeyPrefix+strconv.FormatInt(userID, 10), sessionID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke session family: %w", err)
+	}
+	return nil
+}
+
+// RevokeAll 吊销用户的全部会话
+// 通过递增令牌版本号使此前签发的所有令牌失效，网关只需比较版本号，无需逐个拉黑
+func (r *Registry) RevokeAll(ctx context.Context, userID int64) error {
+	uid := strconv.FormatInt(userID, 10)
+	listKey := userSessionsKeyPrefix + uid
+	ids, err := r.client.SMembers(ctx, listKey).Result()
+	if err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Incr(ctx, tokenVersionKeyPrefix+uid)
+		for _, id := range ids {
+			pipe.Del(ctx, sessionKeyPrefix+id)
+		}
+		pipe.Del(ctx, listKey)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
+
+// TokenVersion 返回用户当前的令牌版本号，签发令牌时写入 ver claim
+func (r *Registry) TokenVersion(ctx context.Context, userID int64) (int64, error) {
+	v, err := r.client.Get(ctx, tokenVersionKeyPrefix+strconv.FormatInt(userID, 10)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get token version: %w", err)
+	}
+	return v, nil
+}
+
+// Check 校验访问令牌是否已被登出或吊销，通过时同时更新会话的最后活跃时间
+func (r *Registry) Check(ctx context.Context, t *TokenRef) error {
+	hasSession := "0"
+	if t.SessionID != "" {
+		hasSession = "1"
+	}
+	keys := []string{
+		BlacklistKeyPrefix + t.JTI,
+		FamilyRevokedKeyPrefix + t.SessionID,
+		tokenVersionKeyPrefix + strconv.FormatInt(t.UserID, 10),
+		sessionKeyPrefix + t.SessionID,
+	}
+	code, err := checkScript.Run(ctx, r.client, keys, t.Version, time.Now().Unix(), t.IP, hasSession).Int()
+	if err != nil {
+		return fmt.Errorf("failed to check token: %w", err)
+	}
+
+	switch code {
+	case 1:
+		return ErrTokenBlacklisted
+	case 2:
+		return ErrSessionRevoked
+	case 3:
+		return ErrTokenVersionStale
+	default:
+		return nil
+	}
+}
+
+// parseSession 将Redis中的会话哈希转换为Session，记录不存在时返回nil
+func parseSession(id string, fields map[string]string) *Session {
+	if len(fields) == 0 {
+		return nil
+	}
+	userID, _ := strconv.ParseInt(fields["user_id"], 10, 64)
+	createdAt, _ := strconv.ParseInt(fields["created_at"], 10, 64)
+	lastSeenAt, _ := strconv.ParseInt(fields["last_seen_at"], 10, 64)
+	return &Session{
+		ID:     id,
+		UserID: userID,
+		Device: Device{
+			Name:      fields["device_name"],
+			UserAgent: fields["user_agent"],
+			IP:        fields["ip"],
+		},
+		JTI:        fields["jti"],
+		CreatedAt:  time.Unix(createdAt, 0),
+		LastSeenAt: time.Unix(lastSeenAt, 0),
+	}
+}
@@ -27,23 +27,56 @@ func (j *JSONB) Scan(value interface{}) error {
 	return json.Unmarshal(bytes, j)
 }
 
+// StringList 以JSON数组形式存储在PostgreSQL JSONB字段中的字符串列表
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(l)
+	return string(b), err
+}
+func (l *StringList) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, l)
+}
+
 // ================= 用户系统 =================
 type User struct {
-	ID           int64  `gorm:"primaryKey;autoIncrement"`
-	Username     string `gorm:"size:50;uniqueIndex;not null"`
-	Email        string `gorm:"size:100;uniqueIndex;not null"`
-	PasswordHash string `gorm:"size:255;not null"`
-	Nickname     string `gorm:"size:50;not null"`
-	AvatarURL    string `gorm:"size:255;default:''"`
-	GradeLevel   int    `gorm:"default:1"`
-	BirthDate    *time.Time
-	Gender       string    `gorm:"type:user_gender;default:'other'"`
-	Status       string    `gorm:"type:user_status;default:'active'"`
-	Role         string    `gorm:"type:user_role;default:'user'"`
-	CreatedAt    time.Time `gorm:"autoCreateTime"`
-	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
-	LastLoginAt  *time.Time
-	Profiles     []UserProfile `gorm:"foreignKey:UserID"`
+	ID              int64  `gorm:"primaryKey;autoIncrement"`
+	Username        string `gorm:"size:50;uniqueIndex;not null"`
+	Email           string `gorm:"size:100;uniqueIndex;not null"`
+	PasswordHash    string `gorm:"size:255;not null"`
+	Nickname        string `gorm:"size:50;not null"`
+	AvatarURL       string `gorm:"size:255;default:''"`
+	GradeLevel      int    `gorm:"default:1"`
+	BirthDate       *time.Time
+	Gender          string `gorm:"type:user_gender;default:'other'"`
+	Status          string `gorm:"type:user_status;default:'active'"`
+	Role            string `gorm:"type:user_role;default:'user'"`
+	EmailVerified   bool   `gorm:"not null;default:false"`
+	EmailVerifiedAt *time.Time
+	Timezone        string    `gorm:"size:64;not null;default:''"` // IANA时区名，如 Asia/Shanghai，为空时使用默认时区
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime"`
+	LastLoginAt     *time.Time
+	Profiles        []UserProfile `gorm:"foreignKey:UserID"`
+}
+
+// AuthAuditLog 记录账号锁定、解锁等安全相关事件
+type AuthAuditLog struct {
+	ID         int64     `gorm:"primaryKey;autoIncrement"`
+	UserID     *int64    // 用户名不存在时为空
+	Username   string    `gorm:"size:50"`
+	Event      string    `gorm:"type:auth_audit_event;not null"`
+	IPAddress  *string   `gorm:"type:inet"`
+	OperatorID *int64    // 执行解锁等操作的管理员
+	Detail     JSONB     `gorm:"type:jsonb;default:'{}'"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
 }
 
 type UserProfile struct {
@@ -61,6 +94,20 @@ type UserProfile struct {
 	PreferredDifficulty float64   `gorm:"type:decimal(3,2);default:2.5"`
 	CreatedAt           time.Time `gorm:"autoCreateTime"`
 	UpdatedAt           time.Time `gorm:"autoUpdateTime"`
+
+	LastActiveDate *time.Time `gorm:"type:date"` // 最近一个学习日 (用户时区)
+	StreakFreezes  int        `gorm:"default:0"` // 持有的连续学习保护卡数
+}
+
+// UserActivityDay 是用户在一个学习日 (用户时区) 内的学习活动，用于计算连续学习天数
+// Frozen 为 true 表示该日没有学习，由连续学习保护卡保住了连续天数
+type UserActivityDay struct {
+	UserID       int64     `gorm:"primaryKey"`
+	ActivityDate time.Time `gorm:"primaryKey;type:date"`
+	Answers      int       `gorm:"default:0"`
+	StudySeconds int       `gorm:"default:0"`
+	XP           int       `gorm:"column:xp;default:0"`
+	Frozen       bool      `gorm:"default:false"`
 }
 
 type UserGradeHistory struct {
@@ -72,6 +119,42 @@ type UserGradeHistory struct {
 	CreatedAt  time.Time `gorm:"autoCreateTime"`
 }
 
+// ParentChildLink 记录家长账号与孩子账号的关联
+type ParentChildLink struct {
+	ParentID  int64     `gorm:"primaryKey"`
+	ChildID   int64     `gorm:"primaryKey;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// ParentalControl 是家长为孩子设置的学习管控，每个孩子一条，多位家长共享
+type ParentalControl struct {
+	ChildID             int64      `gorm:"primaryKey;autoIncrement:false"`
+	DailyLimitMinutes   int        `gorm:"not null;default:0"` // 0 表示不限制
+	AllowedSessionTypes StringList `gorm:"type:jsonb;not null"`
+	UpdatedBy           *int64
+	CreatedAt           time.Time `gorm:"autoCreateTime"`
+	UpdatedAt           time.Time `gorm:"autoUpdateTime"`
+}
+
+// Class 是教师创建的班级，学生通过加入码加入
+type Class struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement"`
+	Name        string    `gorm:"size:100;not null"`
+	Description string    `gorm:"type:text"`
+	GradeLevel  int       `gorm:"not null"`
+	TeacherID   int64     `gorm:"not null;index"`
+	JoinCode    string    `gorm:"size:8;uniqueIndex;not null"`
+	IsArchived  bool      `gorm:"default:false"` // 归档后不能再加入
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+type ClassMember struct {
+	ClassID  int64     `gorm:"primaryKey"`
+	UserID   int64     `gorm:"primaryKey;index"`
+	JoinedAt time.Time `gorm:"autoCreateTime"`
+}
+
 // ================= 数学题库系统 =================
 type KnowledgePoint struct {
 	ID              int64     `gorm:"primaryKey;autoIncrement"`
@@ -111,11 +194,41 @@ type Question struct {
 	UpdatedAt       time.Time `gorm:"autoUpdateTime"`
 }
 
+// KnowledgePointPrerequisite 表示 KnowledgePointID 需要先掌握 PrerequisiteID，全部边构成有向无环图
+type KnowledgePointPrerequisite struct {
+	KnowledgePointID int64     `gorm:"primaryKey"`
+	PrerequisiteID   int64     `gorm:"primaryKey;index"`
+	CreatedAt        time.Time `gorm:"autoCreateTime"`
+}
+
 type QuestionKnowledgePoint struct {
 	QuestionID       int64 `gorm:"primaryKey"`
 	KnowledgePointID int64 `gorm:"primaryKey"`
 }
 
+// KnowledgePointMasteryParam 是知识点的BKT参数，未配置的知识点使用 mastery 配置中的默认值
+type KnowledgePointMasteryParam struct {
+	KnowledgePointID int64   `gorm:"primaryKey;autoIncrement:false"`
+	PInit            float64 `gorm:"type:decimal(5,4);not null"` // 初始掌握概率
+	PLearn           float64 `gorm:"type:decimal(5,4);not null"` // 每次作答后从未掌握转为掌握的概率
+	PSlip            float64 `gorm:"type:decimal(5,4);not null"` // 已掌握但答错的概率
+	PGuess           float64 `gorm:"type:decimal(5,4);not null"` // 未掌握但猜对的概率
+	UpdatedBy        *int64
+	CreatedAt        time.Time `gorm:"autoCreateTime"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime"`
+}
+
+type QuestionReview struct {
+	ID         int64     `gorm:"primaryKey;autoIncrement"`
+	QuestionID int64     `gorm:"not null;index"`
+	ReviewerID *int64    // 操作人，用户被删除后置空
+	Action     string    `gorm:"type:review_action;not null"`
+	FromStatus string    `gorm:"type:review_status;not null"`
+	ToStatus   string    `gorm:"type:review_status;not null"`
+	Comment    string    `gorm:"type:text"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
 // ================= 学习行为记录表 =================
 type AnswerRecord struct {
 	ID               int64     `gorm:"primaryKey;autoIncrement:false"`
@@ -140,15 +253,123 @@ type LearningSession struct {
 	UserID           int64     `gorm:"not null;index"`
 	StartTime        time.Time `gorm:"not null"`
 	EndTime          *time.Time
-	QuestionsCount   int       `gorm:"default:0"`
+	QuestionsCount   int     `gorm:"default:0"`
+	CorrectCount     int     `gorm:"default:0"`
+	AvgDifficulty    float64 `gorm:"type:decimal(3,2);default:0.00"`
+	SessionType      string  `gorm:"type:session_type;default:'practice'"`
+	CompletionStatus string  `gorm:"type:completion_status;default:'ongoing'"`
+	LastActiveAt     *time.Time
+	PausedAt         *time.Time
+	PausedSeconds    int       `gorm:"default:0"`
+	CreatedAt        time.Time `gorm:"autoCreateTime"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime"`
+}
+
+// UserKnowledgeMastery 是用户在单个知识点上的掌握概率，每次答题后按BKT更新
+type UserKnowledgeMastery struct {
+	UserID           int64      `gorm:"primaryKey"`
+	KnowledgePointID int64      `gorm:"primaryKey;index"`
+	Mastery          float64    `gorm:"type:decimal(5,4);not null;default:0"`
+	AttemptCount     int        `gorm:"default:0"`
+	CorrectCount     int        `gorm:"default:0"`
+	LastAnsweredAt   *time.Time // 已计入的最近一次答题时间，早于它的答题不再重复计入
+	CreatedAt        time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt        time.Time  `gorm:"autoUpdateTime"`
+}
+
+// ReviewItem 是用户答错过的题目的间隔复习状态 (SM-2)，每次再次作答后重新安排
+type ReviewItem struct {
+	UserID         int64     `gorm:"primaryKey"`
+	QuestionID     int64     `gorm:"primaryKey;index"`
+	Repetitions    int       `gorm:"default:0"`                      // 连续答对的复习次数
+	IntervalDays   int       `gorm:"default:1"`                      // 当前复习间隔 (天)
+	EaseFactor     float64   `gorm:"type:decimal(4,2);default:2.50"` // 难易系数，最低 1.30
+	DueAt          time.Time `gorm:"not null"`
+	ReviewCount    int       `gorm:"default:0"` // 进入复习队列后的作答次数
+	LapseCount     int       `gorm:"default:0"` // 进入复习队列后答错的次数
+	LastReviewedAt *time.Time
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+}
+
+// NotebookResolution 记录学生将错题本中的题目标记为已掌握，之后再答错该题时重新出现在错题本中
+type NotebookResolution struct {
+	UserID     int64     `gorm:"primaryKey"`
+	QuestionID int64     `gorm:"primaryKey"`
+	ResolvedAt time.Time `gorm:"not null"`
+}
+
+// NotebookPracticeQuestion 是由错题本生成的练习会话中的题目
+type NotebookPracticeQuestion struct {
+	SessionID  string `gorm:"primaryKey;size:64"`
+	Position   int    `gorm:"primaryKey"` // 生成时固定的题序，从1开始
+	QuestionID int64  `gorm:"not null"`
+}
+
+// ================= 班级作业 =================
+type Assignment struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement"`
+	ClassID     int64     `gorm:"not null;index"`
+	CreatedBy   *int64    // 布置作业的教师，用户被删除后置空
+	Title       string    `gorm:"size:200;not null"`
+	Description string    `gorm:"type:text"`
+	OpenAt      time.Time `gorm:"not null"`
+	DueAt       time.Time `gorm:"not null"`
+	MaxAttempts int       `gorm:"not null;default:1"`
+	ShowAnswers bool      `gorm:"default:false"` // 提交后是否向学生展示答案与解析
+	AllowLate   bool      `gorm:"default:false"` // 截止后是否仍允许作答并迟交
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+type AssignmentQuestion struct {
+	AssignmentID int64 `gorm:"primaryKey"`
+	QuestionID   int64 `gorm:"primaryKey;index"`
+	SortOrder    int   `gorm:"not null;default:0"`
+	Points       int   `gorm:"not null;default:1"`
+}
+
+type AssignmentAttempt struct {
+	ID            int64      `gorm:"primaryKey;autoIncrement"`
+	AssignmentID  int64      `gorm:"not null;index"`
+	UserID        int64      `gorm:"not null;index"`
+	AttemptNo     int        `gorm:"not null"`
+	SessionID     string     `gorm:"size:64;uniqueIndex;not null"` // 当前作答所用的学习会话
+	SessionIDs    StringList `gorm:"type:jsonb"`                   // 本次作答用过的全部学习会话
+	Status        string     `gorm:"type:assignment_attempt_status;default:'in_progress'"`
+	StartedAt     time.Time  `gorm:"not null"`
+	SubmittedAt   *time.Time
+	IsLate        bool      `gorm:"default:false"`
+	AnsweredCount int       `gorm:"default:0"`
+	CorrectCount  int       `gorm:"default:0"`
+	Score         float64   `gorm:"type:decimal(5,2);default:0.00"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
+}
+
+type ExamSession struct {
+	SessionID        string    `gorm:"primaryKey;size:64"`
+	UserID           int64     `gorm:"not null;index"`
+	TimeLimitSeconds int       `gorm:"not null"`
+	StartedAt        time.Time `gorm:"not null"`
+	DeadlineAt       time.Time `gorm:"not null"` // 服务端计算的交卷截止时间
+	ShuffleSeed      *int64    // 打乱题序所用的随机种子，未打乱时为空
+	Status           string    `gorm:"type:exam_status;default:'in_progress'"`
+	SubmittedAt      *time.Time
+	QuestionCount    int       `gorm:"default:0"`
+	AnsweredCount    int       `gorm:"default:0"`
 	CorrectCount     int       `gorm:"default:0"`
-	AvgDifficulty    float64   `gorm:"type:decimal(3,2);default:0.00"`
-	SessionType      string    `gorm:"type:session_type;default:'practice'"`
-	CompletionStatus string    `gorm:"type:completion_status;default:'ongoing'"`
+	Score            float64   `gorm:"type:decimal(5,2);default:0.00"`
 	CreatedAt        time.Time `gorm:"autoCreateTime"`
 	UpdatedAt        time.Time `gorm:"autoUpdateTime"`
 }
 
+type ExamQuestion struct {
+	SessionID  string `gorm:"primaryKey;size:64"`
+	Position   int    `gorm:"primaryKey"` // 开考时固定的题序，从1开始
+	QuestionID int64  `gorm:"not null;index"`
+}
+
 // ================= AI系统相关表 =================
 type DifficultyAdjustment struct {
 	ID                int64     `gorm:"primaryKey;autoIncrement"`
@@ -181,6 +402,7 @@ type Reward struct {
 	Description string    `gorm:"type:text"`
 	RewardType  string    `gorm:"type:reward_type;default:'points'"`
 	Value       int       `gorm:"default:0"`
+	Criteria    JSONB     `gorm:"type:jsonb"` // 徽章的获得条件，如 {"all": [{"stat": "max_streak_days", "op": ">=", "value": 30}]}，为空时不自动发放
 	CreatedAt   time.Time `gorm:"autoCreateTime"`
 }
 
@@ -189,19 +411,24 @@ type Task struct {
 	Name        string `gorm:"size:100;not null"`
 	Description string `gorm:"type:text"`
 	Type        string `gorm:"type:task_type;default:'daily'"`
+	Goal        JSONB  `gorm:"type:jsonb;not null;default:'{}'"` // 任务目标，如 {"metric": "answers", "target": 5}
 	RewardID    *int64
 	IsActive    bool      `gorm:"default:true"`
 	CreatedAt   time.Time `gorm:"autoCreateTime"`
 }
 
+// UserTask 是用户在某个任务周期内的进度，每日/每周任务每个周期一条记录
 type UserTask struct {
-	ID          int64  `gorm:"primaryKey;autoIncrement"`
-	UserID      int64  `gorm:"not null;index"`
-	TaskID      int64  `gorm:"not null;index"`
-	Status      string `gorm:"type:task_status;default:'pending'"`
-	Progress    int    `gorm:"default:0"`
+	ID          int64     `gorm:"primaryKey;autoIncrement"`
+	UserID      int64     `gorm:"not null;index"`
+	TaskID      int64     `gorm:"not null;index"`
+	PeriodStart time.Time `gorm:"type:date;not null"` // 周期开始日期 (服务器时区)，成就任务固定为 1970-01-01
+	Status      string    `gorm:"type:task_status;default:'pending'"`
+	Progress    int       `gorm:"default:0"`
 	CompletedAt *time.Time
+	ClaimedAt   *time.Time
 	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
 }
 
 type UserReward struct {
@@ -210,3 +437,29 @@ type UserReward struct {
 	RewardID   int64     `gorm:"not null;index"`
 	ObtainedAt time.Time `gorm:"autoCreateTime"`
 }
+
+// Notification 是推送给用户的站内通知，如获得徽章
+type Notification struct {
+	ID        int64  `gorm:"primaryKey;autoIncrement"`
+	UserID    int64  `gorm:"not null;index"`
+	Type      string `gorm:"size:50;not null"` // 如 badge_earned、level_up
+	Title     string `gorm:"size:200;not null"`
+	Content   string `gorm:"type:text"`
+	Data      JSONB  `gorm:"type:jsonb;default:'{}'"`
+	ReadAt    *time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// PointsTransaction 是积分账本中的一条流水，只追加不修改
+// 余额由流水推导，BalanceAfter 为写入该流水后的余额
+type PointsTransaction struct {
+	ID           int64     `gorm:"primaryKey;autoIncrement"`
+	UserID       int64     `gorm:"not null;index"`
+	Amount       int       `gorm:"not null"` // 正数为入账，负数为扣减
+	BalanceAfter int       `gorm:"not null"`
+	Reason       string    `gorm:"type:points_reason;not null"`
+	Reference    *string   `gorm:"size:100"` // 业务来源标识 (如 user_task:42)，同一用户同一原因下唯一，用于防止重复入账
+	Note         string    `gorm:"type:text"`
+	OperatorID   *int64    // 管理员发放时的操作人
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+}
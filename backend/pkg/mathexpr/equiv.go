@@ -0,0 +1,226 @@
+/*
+File: equiv.go
+Author: lxp
+Description: 数学表达式等价性判断
+*/
+package mathexpr
+
+import (
+	"math"
+	"math/big"
+	"sort"
+)
+
+// 数值比较时的默认相对误差
+const defaultNumericTolerance = 1e-9
+
+// 数值回退比较时为变量取值的样本点
+var samplePoints = []float64{0.731, 1.379, 2.113, 3.697, 5.281}
+
+// Options 定义等价性判断的选项，通常来自题目 Tags / Choices 中的 grading 字段
+type Options struct {
+	SimplestFraction bool    // 常数答案必须写成整数或最简分数 (不接受小数和未化简的算式)
+	Tolerance        float64 // 常数答案的绝对误差容忍度，0 表示精确比较
+	AcceptBareValue  bool    // 允许等式与其一侧的值互相匹配，如 "x=2" 与 "2"
+}
+
+// OptionsFromMap 从JSON配置中读取判分选项
+//
+//	{"simplest_fraction": true, "tolerance": 0.01, "accept_bare_value": true}
+func OptionsFromMap(m map[string]interface{}) Options {
+	var opts Options
+	if m == nil {
+		return opts
+	}
+	if v, ok := m["simplest_fraction"].(bool); ok {
+		opts.SimplestFraction = v
+	}
+	if v, ok := m["tolerance"].(float64); ok && v >= 0 {
+		opts.Tolerance = v
+	}
+	if v, ok := m["accept_bare_value"].(bool); ok {
+		opts.AcceptBareValue = v
+	}
+	return opts
+}
+
+// Equivalent 判断用户答案与标准答案在数学上是否等价
+// 答案无法解析时返回错误，调用方可据此回退到文本比较
+func Equivalent(answer, expected string, opts Options) (bool, error) {
+	a, err := Parse(answer)
+	if err != nil {
+		return false, err
+	}
+	e, err := Parse(expected)
+	if err != nil {
+		return false, err
+	}
+
+	if opts.SimplestFraction && !IsSimplest(a) {
+		return false, nil
+	}
+
+	switch {
+	case a.IsEquation() && e.IsEquation():
+		return equationsEquivalent(a, e), nil
+	case a.IsEquation() != e.IsEquation():
+		if !opts.AcceptBareValue {
+			return false, nil
+		}
+		eq, bare := a, e
+		if e.IsEquation() {
+			eq, bare = e, a
+		}
+		value := solvedValue(eq)
+		return value != nil && nodesEquivalent(value, bare.Left, opts.Tolerance), nil
+	default:
+		return nodesEquivalent(a.Left, e.Left, opts.Tolerance), nil
+	}
+}
+
+// solvedValue 若等式形如 "x = 值" 或 "值 = x"，返回值所在的一侧
+func solvedValue(eq *Expr) *Node {
+	if eq.Left.Op == OpVar && len(Variables(eq.Right)) == 0 {
+		return eq.Right
+	}
+	if eq.Right.Op == OpVar && len(Variables(eq.Left)) == 0 {
+		return eq.Left
+	}
+	return nil
+}
+
+// nodesEquivalent 判断两个表达式是否恒等
+// 优先使用精确的多项式规范形式，无法化为多项式时退化为多点数值比较
+func nodesEquivalent(x, y *Node, tolerance float64) bool {
+	px, ok1 := ToPoly(x)
+	py, ok2 := ToPoly(y)
+	if ok1 && ok2 {
+		cx, isConstX := px.Constant()
+		cy, isConstY := py.Constant()
+		if isConstX && isConstY && tolerance > 0 {
+			diff, _ := new(big.Rat).Sub(cx, cy).Float64()
+			return math.Abs(diff) <= tolerance
+		}
+		return px.Equal(py)
+	}
+
+	tol := math.Max(tolerance, defaultNumericTolerance)
+	valid := 0
+	for _, env := range sampleEnvs(Variables(x, y)) {
+		vx, vy := Eval(x, env), Eval(y, env)
+		if !isFinite(vx) || !isFinite(vy) {
+			if isFinite(vx) != isFinite(vy) {
+				return false
+			}
+			continue
+		}
+		if math.Abs(vx-vy) > tol*math.Max(1, math.Max(math.Abs(vx), math.Abs(vy))) {
+			return false
+		}
+		valid++
+	}
+	return valid > 0
+}
+
+// equationsEquivalent 判断两个等式是否等价：移项后两侧之差互为非零常数倍
+// 如 "x=2"、"2=x"、"2x=4" 互相等价
+func equationsEquivalent(a, e *Expr) bool {
+	da := &Node{Op: OpSub, Args: []*Node{a.Left, a.Right}}
+	de := &Node{Op: OpSub, Args: []*Node{e.Left, e.Right}}
+
+	pa, ok1 := ToPoly(da)
+	pe, ok2 := ToPoly(de)
+	if ok1 && ok2 {
+		if pe.IsZero() || pa.IsZero() {
+			return pe.IsZero() && pa.IsZero()
+		}
+		keys := make([]string, 0, len(pe))
+		for k := range pe {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		coef, ok := pa[keys[0]]
+		if !ok {
+			return false
+		}
+		ratio := new(big.Rat).Quo(coef, pe[keys[0]])
+		return pa.Equal(pe.scale(ratio))
+	}
+
+	// 数值回退：两侧之差的比值在所有样本点上保持为同一个非零常数
+	var ratio float64
+	valid := 0
+	for _, env := range sampleEnvs(Variables(da, de)) {
+		va, ve := Eval(da, env), Eval(de, env)
+		if !isFinite(va) || !isFinite(ve) || math.Abs(ve) < defaultNumericTolerance {
+			continue
+		}
+		r := va / ve
+		if valid == 0 {
+			if math.Abs(r) < defaultNumericTolerance {
+				return false
+			}
+			ratio = r
+		} else if math.Abs(r-ratio) > defaultNumericTolerance*math.Max(1, math.Abs(ratio)) {
+			return false
+		}
+		valid++
+	}
+	return valid > 0
+}
+
+// sampleEnvs 为变量生成确定性的样本取值，不同变量使用不同偏移以避免对称抵消
+func sampleEnvs(vars []string) []map[string]float64 {
+	envs := make([]map[string]float64, 0, len(samplePoints))
+	for _, base := range samplePoints {
+		env := make(map[string]float64, len(vars))
+		for i, name := range vars {
+			env[name] = base + 0.417*float64(i)
+		}
+		envs = append(envs, env)
+	}
+	return envs
+}
+
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// IsSimplest 判断表达式中的常数部分是否已写成整数或最简分数
+// 等式的每一侧分别判断，含变量的一侧不做要求
+func IsSimplest(expr *Expr) bool {
+	for _, side := range []*Node{expr.Left, expr.Right} {
+		if side == nil || len(Variables(side)) > 0 {
+			continue
+		}
+		if !isSimplestNumeral(side) {
+			return false
+		}
+	}
+	return true
+}
+
+// isSimplestNumeral 判断常数是否为整数、负整数或分子分母互质的分数
+func isSimplestNumeral(n *Node) bool {
+	switch n.Op {
+	case OpNeg:
+		return isSimplestNumeral(n.Args[0])
+	case OpNum:
+		return !n.Decimal && n.Value.IsInt()
+	case OpDiv:
+		num, den := n.Args[0], n.Args[1]
+		if num.Op == OpNeg {
+			num = num.Args[0] // "-1/2" 解析为 (-1)/2
+		}
+		if num.Op != OpNum || den.Op != OpNum || num.Decimal || den.Decimal {
+			return false
+		}
+		if !num.Value.IsInt() || !den.Value.IsInt() || den.Value.Cmp(big.NewRat(1, 1)) <= 0 {
+			return false
+		}
+		gcd := new(big.Int).GCD(nil, nil, num.Value.Num(), den.Value.Num())
+		return gcd.Cmp(big.NewInt(1)) == 0
+	}
+	return false
+}
@@ -0,0 +1,233 @@
+/*
+File: equiv_test.go
+Author: lxp
+Description: 数学表达式解析与等价性判断的单元测试
+*/
+package mathexpr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEquivalent(t *testing.T) {
+	tests := []struct {
+		name     string
+		answer   string
+		expected string
+		opts     Options
+		want     bool
+	}{
+		// 等价形式
+		{"相同整数", "42", "42", Options{}, true},
+		{"分数与小数", "0.5", "1/2", Options{}, true},
+		{"百分数", "50%", "1/2", Options{}, true},
+		{"全角与中文运算符", "６÷３", "2", Options{}, true},
+		{"加法交换律", "b+a", "a+b", Options{}, true},
+		{"隐式乘法", "2x", "x*2", Options{}, true},
+		{"连写变量乘积", "xy", "y*x", Options{}, true},
+		{"展开平方", "x^2+2x+1", "(x+1)^2", Options{}, true},
+		{"上标", "x²", "x*x", Options{}, true},
+		{"负指数", "2^-2", "1/4", Options{}, true},
+		{"分数指数", "4^(1/2)", "2", Options{}, true},
+		{"精确开方", "sqrt(9/4)", "3/2", Options{}, true},
+		{"无理数数值比较", "sqrt(2)*sqrt(2)", "2", Options{}, true},
+		{"非多项式数值比较", "1/x+1/x", "2/x", Options{}, true},
+		{"等式两侧交换", "2=x", "x=2", Options{}, true},
+		{"等式倍数", "2x=4", "x=2", Options{}, true},
+		{"等式移项", "x+y=3", "y=3-x", Options{}, true},
+		{"裸值匹配等式", "2", "x=2", Options{AcceptBareValue: true}, true},
+		{"等式匹配裸值", "x=2", "2", Options{AcceptBareValue: true}, true},
+
+		// 不等价形式
+		{"不同整数", "41", "42", Options{}, false},
+		{"符号不同", "a-b", "b-a", Options{}, false},
+		{"平方未展开完整", "x^2+1", "(x+1)^2", Options{}, false},
+		{"变量不同", "x+1", "y+1", Options{}, false},
+		{"等式不等价", "x=3", "x=2", Options{}, false},
+		{"等式与零多项式", "x=x", "x=2", Options{}, false},
+		{"未允许裸值", "2", "x=2", Options{}, false},
+		{"裸值不符", "3", "x=2", Options{AcceptBareValue: true}, false},
+		{"最简分数要求", "2/4", "1/2", Options{SimplestFraction: true}, false},
+		{"最简分数要求拒绝小数", "0.5", "1/2", Options{SimplestFraction: true}, false},
+		{"最简分数", "1/2", "1/2", Options{SimplestFraction: true}, true},
+		{"除以零", "1/0", "1", Options{}, false},
+
+		// 误差容忍度
+		{"容忍度内", "3.14", "3.14159", Options{Tolerance: 0.01}, true},
+		{"容忍度外", "3.1", "3.14159", Options{Tolerance: 0.01}, false},
+		{"无容忍度精确比较", "3.14", "3.14159", Options{}, false},
+		{"数值回退遵循容忍度", "sqrt(2)", "1.414", Options{Tolerance: 0.001}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Equivalent(tt.answer, tt.expected, tt.opts)
+			if err != nil {
+				t.Fatalf("Equivalent(%q, %q) error: %v", tt.answer, tt.expected, err)
+			}
+			if got != tt.want {
+				t.Errorf("Equivalent(%q, %q) = %v, want %v", tt.answer, tt.expected, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEquivalentParseErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		answer   string
+		expected string
+		wantErr  error
+	}{
+		{"空答案", "  ", "1", ErrEmptyExpression},
+		{"中文文本", "三角形", "1", ErrSyntax},
+		{"括号不匹配", "(1+2", "3", ErrSyntax},
+		{"多余的右括号", "1+2)", "3", ErrSyntax},
+		{"多个小数点", "1.2.3", "1", ErrSyntax},
+		{"运算符结尾", "1+", "1", ErrSyntax},
+		{"标准答案无法解析", "1", "1+*2", ErrSyntax},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Equivalent(tt.answer, tt.expected, Options{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Equivalent(%q, %q) error = %v, want %v", tt.answer, tt.expected, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLooksLikeMath(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"42", true},
+		{"x", true},
+		{"X", true},
+		{"x+1", true},
+		{"2x", true},
+		{"sqrt(2)", true},
+		{"x=2", true},
+		{"-y", true},
+		{"no", false},
+		{"on", false},
+		{"cm", false},
+		{"stop", false},
+		{"(ab)", false},
+		{"苹果", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := LooksLikeMath(tt.input); got != tt.want {
+			t.Errorf("LooksLikeMath(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+// 纯字母单词被解析为变量乘积时互为变位词即"等价"，判分前需要用 LooksLikeMath 排除
+func TestAnagramWordsAreNotMath(t *testing.T) {
+	for _, pair := range [][2]string{{"no", "on"}, {"cm", "mc"}, {"stop", "pots"}} {
+		if LooksLikeMath(pair[1]) {
+			t.Errorf("LooksLikeMath(%q) = true, plain words must be compared as text", pair[1])
+		}
+	}
+}
+
+func TestIsSimplest(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"3", true},
+		{"-3", true},
+		{"1/2", true},
+		{"-1/2", true},
+		{"2/4", false},
+		{"3/1", false},
+		{"0.5", false},
+		{"1+1", false},
+		{"x/2+1", true},
+		{"x=1/2", true},
+		{"x=2/4", false},
+	}
+
+	for _, tt := range tests {
+		expr, err := Parse(tt.input)
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", tt.input, err)
+		}
+		if got := IsSimplest(expr); got != tt.want {
+			t.Errorf("IsSimplest(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestPathologicalInput(t *testing.T) {
+	tests := []struct {
+		name     string
+		answer   string
+		expected string
+		want     bool
+		wantErr  error
+	}{
+		{"嵌套幂展开", "((a+b+c+d+e)^12)^12", "(a+b+c+d+e)^144", true, nil},
+		{"多层嵌套幂", "((((x+y)^12)^12)^12)^12", "1", false, nil},
+		{"常数多层嵌套幂", "(((2^12)^12)^12)^12", "2", false, nil},
+		{"超大指数字面量", "x^99999999999999999999999", "x", false, nil},
+		{"超大负指数字面量", "2^-99999999999999999999999", "1", false, nil},
+		{"超长数字", strings.Repeat("9", 900), "1", false, nil},
+		{"重复乘积", strings.Repeat("(a+b+c+d)*", 40) + "1", "1", false, nil},
+		{"括号嵌套在限制内", strings.Repeat("(", 40) + "1" + strings.Repeat(")", 40), "1", true, nil},
+		{"括号嵌套过深", strings.Repeat("(", 400) + "1" + strings.Repeat(")", 400), "1", false, ErrSyntax},
+		{"负号嵌套过深", strings.Repeat("-", 400) + "1", "1", false, ErrSyntax},
+		{"函数嵌套过深", strings.Repeat("sqrt", 200) + "1", "1", false, ErrSyntax},
+		{"超长输入", strings.Repeat("(", 8000000), "1", false, ErrSyntax},
+		{"超长数字字面量", strings.Repeat("9", 5000), "1", false, ErrSyntax},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			got, err := Equivalent(tt.answer, tt.expected, Options{})
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Fatalf("Equivalent took %v", elapsed)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Equivalent error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Equivalent = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToPolyLimits(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"(a+b)^12", true},
+		{"(a+b)^13", false},
+		{"(x^12)^5*x^4", true},
+		{"(x^12)^5*x^5", false},
+		{"((a+b+c+d+e)^12)^12", false},
+		{"x^99999999999999999999999", false},
+	}
+
+	for _, tt := range tests {
+		expr, err := Parse(tt.input)
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", tt.input, err)
+		}
+		if _, ok := ToPoly(expr.Left); ok != tt.want {
+			t.Errorf("ToPoly(%q) ok = %v, want %v", tt.input, ok, tt.want)
+		}
+	}
+}
@@ -0,0 +1,415 @@
+/*
+File: parser.go
+Author: lxp
+Description: 数学表达式词法与语法解析
+*/
+package mathexpr
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	ErrEmptyExpression = errors.New("empty expression")
+	ErrSyntax          = errors.New("invalid expression syntax")
+)
+
+// Op 表示语法树节点的运算类型
+type Op int
+
+const (
+	OpNum Op = iota
+	OpVar
+	OpAdd
+	OpSub
+	OpMul
+	OpDiv
+	OpPow
+	OpNeg
+	OpSqrt
+)
+
+// Node 是表达式语法树节点
+type Node struct {
+	Op      Op
+	Value   *big.Rat // 仅 OpNum 有效
+	Decimal bool     // OpNum 是否以小数或百分数形式书写
+	Name    string   // 仅 OpVar 有效
+	Args    []*Node
+}
+
+// Expr 是解析后的表达式，Right 非空时表示一个等式 Left = Right
+type Expr struct {
+	Left  *Node
+	Right *Node
+}
+
+// IsEquation 判断表达式是否为等式
+func (e *Expr) IsEquation() bool {
+	return e.Right != nil
+}
+
+// ================== 词法分析 ==================
+
+type tokenKind int
+
+const (
+	tokNum tokenKind = iota
+	tokVar
+	tokFunc
+	tokOp
+	tokLParen
+	tokRParen
+	tokEOF
+)
+
+type token struct {
+	kind    tokenKind
+	text    string
+	value   *big.Rat
+	decimal bool
+}
+
+// 支持的函数名
+var functions = map[string]bool{"sqrt": true}
+
+// normalizeInput 统一全角字符、中文运算符以及上标
+func normalizeInput(s string) string {
+	replacer := strings.NewReplacer(
+		"×", "*", "·", "*", "÷", "/", "－", "-", "＋", "+", "＝", "=",
+		"（", "(", "）", ")", "％", "%", "²", "^2", "³", "^3", "√", "sqrt",
+		"−", "-", "＊", "*", "／", "/", "＾", "^",
+	)
+	s = replacer.Replace(s)
+
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '０' && r <= '９' {
+			r = r - '０' + '0'
+		} else if r == '．' {
+			r = '.'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// tokenize 将表达式文本切分为词法单元
+func tokenize(input string) ([]token, error) {
+	runes := []rune(normalizeInput(input))
+	var tokens []token
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			text := string(runes[start:i])
+			value, ok := new(big.Rat).SetString(text)
+			if !ok || strings.Count(text, ".") > 1 {
+				return nil, fmt.Errorf("%w: bad number %q", ErrSyntax, text)
+			}
+			tokens = append(tokens, token{kind: tokNum, text: text, value: value, decimal: strings.Contains(text, ".")})
+		case isVarLetter(r):
+			start := i
+			for i < len(runes) && isVarLetter(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, tokenizeWord(strings.ToLower(string(runes[start:i])))...)
+		case strings.ContainsRune("+-*/^=%", r):
+			tokens = append(tokens, token{kind: tokOp, text: string(r)})
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")"})
+			i++
+		default:
+			return nil, fmt.Errorf("%w: unexpected character %q", ErrSyntax, r)
+		}
+	}
+
+	return append(tokens, token{kind: tokEOF}), nil
+}
+
+// isVarLetter 判断字符能否作为变量名或函数名的一部分
+// 仅接受ASCII字母，中文等文字会被视为语法错误，由调用方回退到文本比较
+func isVarLetter(r rune) bool {
+	return r < unicode.MaxASCII && unicode.IsLetter(r)
+}
+
+// tokenizeWord 切分连续字母：以函数名开头的部分识别为函数 (如 sqrtx = sqrt x)，
+// 其余连写的字母视为多个单字母变量的乘积 (如 xy = x*y)
+func tokenizeWord(word string) []token {
+	var tokens []token
+	for word != "" {
+		matched := false
+		for name := range functions {
+			if strings.HasPrefix(word, name) {
+				tokens = append(tokens, token{kind: tokFunc, text: name})
+				word = word[len(name):]
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		letter, size := utf8.DecodeRuneInString(word)
+		tokens = append(tokens, token{kind: tokVar, text: string(letter)})
+		word = word[size:]
+	}
+	return tokens
+}
+
+// LooksLikeMath 判断文本是否像数学答案：含数字、运算符或函数，或仅为单个字母变量
+// 连写的字母在解析时会被视为变量乘积 (如 "no" = n*o)，纯字母单词应按文本比较，否则 "no" 与 "on" 会被判为等价
+func LooksLikeMath(input string) bool {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return false
+	}
+	vars := 0
+	for _, t := range tokens {
+		switch t.kind {
+		case tokNum, tokOp, tokFunc:
+			return true
+		case tokVar:
+			vars++
+		}
+	}
+	return vars == 1
+}
+
+// ================== 语法分析 ==================
+
+// 解析限制，防止超长或嵌套过深的输入耗尽栈空间与CPU
+const (
+	maxInputLength = 1000 // 表达式的最大字符数
+	maxNestDepth   = 100  // 括号、一元运算符与函数的最大嵌套层数
+)
+
+// parser 是递归下降解析器
+//
+//	equation := expr ('=' expr)?
+//	expr     := term (('+' | '-') term)*
+//	term     := unary (('*' | '/' | 隐式乘法) unary)*
+//	unary    := ('+' | '-') unary | power
+//	power    := postfix ('^' unary)?
+//	postfix  := primary '%'*
+//	primary  := number | variable | func primary | '(' expr ')'
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int // 当前嵌套层数
+}
+
+// Parse 解析表达式或等式
+func Parse(input string) (*Expr, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, ErrEmptyExpression
+	}
+	if utf8.RuneCountInString(input) > maxInputLength {
+		return nil, fmt.Errorf("%w: expression too long", ErrSyntax)
+	}
+
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	left, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	expr := &Expr{Left: left}
+	if p.peekOp("=") {
+		p.pos++
+		if expr.Right, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.peek().kind != tokEOF {
+		return nil, fmt.Errorf("%w: unexpected token %q", ErrSyntax, p.peek().text)
+	}
+	return expr, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) peekOp(op string) bool {
+	t := p.peek()
+	return t.kind == tokOp && t.text == op
+}
+
+func (p *parser) parseExpr() (*Node, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOp("+") || p.peekOp("-") {
+		op := OpAdd
+		if p.peek().text == "-" {
+			op = OpSub
+		}
+		p.pos++
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = &Node{Op: op, Args: []*Node{left, right}}
+	}
+	return left, nil
+}
+
+// startsPrimary 判断当前词法单元能否开始一个隐式乘法的因子
+func (p *parser) startsPrimary() bool {
+	switch p.peek().kind {
+	case tokNum, tokVar, tokFunc, tokLParen:
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseTerm() (*Node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var op Op
+		switch {
+		case p.peekOp("*"):
+			op = OpMul
+			p.pos++
+		case p.peekOp("/"):
+			op = OpDiv
+			p.pos++
+		case p.startsPrimary():
+			op = OpMul
+		default:
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &Node{Op: op, Args: []*Node{left, right}}
+	}
+}
+
+// enter 进入一层嵌套，超过 maxNestDepth 时返回错误，调用方需在返回时 defer p.leave()
+func (p *parser) enter() error {
+	p.depth++
+	if p.depth > maxNestDepth {
+		return fmt.Errorf("%w: expression nested too deeply", ErrSyntax)
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) parseUnary() (*Node, error) {
+	defer p.leave()
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	if p.peekOp("-") || p.peekOp("+") {
+		negative := p.peek().text == "-"
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if negative {
+			return &Node{Op: OpNeg, Args: []*Node{operand}}, nil
+		}
+		return operand, nil
+	}
+	return p.parsePower()
+}
+
+func (p *parser) parsePower() (*Node, error) {
+	base, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+	if p.peekOp("^") {
+		p.pos++
+		exponent, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Node{Op: OpPow, Args: []*Node{base, exponent}}, nil
+	}
+	return base, nil
+}
+
+func (p *parser) parsePostfix() (*Node, error) {
+	node, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOp("%") {
+		p.pos++
+		if node.Op == OpNum {
+			node = &Node{Op: OpNum, Value: new(big.Rat).Quo(node.Value, big.NewRat(100, 1)), Decimal: true}
+			continue
+		}
+		node = &Node{Op: OpDiv, Args: []*Node{node, {Op: OpNum, Value: big.NewRat(100, 1)}}}
+	}
+	return node, nil
+}
+
+func (p *parser) parsePrimary() (*Node, error) {
+	defer p.leave()
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	switch t.kind {
+	case tokNum:
+		p.pos++
+		return &Node{Op: OpNum, Value: t.value, Decimal: t.decimal}, nil
+	case tokVar:
+		p.pos++
+		return &Node{Op: OpVar, Name: t.text}, nil
+	case tokFunc:
+		p.pos++
+		arg, err := p.parsePostfix()
+		if err != nil {
+			return nil, err
+		}
+		return &Node{Op: OpSqrt, Args: []*Node{arg}}, nil
+	case tokLParen:
+		p.pos++
+		inner, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokRParen {
+			return nil, fmt.Errorf("%w: missing ')'", ErrSyntax)
+		}
+		p.pos++
+		return inner, nil
+	}
+	return nil, fmt.Errorf("%w: unexpected token %q", ErrSyntax, t.text)
+}
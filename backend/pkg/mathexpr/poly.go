@@ -0,0 +1,368 @@
+/*
+File: poly.go
+Author: lxp
+Description: 有理系数多项式规范形式与数值求值
+*/
+package mathexpr
+
+import (
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 多项式展开的限制，超过任一限制时退化为数值比较，避免用户输入 (如 ((a+b+c)^12)^12) 耗尽CPU或内存
+const (
+	maxExpandExponent  = 12    // 单次幂运算允许的最大整数指数
+	maxExpandDegree    = 64    // 单项式允许的最大总次数
+	maxExpandWork      = 50000 // 整个表达式展开过程中单项式乘法的总次数
+	maxCoefficientBits = 2048  // 系数分子与分母的总位数
+)
+
+// monomial 表示单项式中各变量的指数，如 {"x": 2, "y": 1}
+type monomial map[string]int
+
+// key 返回单项式的规范字符串表示，如 "x^2*y"，常数项为空串
+func (m monomial) key() string {
+	names := make([]string, 0, len(m))
+	for name, exp := range m {
+		if exp != 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		if m[name] == 1 {
+			parts = append(parts, name)
+		} else {
+			parts = append(parts, name+"^"+strconv.Itoa(m[name]))
+		}
+	}
+	return strings.Join(parts, "*")
+}
+
+// parseMonomialKey 将规范字符串还原为单项式
+func parseMonomialKey(key string) monomial {
+	m := monomial{}
+	if key == "" {
+		return m
+	}
+	for _, part := range strings.Split(key, "*") {
+		name, expText, found := strings.Cut(part, "^")
+		exp := 1
+		if found {
+			exp, _ = strconv.Atoi(expText)
+		}
+		m[name] = exp
+	}
+	return m
+}
+
+// Poly 是以单项式规范字符串为键的有理系数多项式
+type Poly map[string]*big.Rat
+
+// constPoly 构造常数多项式
+func constPoly(v *big.Rat) Poly {
+	p := Poly{}
+	if v.Sign() != 0 {
+		p[""] = new(big.Rat).Set(v)
+	}
+	return p
+}
+
+// IsZero 判断多项式是否为零
+func (p Poly) IsZero() bool {
+	return len(p) == 0
+}
+
+// Constant 判断多项式是否为常数，并返回其值
+func (p Poly) Constant() (*big.Rat, bool) {
+	switch len(p) {
+	case 0:
+		return new(big.Rat), true
+	case 1:
+		if v, ok := p[""]; ok {
+			return new(big.Rat).Set(v), true
+		}
+	}
+	return nil, false
+}
+
+func (p Poly) add(q Poly, sign int) Poly {
+	result := Poly{}
+	for k, v := range p {
+		result[k] = new(big.Rat).Set(v)
+	}
+	for k, v := range q {
+		term := new(big.Rat).Set(v)
+		if sign < 0 {
+			term.Neg(term)
+		}
+		if cur, ok := result[k]; ok {
+			cur.Add(cur, term)
+			if cur.Sign() == 0 {
+				delete(result, k)
+			}
+		} else {
+			result[k] = term
+		}
+	}
+	return result
+}
+
+func (p Poly) scale(k *big.Rat) Poly {
+	result := Poly{}
+	if k.Sign() == 0 {
+		return result
+	}
+	for key, v := range p {
+		result[key] = new(big.Rat).Mul(v, k)
+	}
+	return result
+}
+
+// Equal 判断两个多项式是否完全相同
+func (p Poly) Equal(q Poly) bool {
+	return p.add(q, -1).IsZero()
+}
+
+// ToPoly 尝试将语法树化为有理系数多项式
+// 遇到非常数除数、无理根式、非整数指数或超出展开限制时返回 false
+func ToPoly(n *Node) (Poly, bool) {
+	x := &expander{}
+	return x.toPoly(n)
+}
+
+// expander 记录一次展开累计的工作量，限制作用于整个表达式而非单个运算
+type expander struct {
+	work int
+}
+
+func (x *expander) toPoly(n *Node) (Poly, bool) {
+	switch n.Op {
+	case OpNum:
+		if ratBits(n.Value) > maxCoefficientBits {
+			return nil, false
+		}
+		return constPoly(n.Value), true
+	case OpVar:
+		return Poly{n.Name: big.NewRat(1, 1)}, true
+	case OpNeg:
+		inner, ok := x.toPoly(n.Args[0])
+		if !ok {
+			return nil, false
+		}
+		return inner.scale(big.NewRat(-1, 1)), true
+	case OpAdd, OpSub, OpMul, OpDiv:
+		left, ok1 := x.toPoly(n.Args[0])
+		if !ok1 {
+			return nil, false
+		}
+		right, ok2 := x.toPoly(n.Args[1])
+		if !ok2 {
+			return nil, false
+		}
+		switch n.Op {
+		case OpAdd:
+			return left.add(right, 1), true
+		case OpSub:
+			return left.add(right, -1), true
+		case OpMul:
+			return x.mul(left, right)
+		default:
+			divisor, isConst := right.Constant()
+			if !isConst || divisor.Sign() == 0 {
+				return nil, false
+			}
+			return x.mul(left, constPoly(new(big.Rat).Inv(divisor)))
+		}
+	case OpPow:
+		return x.pow(n)
+	case OpSqrt:
+		inner, ok := x.toPoly(n.Args[0])
+		if !ok {
+			return nil, false
+		}
+		v, isConst := inner.Constant()
+		if !isConst {
+			return nil, false
+		}
+		root, exact := ratSqrt(v)
+		if !exact {
+			return nil, false
+		}
+		return constPoly(root), true
+	}
+	return nil, false
+}
+
+// mul 计算两个多项式的乘积，累计工作量、单项式次数或系数位数超出限制时返回 false
+func (x *expander) mul(p, q Poly) (Poly, bool) {
+	x.work += len(p) * len(q)
+	if x.work > maxExpandWork {
+		return nil, false
+	}
+
+	result := Poly{}
+	for k1, v1 := range p {
+		m1 := parseMonomialKey(k1)
+		for k2, v2 := range q {
+			if ratBits(v1)+ratBits(v2) > maxCoefficientBits {
+				return nil, false
+			}
+			m := monomial{}
+			degree := 0
+			for name, exp := range m1 {
+				m[name] += exp
+				degree += exp
+			}
+			for name, exp := range parseMonomialKey(k2) {
+				m[name] += exp
+				degree += exp
+			}
+			if degree > maxExpandDegree {
+				return nil, false
+			}
+
+			coef := new(big.Rat).Mul(v1, v2)
+			key := m.key()
+			if cur, ok := result[key]; ok {
+				cur.Add(cur, coef)
+			} else {
+				result[key] = coef
+			}
+		}
+	}
+	for k, v := range result {
+		if v.Sign() == 0 {
+			delete(result, k)
+		}
+	}
+	return result, true
+}
+
+// pow 展开整数次幂，负指数仅支持常数底数
+func (x *expander) pow(n *Node) (Poly, bool) {
+	base, ok := x.toPoly(n.Args[0])
+	if !ok {
+		return nil, false
+	}
+	expPoly, ok := x.toPoly(n.Args[1])
+	if !ok {
+		return nil, false
+	}
+	exp, isConst := expPoly.Constant()
+	if !isConst || !exp.IsInt() {
+		// 分数指数：仅处理常数底数的精确开方，如 4^(1/2)
+		if isConst && exp.Denom().Cmp(big.NewInt(2)) == 0 {
+			if b, ok := base.Constant(); ok {
+				if root, exact := ratSqrt(b); exact {
+					return x.pow(&Node{Op: OpPow, Args: []*Node{
+						{Op: OpNum, Value: root},
+						{Op: OpNum, Value: new(big.Rat).SetInt(exp.Num())},
+					}})
+				}
+			}
+		}
+		return nil, false
+	}
+	if !exp.Num().IsInt64() {
+		return nil, false
+	}
+
+	e := exp.Num().Int64()
+	if e < 0 {
+		b, isConstBase := base.Constant()
+		if !isConstBase || b.Sign() == 0 || -e > maxExpandExponent {
+			return nil, false
+		}
+		base = constPoly(new(big.Rat).Inv(b))
+		e = -e
+	}
+	if e > maxExpandExponent {
+		return nil, false
+	}
+
+	result := constPoly(big.NewRat(1, 1))
+	for i := int64(0); i < e; i++ {
+		if result, ok = x.mul(result, base); !ok {
+			return nil, false
+		}
+	}
+	return result, true
+}
+
+// ratBits 返回有理数分子与分母的总位数
+func ratBits(v *big.Rat) int {
+	return v.Num().BitLen() + v.Denom().BitLen()
+}
+
+// ratSqrt 计算有理数的精确平方根，分子分母均为完全平方数时才有精确结果
+func ratSqrt(v *big.Rat) (*big.Rat, bool) {
+	if v.Sign() < 0 {
+		return nil, false
+	}
+	num := new(big.Int).Sqrt(v.Num())
+	den := new(big.Int).Sqrt(v.Denom())
+	if new(big.Int).Mul(num, num).Cmp(v.Num()) != 0 || new(big.Int).Mul(den, den).Cmp(v.Denom()) != 0 {
+		return nil, false
+	}
+	return new(big.Rat).SetFrac(num, den), true
+}
+
+// Eval 在给定变量取值下对语法树进行浮点求值
+func Eval(n *Node, env map[string]float64) float64 {
+	switch n.Op {
+	case OpNum:
+		f, _ := n.Value.Float64()
+		return f
+	case OpVar:
+		return env[n.Name]
+	case OpNeg:
+		return -Eval(n.Args[0], env)
+	case OpAdd:
+		return Eval(n.Args[0], env) + Eval(n.Args[1], env)
+	case OpSub:
+		return Eval(n.Args[0], env) - Eval(n.Args[1], env)
+	case OpMul:
+		return Eval(n.Args[0], env) * Eval(n.Args[1], env)
+	case OpDiv:
+		return Eval(n.Args[0], env) / Eval(n.Args[1], env)
+	case OpPow:
+		return math.Pow(Eval(n.Args[0], env), Eval(n.Args[1], env))
+	case OpSqrt:
+		return math.Sqrt(Eval(n.Args[0], env))
+	}
+	return math.NaN()
+}
+
+// Variables 收集语法树中出现的所有变量名
+func Variables(nodes ...*Node) []string {
+	seen := map[string]bool{}
+	var walk func(*Node)
+	walk = func(n *Node) {
+		if n == nil {
+			return
+		}
+		if n.Op == OpVar {
+			seen[n.Name] = true
+		}
+		for _, arg := range n.Args {
+			walk(arg)
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -158,6 +158,21 @@ function manage_answer_partitions() {
     print_status "answer_records 分区表自动管理完成"
 }
 
+# 按文件名顺序执行 database/migrations 下的增量迁移脚本
+# 迁移脚本均为幂等写法 (IF NOT EXISTS)，可重复执行
+function apply_migrations() {
+    print_info "执行数据库增量迁移..."
+    for migration in ./database/migrations/*.sql; do
+        [ -f "$migration" ] || continue
+        print_info "应用迁移: $(basename "$migration")"
+        if ! psql -h $DB_HOST -p $DB_PORT -U $DB_USER -d $DB_NAME -v ON_ERROR_STOP=1 -f "$migration"; then
+            print_error "迁移失败: $(basename "$migration")"
+            return 1
+        fi
+    done
+    print_status "数据库增量迁移完成"
+}
+
 # 主函数
 function main() {
     echo "开始初始化智学奇境数据库..."
@@ -201,6 +216,7 @@ function show_help() {
     echo "  verify   验证数据库状态"
     echo "  clean    清理数据库"
     echo "  partition  自动管理 answer_records 分区表（建议配合cron定时执行）"
+    echo "  migrate  对已有数据库执行增量迁移 (database/migrations)"
     echo "  help     显示帮助信息"
 }
 
@@ -233,6 +249,9 @@ case "${1:-init}" in
     "partition")
         manage_answer_partitions
         ;;
+    "migrate")
+        apply_migrations
+        ;;
     "help")
         show_help
         ;;
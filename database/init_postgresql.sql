@@ -20,16 +20,21 @@ CREATE TYPE user_status AS ENUM ('active', 'inactive', 'banned');
 CREATE TYPE learning_style AS ENUM ('visual', 'auditory', 'kinesthetic', 'mixed');
 CREATE TYPE question_type AS ENUM ('single_choice', 'multiple_choice', 'fill_blank', 'calculation', 'proof');
 CREATE TYPE review_status AS ENUM ('draft', 'reviewing', 'approved', 'rejected');
+CREATE TYPE review_action AS ENUM ('submit', 'approve', 'reject', 'revise');
 CREATE TYPE answer_method AS ENUM ('direct', 'hint', 'guess');
-CREATE TYPE session_type AS ENUM ('practice', 'test', 'challenge');
-CREATE TYPE completion_status AS ENUM ('ongoing', 'completed', 'interrupted');
+CREATE TYPE session_type AS ENUM ('practice', 'test', 'challenge', 'homework');
+CREATE TYPE assignment_attempt_status AS ENUM ('in_progress', 'submitted');
+CREATE TYPE exam_status AS ENUM ('in_progress', 'submitted', 'expired');
+CREATE TYPE completion_status AS ENUM ('ongoing', 'paused', 'completed', 'interrupted');
 CREATE TYPE model_type AS ENUM ('difficulty_adjustment', 'recommendation', 'performance_prediction');
 CREATE TYPE trigger_event AS ENUM ('answer_correct', 'answer_wrong', 'time_based', 'manual');
 CREATE TYPE config_type AS ENUM ('string', 'integer', 'decimal', 'boolean', 'json');
-CREATE TYPE user_role AS ENUM ('user', 'admin', 'teacher');
+CREATE TYPE user_role AS ENUM ('user', 'admin', 'teacher', 'parent');
+CREATE TYPE auth_audit_event AS ENUM ('account_locked', 'ip_locked', 'account_unlocked');
 CREATE TYPE task_type AS ENUM ('daily', 'weekly', 'achievement');
 CREATE TYPE task_status AS ENUM ('pending', 'completed', 'claimed');
 CREATE TYPE reward_type AS ENUM ('points', 'item', 'badge');
+CREATE TYPE points_reason AS ENUM ('task_claim', 'streak_bonus', 'admin_grant', 'purchase');
 
 -- ============================================
 -- 1. 用户系统表
@@ -49,6 +54,9 @@ CREATE TABLE users (
     gender user_gender DEFAULT 'other',
     status user_status DEFAULT 'active',
     role user_role NOT NULL DEFAULT 'user',
+    email_verified BOOLEAN NOT NULL DEFAULT FALSE,
+    email_verified_at TIMESTAMP WITH TIME ZONE,
+    timezone VARCHAR(64) NOT NULL DEFAULT '', -- IANA时区名，为空时使用默认时区划分学习日
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     last_login_at TIMESTAMP WITH TIME ZONE
@@ -60,15 +68,30 @@ CREATE INDEX idx_users_status ON users(status);
 CREATE INDEX idx_users_created_at ON users(created_at);
 CREATE INDEX idx_users_role ON users(role);
 
+CREATE TABLE auth_audit_logs (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    username VARCHAR(50),
+    event auth_audit_event NOT NULL,
+    ip_address INET,
+    operator_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    detail JSONB DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_auth_audit_logs_user ON auth_audit_logs(user_id, created_at);
+CREATE INDEX idx_auth_audit_logs_created_at ON auth_audit_logs(created_at);
+
 CREATE TABLE user_profiles (
     id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
     user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     current_difficulty DECIMAL(3,2) DEFAULT 1.0 CHECK (current_difficulty BETWEEN 1.0 AND 5.0),
-    total_study_time INTEGER DEFAULT 0,
+    total_study_time INTEGER DEFAULT 0, -- 累计有效学习时长 (秒)
     total_questions INTEGER DEFAULT 0,
     correct_answers INTEGER DEFAULT 0,
     streak_days INTEGER DEFAULT 0,
     max_streak_days INTEGER DEFAULT 0,
+    last_active_date DATE, -- 最近一个学习日 (用户时区)
+    streak_freezes INTEGER DEFAULT 0 CHECK (streak_freezes >= 0), -- 持有的连续学习保护卡数
     level_score INTEGER DEFAULT 0,
     user_level INTEGER DEFAULT 1,
     learning_style learning_style DEFAULT 'mixed',
@@ -80,6 +103,17 @@ CREATE TABLE user_profiles (
 CREATE INDEX idx_user_profiles_difficulty ON user_profiles(current_difficulty);
 CREATE INDEX idx_user_profiles_level ON user_profiles(user_level);
 
+-- 每个学习日 (用户时区) 的学习活动，frozen 表示该日由连续学习保护卡保住了连续天数
+CREATE TABLE user_activity_days (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    activity_date DATE NOT NULL,
+    answers INTEGER DEFAULT 0,
+    study_seconds INTEGER DEFAULT 0,
+    xp INTEGER DEFAULT 0,
+    frozen BOOLEAN DEFAULT FALSE,
+    PRIMARY KEY (user_id, activity_date)
+);
+
 CREATE TABLE user_grade_history (
     id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
     user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
@@ -91,6 +125,44 @@ CREATE TABLE user_grade_history (
 );
 CREATE INDEX idx_user_grade_history_user_id ON user_grade_history(user_id);
 
+CREATE TABLE parent_child_links (
+    parent_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    child_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (parent_id, child_id)
+);
+CREATE INDEX idx_parent_child_links_child ON parent_child_links(child_id);
+
+CREATE TABLE parental_controls (
+    child_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    daily_limit_minutes INTEGER NOT NULL DEFAULT 0 CHECK (daily_limit_minutes BETWEEN 0 AND 1440),
+    allowed_session_types JSONB NOT NULL DEFAULT '["practice", "test", "challenge"]',
+    updated_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE classes (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    grade_level INTEGER NOT NULL CHECK (grade_level BETWEEN 1 AND 12),
+    teacher_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    join_code VARCHAR(8) NOT NULL UNIQUE,
+    is_archived BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_classes_teacher ON classes(teacher_id);
+
+CREATE TABLE class_members (
+    class_id BIGINT NOT NULL REFERENCES classes(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    joined_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (class_id, user_id)
+);
+CREATE INDEX idx_class_members_user ON class_members(user_id);
+
 -- ============================================
 -- 2. 数学题库系统表
 -- ============================================
@@ -113,6 +185,27 @@ CREATE INDEX idx_knowledge_points_parent ON knowledge_points(parent_id);
 CREATE INDEX idx_knowledge_points_grade ON knowledge_points(grade_level);
 CREATE INDEX idx_knowledge_points_active ON knowledge_points(is_active);
 
+CREATE TABLE knowledge_point_prerequisites (
+    knowledge_point_id BIGINT NOT NULL REFERENCES knowledge_points(id) ON DELETE CASCADE,
+    prerequisite_id BIGINT NOT NULL REFERENCES knowledge_points(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (knowledge_point_id, prerequisite_id),
+    CHECK (knowledge_point_id <> prerequisite_id)
+);
+CREATE INDEX idx_kp_prerequisites_prerequisite ON knowledge_point_prerequisites(prerequisite_id);
+
+CREATE TABLE knowledge_point_mastery_params (
+    knowledge_point_id BIGINT PRIMARY KEY REFERENCES knowledge_points(id) ON DELETE CASCADE,
+    p_init DECIMAL(5,4) NOT NULL CHECK (p_init > 0 AND p_init < 1),
+    p_learn DECIMAL(5,4) NOT NULL CHECK (p_learn > 0 AND p_learn < 1),
+    p_slip DECIMAL(5,4) NOT NULL CHECK (p_slip > 0 AND p_slip < 1),
+    p_guess DECIMAL(5,4) NOT NULL CHECK (p_guess > 0 AND p_guess < 1),
+    updated_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (p_slip + p_guess < 1)
+);
+
 CREATE TABLE questions (
     id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
     title VARCHAR(200) NOT NULL,
@@ -143,6 +236,7 @@ CREATE INDEX idx_questions_status ON questions(review_status);
 CREATE INDEX idx_questions_active ON questions(is_active);
 CREATE INDEX idx_questions_correct_rate ON questions(correct_rate);
 CREATE INDEX idx_questions_search ON questions USING gin(to_tsvector('simple', title || ' ' || content));
+CREATE INDEX idx_questions_author ON questions(author_id);
 
 CREATE TABLE question_knowledge_points (
     question_id BIGINT NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
@@ -152,6 +246,18 @@ CREATE TABLE question_knowledge_points (
 CREATE INDEX idx_qkp_question_id ON question_knowledge_points(question_id);
 CREATE INDEX idx_qkp_knowledge_point_id ON question_knowledge_points(knowledge_point_id);
 
+CREATE TABLE question_reviews (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    question_id BIGINT NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    reviewer_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    action review_action NOT NULL,
+    from_status review_status NOT NULL,
+    to_status review_status NOT NULL,
+    comment TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_question_reviews_question ON question_reviews(question_id, created_at);
+
 -- ============================================
 -- 3. 学习行为记录表
 -- ============================================
@@ -191,12 +297,136 @@ CREATE TABLE learning_sessions (
     avg_difficulty DECIMAL(3,2) DEFAULT 0.00,
     session_type session_type DEFAULT 'practice',
     completion_status completion_status DEFAULT 'ongoing',
+    last_active_at TIMESTAMP WITH TIME ZONE,
+    paused_at TIMESTAMP WITH TIME ZONE,
+    paused_seconds INTEGER DEFAULT 0,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 CREATE INDEX idx_learning_sessions_session_id ON learning_sessions(session_id);
 CREATE INDEX idx_learning_sessions_user_time ON learning_sessions(user_id, start_time);
 CREATE INDEX idx_learning_sessions_status ON learning_sessions(completion_status);
+CREATE INDEX idx_learning_sessions_status_active ON learning_sessions(completion_status, last_active_at);
+
+CREATE TABLE user_knowledge_masteries (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    knowledge_point_id BIGINT NOT NULL REFERENCES knowledge_points(id) ON DELETE CASCADE,
+    mastery DECIMAL(5,4) NOT NULL DEFAULT 0 CHECK (mastery BETWEEN 0 AND 1),
+    attempt_count INTEGER NOT NULL DEFAULT 0,
+    correct_count INTEGER NOT NULL DEFAULT 0,
+    last_answered_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, knowledge_point_id)
+);
+CREATE INDEX idx_user_knowledge_masteries_kp ON user_knowledge_masteries(knowledge_point_id);
+
+CREATE TABLE review_items (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    question_id BIGINT NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    repetitions INTEGER NOT NULL DEFAULT 0,
+    interval_days INTEGER NOT NULL DEFAULT 1 CHECK (interval_days > 0),
+    ease_factor DECIMAL(4,2) NOT NULL DEFAULT 2.50 CHECK (ease_factor >= 1.30),
+    due_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    review_count INTEGER NOT NULL DEFAULT 0,
+    lapse_count INTEGER NOT NULL DEFAULT 0,
+    last_reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, question_id)
+);
+CREATE INDEX idx_review_items_user_due ON review_items(user_id, due_at);
+CREATE INDEX idx_review_items_question ON review_items(question_id);
+
+CREATE TABLE notebook_resolutions (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    question_id BIGINT NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    resolved_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (user_id, question_id)
+);
+
+CREATE TABLE notebook_practice_questions (
+    session_id VARCHAR(64) NOT NULL REFERENCES learning_sessions(session_id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    question_id BIGINT NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    PRIMARY KEY (session_id, position),
+    UNIQUE(session_id, question_id)
+);
+
+CREATE TABLE assignments (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    class_id BIGINT NOT NULL REFERENCES classes(id) ON DELETE CASCADE,
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    title VARCHAR(200) NOT NULL,
+    description TEXT,
+    open_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    due_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    max_attempts INTEGER NOT NULL DEFAULT 1 CHECK (max_attempts >= 1),
+    show_answers BOOLEAN NOT NULL DEFAULT FALSE,
+    allow_late BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (due_at > open_at)
+);
+CREATE INDEX idx_assignments_class_due ON assignments(class_id, due_at);
+
+CREATE TABLE assignment_questions (
+    assignment_id BIGINT NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    question_id BIGINT NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    points INTEGER NOT NULL DEFAULT 1 CHECK (points > 0),
+    PRIMARY KEY (assignment_id, question_id)
+);
+CREATE INDEX idx_assignment_questions_question ON assignment_questions(question_id);
+
+CREATE TABLE assignment_attempts (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    assignment_id BIGINT NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    attempt_no INTEGER NOT NULL,
+    session_id VARCHAR(64) NOT NULL UNIQUE,
+    session_ids JSONB NOT NULL DEFAULT '[]',
+    status assignment_attempt_status NOT NULL DEFAULT 'in_progress',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    submitted_at TIMESTAMP WITH TIME ZONE,
+    is_late BOOLEAN NOT NULL DEFAULT FALSE,
+    answered_count INTEGER NOT NULL DEFAULT 0,
+    correct_count INTEGER NOT NULL DEFAULT 0,
+    score DECIMAL(5,2) NOT NULL DEFAULT 0.00,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(assignment_id, user_id, attempt_no)
+);
+CREATE INDEX idx_assignment_attempts_user ON assignment_attempts(user_id, status);
+CREATE UNIQUE INDEX idx_assignment_attempts_open ON assignment_attempts(assignment_id, user_id) WHERE status = 'in_progress';
+
+CREATE TABLE exam_sessions (
+    session_id VARCHAR(64) PRIMARY KEY REFERENCES learning_sessions(session_id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    time_limit_seconds INTEGER NOT NULL CHECK (time_limit_seconds > 0),
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    deadline_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    shuffle_seed BIGINT,
+    status exam_status NOT NULL DEFAULT 'in_progress',
+    submitted_at TIMESTAMP WITH TIME ZONE,
+    question_count INTEGER NOT NULL DEFAULT 0,
+    answered_count INTEGER NOT NULL DEFAULT 0,
+    correct_count INTEGER NOT NULL DEFAULT 0,
+    score DECIMAL(5,2) NOT NULL DEFAULT 0.00,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_exam_sessions_user ON exam_sessions(user_id, started_at);
+CREATE INDEX idx_exam_sessions_status_deadline ON exam_sessions(status, deadline_at);
+
+CREATE TABLE exam_questions (
+    session_id VARCHAR(64) NOT NULL REFERENCES exam_sessions(session_id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    question_id BIGINT NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    PRIMARY KEY (session_id, position),
+    UNIQUE(session_id, question_id)
+);
+CREATE INDEX idx_exam_questions_question ON exam_questions(question_id);
 
 -- ============================================
 -- 4. AI系统相关表（MVP仅保留难度调节相关）
@@ -243,6 +473,8 @@ CREATE TABLE rewards (
     description TEXT,
     reward_type reward_type DEFAULT 'points',
     value INTEGER DEFAULT 0,
+    -- 徽章的获得条件，如 {"all": [{"stat": "max_streak_days", "op": ">=", "value": 30}]}，为空时不自动发放
+    criteria JSONB,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
@@ -251,6 +483,7 @@ CREATE TABLE tasks (
     name VARCHAR(100) NOT NULL,
     description TEXT,
     type task_type NOT NULL DEFAULT 'daily',
+    goal JSONB NOT NULL DEFAULT '{}',
     reward_id BIGINT REFERENCES rewards(id),
     is_active BOOLEAN DEFAULT TRUE,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
@@ -260,11 +493,14 @@ CREATE TABLE user_tasks (
     id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
     user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    period_start DATE NOT NULL,
     status task_status NOT NULL DEFAULT 'pending',
     progress INTEGER DEFAULT 0,
     completed_at TIMESTAMP WITH TIME ZONE,
+    claimed_at TIMESTAMP WITH TIME ZONE,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    UNIQUE(user_id, task_id)
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, task_id, period_start)
 );
 
 CREATE TABLE user_rewards (
@@ -274,6 +510,35 @@ CREATE TABLE user_rewards (
     obtained_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
+-- 站内通知 (获得徽章等)，同时通过 Redis 频道 notifications:{user_id} 推送
+CREATE TABLE notifications (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    title VARCHAR(200) NOT NULL,
+    content TEXT,
+    data JSONB DEFAULT '{}',
+    read_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_notifications_user ON notifications(user_id, id DESC);
+CREATE INDEX idx_notifications_unread ON notifications(user_id) WHERE read_at IS NULL;
+
+-- 积分账本：只追加，余额由流水推导 (balance_after 为写入后的余额)
+CREATE TABLE points_transactions (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount INTEGER NOT NULL CHECK (amount <> 0),
+    balance_after INTEGER NOT NULL CHECK (balance_after >= 0),
+    reason points_reason NOT NULL,
+    reference VARCHAR(100),
+    note TEXT DEFAULT '',
+    operator_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_points_transactions_user ON points_transactions(user_id, id DESC);
+CREATE UNIQUE INDEX idx_points_transactions_reference ON points_transactions(user_id, reason, reference) WHERE reference IS NOT NULL;
+
 -- 为游戏化系统表添加必要的索引（MVP必需）
 CREATE INDEX idx_tasks_reward_id ON tasks(reward_id);
 CREATE INDEX idx_user_tasks_user_id ON user_tasks(user_id);
@@ -299,10 +564,38 @@ CREATE TRIGGER update_user_profiles_updated_at BEFORE UPDATE ON user_profiles
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 CREATE TRIGGER update_knowledge_points_updated_at BEFORE UPDATE ON knowledge_points 
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_knowledge_point_mastery_params_updated_at BEFORE UPDATE ON knowledge_point_mastery_params 
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 CREATE TRIGGER update_questions_updated_at BEFORE UPDATE ON questions 
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 CREATE TRIGGER update_learning_sessions_updated_at BEFORE UPDATE ON learning_sessions 
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_user_knowledge_masteries_updated_at BEFORE UPDATE ON user_knowledge_masteries 
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_review_items_updated_at BEFORE UPDATE ON review_items 
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_user_tasks_updated_at BEFORE UPDATE ON user_tasks 
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- 积分账本只允许追加；删除用户时由外键引发的级联删除与置空 (触发器嵌套深度大于1) 除外
+CREATE OR REPLACE FUNCTION forbid_points_transaction_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF pg_trigger_depth() > 1 THEN
+        RETURN COALESCE(NEW, OLD);
+    END IF;
+    RAISE EXCEPTION 'points_transactions is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER points_transactions_append_only BEFORE UPDATE OR DELETE ON points_transactions
+    FOR EACH ROW EXECUTE FUNCTION forbid_points_transaction_change();
+CREATE TRIGGER update_assignments_updated_at BEFORE UPDATE ON assignments 
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_assignment_attempts_updated_at BEFORE UPDATE ON assignment_attempts 
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_exam_sessions_updated_at BEFORE UPDATE ON exam_sessions 
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 CREATE TRIGGER update_system_configs_updated_at BEFORE UPDATE ON system_configs 
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 
@@ -312,7 +605,11 @@ CREATE TRIGGER update_system_configs_updated_at BEFORE UPDATE ON system_configs
 INSERT INTO knowledge_points (name, code, grade_level, description) VALUES ('加法', 'MATH_ADD', 1, '一年级加法基础');
 INSERT INTO system_configs (config_key, config_value, description) VALUES ('default_avatar', 'default.png', '默认头像');
 INSERT INTO rewards (name, reward_type, value) VALUES ('新手奖励', 'points', 100);
-INSERT INTO tasks (name, description, reward_id) VALUES ('每日答题', '每天完成5道题目', 1);
+INSERT INTO rewards (name, description, reward_type, criteria) VALUES
+    ('坚持不懈', '连续学习30天', 'badge', '{"all": [{"stat": "max_streak_days", "op": ">=", "value": 30}]}'),
+    ('加法达人', '在加法知识点累计答对100道题', 'badge', '{"all": [{"stat": "correct_answers", "knowledge_point": "MATH_ADD", "op": ">=", "value": 100}]}'),
+    ('初露锋芒', '第一次在考试中获得满分', 'badge', '{"all": [{"stat": "perfect_exams", "op": ">=", "value": 1}]}');
+INSERT INTO tasks (name, description, goal, reward_id) VALUES ('每日答题', '每天完成5道题目', '{"metric": "answers", "target": 5}', 1);
 INSERT INTO users (username, email, password_hash, nickname, role) VALUES ('admin', 'admin@example.com', 'HASHED_PASSWORD', '管理员', 'admin');
 
 -- ============================================
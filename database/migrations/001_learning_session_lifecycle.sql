@@ -0,0 +1,11 @@
+-- ============================================
+-- 001 学习会话生命周期 (暂停/恢复/空闲中断)
+-- ============================================
+
+ALTER TYPE completion_status ADD VALUE IF NOT EXISTS 'paused';
+
+ALTER TABLE learning_sessions ADD COLUMN IF NOT EXISTS last_active_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE learning_sessions ADD COLUMN IF NOT EXISTS paused_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE learning_sessions ADD COLUMN IF NOT EXISTS paused_seconds INTEGER DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_learning_sessions_status_active ON learning_sessions(completion_status, last_active_at);
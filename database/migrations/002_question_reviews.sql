@@ -0,0 +1,22 @@
+-- ============================================
+-- 002 题目审核流程 (审核记录与审核意见)
+-- ============================================
+
+DO $$ BEGIN
+    CREATE TYPE review_action AS ENUM ('submit', 'approve', 'reject', 'revise');
+EXCEPTION
+    WHEN duplicate_object THEN NULL;
+END $$;
+
+CREATE TABLE IF NOT EXISTS question_reviews (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    question_id BIGINT NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    reviewer_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    action review_action NOT NULL,
+    from_status review_status NOT NULL,
+    to_status review_status NOT NULL,
+    comment TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_question_reviews_question ON question_reviews(question_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_questions_author ON questions(author_id);
@@ -0,0 +1,15 @@
+-- ============================================
+-- 003 邮箱验证
+-- ============================================
+
+-- 新增字段时将已有用户视为已验证，避免上线后现有管理员和教师被挡在需要验证邮箱的功能之外
+DO $$ BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'users' AND column_name = 'email_verified'
+    ) THEN
+        ALTER TABLE users ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT FALSE;
+        ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP WITH TIME ZONE;
+        UPDATE users SET email_verified = TRUE, email_verified_at = created_at;
+    END IF;
+END $$;
@@ -0,0 +1,22 @@
+-- ============================================
+-- 004 登录防暴力破解 (锁定与解锁审计日志)
+-- ============================================
+
+DO $$ BEGIN
+    CREATE TYPE auth_audit_event AS ENUM ('account_locked', 'ip_locked', 'account_unlocked');
+EXCEPTION
+    WHEN duplicate_object THEN NULL;
+END $$;
+
+CREATE TABLE IF NOT EXISTS auth_audit_logs (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    username VARCHAR(50),
+    event auth_audit_event NOT NULL,
+    ip_address INET,
+    operator_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    detail JSONB DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_auth_audit_logs_user ON auth_audit_logs(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_auth_audit_logs_created_at ON auth_audit_logs(created_at);
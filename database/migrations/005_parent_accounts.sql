@@ -0,0 +1,23 @@
+-- ============================================
+-- 005 家长账号 (家长与孩子关联、家长管控设置)
+-- ============================================
+
+ALTER TYPE user_role ADD VALUE IF NOT EXISTS 'parent';
+
+CREATE TABLE IF NOT EXISTS parent_child_links (
+    parent_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    child_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (parent_id, child_id)
+);
+CREATE INDEX IF NOT EXISTS idx_parent_child_links_child ON parent_child_links(child_id);
+
+-- daily_limit_minutes 为 0 表示不限制每日学习时长
+CREATE TABLE IF NOT EXISTS parental_controls (
+    child_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    daily_limit_minutes INTEGER NOT NULL DEFAULT 0 CHECK (daily_limit_minutes BETWEEN 0 AND 1440),
+    allowed_session_types JSONB NOT NULL DEFAULT '["practice", "test", "challenge"]',
+    updated_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
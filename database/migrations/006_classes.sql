@@ -0,0 +1,24 @@
+-- ============================================
+-- 006 教师班级 (班级、班级成员与加入码)
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS classes (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    grade_level INTEGER NOT NULL CHECK (grade_level BETWEEN 1 AND 12),
+    teacher_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    join_code VARCHAR(8) NOT NULL UNIQUE,
+    is_archived BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_classes_teacher ON classes(teacher_id);
+
+CREATE TABLE IF NOT EXISTS class_members (
+    class_id BIGINT NOT NULL REFERENCES classes(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    joined_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (class_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_class_members_user ON class_members(user_id);
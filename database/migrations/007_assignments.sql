@@ -0,0 +1,69 @@
+-- ============================================
+-- 007 班级作业 (题目集合、开放/截止时间、作答次数与迟交)
+-- ============================================
+
+ALTER TYPE session_type ADD VALUE IF NOT EXISTS 'homework';
+
+DO $$ BEGIN
+    CREATE TYPE assignment_attempt_status AS ENUM ('in_progress', 'submitted');
+EXCEPTION
+    WHEN duplicate_object THEN NULL;
+END $$;
+
+-- show_answers: 学生提交后是否可以查看答案与解析
+-- allow_late: 截止后是否仍允许作答并迟交
+CREATE TABLE IF NOT EXISTS assignments (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    class_id BIGINT NOT NULL REFERENCES classes(id) ON DELETE CASCADE,
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    title VARCHAR(200) NOT NULL,
+    description TEXT,
+    open_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    due_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    max_attempts INTEGER NOT NULL DEFAULT 1 CHECK (max_attempts >= 1),
+    show_answers BOOLEAN NOT NULL DEFAULT FALSE,
+    allow_late BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (due_at > open_at)
+);
+CREATE INDEX IF NOT EXISTS idx_assignments_class_due ON assignments(class_id, due_at);
+
+CREATE TABLE IF NOT EXISTS assignment_questions (
+    assignment_id BIGINT NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    question_id BIGINT NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    points INTEGER NOT NULL DEFAULT 1 CHECK (points > 0),
+    PRIMARY KEY (assignment_id, question_id)
+);
+CREATE INDEX IF NOT EXISTS idx_assignment_questions_question ON assignment_questions(question_id);
+
+-- 每次作答对应一个 homework 类型的学习会话，会话中断后继续作答会创建新会话，
+-- session_id 为当前会话，session_ids 记录本次作答用过的全部会话，判分以这些会话中的答题记录为准
+CREATE TABLE IF NOT EXISTS assignment_attempts (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    assignment_id BIGINT NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    attempt_no INTEGER NOT NULL,
+    session_id VARCHAR(64) NOT NULL UNIQUE,
+    session_ids JSONB NOT NULL DEFAULT '[]',
+    status assignment_attempt_status NOT NULL DEFAULT 'in_progress',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    submitted_at TIMESTAMP WITH TIME ZONE,
+    is_late BOOLEAN NOT NULL DEFAULT FALSE,
+    answered_count INTEGER NOT NULL DEFAULT 0,
+    correct_count INTEGER NOT NULL DEFAULT 0,
+    score DECIMAL(5,2) NOT NULL DEFAULT 0.00,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(assignment_id, user_id, attempt_no)
+);
+CREATE INDEX IF NOT EXISTS idx_assignment_attempts_user ON assignment_attempts(user_id, status);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_assignment_attempts_open ON assignment_attempts(assignment_id, user_id) WHERE status = 'in_progress';
+
+DROP TRIGGER IF EXISTS update_assignments_updated_at ON assignments;
+CREATE TRIGGER update_assignments_updated_at BEFORE UPDATE ON assignments
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+DROP TRIGGER IF EXISTS update_assignment_attempts_updated_at ON assignment_attempts;
+CREATE TRIGGER update_assignment_attempts_updated_at BEFORE UPDATE ON assignment_attempts
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,45 @@
+-- ============================================
+-- 008 限时考试 (服务端倒计时、固定题序、超时自动交卷)
+-- ============================================
+
+DO $$ BEGIN
+    CREATE TYPE exam_status AS ENUM ('in_progress', 'submitted', 'expired');
+EXCEPTION
+    WHEN duplicate_object THEN NULL;
+END $$;
+
+-- 每场考试对应一个 test 类型的学习会话
+-- deadline_at 为服务端计算的截止时间，之后的答题被拒绝并自动交卷 (status = 'expired')
+-- shuffle_seed 记录打乱题序所用的随机种子，未打乱时为空
+CREATE TABLE IF NOT EXISTS exam_sessions (
+    session_id VARCHAR(64) PRIMARY KEY REFERENCES learning_sessions(session_id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    time_limit_seconds INTEGER NOT NULL CHECK (time_limit_seconds > 0),
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    deadline_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    shuffle_seed BIGINT,
+    status exam_status NOT NULL DEFAULT 'in_progress',
+    submitted_at TIMESTAMP WITH TIME ZONE,
+    question_count INTEGER NOT NULL DEFAULT 0,
+    answered_count INTEGER NOT NULL DEFAULT 0,
+    correct_count INTEGER NOT NULL DEFAULT 0,
+    score DECIMAL(5,2) NOT NULL DEFAULT 0.00,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_exam_sessions_user ON exam_sessions(user_id, started_at);
+CREATE INDEX IF NOT EXISTS idx_exam_sessions_status_deadline ON exam_sessions(status, deadline_at);
+
+-- 试卷题目，position 为开考时固定下来的题序 (从1开始)
+CREATE TABLE IF NOT EXISTS exam_questions (
+    session_id VARCHAR(64) NOT NULL REFERENCES exam_sessions(session_id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    question_id BIGINT NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    PRIMARY KEY (session_id, position),
+    UNIQUE(session_id, question_id)
+);
+CREATE INDEX IF NOT EXISTS idx_exam_questions_question ON exam_questions(question_id);
+
+DROP TRIGGER IF EXISTS update_exam_sessions_updated_at ON exam_sessions;
+CREATE TRIGGER update_exam_sessions_updated_at BEFORE UPDATE ON exam_sessions
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
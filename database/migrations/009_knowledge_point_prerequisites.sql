@@ -0,0 +1,14 @@
+-- ============================================
+-- 009 知识点先修关系 (独立于父子层级的有向无环图)
+-- ============================================
+
+-- 一条边表示 knowledge_point_id 需要先掌握 prerequisite_id
+-- 成环校验在应用层写入时完成
+CREATE TABLE IF NOT EXISTS knowledge_point_prerequisites (
+    knowledge_point_id BIGINT NOT NULL REFERENCES knowledge_points(id) ON DELETE CASCADE,
+    prerequisite_id BIGINT NOT NULL REFERENCES knowledge_points(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (knowledge_point_id, prerequisite_id),
+    CHECK (knowledge_point_id <> prerequisite_id)
+);
+CREATE INDEX IF NOT EXISTS idx_kp_prerequisites_prerequisite ON knowledge_point_prerequisites(prerequisite_id);
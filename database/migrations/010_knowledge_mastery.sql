@@ -0,0 +1,40 @@
+-- ============================================
+-- 010 知识点掌握度 (贝叶斯知识追踪 BKT)
+-- ============================================
+
+-- 知识点的BKT参数，未配置的知识点使用 mastery 配置中的默认值
+-- p_slip + p_guess < 1，保证答对总是提高掌握概率
+CREATE TABLE IF NOT EXISTS knowledge_point_mastery_params (
+    knowledge_point_id BIGINT PRIMARY KEY REFERENCES knowledge_points(id) ON DELETE CASCADE,
+    p_init DECIMAL(5,4) NOT NULL CHECK (p_init > 0 AND p_init < 1),
+    p_learn DECIMAL(5,4) NOT NULL CHECK (p_learn > 0 AND p_learn < 1),
+    p_slip DECIMAL(5,4) NOT NULL CHECK (p_slip > 0 AND p_slip < 1),
+    p_guess DECIMAL(5,4) NOT NULL CHECK (p_guess > 0 AND p_guess < 1),
+    updated_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (p_slip + p_guess < 1)
+);
+
+-- 用户在每个知识点上的掌握概率，每次答题后通过 question_knowledge_points 更新
+-- last_answered_at 为已计入的最近一次答题时间，用于避免重建任务与在线更新重复计入同一次答题
+CREATE TABLE IF NOT EXISTS user_knowledge_masteries (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    knowledge_point_id BIGINT NOT NULL REFERENCES knowledge_points(id) ON DELETE CASCADE,
+    mastery DECIMAL(5,4) NOT NULL DEFAULT 0 CHECK (mastery BETWEEN 0 AND 1),
+    attempt_count INTEGER NOT NULL DEFAULT 0,
+    correct_count INTEGER NOT NULL DEFAULT 0,
+    last_answered_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, knowledge_point_id)
+);
+CREATE INDEX IF NOT EXISTS idx_user_knowledge_masteries_kp ON user_knowledge_masteries(knowledge_point_id);
+
+DROP TRIGGER IF EXISTS update_knowledge_point_mastery_params_updated_at ON knowledge_point_mastery_params;
+CREATE TRIGGER update_knowledge_point_mastery_params_updated_at BEFORE UPDATE ON knowledge_point_mastery_params
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_user_knowledge_masteries_updated_at ON user_knowledge_masteries;
+CREATE TRIGGER update_user_knowledge_masteries_updated_at BEFORE UPDATE ON user_knowledge_masteries
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,26 @@
+-- ============================================
+-- 011 错题间隔复习队列 (SM-2)
+-- ============================================
+
+-- 用户答错的题目进入复习队列，之后每次再作答都按 SM-2 重新安排 due_at
+-- last_reviewed_at 为已计入的最近一次作答时间，用于避免重复计入同一次作答
+CREATE TABLE IF NOT EXISTS review_items (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    question_id BIGINT NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    repetitions INTEGER NOT NULL DEFAULT 0,
+    interval_days INTEGER NOT NULL DEFAULT 1 CHECK (interval_days > 0),
+    ease_factor DECIMAL(4,2) NOT NULL DEFAULT 2.50 CHECK (ease_factor >= 1.30),
+    due_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    review_count INTEGER NOT NULL DEFAULT 0,
+    lapse_count INTEGER NOT NULL DEFAULT 0,
+    last_reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, question_id)
+);
+CREATE INDEX IF NOT EXISTS idx_review_items_user_due ON review_items(user_id, due_at);
+CREATE INDEX IF NOT EXISTS idx_review_items_question ON review_items(question_id);
+
+DROP TRIGGER IF EXISTS update_review_items_updated_at ON review_items;
+CREATE TRIGGER update_review_items_updated_at BEFORE UPDATE ON review_items
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
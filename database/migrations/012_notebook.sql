@@ -0,0 +1,21 @@
+-- ============================================
+-- 012 错题本 (标记已掌握、错题练习)
+-- ============================================
+
+-- 错题本条目由 answer_records 实时汇总，这里只记录学生的已掌握标记
+-- resolved_at 早于该题最近一次答错时间的标记视为失效，题目重新出现在错题本中
+CREATE TABLE IF NOT EXISTS notebook_resolutions (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    question_id BIGINT NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    resolved_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (user_id, question_id)
+);
+
+-- 由错题本生成的练习会话 (practice 类型) 的题目，position 为生成时固定的题序 (从1开始)
+CREATE TABLE IF NOT EXISTS notebook_practice_questions (
+    session_id VARCHAR(64) NOT NULL REFERENCES learning_sessions(session_id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    question_id BIGINT NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    PRIMARY KEY (session_id, position),
+    UNIQUE(session_id, question_id)
+);
@@ -0,0 +1,23 @@
+-- ============================================
+-- 013 每日/每周任务引擎 (任务目标、按周期记录进度、领取奖励)
+-- ============================================
+
+-- 任务目标，如 {"metric": "answers", "target": 5}
+-- 或 {"metric": "correct_answers", "target": 3, "knowledge_point": "MATH_FRACTION"}
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS goal JSONB NOT NULL DEFAULT '{}';
+UPDATE tasks SET goal = '{"metric": "answers", "target": 5}' WHERE name = '每日答题' AND goal = '{}';
+
+-- 每日/每周任务每个周期一条进度记录，周期按服务器时区的零点切换；成就任务的周期固定为 1970-01-01
+-- 已有记录归入迁移当天的周期
+ALTER TABLE user_tasks ADD COLUMN IF NOT EXISTS period_start DATE NOT NULL DEFAULT CURRENT_DATE;
+ALTER TABLE user_tasks ALTER COLUMN period_start DROP DEFAULT;
+ALTER TABLE user_tasks ADD COLUMN IF NOT EXISTS claimed_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE user_tasks ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+
+ALTER TABLE user_tasks DROP CONSTRAINT IF EXISTS user_tasks_user_id_task_id_key;
+ALTER TABLE user_tasks DROP CONSTRAINT IF EXISTS user_tasks_user_id_task_id_period_start_key;
+ALTER TABLE user_tasks ADD CONSTRAINT user_tasks_user_id_task_id_period_start_key UNIQUE (user_id, task_id, period_start);
+
+DROP TRIGGER IF EXISTS update_user_tasks_updated_at ON user_tasks;
+CREATE TRIGGER update_user_tasks_updated_at BEFORE UPDATE ON user_tasks
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,41 @@
+-- ============================================
+-- 014 积分钱包与账本
+-- ============================================
+
+DO $$ BEGIN
+    CREATE TYPE points_reason AS ENUM ('task_claim', 'streak_bonus', 'admin_grant', 'purchase');
+EXCEPTION
+    WHEN duplicate_object THEN NULL;
+END $$;
+
+-- 积分账本：只追加，余额由流水推导 (balance_after 为写入后的余额)
+-- reference 为业务来源标识 (如 user_task:42)，同一用户同一原因下唯一，防止重复入账
+CREATE TABLE IF NOT EXISTS points_transactions (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount INTEGER NOT NULL CHECK (amount <> 0),
+    balance_after INTEGER NOT NULL CHECK (balance_after >= 0),
+    reason points_reason NOT NULL,
+    reference VARCHAR(100),
+    note TEXT DEFAULT '',
+    operator_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_points_transactions_user ON points_transactions(user_id, id DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_points_transactions_reference
+    ON points_transactions(user_id, reason, reference) WHERE reference IS NOT NULL;
+
+-- 积分账本只允许追加；删除用户时由外键引发的级联删除与置空 (触发器嵌套深度大于1) 除外
+CREATE OR REPLACE FUNCTION forbid_points_transaction_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF pg_trigger_depth() > 1 THEN
+        RETURN COALESCE(NEW, OLD);
+    END IF;
+    RAISE EXCEPTION 'points_transactions is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS points_transactions_append_only ON points_transactions;
+CREATE TRIGGER points_transactions_append_only BEFORE UPDATE OR DELETE ON points_transactions
+    FOR EACH ROW EXECUTE FUNCTION forbid_points_transaction_change();
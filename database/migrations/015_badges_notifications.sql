@@ -0,0 +1,30 @@
+-- ============================================
+-- 015 成就徽章 (规则以数据形式声明) 与站内通知
+-- ============================================
+
+-- 徽章的获得条件，如 {"all": [{"stat": "max_streak_days", "op": ">=", "value": 30}]}，为空时不自动发放
+ALTER TABLE rewards ADD COLUMN IF NOT EXISTS criteria JSONB;
+
+-- 站内通知 (获得徽章等)，同时通过 Redis 频道 notifications:{user_id} 推送
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    title VARCHAR(200) NOT NULL,
+    content TEXT,
+    data JSONB DEFAULT '{}',
+    read_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(user_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications(user_id) WHERE read_at IS NULL;
+
+-- 默认徽章，已有用户运行 go run ./cmd/backfill-badges 补发
+INSERT INTO rewards (name, description, reward_type, criteria)
+SELECT v.name, v.description, 'badge', v.criteria::jsonb
+FROM (VALUES
+    ('坚持不懈', '连续学习30天', '{"all": [{"stat": "max_streak_days", "op": ">=", "value": 30}]}'),
+    ('加法达人', '在加法知识点累计答对100道题', '{"all": [{"stat": "correct_answers", "knowledge_point": "MATH_ADD", "op": ">=", "value": 100}]}'),
+    ('初露锋芒', '第一次在考试中获得满分', '{"all": [{"stat": "perfect_exams", "op": ">=", "value": 1}]}')
+) AS v(name, description, criteria)
+WHERE NOT EXISTS (SELECT 1 FROM rewards r WHERE r.name = v.name AND r.reward_type = 'badge');
@@ -0,0 +1,21 @@
+-- ============================================
+-- 016 连续学习天数与等级成长 (用户时区、学习日活动、连续学习保护卡)
+-- ============================================
+
+-- IANA时区名，为空时使用默认时区 (progression.default_timezone) 划分学习日
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT '';
+
+-- total_study_time 为累计有效学习时长 (秒)，由学习会话结束时累加
+ALTER TABLE user_profiles ADD COLUMN IF NOT EXISTS last_active_date DATE;
+ALTER TABLE user_profiles ADD COLUMN IF NOT EXISTS streak_freezes INTEGER DEFAULT 0 CHECK (streak_freezes >= 0);
+
+-- 每个学习日 (用户时区) 的学习活动，frozen 表示该日由连续学习保护卡保住了连续天数
+CREATE TABLE IF NOT EXISTS user_activity_days (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    activity_date DATE NOT NULL,
+    answers INTEGER DEFAULT 0,
+    study_seconds INTEGER DEFAULT 0,
+    xp INTEGER DEFAULT 0,
+    frozen BOOLEAN DEFAULT FALSE,
+    PRIMARY KEY (user_id, activity_date)
+);